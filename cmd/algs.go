@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/solve"
+)
+
+// algsCommand prints every algorithm up to a length over a move set with an
+// effect, or completing a mask from a case, one per line.
+func algsCommand(args []string) error {
+	flags := newFlagSet("algs")
+	effect := flags.String("effect", "", "moves whose effect the algorithms must have, such as a known algorithm")
+	setup := flags.String("case", "", "moves setting up the case to solve, with -mask")
+	maskExpr := flags.String("mask", "", "partial state to complete from the case, as for solve.ParseMask, such as \"oll\" or \"solved\"")
+	moveSet := flags.String("gen", "", "move set of the algorithms, such as <R,U>, instead of all face turns")
+	maxMoves := flags.Int("max-length", 8, "longest algorithm")
+	limit := flags.Int("limit", 0, "algorithms to print, or 0 for all")
+	cost := flags.String("cost", settings.String("cost", ""), "order the algorithms by the cost of executing them, as for solve, rather than by length")
+	symmetric := flags.Bool("symmetric", false, "print one of the algorithms that a symmetry of the cube turns into each other")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	opts := solve.AlgOptions{MaxMoves: *maxMoves, Limit: *limit, Symmetric: *symmetric}
+	if *cost != "" {
+		model, err := costModel(*cost)
+		if err != nil {
+			return err
+		}
+		opts.Cost = model.Weighted
+	}
+	if *moveSet != "" {
+		ops, err := cube.ParseMoveSet(*moveSet)
+		if err != nil {
+			return err
+		}
+		if opts.Generators, err = solve.Subset(ops); err != nil {
+			return err
+		}
+	}
+	if *effect != "" {
+		moves, err := parseMoves(*effect)
+		if err != nil {
+			return err
+		}
+		s, err := solve.StateOf(moves...)
+		if err != nil {
+			return err
+		}
+		opts.Effect = &s
+	} else if *maskExpr != "" {
+		mask, err := solve.ParseMask(*maskExpr)
+		if err != nil {
+			return err
+		}
+		moves, err := parseMoves(*setup)
+		if err != nil {
+			return err
+		}
+		if opts.Case, err = solve.StateOf(moves...); err != nil {
+			return err
+		}
+		opts.Mask = mask
+	}
+
+	algs, err := solve.GenerateAlgs(opts)
+	if err != nil {
+		return err
+	}
+	for _, alg := range algs {
+		fmt.Println(cube.FormatMoves(alg))
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/sheet"
+	"github.com/larssont/go-cubic/pkg/solve"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// batch writes a grid of the cubes of scrambles read one per line, such as a
+// practice packet.
+func batch(args []string) error {
+	flags := newFlagSet("batch")
+	size := flags.Int("size", settings.Int("size", 3), "size of the cubes")
+	in := flags.String("in", "", "file of scrambles, one per line, instead of standard input")
+	out := flags.String("out", path.Join(outPath, "batch.html"), "HTML file to write")
+	title := flags.String("title", "", "title of every page")
+	columns := flags.Int("columns", 3, "cubes in a row")
+	perPage := flags.Int("per-page", 12, "cubes on a page")
+	schemeName := flags.String("scheme", settings.String("scheme", "western"), "color scheme, as for the cube")
+	optimal := flags.Int("optimal", 0, "note the optimal length of 3x3 scrambles solving in at most this many moves, and the two-phase length of the others")
+	patterns := flags.Bool("patterns", patternsTheme(), "mark the colors with shapes")
+	qrName := flags.String("qr", "none", "QR code on every cube: none, scramble, or link to alg.cubing.net")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	scheme, err := cube.LookupScheme(*schemeName)
+	if err != nil {
+		return err
+	}
+	scheme.Patterns = *patterns
+	qr, err := sheet.ParseQRContent(*qrName)
+	if err != nil {
+		return err
+	}
+	var r io.Reader = os.Stdin
+	if *in != "" {
+		f, err := os.Open(*in)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var cells []sheet.Cell
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		group, err := notation().ParseNotation(text)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		moves, err := group.Expand()
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		cell := sheet.Cell{Size: *size, Moves: moves}
+		if *size == 3 && *optimal > 0 {
+			cell.Note = lengthNote(moves, *optimal)
+		}
+		cells = append(cells, cell)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	file, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return sheet.RenderGrid(file, cells, sheet.GridOptions{
+		Options: sheet.Options{Title: *title, Scheme: scheme, QR: qr},
+		Columns: *columns,
+		PerPage: *perPage,
+	})
+}
+
+// lengthNote returns the optimal length of the solution of a 3x3 scramble if
+// it is at most max, and else the length of a two-phase solution, or nothing
+// for scrambles that turn inner layers.
+func lengthNote(moves []cube.Move, max int) string {
+	s, err := solve.StateOf(moves...)
+	if err != nil {
+		return ""
+	}
+	solution, err := solve.SolveWithin(s.Reoriented(), max, solve.SolveOptions{Timeout: 2 * time.Second})
+	if err == nil {
+		return fmt.Sprintf("%d moves, optimal", len(solution.Moves))
+	}
+	if !errors.Is(err, solve.ErrNoSolution) && !errors.Is(err, solve.ErrBudget) {
+		return ""
+	}
+	estimate, err := solve.Solve(s.Reoriented(), solve.SolveOptions{})
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d moves", len(estimate))
+}
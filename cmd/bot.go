@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/bot"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/daemon"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+)
+
+var errBotUsage = errors.New("usage: bot [flags] message, or bot -http addr")
+
+// botCommand answers a chat message such as "!cube R U R' U'" as a bot
+// would, printing the text and writing the image to outPath, or with -http
+// serves the slash commands of a Slack app at /slack and the images its
+// replies link to at /img/.
+func botCommand(args []string) error {
+	flags := newFlagSet("bot")
+	prefix := flags.String("prefix", bot.DefaultPrefix, "command of the messages to answer")
+	schemeName := flags.String("scheme", settings.String("scheme", "western"), "color scheme of the cubes")
+	scale := flags.Float64("scale", cube.DefaultPNGScale, "pixels a unit of the SVG drawings, halved until the image fits -max-image-bytes")
+	maxImage := flags.Int("max-image-bytes", 8<<20, "largest image to attach, such as the upload limit of the chat service")
+	maxText := flags.Int("max-text", 2000, "longest text of a reply in characters")
+	imageURL := flags.String("image-url", "", "address that the images of replies are served at by daemon -http or bot -http, such as https://cubes.example.com")
+	httpAddr := flags.String("http", "", "serve Slack slash commands at /slack and images at /img/ over HTTP on this address, such as :8081")
+	secret := flags.String("slack-secret", os.Getenv("SLACK_SIGNING_SECRET"), "signing secret of the Slack app, to check requests with, $SLACK_SIGNING_SECRET by default")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	scheme, err := cube.LookupScheme(*schemeName)
+	if err != nil {
+		return err
+	}
+	opts := bot.Options{
+		Prefix:        *prefix,
+		Scheme:        scheme,
+		Scale:         *scale,
+		MaxImageBytes: *maxImage,
+		MaxText:       *maxText,
+		ImageURL:      *imageURL,
+	}
+
+	if *httpAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/slack", &bot.SlackHandler{Options: opts, SigningSecret: *secret})
+		mux.Handle("/img/", daemon.NewImageHandler(0))
+		log.Printf("serving Slack commands on %s", *httpAddr)
+		return http.ListenAndServe(*httpAddr, mux)
+	}
+	if flags.NArg() == 0 {
+		return errBotUsage
+	}
+
+	// The prefix is optional on the command line.
+	message := strings.Join(flags.Args(), " ")
+	reply, ok, err := bot.Answer(message, opts)
+	if !ok {
+		reply, err = bot.Command(message, opts)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println(reply.Text)
+	if reply.ImageURL != "" {
+		fmt.Println(reply.ImageURL)
+	}
+	if reply.Image == nil {
+		return nil
+	}
+	if err := os.MkdirAll(outPath, 0o755); err != nil {
+		return err
+	}
+	file := path.Join(outPath, reply.Filename)
+	if err := os.WriteFile(file, reply.Image, 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("Image: %s\n", file)
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"github.com/larssont/go-cubic/pkg/algdb"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/sheet"
+	"os"
+	"path"
+)
+
+// casesSheet writes a sheet of every case of a set of an imported algorithm
+// database.
+func casesSheet(args []string) error {
+	flags := newFlagSet("cases")
+	db := flags.String("db", "", "algorithm database in CSV as set,case,alg")
+	set := flags.String("set", "OLL", "set of the cases, such as OLL, PLL or CMLL")
+	out := flags.String("out", path.Join(outPath, "cases.html"), "HTML file to write")
+	columns := flags.Int("columns", 3, "cases in a row")
+	schemeName := flags.String("scheme", settings.String("scheme", "western"), "color scheme, as for the cube")
+	patterns := flags.Bool("patterns", patternsTheme(), "mark the colors with shapes")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	scheme, err := cube.LookupScheme(*schemeName)
+	if err != nil {
+		return err
+	}
+	scheme.Patterns = *patterns
+
+	f, err := os.Open(*db)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	imported, err := algdb.Import(f)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return sheet.RenderCaseSheet(file, imported, *set, sheet.CaseOptions{Columns: *columns, Scheme: scheme})
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/analysis"
+	"github.com/larssont/go-cubic/pkg/config"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"os"
+)
+
+var errConfigUsage = errors.New("usage: config list | path | get key | set key value | unset key")
+
+// loadSettings loads the config file into settings.
+func loadSettings() error {
+	path, err := config.Path()
+	if err != nil {
+		return err
+	}
+	if settings, err = config.LoadFile(path); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	outPath = settings.String("out", outPath)
+	return nil
+}
+
+// notation returns the dialect of the moves given to commands.
+func notation() *cube.Dialect {
+	dialect := cube.DialectCube
+	if settings.String("dialect", "wca") == "timed" {
+		dialect = dialect.Timed()
+	}
+	if settings.String("input", "lenient") == "strict" {
+		dialect = dialect.Strict()
+	}
+	return dialect
+}
+
+// costModel returns the cost model of -cost: a registered one by name, the
+// table of a file, or a table given inline.
+func costModel(spec string) (analysis.CostModel, error) {
+	if table, err := os.ReadFile(spec); err == nil {
+		return analysis.ParseCostModel(string(table))
+	}
+	return analysis.LookupCostModel(spec)
+}
+
+// patternsTheme reports whether the config marks colors with shapes.
+func patternsTheme() bool {
+	return settings.String("theme", "color") == "patterns"
+}
+
+// configCommand lists, reads and changes the settings of the config file.
+func configCommand(args []string) error {
+	flags := newFlagSet("config")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	path, err := config.Path()
+	if err != nil {
+		return err
+	}
+
+	args = flags.Args()
+	if len(args) == 0 {
+		return errConfigUsage
+	}
+	switch {
+	case args[0] == "list" && len(args) == 1:
+		for _, k := range config.Keys {
+			value, ok := settings[k.Name]
+			if !ok {
+				value = "(unset)"
+			}
+			fmt.Printf("%-8s %-20s %s\n", k.Name, value, k.Usage)
+		}
+		return nil
+	case args[0] == "path" && len(args) == 1:
+		fmt.Println(path)
+		return nil
+	case args[0] == "get" && len(args) == 2:
+		value, ok := settings[args[1]]
+		if !ok {
+			// Unknown keys are errors, and known ones unset print nothing.
+			return settings.Unset(args[1])
+		}
+		fmt.Println(value)
+		return nil
+	case args[0] == "set" && len(args) == 3:
+		if err := settings.Set(args[1], args[2]); err != nil {
+			return err
+		}
+	case args[0] == "unset" && len(args) == 2:
+		if err := settings.Unset(args[1]); err != nil {
+			return err
+		}
+	default:
+		return errConfigUsage
+	}
+	return settings.WriteFile(path)
+}
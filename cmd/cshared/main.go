@@ -0,0 +1,125 @@
+// Command cshared is the C API of the engine, for applications in other
+// languages that embed it. Build it as a shared library with
+//
+//	go build -buildmode=c-shared -o libcubic.so ./cmd/cshared
+//
+// which also writes the header libcubic.h. Cube states are strings of sticker
+// colors, face by face in the order U L F R B D, as Cube.State returns them.
+//
+// Strings returned by the functions are allocated with malloc and released
+// with cubic_free. Functions that can fail take a char **err, which is set to
+// an error message to release with cubic_free, or to NULL, and return NULL on
+// failure.
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/solve"
+	"time"
+	"unsafe"
+)
+
+// APIVersion changes with every incompatible change of the C API.
+const APIVersion = 1
+
+var ErrFacelets = errors.New("facelets do not match the dimension")
+
+func main() {}
+
+// result sets err and returns s as C strings.
+func result(s string, e error, err **C.char) *C.char {
+	if err != nil {
+		*err = nil
+	}
+	if e != nil {
+		if err != nil {
+			*err = C.CString(e.Error())
+		}
+		return nil
+	}
+	return C.CString(s)
+}
+
+func moves(notation string) ([]cube.Move, error) {
+	group, err := cube.ParseNotation(notation)
+	if err != nil {
+		return nil, err
+	}
+	return group.Expand()
+}
+
+//export cubic_api_version
+func cubic_api_version() C.int {
+	return APIVersion
+}
+
+//export cubic_free
+func cubic_free(s *C.char) {
+	C.free(unsafe.Pointer(s))
+}
+
+// cubic_parse returns the expanded moves of the notation, such as "R U R U"
+// for "(R U)2".
+//
+//export cubic_parse
+func cubic_parse(notation *C.char, err **C.char) *C.char {
+	ms, e := moves(C.GoString(notation))
+	return result(cube.FormatMoves(ms), e, err)
+}
+
+// cubic_apply returns the state of a cube of the dimension after the moves,
+// starting from the state given by facelets, or from a solved cube for NULL
+// or an empty string.
+//
+//export cubic_apply
+func cubic_apply(dimension C.int, facelets, notation *C.char, err **C.char) *C.char {
+	s, e := apply(int(dimension), C.GoString(facelets), C.GoString(notation))
+	return result(s, e, err)
+}
+
+func apply(dimension int, facelets, notation string) (string, error) {
+	ms, err := moves(notation)
+	if err != nil {
+		return "", err
+	}
+	if facelets == "" {
+		c := cube.NewCube(dimension)
+		if err := c.ExecuteMoves(ms...); err != nil {
+			return "", err
+		}
+		return c.State(), nil
+	}
+
+	colors := []rune(facelets)
+	perm, err := cube.Permutation(dimension, ms...)
+	if err != nil {
+		return "", err
+	}
+	if len(colors) != len(perm) {
+		return "", ErrFacelets
+	}
+	out := make([]rune, len(perm))
+	for i, p := range perm {
+		out[i] = colors[p]
+	}
+	return string(out), nil
+}
+
+// cubic_solve returns a solution of a 3x3 state within timeout_ms
+// milliseconds, or two seconds for 0.
+//
+//export cubic_solve
+func cubic_solve(facelets *C.char, timeoutMs C.int, err **C.char) *C.char {
+	s, e := solve.ParseFacelets(C.GoString(facelets))
+	if e != nil {
+		return result("", e, err)
+	}
+	solution, e := solve.Solve(s, solve.SolveOptions{Timeout: time.Duration(timeoutMs) * time.Millisecond})
+	return result(cube.FormatMoves(solution), e, err)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"github.com/larssont/go-cubic/pkg/daemon"
+	"github.com/larssont/go-cubic/pkg/metrics"
+	"github.com/larssont/go-cubic/pkg/solve"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+)
+
+// daemonCommand serves the solvers and cubes over a unix socket until it is
+// interrupted, see package daemon for the protocol, and with -http images
+// and metrics over HTTP.
+func daemonCommand(args []string) error {
+	flags := newFlagSet("daemon")
+	socket := flags.String("socket", filepath.Join(os.TempDir(), "go-cubic.sock"), "unix socket to listen on")
+	httpAddr := flags.String("http", "", "also serve SVG and PNG images of cubes at /img/NxN.svg and /img/NxN.png, see daemon.ImageHandler, and Prometheus metrics at /metrics over HTTP on this address, such as :8080")
+	imageCache := flags.Int("image-cache", daemon.DefaultImageCache, "images that -http keeps rendered")
+	limits := daemon.DefaultLimits
+	flags.IntVar(&limits.MaxRequestBytes, "max-request-bytes", limits.MaxRequestBytes, "longest request line, or URL of an image, 0 for no limit")
+	flags.IntVar(&limits.MaxMoves, "max-moves", limits.MaxMoves, "longest algorithm once its groups are expanded, 0 for no limit")
+	flags.IntVar(&limits.MaxCubes, "max-cubes", limits.MaxCubes, "most cubes held at once, 0 for no limit")
+	flags.DurationVar(&limits.MaxSolveTime, "max-solve-time", limits.MaxSolveTime, "longest search, whatever timeout a request asks for, 0 for no limit")
+	flags.Float64Var(&limits.Rate, "rate", limits.Rate, "requests a second per client IP, or per socket connection, 0 for no limit")
+	flags.IntVar(&limits.Burst, "burst", 10, "requests a client may make at once beyond -rate")
+	tables := flags.String("tables", settings.String("tables", ""), "directory of pruning tables written by solve.SaveTables, to load rather than build")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if *tables != "" {
+		if err := solve.UseTables(*tables); err != nil {
+			return err
+		}
+	}
+	solve.Warm()
+
+	// A socket left by a daemon that did not exit cleanly blocks listening.
+	if err := os.Remove(*socket); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	l, err := net.Listen("unix", *socket)
+	if err != nil {
+		return err
+	}
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-stop
+		l.Close()
+	}()
+
+	if *httpAddr != "" {
+		mux := http.NewServeMux()
+		images := daemon.NewImageHandler(*imageCache)
+		images.Limits = limits
+		mux.Handle("/img/", images)
+		mux.Handle("/metrics", metrics.Default.Handler())
+		server := &http.Server{Addr: *httpAddr, Handler: mux}
+		go func() {
+			log.Printf("serving images on %s", *httpAddr)
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Print(err)
+			}
+		}()
+		defer server.Close()
+	}
+
+	log.Printf("listening on %s", *socket)
+	server := daemon.NewServer()
+	server.Limits = limits
+	return server.Serve(l)
+}
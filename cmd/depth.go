@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/analysis"
+	"math/rand/v2"
+	"runtime"
+	"time"
+)
+
+// depthCommand samples random states of the 2x2 or 3x3 and prints the
+// distribution of their optimal depths with 95% confidence intervals.
+func depthCommand(args []string) error {
+	flags := newFlagSet("depth")
+	size := flags.Int("size", 3, "size of the cube, 2 or 3")
+	samples := flags.Int("samples", 100, "random states to solve")
+	maxOptimal := flags.Int("optimal", 20, "longest 3x3 solution to prove optimal, bounding the depth of deeper states")
+	timeout := flags.Duration("timeout", time.Second, "time to solve every 3x3 state optimally before bounding it")
+	workers := flags.Int("workers", runtime.NumCPU(), "goroutines solving states")
+	seed := flags.Uint64("seed", 0, "seed of the random states, or 0 for a random sample")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	var rng *rand.Rand
+	if *seed != 0 {
+		rng = rand.New(rand.NewPCG(*seed, 0))
+	}
+	r, err := analysis.SampleDepths(analysis.DepthOptions{
+		Size:       *size,
+		Samples:    *samples,
+		Workers:    *workers,
+		Rand:       rng,
+		MaxOptimal: *maxOptimal,
+		Timeout:    *timeout,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d states of the %dx%d, %d solved optimally, in %s\n", len(r.Samples), r.Size, r.Size, r.Exact(), r.Elapsed.Round(time.Millisecond))
+	fmt.Println("depth  count   share  95% interval")
+	for _, s := range r.Distribution() {
+		fmt.Printf("%5d  %5d  %5.1f%%  %5.1f%% - %5.1f%%\n", s.Depth, s.Count, 100*s.Share, 100*s.Low, 100*s.High)
+	}
+	low, high := r.Mean()
+	fmt.Printf("mean depth %.2f - %.2f\n", low, high)
+	return nil
+}
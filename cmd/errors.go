@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/analysis"
+	"github.com/larssont/go-cubic/pkg/bot"
+	"github.com/larssont/go-cubic/pkg/config"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/replay"
+	"github.com/larssont/go-cubic/pkg/scramble"
+	"github.com/larssont/go-cubic/pkg/sheet"
+	"github.com/larssont/go-cubic/pkg/solve"
+	"github.com/larssont/go-cubic/pkg/square1"
+	"os"
+)
+
+// Exit codes of the commands, for scripts to tell failures apart.
+const (
+	exitError      = 1 // Other failures, such as of reading or writing files
+	exitUsage      = 2 // Unknown flags or values of flags
+	exitParse      = 3 // Notation that does not parse
+	exitInvalid    = 4 // States or moves that the puzzle cannot have
+	exitTimeout    = 5 // Searches that ran out of time or nodes
+	exitNoSolution = 6 // Searches that proved there is no solution
+)
+
+// jsonErrors reports errors as JSON on standard output, set by the -json
+// flag of every command.
+var jsonErrors bool
+
+// newFlagSet returns the flags of a command, with -json.
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.BoolVar(&jsonErrors, "json", false, "report errors as JSON on standard output")
+	return flags
+}
+
+// usageError is an error of the flags of a command, which the flag package
+// has written already.
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string { return e.err.Error() }
+func (e *usageError) Unwrap() error { return e.err }
+
+// parseFlags parses the arguments of a command.
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		return &usageError{err}
+	}
+	return nil
+}
+
+// usageErrors are the errors of unknown values of flags.
+var usageErrors = []error{
+	cube.ErrScheme, cube.ErrAnnotation, cube.ErrOrientation, sheet.ErrQRContent, errProgress,
+	config.ErrKey, config.ErrValue, errConfigUsage, errHistoryUsage, cube.ErrMoveSet,
+	solve.ErrPocketMethod, analysis.ErrDepthSize, solve.ErrAlgTarget, solve.ErrMask,
+	analysis.ErrUnknownCostModel, analysis.ErrCostEntry, scramble.ErrEvent, scramble.ErrSize,
+	errBotUsage, bot.ErrSize, bot.ErrNoMoves, replay.ErrEasing,
+	errSheetUsage, errGhostSize, errFMCUsage, scramble.ErrLengthSize, errCandidates,
+}
+
+// invalidErrors are the errors of states and moves that a puzzle cannot
+// have.
+var invalidErrors = []error{
+	cube.ErrFaceLengthsDiffer, cube.ErrFaceNotPerfectSquare, cube.ErrSliceParam,
+	cube.ErrPieceBounds, cube.ErrPieceOverlap, cube.ErrPieceColors, cube.ErrStickerCount, cube.ErrParity,
+	solve.ErrInvalidState, solve.ErrFacelets, solve.ErrEncoding, solve.ErrNotInSubgroup, solve.ErrCrossUnsolved,
+	square1.ErrTokenExtraction, square1.ErrSlashBlocked,
+}
+
+// errorReport is the JSON form of an error of a command.
+type errorReport struct {
+	Code     string `json:"code"` // Such as "parse", named after the exit code
+	Message  string `json:"message"`
+	Position *int   `json:"position,omitempty"` // Byte offset of a parse error
+	Exit     int    `json:"exit"`
+}
+
+// report classifies the error by its exit code.
+func report(err error) errorReport {
+	r := errorReport{Code: "error", Message: err.Error(), Exit: exitError}
+	var usage *usageError
+	var parse *cube.ParseError
+	switch {
+	case errors.As(err, &usage):
+		r.Code, r.Exit = "usage", exitUsage
+	case errors.As(err, &parse):
+		r.Code, r.Exit, r.Position = "parse", exitParse, &parse.Pos
+	case errors.Is(err, solve.ErrBudget):
+		r.Code, r.Exit = "timeout", exitTimeout
+	case errors.Is(err, solve.ErrNoSolution), errors.Is(err, square1.ErrNoSolution):
+		r.Code, r.Exit = "no-solution", exitNoSolution
+	case isAny(err, usageErrors):
+		r.Code, r.Exit = "usage", exitUsage
+	case isAny(err, invalidErrors):
+		r.Code, r.Exit = "invalid", exitInvalid
+	}
+	return r
+}
+
+// isAny reports whether the error is any of the targets.
+func isAny(err error, targets []error) bool {
+	for _, target := range targets {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// exit ends the program with the exit code of the error, writing it as
+// JSON if -json is set, or as text on standard error.
+func exit(err error) {
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	r := report(err)
+	if jsonErrors {
+		json.NewEncoder(os.Stdout).Encode(struct {
+			Error errorReport `json:"error"`
+		}{r})
+	} else if usage := (*usageError)(nil); !errors.As(err, &usage) {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	os.Exit(r.Exit)
+}
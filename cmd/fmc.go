@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/scramble"
+)
+
+var errFMCUsage = errors.New("usage: fmc -scramble moves -solution moves, with the moves on the inverse in parentheses")
+
+// fmcCommand checks a fewest moves solution written with NISS, printing the
+// moves on the scramble and on its inverse, and the solution they make.
+func fmcCommand(args []string) error {
+	flags := newFlagSet("fmc")
+	scrambleText := flags.String("scramble", "", "scramble of the attempt")
+	solution := flags.String("solution", "", "solution, with the moves on the inverse of the scramble in parentheses, such as R U (F' D)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *scrambleText == "" || *solution == "" {
+		return errFMCUsage
+	}
+
+	moves, err := parseMoves(*scrambleText)
+	if err != nil {
+		return err
+	}
+	n, err := cube.ParseNISS(*solution)
+	if err != nil {
+		return err
+	}
+	final := cube.FormatMoves(n.Solution())
+	fmt.Printf("Normal:   %s\n", cube.FormatMoves(n.Normal))
+	fmt.Printf("Inverse:  %s\n", cube.FormatMoves(n.Inverse))
+	fmt.Printf("Solution: %s\n", final)
+	fmt.Printf("On the inverse: %s\n", cube.FormatMoves(n.InverseSolution()))
+	count, err := scramble.VerifyFMC(moves, final)
+	fmt.Printf("Moves: %d\n", count)
+	return err
+}
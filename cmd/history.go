@@ -0,0 +1,147 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/analysis"
+	"github.com/larssont/go-cubic/pkg/replay"
+	"github.com/larssont/go-cubic/pkg/store"
+	"time"
+)
+
+var errHistoryUsage = errors.New("usage: history path | sessions | solves [session] | cases [set] | drill [set] | learn set case unlearned|learning|learned | analyze solve")
+
+// historyCommand lists the sessions, solves and trainer case statistics of
+// the store and the cases to drill today, sets the progress of cases, and
+// analyzes the replay of a solve.
+func historyCommand(args []string) error {
+	flags := newFlagSet("history")
+	dir := flags.String("data", settings.String("data", ""), "directory of the store, $GO_CUBIC_DATA or go-cubic/data in the user's config directory if empty")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	args = flags.Args()
+	if len(args) == 0 || len(args) > 4 {
+		return errHistoryUsage
+	}
+
+	if *dir == "" {
+		var err error
+		if *dir, err = store.DefaultDir(); err != nil {
+			return err
+		}
+	}
+	if args[0] == "path" && len(args) == 1 {
+		fmt.Println(*dir)
+		return nil
+	}
+	s, err := store.Open(*dir)
+	if err != nil {
+		return err
+	}
+	arg := ""
+	if len(args) == 2 {
+		arg = args[1]
+	}
+
+	switch {
+	case args[0] == "learn" && len(args) == 4:
+		p := store.Progress(args[3])
+		if p != store.Unlearned && p != store.Learning && p != store.Learned {
+			return errHistoryUsage
+		}
+		c, err := s.SetProgress(args[1], args[2], p)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s %s: %s, due %s\n", c.Set, c.Case, c.Progress, c.Due.Format(time.DateOnly))
+		return nil
+	case len(args) > 2:
+		return errHistoryUsage
+	case args[0] == "sessions" && len(args) == 1:
+		sessions, err := s.Sessions()
+		if err != nil {
+			return err
+		}
+		for _, ses := range sessions {
+			fmt.Printf("%s  %s  %-12s %s\n", ses.ID, ses.Started.Format(time.DateTime), ses.Event, ses.Time.Round(time.Millisecond))
+		}
+	case args[0] == "solves":
+		solves, err := s.Solves(arg)
+		if err != nil {
+			return err
+		}
+		for _, solve := range solves {
+			fmt.Printf("%s  %s  %dx%d  %s\n", solve.ID, solve.Recorded.Format(time.DateTime), solve.Dimension, solve.Dimension, solve.Result)
+		}
+	case args[0] == "cases":
+		cases, err := s.Cases(arg)
+		if err != nil {
+			return err
+		}
+		for _, c := range cases {
+			fmt.Printf("%-8s %-12s %-9s %d/%d correct, mean %s, best %s\n", c.Set, c.Case, c.Progress, c.Correct, c.Attempts, c.Mean().Round(time.Millisecond), c.Best)
+		}
+	case args[0] == "drill":
+		cases, err := s.Drill(arg, time.Now())
+		if err != nil {
+			return err
+		}
+		for _, c := range cases {
+			last := "never"
+			if !c.Last.IsZero() {
+				last = c.Last.Format(time.DateOnly)
+			}
+			fmt.Printf("%-8s %-12s %-9s box %d, last drilled %s, mean %s\n", c.Set, c.Case, c.Progress, c.Box, last, c.Mean().Round(time.Millisecond))
+		}
+	case args[0] == "analyze" && len(args) == 2:
+		return analyzeSolve(s, arg)
+	default:
+		return errHistoryUsage
+	}
+	return nil
+}
+
+// openStore opens the store in the directory, or in store.DefaultDir if it
+// is empty.
+func openStore(dir string) (*store.Store, error) {
+	if dir == "" {
+		var err error
+		if dir, err = store.DefaultDir(); err != nil {
+			return nil, err
+		}
+	}
+	return store.Open(dir)
+}
+
+// loadReplay returns the replay of the solve of the ID.
+func loadReplay(s *store.Store, id string) (*replay.Replay, error) {
+	solves, err := s.Solves("")
+	if err != nil {
+		return nil, err
+	}
+	for _, solve := range solves {
+		if solve.ID == id {
+			return s.Replay(solve)
+		}
+	}
+	return nil, fmt.Errorf("%q: %w", id, store.ErrNotFound)
+}
+
+// analyzeSolve prints the turns per second of the replay of a solve, overall
+// and per phase.
+func analyzeSolve(s *store.Store, id string) error {
+	r, err := loadReplay(s, id)
+	if err != nil {
+		return err
+	}
+	report, err := analysis.Analyze(r.Scramble, r.Moves, analysis.Options{})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d moves in %s, %.2f TPS\n", report.Moves, report.Duration.Round(time.Millisecond), report.TPS)
+	for _, p := range report.Phases {
+		fmt.Printf("%-6s %3d moves in %s, %.2f TPS\n", p.Name, p.Moves, p.Duration.Round(time.Millisecond), p.TPS)
+	}
+	return nil
+}
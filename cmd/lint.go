@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// lintCommand prints the issues that alg.Lint flags in the moves, one per
+// line, or as JSON with -json. Issues are no error: it exits 0 with them.
+func lintCommand(args []string) error {
+	flags := newFlagSet("lint")
+	input := flags.String("moves", "", "algorithm to lint")
+	size := flags.Int("size", settings.Int("size", 3), "size of the cube to check the moves against, or 0 for any")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	issues, err := notation().Lint(*input, *size)
+	if err != nil {
+		return err
+	}
+	if jsonErrors {
+		type issue struct {
+			Pos     int    `json:"pos"`
+			Kind    string `json:"kind"`
+			Text    string `json:"text"`
+			Message string `json:"message"`
+			Fix     string `json:"fix"`
+		}
+		out := []issue{}
+		for _, i := range issues {
+			out = append(out, issue{i.Pos, string(i.Kind), i.Text, i.Message, i.Fix})
+		}
+		return json.NewEncoder(os.Stdout).Encode(struct {
+			Issues []issue `json:"issues"`
+		}{out})
+	}
+	for _, i := range issues {
+		fmt.Println(i)
+	}
+	return nil
+}
@@ -1,52 +1,211 @@
 package main
 
 import (
-	"go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/analysis"
+	"github.com/larssont/go-cubic/pkg/config"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/render"
+	"github.com/larssont/go-cubic/pkg/sheet"
 	"html/template"
+	"io"
 	"os"
 	"path"
 )
 
-var (
-	tmpl *template.Template
-)
+// settings are the defaults of the config file, see package config.
+var settings config.Config
 
-const (
-	outPath = "out/"
-)
+// outPath is the directory of the files that commands write.
+var outPath = "out/"
+
+// commands are the subcommands, run with the arguments after their name.
+var commands = map[string]func(args []string) error{
+	"algs":       algsCommand,
+	"batch":      batch,
+	"bot":        botCommand,
+	"cases":      casesSheet,
+	"config":     configCommand,
+	"daemon":     daemonCommand,
+	"depth":      depthCommand,
+	"fmc":        fmcCommand,
+	"history":    historyCommand,
+	"lint":       lintCommand,
+	"play":       playCommand,
+	"scramble":   scrambleCommand,
+	"sheet":      algSheet,
+	"solve":      solveCommand,
+	"storyboard": storyboardSheet,
+	"timer":      timerCommand,
+}
 
-func GenerateHTML(c *cube.Cube, filename string) error {
+// GenerateHTML writes the cube as HTML to the file in outPath, with the
+// stylesheet it links unless it is standalone.
+func GenerateHTML(c *cube.Cube, filename string, opts render.Options) error {
+	if !opts.Standalone {
+		if err := render.WriteStatic(outPath); err != nil {
+			return err
+		}
+	}
 	file, err := os.Create(path.Join(outPath, filename))
 	if err != nil {
 		return err
 	}
 	defer file.Close()
+	return render.HTML(file, c, opts)
+}
 
-	data := struct {
-		Faces     *cube.CubeFaces
-		Dimension int
-	}{c.Faces(), c.Dimension()}
+// readTemplate parses the template of a page in the file, or on standard
+// input if it is -.
+func readTemplate(filename string) (*template.Template, error) {
+	var text []byte
+	var err error
+	if filename == "-" {
+		text, err = io.ReadAll(os.Stdin)
+	} else {
+		text, err = os.ReadFile(filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return render.ParseTemplate(filename, string(text))
+}
 
-	return tmpl.ExecuteTemplate(file, "cube", data)
+// GenerateSVG writes the net of the cube with the annotation.
+func GenerateSVG(c *cube.Cube, filename string, a cube.Annotation) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return c.RenderAnnotatedSVG(file, cube.CrossLayout, a)
 }
 
-func init() {
-	var err error
-	tmpl, err = template.ParseFiles("ui/html/cube.tmpl")
+// GenerateQR writes a QR code of the link to the moves on alg.cubing.net.
+func GenerateQR(size int, moves []cube.Move, filename string) error {
+	file, err := os.Create(filename)
 	if err != nil {
-		panic("Failed to parse template: " + err.Error())
+		return err
 	}
+	defer file.Close()
+	return sheet.WriteQRSVG(file, sheet.AlgCubingLink(size, moves), 200)
 }
 
 func main() {
-	input := "U2 R2 B L2 U2 D2 F' U2 F B2 L' B' D F U L' B' D R2 Fw2 D L Rw2 Fw2 L D2 F2 L' U' R' F Fw' D' R2 D B2 Rw' Uw2 R Rw' D Rw2 Uw'"
+	if err := loadSettings(); err != nil {
+		exit(err)
+	}
+	if len(os.Args) > 1 {
+		if run, ok := commands[os.Args[1]]; ok {
+			exit(run(os.Args[2:]))
+		}
+	}
+	exit(renderCube(os.Args[1:]))
+}
+
+// renderCube writes the cube after the moves as HTML, and as SVG and a QR code
+// if asked to.
+func renderCube(args []string) error {
+	flags := newFlagSet("cubic")
+	size := flags.Int("size", settings.Int("size", 4), "size of the cube")
+	input := flags.String("moves", "U2 R2 B L2 U2 D2 F' U2 F B2 L' B' D F U L' B' D R2 Fw2 D L Rw2 Fw2 L D2 F2 L' U' R' F Fw' D' R2 D B2 Rw' Uw2 R Rw' D Rw2 Uw'", "moves to apply")
+	schemeName := flags.String("scheme", settings.String("scheme", "western"), "color scheme: western, boy, japanese, stickerless, colorblind, high-contrast, or six hex colors for U,L,F,R,B,D")
+	patterns := flags.Bool("patterns", patternsTheme(), "mark the colors with shapes in the SVG net")
+	orientationName := flags.String("orientation", "", "colors on Up and Front to hold the cube with, such as wg, with moves written for the home orientation of the scheme")
+	svgFile := flags.String("svg", "", "also write the net of the cube as SVG to this file")
+	annotationName := flags.String("annotate", "none", "label the stickers of the SVG net: none, faces, indices or speffz")
+	standalone := flags.Bool("standalone", false, "inline the stylesheet in the HTML, so the page is a single file to share")
+	netImage := flags.Bool("net-image", false, "add the net of the cube to the HTML as an image, embedded in the page")
+	random := flags.Bool("random", false, "start from a uniformly random state instead of solved, before the moves")
+	moveSet := flags.String("gen", "", "accept only the turns of a move set in the moves, such as <R,U>")
+	solution := flags.String("solution", "", "CFOP solution of the 3x3 after the moves to list under the cube, every phase explained")
+	qrFile := flags.String("qr", "", "also write a QR code of the link to the moves on alg.cubing.net as SVG to this file")
+	templateFile := flags.String("template", "", "write the HTML with this Go template instead, or with the one on standard input if -, see render.Page")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
 
-	group, _ := cube.ParseNotation(input)
+	scheme, err := cube.LookupScheme(*schemeName)
+	if err != nil {
+		return err
+	}
+	scheme.Patterns = *patterns
+	annotation, err := cube.ParseAnnotation(*annotationName)
+	if err != nil {
+		return err
+	}
+
+	dialect := notation()
+	if *moveSet != "" {
+		ops, err := cube.ParseMoveSet(*moveSet)
+		if err != nil {
+			return err
+		}
+		dialect = dialect.Restricted(ops)
+	}
+	group, err := dialect.ParseNotation(*input)
+	if err != nil {
+		return err
+	}
 	group.Print()
 
-	moves, _ := group.Expand()
-	c := cube.NewCube(4)
-	c.ExecuteMoves(moves...)
+	moves, err := group.Expand()
+	if err != nil {
+		return err
+	}
+	c := cube.NewCubeWith(*size, cube.Options{Scheme: scheme})
+	if *random {
+		c = cube.RandomState(*size, nil)
+		c.Recolor(scheme)
+	}
+	if *orientationName != "" {
+		orientation, err := cube.ParseOrientation(*orientationName)
+		if err != nil {
+			return err
+		}
+		rotation, err := orientation.Rotation(scheme)
+		if err != nil {
+			return err
+		}
+		if err := c.ExecuteMoves(rotation...); err != nil {
+			return err
+		}
+		if moves, err = orientation.Reframe(moves, scheme); err != nil {
+			return err
+		}
+	}
+	if err := c.ExecuteMoves(moves...); err != nil {
+		return err
+	}
 
-	GenerateHTML(c, "cube.html")
+	opts := render.Options{Standalone: *standalone, NetImage: *netImage, Moves: moves}
+	if *templateFile != "" {
+		if opts.Template, err = readTemplate(*templateFile); err != nil {
+			return err
+		}
+	}
+	if *solution != "" {
+		solved, err := parseMoves(*solution)
+		if err != nil {
+			return err
+		}
+		explained, err := analysis.ExplainCFOP(moves, solved)
+		if err != nil {
+			return err
+		}
+		opts.Solution = &explained
+	}
+	if err := GenerateHTML(c, "cube.html", opts); err != nil {
+		return err
+	}
+	if *svgFile != "" {
+		if err := GenerateSVG(c, *svgFile, annotation); err != nil {
+			return err
+		}
+	}
+	if *qrFile != "" {
+		if err := GenerateQR(*size, moves, *qrFile); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/replay"
+	"os"
+	"path"
+)
+
+// playCommand writes a page that animates an algorithm, timed by the kinds
+// of its moves, or at a target pace.
+func playCommand(args []string) error {
+	flags := newFlagSet("play")
+	size := flags.Int("size", settings.Int("size", 3), "size of the cube")
+	setup := flags.String("setup", "", "moves to apply before playing, such as a scramble")
+	alg := flags.String("alg", "", "moves to play")
+	out := flags.String("out", path.Join(outPath, "play.html"), "HTML file to write")
+	timeline := flags.String("timeline", "", "also write the timeline of the turns as JSON to this file, for animation engines")
+	quarter := flags.Duration("quarter", replay.DefaultTiming.QuarterTurn, "time of a quarter turn of a face")
+	slice := flags.Duration("slice", replay.DefaultTiming.Slice, "time of a quarter turn of a middle slice")
+	rotation := flags.Duration("rotation", replay.DefaultTiming.Rotation, "time of a quarter rotation of the cube")
+	half := flags.Float64("half", replay.DefaultTiming.HalfTurn, "time of a half turn as a factor of that of a quarter turn")
+	pause := flags.Duration("pause", replay.DefaultTiming.Pause, "pause between moves")
+	easing := flags.String("easing", string(replay.DefaultTiming.Easing), "easing of the moves: none, linear or ease-in-out")
+	tps := flags.Float64("tps", 0, "scale the times to play this many moves a second, such as the pace of a solver")
+	schemeName := flags.String("scheme", settings.String("scheme", "western"), "color scheme, as for the cube")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	ease, err := replay.ParseEasing(*easing)
+	if err != nil {
+		return err
+	}
+	scheme, err := cube.LookupScheme(*schemeName)
+	if err != nil {
+		return err
+	}
+	setupMoves, err := parseMoves(*setup)
+	if err != nil {
+		return err
+	}
+	moves, err := parseMoves(*alg)
+	if err != nil {
+		return err
+	}
+	t := replay.Timing{
+		QuarterTurn: *quarter,
+		Slice:       *slice,
+		Rotation:    *rotation,
+		HalfTurn:    *half,
+		Pause:       *pause,
+		Easing:      ease,
+	}
+	if *tps > 0 {
+		t = t.AtTPS(moves, *tps)
+	}
+
+	p := cube.Playback{Dimension: *size, Setup: setupMoves, Alg: moves, Scheme: scheme}
+	if *timeline != "" {
+		if err := writeTimeline(*timeline, p, t); err != nil {
+			return err
+		}
+	}
+	file, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return replay.RenderTimedPlaybackHTML(file, p, t, 1)
+}
+
+// writeTimeline writes the timeline of the playback as JSON to the file.
+func writeTimeline(filename string, p cube.Playback, t replay.Timing) error {
+	tl, err := replay.NewTimeline(p, t)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return tl.WriteJSON(file)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/scramble"
+	"github.com/larssont/go-cubic/pkg/store"
+	"strings"
+	"time"
+)
+
+// scrambleCommand prints scrambles a line each, drawn again until the
+// filters of an event pass or, with -unique, until their state is new to a
+// scope of the store, prefixed with a random orientation and noted with how
+// many moves they take to solve if asked to.
+func scrambleCommand(args []string) error {
+	flags := newFlagSet("scramble")
+	size := flags.Int("size", settings.Int("size", 3), "size of the cubes")
+	count := flags.Int("n", 1, "scrambles to print")
+	event := flags.String("event", "", "WCA event whose scramble rules to keep, such as 333 or 333bf")
+	orient := flags.Bool("orient", false, "prefix every scramble with the rotation to a random orientation, as blindfolded scrambles, and note the orientation")
+	schemeName := flags.String("scheme", settings.String("scheme", "western"), "color scheme naming the orientation of -orient")
+	unique := flags.String("unique", "", "scope, such as a session or a competition event, in which to never repeat the state of a scramble")
+	dir := flags.String("data", settings.String("data", ""), "directory of the store remembering the scrambles of -unique, as for history")
+	length := flags.Bool("length", false, "note how many moves every 2x2 or 3x3 scramble takes to solve, optimal or bounded by the searches")
+	lengthTimeout := flags.Duration("length-timeout", time.Second, "time of each search of -length")
+	lengthDepth := flags.Int("length-depth", 0, "end the optimal search of -length at solutions of this many moves, leaving longer ones bounded")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *length && *size != 2 && *size != 3 {
+		return scramble.ErrLengthSize
+	}
+
+	scheme, err := cube.LookupScheme(*schemeName)
+	if err != nil {
+		return err
+	}
+	var filters []scramble.Filter
+	if *event != "" {
+		if filters, err = scramble.WCA(*event); err != nil {
+			return err
+		}
+	}
+	generate := func() ([]cube.Move, error) {
+		return scramble.Filtered(*size, filters, scramble.FilterOptions{})
+	}
+	if *unique != "" {
+		if *dir == "" {
+			if *dir, err = store.DefaultDir(); err != nil {
+				return err
+			}
+		}
+		s, err := store.Open(*dir)
+		if err != nil {
+			return err
+		}
+		filtered := generate
+		generate = func() ([]cube.Move, error) {
+			return scramble.Unique(s, *unique, *size, filtered)
+		}
+	}
+	for range *count {
+		moves, err := generate()
+		if err != nil {
+			return err
+		}
+		var notes []string
+		if *length {
+			l, err := scramble.SolutionLength(*size, moves, scramble.LengthOptions{Timeout: *lengthTimeout, MaxDepth: *lengthDepth})
+			if err != nil {
+				return err
+			}
+			notes = append(notes, l.String())
+		}
+		if *orient {
+			var o cube.Orientation
+			moves, o = scramble.Orient(moves, scheme, nil)
+			notes = append([]string{fmt.Sprintf("%c on top, %c in front", o.Up, o.Front)}, notes...)
+		}
+		if len(notes) == 0 {
+			fmt.Println(cube.FormatMoves(moves))
+			continue
+		}
+		fmt.Printf("%s // %s\n", cube.FormatMoves(moves), strings.Join(notes, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"github.com/larssont/go-cubic/pkg/algdb"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/sheet"
+	"os"
+	"path"
+	"strings"
+)
+
+var errSheetUsage = errors.New("usage: sheet -db algs.csv [-set OLL,PLL] [-learned | -unlearned]")
+
+// algSheet writes a printable alg sheet of the sets of a personal algorithm
+// database, with diagrams, alternatives and notes, of the cases learned or
+// not if asked to.
+func algSheet(args []string) error {
+	flags := newFlagSet("sheet")
+	db := flags.String("db", "", "algorithm database in CSV as set,case,alg[,learned[,notes]]")
+	sets := flags.String("set", "", "comma-separated sets to show in order, such as OLL,PLL, or every set if empty")
+	learned := flags.Bool("learned", false, "show only the cases marked learned")
+	unlearned := flags.Bool("unlearned", false, "show only the cases not marked learned")
+	out := flags.String("out", path.Join(outPath, "sheet.html"), "HTML file to write, to print or save as PDF from a browser")
+	title := flags.String("title", "", "title of the page")
+	columns := flags.Int("columns", 3, "cases in a row")
+	schemeName := flags.String("scheme", settings.String("scheme", "western"), "color scheme, as for the cube")
+	patterns := flags.Bool("patterns", patternsTheme(), "mark the colors with shapes")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *db == "" || (*learned && *unlearned) {
+		return errSheetUsage
+	}
+
+	scheme, err := cube.LookupScheme(*schemeName)
+	if err != nil {
+		return err
+	}
+	scheme.Patterns = *patterns
+	opts := sheet.AlgSheetOptions{CaseOptions: sheet.CaseOptions{Title: *title, Columns: *columns, Scheme: scheme}}
+	for _, set := range strings.Split(*sets, ",") {
+		if set = strings.TrimSpace(set); set != "" {
+			opts.Sets = append(opts.Sets, set)
+		}
+	}
+	switch {
+	case *learned:
+		opts.Learned = sheet.LearnedCases
+	case *unlearned:
+		opts.Learned = sheet.UnlearnedCases
+	}
+
+	f, err := os.Open(*db)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	imported, err := algdb.Import(f)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return sheet.RenderAlgSheet(file, imported, opts)
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/solve"
+	"github.com/larssont/go-cubic/pkg/square1"
+	"os"
+	"runtime"
+	"time"
+)
+
+var (
+	errProgress   = errors.New("unknown progress output")
+	errCandidates = errors.New("-candidates must be at least 1")
+)
+
+// progressEvent is a line of the JSON output of the solve command.
+type progressEvent struct {
+	Event     string  `json:"event"` // "progress", "solution" or "done"
+	Depth     int     `json:"depth,omitempty"`
+	Nodes     int     `json:"nodes"`
+	ElapsedMS float64 `json:"elapsed_ms"`
+	Found     bool    `json:"found"` // Whether there is a solution, which may have no moves
+	Solution  string  `json:"solution,omitempty"`
+	Length    int     `json:"length,omitempty"`
+	Stopped   string  `json:"stopped,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// solveCommand solves a scramble of the 3x3 cube with the two-phase solver,
+// reporting its progress as it searches, as text on standard error or as
+// JSON lines on standard output.
+func solveCommand(args []string) error {
+	flags := newFlagSet("solve")
+	scramble := flags.String("moves", "", "scramble to solve")
+	timeout := flags.Duration("timeout", 2*time.Second, "time to search for shorter solutions")
+	maxLength := flags.Int("max-length", 21, "stop at the first solution this short")
+	keepImproving := flags.Bool("keep-improving", false, "search for shorter solutions until the timeout")
+	workers := flags.Int("workers", runtime.NumCPU(), "goroutines searching")
+	tables := flags.String("tables", settings.String("tables", ""), "directory of pruning tables written by solve.SaveTables, to load rather than build")
+	format := flags.String("progress", "text", "progress output: text on standard error, json lines on standard output, or none")
+	method := flags.String("method", "", "solve a 2x2 scramble in the labeled steps of a method, ortega or cll, instead")
+	sq1 := flags.Bool("square1", false, "solve a Square-1 scramble in WCA notation, such as (1,0)/ (-3,3)/, to cube shape and then the pieces, instead")
+	f2l := flags.Bool("f2l", false, "solve the cross on D and then F2L a slot at a time, pairing up and inserting as people do, for teaching examples")
+	rotations := flags.Int("rotations", 1, "y rotations that -f2l may use to bring back slots to the front")
+	explain := flags.Bool("explain", false, "annotate the solution with what every part of it does, a part a line")
+	cost := flags.String("cost", settings.String("cost", ""), "print the solution of the lowest weighted cost of -candidates, by a cost model: default, a file or a table such as \"R=1, F2=1.8, rotations=2.5\"")
+	candidates := flags.Int("candidates", 10, "shortest solutions that -cost chooses from")
+	gen := flags.String("gen", "", "solve optimally with only the turns of a move set, such as <R,U> or <M,U>, for a scramble in its subgroup")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if *tables != "" {
+		if err := solve.UseTables(*tables); err != nil {
+			return err
+		}
+	}
+	if *sq1 {
+		return solveSquare1(*scramble)
+	}
+	moves, err := parseMoves(*scramble)
+	if err != nil {
+		return err
+	}
+	s, err := solve.StateOf(moves...)
+	if err != nil {
+		return err
+	}
+
+	if *method != "" {
+		return solvePocket(s, *method, *explain)
+	}
+	if *f2l {
+		return solveF2L(s, *rotations, *explain)
+	}
+	if *gen != "" {
+		return solveSubset(s, *gen, *maxLength, *timeout)
+	}
+
+	if *cost != "" {
+		if *candidates < 1 {
+			return errCandidates
+		}
+		return solveWeighted(s, *cost, *candidates, solve.SolveOptions{
+			MaxLength:     *maxLength,
+			Timeout:       *timeout,
+			KeepImproving: *keepImproving,
+			Workers:       *workers,
+		})
+	}
+
+	var emit func(progressEvent)
+	switch *format {
+	case "text":
+		emit = func(e progressEvent) {
+			switch e.Event {
+			case "solution":
+				if e.Length == 0 {
+					fmt.Fprintf(os.Stderr, "%8.0fms  already solved\n", e.ElapsedMS)
+					break
+				}
+				fmt.Fprintf(os.Stderr, "%8.0fms  found %d moves: %s\n", e.ElapsedMS, e.Length, e.Solution)
+			case "progress":
+				best := "none yet"
+				if e.Found {
+					best = fmt.Sprintf("%d moves", e.Length)
+				}
+				fmt.Fprintf(os.Stderr, "%8.0fms  depth %d, %d nodes, best %s\n", e.ElapsedMS, e.Depth, e.Nodes, best)
+			}
+		}
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		emit = func(e progressEvent) { enc.Encode(e) }
+	case "none":
+		emit = func(progressEvent) {}
+	default:
+		return fmt.Errorf("%q: %w", *format, errProgress)
+	}
+
+	solution, err := solve.SolveDetailed(s.Reoriented(), solve.SolveOptions{
+		MaxLength:     *maxLength,
+		Timeout:       *timeout,
+		KeepImproving: *keepImproving,
+		Workers:       *workers,
+		Annotate:      *explain,
+		Progress: func(p solve.Progress) {
+			e := progressEvent{
+				Event:     "progress",
+				Depth:     p.Depth,
+				Nodes:     p.Nodes,
+				ElapsedMS: float64(p.Elapsed.Microseconds()) / 1000,
+				Found:     p.Best != nil,
+				Length:    len(p.Best),
+			}
+			if p.Improved {
+				e.Event = "solution"
+				e.Solution = cube.FormatMoves(p.Best)
+			}
+			emit(e)
+		},
+	})
+
+	if errors.Is(err, solve.ErrNoSolution) && (solution.Stopped == solve.OutOfTime || solution.Stopped == solve.OutOfNodes) {
+		err = fmt.Errorf("%w: %w", solve.ErrBudget, err)
+	}
+
+	done := progressEvent{
+		Event:     "done",
+		Nodes:     solution.Nodes,
+		ElapsedMS: float64(solution.Elapsed.Microseconds()) / 1000,
+		Found:     err == nil,
+		Solution:  cube.FormatMoves(solution.Moves),
+		Length:    len(solution.Moves),
+		Stopped:   solution.Stopped.String(),
+	}
+	if err != nil {
+		done.Error = err.Error()
+	}
+	if *format == "json" {
+		emit(done)
+	}
+	if err != nil {
+		return err
+	}
+	if *format == "json" {
+		return nil
+	}
+	if *explain {
+		fmt.Print(solution.Explain())
+		return nil
+	}
+	fmt.Println(done.Solution)
+	return nil
+}
+
+// solveSubset prints the shortest solution of the state with the turns of a
+// move set.
+func solveSubset(s solve.State, moveSet string, maxLength int, timeout time.Duration) error {
+	ops, err := cube.ParseMoveSet(moveSet)
+	if err != nil {
+		return err
+	}
+	gens, err := solve.Subset(ops)
+	if err != nil {
+		return err
+	}
+	var stats solve.SearchStats
+	solutions, err := solve.SolveSubset(s, gens, solve.CrossOptions{MaxMoves: maxLength, MaxTime: timeout, Stats: &stats})
+	if err != nil {
+		return err
+	}
+	if len(solutions) == 0 {
+		if stats.Stopped == solve.OutOfTime {
+			return solve.ErrBudget
+		}
+		return solve.ErrNoSolution
+	}
+	fmt.Println(cube.FormatMoves(solutions[0]))
+	return nil
+}
+
+// solveWeighted prints the solution of the state of the lowest weighted cost
+// of the cost model among the shortest ones, with its cost.
+func solveWeighted(s solve.State, spec string, candidates int, opts solve.SolveOptions) error {
+	model, err := costModel(spec)
+	if err != nil {
+		return err
+	}
+	opts.Cost = model.Weighted
+	solutions, err := solve.SolveTop(s.Reoriented(), candidates, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s (cost %.1f)\n", cube.FormatMoves(solutions[0]), model.Weighted(solutions[0]))
+	return nil
+}
+
+// solvePocket prints a 2x2 solution in the steps of a method, a step a line,
+// explained if asked to.
+func solvePocket(s solve.State, name string, explain bool) error {
+	method, err := solve.ParsePocketMethod(name)
+	if err != nil {
+		return err
+	}
+	steps, err := solve.SolvePocket(s, method)
+	if err != nil {
+		return err
+	}
+	if explain {
+		fmt.Print(solve.PocketSolution(steps).Explain())
+		return nil
+	}
+	for _, step := range steps {
+		moves := cube.FormatMoves(step.Moves)
+		if moves == "" {
+			moves = "skip"
+		}
+		fmt.Printf("%s: %s\n", step.Name, moves)
+	}
+	return nil
+}
+
+// solveF2L prints the cross on D and then every F2L slot the way people
+// solve them, a slot a line, or explained a part a line if asked to.
+func solveF2L(s solve.State, rotations int, explain bool) error {
+	var cross []cube.Move
+	if solutions := solve.SolveCross(s, cube.FaceDown, solve.CrossOptions{}); len(solutions) > 0 {
+		cross = solutions[0]
+	}
+	after, err := solve.StateOf(cross...)
+	if err != nil {
+		return err
+	}
+	steps, err := solve.SolveF2L(s.Then(after), solve.F2LOptions{Rotations: rotations})
+	if err != nil {
+		return err
+	}
+	if explain {
+		fmt.Print(solve.F2LSolution(cross, steps).Explain())
+		return nil
+	}
+	moves := cube.FormatMoves(cross)
+	if moves == "" {
+		moves = "skip"
+	}
+	fmt.Printf("Cross: %s\n", moves)
+	for _, step := range steps {
+		fmt.Printf("%s: %s\n", step.Slot, cube.FormatMoves(step.Moves()))
+	}
+	return nil
+}
+
+// solveSquare1 prints a Square-1 solution in its phases, a phase a line.
+func solveSquare1(scramble string) error {
+	moves, err := square1.ParseNotation(scramble)
+	if err != nil {
+		return err
+	}
+	s := square1.New()
+	if err := s.ExecuteMoves(moves...); err != nil {
+		return err
+	}
+	phases, err := s.Solve()
+	if err != nil {
+		return err
+	}
+	for _, phase := range phases {
+		moves := square1.FormatMoves(phase.Moves)
+		if moves == "" {
+			moves = "skip"
+		}
+		fmt.Printf("%s: %s\n", phase.Name, moves)
+	}
+	return nil
+}
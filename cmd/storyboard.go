@@ -0,0 +1,77 @@
+package main
+
+import (
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/sheet"
+	"os"
+	"path"
+	"strings"
+)
+
+// storyboardSheet writes the cube after every move or phase of a solution,
+// as HTML or, for files ending in .svg, as one SVG image.
+func storyboardSheet(args []string) error {
+	flags := newFlagSet("storyboard")
+	size := flags.Int("size", settings.Int("size", 3), "size of the cube")
+	setup := flags.String("setup", "", "moves to apply before the first frame, such as a scramble")
+	alg := flags.String("alg", "", "moves of a frame each, or phases split by | with an optional name, such as \"Cross: D R | F2L: U R U' R'\"")
+	out := flags.String("out", path.Join(outPath, "storyboard.html"), "HTML or SVG file to write")
+	title := flags.String("title", "", "title of the page")
+	columns := flags.Int("columns", 6, "frames in a row")
+	schemeName := flags.String("scheme", settings.String("scheme", "western"), "color scheme, as for the cube")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	scheme, err := cube.LookupScheme(*schemeName)
+	if err != nil {
+		return err
+	}
+	setupMoves, err := parseMoves(*setup)
+	if err != nil {
+		return err
+	}
+	var steps []sheet.Step
+	if strings.Contains(*alg, "|") {
+		for _, phase := range strings.Split(*alg, "|") {
+			var name string
+			if before, after, ok := strings.Cut(phase, ":"); ok {
+				name, phase = strings.TrimSpace(before), after
+			}
+			moves, err := parseMoves(phase)
+			if err != nil {
+				return err
+			}
+			steps = append(steps, sheet.Step{Name: name, Moves: moves})
+		}
+	} else {
+		moves, err := parseMoves(*alg)
+		if err != nil {
+			return err
+		}
+		steps = sheet.MoveSteps(moves)
+	}
+
+	file, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	opts := sheet.StoryboardOptions{Options: sheet.Options{Title: *title, Scheme: scheme}, Columns: *columns}
+	if strings.HasSuffix(*out, ".svg") {
+		return sheet.RenderStoryboardSVG(file, *size, setupMoves, steps, opts)
+	}
+	return sheet.RenderStoryboard(file, *size, setupMoves, steps, opts)
+}
+
+// parseMoves parses and expands notation, with none for blank text.
+func parseMoves(text string) ([]cube.Move, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+	group, err := notation().ParseNotation(text)
+	if err != nil {
+		return nil, err
+	}
+	return group.Expand()
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/replay"
+	"github.com/larssont/go-cubic/pkg/scramble"
+	"github.com/larssont/go-cubic/pkg/solve"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+var errGhostSize = errors.New("-ghost-tps races a solver solution of the 3x3 only")
+
+// timerCommand times a solve from the moves of a smart cube, read as
+// notation from standard input as the cube reports them, such as from a
+// bridge to its Bluetooth connection. It prints the scramble and every step
+// of the solve, and stores the timed reconstruction in the history. With
+// -race or -ghost-tps, the solve races an opponent on the same scramble,
+// the gap printed after every move and both played back side by side.
+func timerCommand(args []string) error {
+	flags := newFlagSet("timer")
+	size := flags.Int("size", settings.Int("size", 3), "size of the cube")
+	scrambleText := flags.String("scramble", "", "scramble to apply, instead of a random one")
+	in := flags.String("in", "", "file or pipe of the moves of the cube, instead of standard input")
+	dir := flags.String("data", settings.String("data", ""), "directory of the store to keep the solve in, as for history")
+	save := flags.Bool("save", true, "keep the solve and its replay in the store")
+	raceID := flags.String("race", "", "ID of a stored solve to race against, on its scramble")
+	ghostTPS := flags.Float64("ghost-tps", 0, "race a solver solution of the scramble played at this many moves a second")
+	raceOut := flags.String("race-out", path.Join(outPath, "race.html"), "HTML file to write of the race played back side by side")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	moves, err := scramble.Cube(*size, nil)
+	if *scrambleText != "" {
+		moves, err = parseMoves(*scrambleText)
+	}
+	if err != nil {
+		return err
+	}
+	var opponent *replay.Replay
+	switch {
+	case *raceID != "":
+		s, err := openStore(*dir)
+		if err != nil {
+			return err
+		}
+		if opponent, err = loadReplay(s, *raceID); err != nil {
+			return err
+		}
+		moves = opponent.Scramble
+	case *ghostTPS > 0:
+		if *size != 3 {
+			return errGhostSize
+		}
+		state, err := solve.StateOf(moves...)
+		if err != nil {
+			return err
+		}
+		solution, err := solve.Solve(state, solve.SolveOptions{})
+		if err != nil {
+			return err
+		}
+		t := replay.DefaultTiming.AtTPS(solution, *ghostTPS)
+		if opponent, err = replay.Ghost(*size, moves, solution, t); err != nil {
+			return err
+		}
+	}
+	var timer *replay.SmartTimer
+	var race *replay.Race
+	if opponent != nil {
+		if race, err = replay.NewRace(opponent); err != nil {
+			return err
+		}
+		timer = race.SmartTimer
+	} else if timer, err = replay.NewSmartTimer(*size, moves); err != nil {
+		return err
+	}
+	var r io.Reader = os.Stdin
+	if *in != "" {
+		f, err := os.Open(*in)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	fmt.Printf("Scramble: %s\n", cube.FormatMoves(moves))
+	phase := timer.Phase()
+	if phase == replay.Ready {
+		fmt.Println("Scrambled, the first move starts the timer")
+	}
+	scanner := bufio.NewScanner(r)
+	for phase != replay.Done && scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		turned, err := parseMoves(text)
+		if err != nil {
+			return err
+		}
+		for _, m := range turned {
+			next, err := timer.Move(m)
+			if err != nil {
+				return err
+			}
+			switch {
+			case next == phase:
+			case next == replay.Ready:
+				fmt.Println("Scrambled, the first move starts the timer")
+			case next == replay.Solving:
+				fmt.Println("Started")
+			}
+			phase = next
+		}
+		if race != nil && phase != replay.Scrambling && phase != replay.Ready {
+			printStanding(race)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if phase == replay.Solving {
+		if _, err := timer.Stop(); err != nil {
+			return err
+		}
+	}
+	recorded := timer.Replay()
+	if recorded == nil {
+		return nil
+	}
+	fmt.Printf("Result: %s\n", recorded.Result)
+	fmt.Printf("Solution: %s\n", cube.FormatTimedMoves(recorded.Moves))
+	if race != nil {
+		if err := writeRace(*raceOut, recorded, opponent); err != nil {
+			return err
+		}
+	}
+	if !*save {
+		return nil
+	}
+
+	s, err := openStore(*dir)
+	if err != nil {
+		return err
+	}
+	saved, err := s.SaveReplay(recorded, "")
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Saved as %s\n", saved.ID)
+	return nil
+}
+
+// printStanding prints the moves of the race so far and the gap to the
+// opponent.
+func printStanding(race *replay.Race) {
+	st, err := race.Standing(time.Now())
+	if err != nil {
+		return
+	}
+	opponent := fmt.Sprintf("%d moves", st.OpponentMoves)
+	if st.OpponentDone {
+		opponent = "done in " + race.Opponent.Result.String()
+	}
+	fmt.Printf("You %d moves, opponent %s, %+.2fs\n", st.YourMoves, opponent, st.Gap.Seconds())
+}
+
+// writeRace writes the page of a race, and prints who won.
+func writeRace(out string, you, opponent *replay.Replay) error {
+	yours, youCount := you.Result.Counted()
+	theirs, theyCount := opponent.Result.Counted()
+	switch gap := yours - theirs; {
+	case !youCount && !theyCount:
+		fmt.Println("Neither of you finished")
+	case !youCount:
+		fmt.Println("The opponent won")
+	case !theyCount:
+		fmt.Println("You won")
+	case gap < 0:
+		fmt.Printf("You won by %.2fs\n", -gap.Seconds())
+	case gap > 0:
+		fmt.Printf("The opponent won by %.2fs\n", gap.Seconds())
+	default:
+		fmt.Println("A tie")
+	}
+	file, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return replay.RenderRaceHTML(file, you, opponent, 1)
+}
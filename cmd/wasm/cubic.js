@@ -0,0 +1,37 @@
+// cubic.js loads cubic.wasm and wraps the functions it exposes, throwing an
+// Error where they return one. It needs wasm_exec.js from the Go
+// distribution, loaded first:
+//
+//   <script src="wasm_exec.js"></script>
+//   <script src="cubic.js"></script>
+//   <script>
+//     loadCubic("cubic.wasm").then(cubic => {
+//       document.body.innerHTML = cubic.svg(3, "R U R' U'")
+//     })
+//   </script>
+
+async function loadCubic(url = "cubic.wasm") {
+    const go = new Go()
+    const { instance } = await WebAssembly.instantiateStreaming(fetch(url), go.importObject)
+    go.run(instance)
+
+    const call = (name, ...args) => {
+        const out = globalThis.cubic[name](...args)
+        if (out.error) {
+            throw new Error(out.error)
+        }
+        return out
+    }
+
+    return {
+        // parse returns the expanded moves of the notation.
+        parse: notation => call("parse", notation).moves,
+        // apply returns the sticker colors of a cube after the moves, face
+        // by face in the order U L F R B D, and whether it is solved.
+        apply: (dimension, notation) => call("apply", dimension, notation),
+        // solve returns a 3x3 solution of the scramble.
+        solve: (scramble, timeoutMs) => call("solve", scramble, timeoutMs).solution,
+        // svg returns the net of a cube after the moves as an SVG image.
+        svg: (dimension, notation) => call("svg", dimension, notation).svg,
+    }
+}
@@ -0,0 +1,105 @@
+//go:build js && wasm
+
+// Command wasm exposes the engine to JavaScript as the global object cubic,
+// for web apps that run it client-side. Build it with
+//
+//	GOOS=js GOARCH=wasm go build -o cubic.wasm ./cmd/wasm
+//
+// and load it with wasm_exec.js from the Go distribution and cubic.js.
+//
+// Every function returns an object with an error message in error, or the
+// result in the other fields.
+package main
+
+import (
+	"bytes"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/solve"
+	"syscall/js"
+	"time"
+)
+
+func main() {
+	js.Global().Set("cubic", js.ValueOf(map[string]any{
+		"parse": js.FuncOf(parse),
+		"apply": js.FuncOf(apply),
+		"solve": js.FuncOf(solveScramble),
+		"svg":   js.FuncOf(render),
+	}))
+	select {}
+}
+
+func result(fields map[string]any, err error) any {
+	if err != nil {
+		return map[string]any{"error": err.Error()}
+	}
+	return fields
+}
+
+func moves(notation string) ([]cube.Move, error) {
+	group, err := cube.ParseNotation(notation)
+	if err != nil {
+		return nil, err
+	}
+	return group.Expand()
+}
+
+// turned returns a cube of the dimension after the moves.
+func turned(dimension int, notation string) (*cube.Cube, error) {
+	ms, err := moves(notation)
+	if err != nil {
+		return nil, err
+	}
+	c := cube.NewCube(dimension)
+	if err := c.ExecuteMoves(ms...); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// parse(notation) returns the expanded moves of the notation.
+func parse(this js.Value, args []js.Value) any {
+	ms, err := moves(args[0].String())
+	return result(map[string]any{"moves": cube.FormatMoves(ms)}, err)
+}
+
+// apply(dimension, notation) returns the sticker colors of a cube after the
+// moves, in the format of Cube.State, and whether it is solved.
+func apply(this js.Value, args []js.Value) any {
+	c, err := turned(args[0].Int(), args[1].String())
+	if err != nil {
+		return result(nil, err)
+	}
+	return result(map[string]any{"state": c.State(), "solved": c.IsSolved()}, nil)
+}
+
+// solve(scramble, timeoutMs) returns a two-phase solution of a 3x3
+// scramble.
+func solveScramble(this js.Value, args []js.Value) any {
+	ms, err := moves(args[0].String())
+	if err != nil {
+		return result(nil, err)
+	}
+	s, err := solve.StateOf(ms...)
+	if err != nil {
+		return result(nil, err)
+	}
+	var opts solve.SolveOptions
+	if len(args) > 1 && args[1].Type() == js.TypeNumber {
+		opts.Timeout = time.Duration(args[1].Int()) * time.Millisecond
+	}
+	solution, err := solve.Solve(s.Reoriented(), opts)
+	return result(map[string]any{"solution": cube.FormatMoves(solution), "length": len(solution)}, err)
+}
+
+// svg(dimension, notation) returns the net of a cube after the moves as an
+// SVG image.
+func render(this js.Value, args []js.Value) any {
+	c, err := turned(args[0].Int(), args[1].String())
+	if err != nil {
+		return result(nil, err)
+	}
+	var buf bytes.Buffer
+	err = c.RenderSVG(&buf)
+	return result(map[string]any{"svg": buf.String()}, err)
+}
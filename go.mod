@@ -1,4 +1,4 @@
-module go-cubic
+module github.com/larssont/go-cubic
 
 go 1.23.1
 
@@ -0,0 +1,48 @@
+package alg
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// lookalikes maps the characters that web pages and PDFs write in place of
+// those of notation to them: typographic primes and quotes, dashes and the
+// minus sign, superscript digits, and spaces of other widths. Characters
+// mapped to "" are dropped, such as zero-width spaces.
+var lookalikes = map[rune]string{
+	'\u2032': "'", '\u2019': "'", '\u2018': "'", '\u02bc': "'", '\u00b4': "'", '`': "'", '\uff07': "'",
+	'\u2013': "-", '\u2014': "-", '\u2212': "-", '\u2010': "-", '\u2011': "-",
+	'\u00b2': "2", '\u00b3': "3",
+	'\u00a0': " ", '\u2002': " ", '\u2003': " ", '\u2009': " ", '\u200a': " ",
+	'\u202f': " ", '\u205f': " ", '\u3000': " ", '\t': " ", '\n': " ", '\r': " ",
+	'\u200b': "", '\u2060': "", '\ufeff': "",
+}
+
+// Clean replaces the Unicode lookalikes of notation characters in text
+// pasted from web pages and PDFs, such as R′ or R’ for R' and an en dash
+// for a minus, so that the parsers accept it. Dialects clean their input
+// unless Strict.
+func Clean(input string) string {
+	cleaned, _ := clean(input)
+	return cleaned
+}
+
+// clean returns the cleaned input with, for every byte of it, the offset in
+// the input of the character it came from.
+func clean(input string) (string, []int) {
+	var sb strings.Builder
+	offsets := make([]int, 0, len(input))
+	for i := 0; i < len(input); {
+		r, size := utf8.DecodeRuneInString(input[i:])
+		s, ok := lookalikes[r]
+		if !ok {
+			s = input[i : i+size]
+		}
+		sb.WriteString(s)
+		for range len(s) {
+			offsets = append(offsets, i)
+		}
+		i += size
+	}
+	return sb.String(), offsets
+}
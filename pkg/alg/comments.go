@@ -0,0 +1,49 @@
+package alg
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrUnclosedComment = errors.New("unclosed comment")
+
+// StripComments replaces the comments of notation with spaces: line comments
+// from // to the end of the line, and block comments from /* to */, such as
+// in annotated reconstructions:
+//
+//	z2 // inspection
+//	R U R' /* pair */ U' F // insert
+//
+// Every byte of a comment becomes a space, so that the positions of errors
+// in what is left are those in the input. Dialects strip comments before
+// they parse.
+func StripComments(input string) (string, error) {
+	if !strings.Contains(input, "/") {
+		return input, nil
+	}
+	out := []byte(input)
+	blank := func(from, to int) {
+		for i := from; i < to; i++ {
+			out[i] = ' '
+		}
+	}
+	for i := 0; i+1 < len(input); i++ {
+		switch input[i : i+2] {
+		case "//":
+			end := strings.IndexByte(input[i:], '\n')
+			if end < 0 {
+				end = len(input) - i
+			}
+			blank(i, i+end)
+			i += end
+		case "/*":
+			end := strings.Index(input[i+2:], "*/")
+			if end < 0 {
+				return "", &ParseError{i, ErrUnclosedComment}
+			}
+			blank(i, i+2+end+2)
+			i += 2 + end + 1
+		}
+	}
+	return string(out), nil
+}
@@ -0,0 +1,81 @@
+package alg
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStripComments(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{"R U", "R U"},
+		{"R U //x", "R U    "},
+		{"R // pair\nU", "R        \nU"},
+		{"R /* a */ U", "R         U"},
+		{"R /* a // b */ U // c", "R              U     "},
+	}
+	for _, tt := range tests {
+		got, err := StripComments(tt.input)
+		if err != nil {
+			t.Fatalf("StripComments(%q): %v", tt.input, err)
+		}
+		if got != tt.want {
+			t.Errorf("StripComments(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+		g, err := ParseNotation(tt.input)
+		if err != nil {
+			t.Fatalf("ParseNotation(%q): %v", tt.input, err)
+		}
+		if n := g.Notation(); n != "R U" {
+			t.Errorf("ParseNotation(%q).Notation() = %q, want %q", tt.input, n, "R U")
+		}
+	}
+}
+
+func TestUnclosedComment(t *testing.T) {
+	for _, parse := range []func(string) error{
+		func(s string) error { _, err := StripComments(s); return err },
+		func(s string) error { _, err := ParseNotation(s); return err },
+	} {
+		err := parse("R U /* x")
+		var pe *ParseError
+		if !errors.As(err, &pe) || !errors.Is(err, ErrUnclosedComment) {
+			t.Fatalf("error %v, want a ParseError of ErrUnclosedComment", err)
+		}
+		if pe.Pos != 4 {
+			t.Errorf("position %d, want 4", pe.Pos)
+		}
+	}
+}
+
+func TestLintAcrossComment(t *testing.T) {
+	for _, input := range []string{"R /* c */ R'", "R // c\nR'"} {
+		issues, err := DialectCube.Lint(input, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(issues) != 1 || issues[0].Message != "R R' cancel out" {
+			t.Errorf("Lint(%q) = %v, want R R' cancel out", input, issues)
+		}
+	}
+}
+
+func TestLintAfterComment(t *testing.T) {
+	issues, err := DialectCube.Lint("R R /* merge */ U U'", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		pos  int
+		text string
+	}{{0, "R R"}, {16, "U U'"}}
+	if len(issues) != len(want) {
+		t.Fatalf("issues %v, want %d", issues, len(want))
+	}
+	for i, w := range want {
+		if issues[i].Pos != w.pos || issues[i].Text != w.text {
+			t.Errorf("issue %d at %d of %q, want at %d of %q", i, issues[i].Pos, issues[i].Text, w.pos, w.text)
+		}
+	}
+}
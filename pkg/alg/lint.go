@@ -0,0 +1,245 @@
+package alg
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// IssueKind is the kind of an issue that Lint flags.
+type IssueKind string
+
+const (
+	IssueCancel   IssueKind = "cancel"   // Adjacent moves that cancel or merge
+	IssueRotation IssueKind = "rotation" // Rotations that cancel, merge or end the algorithm
+	IssueWide     IssueKind = "wide"     // Wide turns not written as Rw or 3Rw
+	IssueAmount   IssueKind = "amount"   // Turn amounts such as R3 or R1
+	IssueFactor   IssueKind = "factor"   // Group factors that repeat nothing
+	IssueSize     IssueKind = "size"     // Moves that the cube size cannot turn
+)
+
+// Issue is something Lint flags in an algorithm, at a byte offset of its
+// input, with the text it flags and the text to replace it with.
+type Issue struct {
+	Pos     int
+	Kind    IssueKind
+	Text    string
+	Message string
+	Fix     string // Empty to delete Text
+}
+
+func (i Issue) String() string {
+	fix := "remove it"
+	if i.Fix != "" {
+		fix = "write " + i.Fix
+	}
+	return fmt.Sprintf("position %d: %s: %s, %s", i.Pos, i.Kind, i.Message, fix)
+}
+
+// lintMove is a move of the input, between byte offsets of the cleaned input.
+type lintMove struct {
+	pos, end int
+	move     Move
+}
+
+// Lint flags what is valid but likely unintended or nonstandard in an
+// algorithm, rather than rejecting it: moves that cancel or merge with the
+// next one, redundant rotations, wide turns written as r or 2Rw, turn amounts
+// such as R3, group factors of 0 or 1 and, if size is not 0, moves that a
+// cube of the size cannot turn. Lowercase wide turns are accepted for this.
+// It returns a *ParseError if the input does not parse at all.
+func (d *Dialect) Lint(input string, size int) ([]Issue, error) {
+	text, err := StripComments(input)
+	if err != nil {
+		return nil, err
+	}
+	var offsets []int
+	if !d.strict {
+		text, offsets = clean(text)
+	}
+	at := func(i int) int {
+		switch {
+		case offsets == nil:
+			return i
+		case i < len(offsets):
+			return offsets[i]
+		}
+		return len(input)
+	}
+
+	var issues []Issue
+	flag := func(pos, end int, kind IssueKind, message, fix string) {
+		issues = append(issues, Issue{at(pos), kind, input[at(pos):at(end)], message, fix})
+	}
+
+	// Moves are compared with the one before them in the same group, which
+	// groups and separators in between reset.
+	var prev *lintMove
+	var outer []*lintMove
+	var opens []int
+	// Rotations at the end hold the cube another way without moving a piece.
+	var trailing []lintMove
+	moved := false
+	for i := 0; i < len(text); i++ {
+		ch := text[i]
+		if ch == ' ' {
+			continue
+		}
+		if _, ok := OpenGroupTypes[ch]; ok {
+			outer = append(outer, prev)
+			opens = append(opens, i)
+			prev = nil
+			continue
+		}
+		if _, ok := CloseGroupTypes[ch]; ok {
+			if len(outer) == 0 {
+				return nil, &ParseError{at(i), ErrUnexpectedGroupClosure}
+			}
+			open := opens[len(opens)-1]
+			outer, opens = outer[:len(outer)-1], opens[:len(opens)-1]
+			prev = nil
+			if len(outer) == 0 {
+				trailing, moved = nil, true
+			}
+			end := i + 1
+			for end < len(text) && isDigit(text[end]) {
+				end++
+			}
+			if end > i+1 {
+				switch factor, err := strToInt(text[i+1 : end]); {
+				case err != nil:
+				case factor == 0:
+					flag(open, end, IssueFactor, "a factor of 0 leaves the group out", "")
+				case factor == 1:
+					flag(i+1, end, IssueFactor, "a factor of 1 repeats nothing", "")
+				}
+				i = end - 1
+			}
+			continue
+		}
+		if _, ok := Separators[ch]; ok {
+			prev = nil
+			continue
+		}
+
+		m, n, lowercase, err := d.lintToken(text[i:])
+		if err != nil {
+			return nil, &ParseError{at(i), err}
+		}
+		cur := &lintMove{i, i + n, *m}
+		raw, _, _ := strings.Cut(text[i:i+n], "@")
+		d.lintNotation(raw, m, lowercase, func(kind IssueKind, message, fix string) {
+			flag(cur.pos, cur.end, kind, message, fix)
+		})
+		if size > 0 {
+			lintSize(m, size, func(kind IssueKind, message, fix string) {
+				flag(cur.pos, cur.end, kind, message, fix)
+			})
+		}
+
+		if prev != nil {
+			if combined, ok := prev.move.CombineMove(*m); ok {
+				kind := IssueCancel
+				if m.IsAny('x', 'y', 'z') {
+					kind = IssueRotation
+				}
+				// Comments between the moves are blanks in text.
+				pair := strings.Join(strings.Fields(text[prev.pos:cur.end]), " ")
+				if combined == nil {
+					flag(prev.pos, cur.end, kind, pair+" cancel out", "")
+				} else {
+					combined.Normalize()
+					flag(prev.pos, cur.end, kind, pair+" merge into one move", combined.Notation())
+				}
+				cur = nil
+			}
+		}
+		prev = cur
+		if len(outer) == 0 {
+			if m.IsAny('x', 'y', 'z') {
+				trailing = append(trailing, lintMove{i, i + n, *m})
+			} else {
+				trailing, moved = nil, true
+			}
+		}
+		i += n - 1
+	}
+	if len(outer) > 0 {
+		return nil, &ParseError{len(input), ErrUnclosedGroup}
+	}
+
+	if moved && len(trailing) > 0 {
+		flag(trailing[0].pos, trailing[len(trailing)-1].end, IssueRotation, "rotations at the end move no piece", "")
+	}
+
+	slices.SortStableFunc(issues, func(a, b Issue) int { return a.Pos - b.Pos })
+	return issues, nil
+}
+
+// lintToken extracts the move at the start of input like the tokenizer, and
+// also a lowercase wide turn such as r', which it reports.
+func (d *Dialect) lintToken(input string) (*Move, int, bool, error) {
+	m, n, err := d.extractToken(input)
+	if !errors.Is(err, ErrTokenExtraction) {
+		return m, n, false, err
+	}
+	upper := unicode.ToUpper(rune(input[0]))
+	if upper == rune(input[0]) || d.orders[upper] == 0 {
+		return nil, 0, false, err
+	}
+	m, n, err = d.extractToken(string(upper) + "w" + input[1:])
+	return m, n - 1, true, err
+}
+
+// lintNotation flags a move written other than as Notation writes it,
+// except for a prime on a half turn, which is common.
+func (d *Dialect) lintNotation(raw string, m *Move, lowercase bool, flag func(IssueKind, string, string)) {
+	canonical := m.Notation()
+	match := d.reToken.FindStringSubmatch(raw + " ")
+	if !lowercase && match != nil {
+		if r, err := strconv.Atoi(match[d.reToken.SubexpIndex("rotations")]); err == nil && r%m.order() == 0 {
+			flag(IssueAmount, raw+" turns a full revolution, which reads as "+canonical, "")
+			return
+		}
+	}
+	switch {
+	case raw == canonical || raw == canonical+"'":
+	case lowercase:
+		flag(IssueWide, raw+" is written "+canonical+" in WCA notation", canonical)
+	case m.Wide && match != nil && match[d.reToken.SubexpIndex("slices")] != "":
+		flag(IssueWide, raw+" is written "+canonical+" in WCA notation", canonical)
+	default:
+		flag(IssueAmount, raw+" is written "+canonical, canonical)
+	}
+}
+
+// wholeCube maps the faces to the rotations that turn the whole cube with
+// them.
+var wholeCube = map[rune]Move{
+	'R': {Operator: 'x', Rotations: 1}, 'L': {Operator: 'x', Rotations: 1, Inverted: true},
+	'U': {Operator: 'y', Rotations: 1}, 'D': {Operator: 'y', Rotations: 1, Inverted: true},
+	'F': {Operator: 'z', Rotations: 1}, 'B': {Operator: 'z', Rotations: 1, Inverted: true},
+}
+
+// lintSize flags moves that a cube of the size cannot turn, or turns
+// otherwise than they read.
+func lintSize(m *Move, size int, flag func(IssueKind, string, string)) {
+	dim := fmt.Sprintf("%dx%d", size, size)
+	switch {
+	case m.Wide && m.Slices > size:
+		flag(IssueSize, fmt.Sprintf("%s turns more layers than the %s has", m.Notation(), dim), "")
+	case m.Wide && m.Slices == size && m.From == 0:
+		rotation, ok := wholeCube[m.Operator]
+		if !ok {
+			return
+		}
+		rotation.Rotations = m.Rotations
+		rotation.Inverted = rotation.Inverted != m.Inverted
+		flag(IssueSize, fmt.Sprintf("%s turns every layer of the %s", m.Notation(), dim), rotation.Notation())
+	case m.IsAny('M', 'E', 'S') && size%2 == 0:
+		flag(IssueSize, fmt.Sprintf("%s does nothing on the %s, which has no middle layer", m.Notation(), dim), "")
+	}
+}
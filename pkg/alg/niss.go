@@ -0,0 +1,74 @@
+package alg
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrNISS = errors.New("only moves and parentheses of moves in NISS")
+
+// NISS is a solution written with NISS, the normal-inverse scramble switch of
+// fewest moves solving: moves in parentheses are turned on the inverse of the
+// scramble, the others on the scramble, such as R U (F' D) L2 for R U L2 on
+// the scramble and F' D on its inverse.
+type NISS struct {
+	Normal  []Move // The moves on the scramble, in order
+	Inverse []Move // The moves on the inverse of the scramble, in order
+}
+
+// ParseNISS parses a solution written with NISS. Parentheses may not nest,
+// repeat or hold brackets, and brackets may not be used outside them, as
+// NISS gives parentheses a meaning of their own.
+func ParseNISS(input string) (NISS, error) {
+	g, err := ParseNotation(input)
+	if err != nil {
+		return NISS{}, err
+	}
+	var n NISS
+	for _, token := range g.Tokens {
+		switch v := token.(type) {
+		case *Move:
+			n.Normal = append(n.Normal, *v)
+		case *Group:
+			if v.GroupType != GroupTypeMove || v.Factor > 1 {
+				return NISS{}, fmt.Errorf("%q: %w", v.Notation(), ErrNISS)
+			}
+			for _, inner := range v.Tokens {
+				m, ok := inner.(*Move)
+				if !ok {
+					return NISS{}, fmt.Errorf("%q: %w", v.Notation(), ErrNISS)
+				}
+				n.Inverse = append(n.Inverse, *m)
+			}
+		case *Separator:
+			return NISS{}, fmt.Errorf("%q: %w", v.Separator, ErrNISS)
+		}
+	}
+	return n, nil
+}
+
+// Solution returns the solution of the scramble: the normal moves, then the
+// inverse moves inverted.
+func (n NISS) Solution() []Move {
+	return append(append([]Move{}, n.Normal...), ReverseMoves(n.Inverse)...)
+}
+
+// InverseSolution returns the solution of the inverse of the scramble: the
+// inverse moves, then the normal moves inverted. It is the inverse of
+// Solution.
+func (n NISS) InverseSolution() []Move {
+	return append(append([]Move{}, n.Inverse...), ReverseMoves(n.Normal)...)
+}
+
+// Notation returns the solution in NISS, the normal moves first and the
+// inverse moves in parentheses after them, such as R U L2 (F' D).
+func (n NISS) Notation() string {
+	s := FormatMoves(n.Normal)
+	if len(n.Inverse) > 0 {
+		if s != "" {
+			s += " "
+		}
+		s += "(" + FormatMoves(n.Inverse) + ")"
+	}
+	return s
+}
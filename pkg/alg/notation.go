@@ -0,0 +1,932 @@
+// Package alg parses and writes the notation of twisty puzzles, WCA notation
+// for cubes with commutators, conjugates and timestamps, and the dialects of
+// other puzzles, into a Group tree and moves.
+package alg
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/zyedidia/generic/stack"
+)
+
+// Regex based on official WCA notation https://www.worldcubeassociation.org/regulations/#article-12-notation
+// Also includes support for commutators and conjugates
+func newTokenRegexp(faces string) *regexp.Regexp {
+	return regexp.MustCompile(
+		`^` +
+			`(?P<from>(?:\d+-)?)` + // Optional first layer of a layer range, e.g. '2-' of 2-4Rw
+			`(?P<slices>\d*)` + // Optional number of slices, or the last layer of a range
+			`(?P<face>[` + regexp.QuoteMeta(faces) + `])` + // A single character for the move, e.g. (U, L, F, R, B, D, M, E, S, x, y, z)
+			`(?P<wide>w?)` + // Optional 'w' for wide
+			`(?P<rotations>\d?)` + // Optional digit for rotations
+			`(?P<prime>'?)` + // Optional "'" for prime
+			`(?P<time>(?:@\d+(?:\.\d+)?)?)` + // Optional timestamp in seconds, e.g. '@0.52'
+			`(?P<end>[ \]\),:]?)`, // Optional end character (space, ']', ')', ',', ':')
+	)
+}
+
+// Dialect holds the tokenizer rules for the notation of one puzzle type.
+// The parsed Group tree is shared between all dialects.
+type Dialect struct {
+	reToken  *regexp.Regexp
+	orders   map[rune]int
+	validate func(*Move) error
+	timed    bool
+	strict   bool
+}
+
+// DialectCube is the WCA notation for NxNxN cubes.
+var DialectCube = NewDialect(turnOrders("ULFRBDMESxyz", 4), (*Move).validate)
+
+// NewDialect returns a Dialect accepting the operators in orders, which maps
+// each operator to the number of turns needed for a full revolution. Validate
+// is called on every extracted move.
+func NewDialect(orders map[rune]int, validate func(*Move) error) *Dialect {
+	faces := make([]rune, 0, len(orders))
+	for op := range orders {
+		faces = append(faces, op)
+	}
+	slices.Sort(faces)
+
+	return &Dialect{
+		reToken:  newTokenRegexp(string(faces)),
+		orders:   orders,
+		validate: validate,
+	}
+}
+
+// Timed returns a copy of the dialect that accepts an optional timestamp in
+// seconds after every move, such as "R@0.52 U'@0.61", as recorded by smart
+// cubes. Moves without one are parsed untimed, with Timed false.
+func (d *Dialect) Timed() *Dialect {
+	timed := *d
+	timed.timed = true
+	return &timed
+}
+
+// Strict returns a copy of the dialect that parses its input as it is,
+// without replacing the Unicode lookalikes of notation characters first, see
+// Clean.
+func (d *Dialect) Strict() *Dialect {
+	strict := *d
+	strict.strict = true
+	return &strict
+}
+
+// Restricted returns a copy of the dialect that accepts only the turns of
+// the operators, such as "RU" for <R,U>, see ParseMoveSet. Wide turns are
+// accepted only for lowercase operators, such as "r".
+func (d *Dialect) Restricted(operators string) *Dialect {
+	restricted := *d
+	restricted.validate = func(m *Move) error {
+		op := m.Operator
+		if m.Wide {
+			op = unicode.ToLower(op)
+		}
+		if !strings.ContainsRune(operators, op) {
+			return ErrMoveNotAllowed
+		}
+		return d.validate(m)
+	}
+	return &restricted
+}
+
+// ParseMoveSet parses a set of operators written as <R,U>, ⟨R,U⟩, "R, U"
+// or "RU", and returns them as "RU". Lowercase letters other than x, y and z
+// stand for wide turns, such as "r" for Rw.
+func ParseMoveSet(text string) (string, error) {
+	var ops []rune
+	for _, r := range text {
+		switch {
+		case strings.ContainsRune("<>⟨⟩, ", r):
+			continue
+		case DialectCube.orders[r] == 0 && DialectCube.orders[unicode.ToUpper(r)] == 0:
+			return "", fmt.Errorf("%q: %w", r, ErrMoveSet)
+		case !slices.Contains(ops, r):
+			ops = append(ops, r)
+		}
+	}
+	if len(ops) == 0 {
+		return "", fmt.Errorf("%q: %w", text, ErrMoveSet)
+	}
+	return string(ops), nil
+}
+
+func turnOrders(faces string, order int) map[rune]int {
+	orders := make(map[rune]int, len(faces))
+	for _, op := range faces {
+		orders[op] = order
+	}
+	return orders
+}
+
+type GroupType int
+
+const (
+	groupTypeNil GroupType = iota
+	GroupTypeMove
+	GroupTypeComm
+)
+
+var (
+	OpenGroupTypes = map[byte]GroupType{
+		'(': GroupTypeMove,
+		'[': GroupTypeComm,
+	}
+	CloseGroupTypes = map[byte]GroupType{
+		')': GroupTypeMove,
+		']': GroupTypeComm,
+	}
+
+	ErrUnexpectedGroupClosure = errors.New("unexpected group closure")
+	ErrUnclosedGroup          = errors.New("unclosed group")
+	ErrTokenExtraction        = errors.New("token extraction")
+	ErrRotationMove           = errors.New("slices or wide with x, y, z")
+	ErrSlicesMove             = errors.New("slices without wide move")
+	ErrMultipleSeparators     = errors.New("multiple separators in one group")
+	ErrSeparatorGroup         = errors.New("separators not in comm group")
+	ErrTimestamp              = errors.New("timestamp in untimed notation")
+	ErrMoveNotAllowed         = errors.New("move outside the allowed move set")
+	ErrMoveSet                = errors.New("unknown operator in move set")
+	ErrLayerRange             = errors.New("layer range not of a face from outer to inner layer")
+	ErrExpansionTooLarge      = errors.New("expansion larger than the limit")
+)
+
+// ParseError is an error of ParseNotation at a byte offset of its input.
+type ParseError struct {
+	Pos int
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("position %d: %v", e.Pos, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+type Tokenizable interface {
+	String() string
+	CombineMove(Move) (*Move, bool)
+}
+
+type Group struct {
+	Tokens    []Tokenizable
+	Factor    int
+	GroupType GroupType
+}
+
+func NewGroup(groupType GroupType) Group {
+	return Group{
+		GroupType: groupType,
+		Factor:    1,
+	}
+}
+
+func (t *Group) String() string {
+	return fmt.Sprintf("Group: n=%d factor=%d type=%d", len(t.Tokens), t.Factor, t.GroupType)
+}
+
+func (t *Group) CombineMove(combo Move) (*Move, bool) {
+	return nil, false
+}
+
+func (g *Group) AddToken(t Tokenizable) {
+	g.Tokens = append(g.Tokens, t)
+}
+
+func (g *Group) Print() {
+	g.print(0)
+}
+
+func (g *Group) print(level int) {
+	indent := strings.Repeat("  ", level)
+	fmt.Printf("%s%s\n", indent, g.String())
+
+	for _, token := range g.Tokens {
+		group, ok := token.(*Group)
+		if ok {
+			group.print(level + 1)
+		} else {
+			fmt.Printf("%s%s\n", indent, token.String())
+		}
+
+	}
+}
+
+// ExpandLimits bound what ExpandWithin expands, so that notation from
+// anyone, such as requests of a server, cannot take all memory with groups
+// of groups such as ((((R)9)9)9)9 nested many times. Zero fields set no
+// limit.
+type ExpandLimits struct {
+	MaxMoves  int // Most moves, counted as Length counts them
+	MaxDepth  int // Deepest nesting of groups, 1 for (R U)
+	MaxFactor int // Largest product of the factors of nested groups, 81 for ((R)9)9
+}
+
+// DefaultExpandLimits are the limits of Expand, far above any algorithm
+// written by hand.
+var DefaultExpandLimits = ExpandLimits{MaxMoves: 1_000_000, MaxDepth: 100}
+
+// Expand returns a flattened slice of Moves from the Group, handling nested groups and separators.
+// Moves are repeated by if the group Factor > 1.
+// Returns an error for multiple or invalid separators, and ErrExpansionTooLarge
+// beyond DefaultExpandLimits.
+func (g *Group) Expand() ([]Move, error) {
+	return g.ExpandWithin(DefaultExpandLimits)
+}
+
+// ExpandWithin returns the moves of the group as Expand does, or
+// ErrExpansionTooLarge without expanding it if it would go beyond the limits.
+func (g *Group) ExpandWithin(l ExpandLimits) ([]Move, error) {
+	if l.MaxDepth > 0 {
+		if d := g.depth(l.MaxDepth + 1); d > l.MaxDepth {
+			return nil, fmt.Errorf("depth of more than %d: %w", l.MaxDepth, ErrExpansionTooLarge)
+		}
+	}
+	if l.MaxFactor > 0 && g.factor(l.MaxFactor+1) > l.MaxFactor {
+		return nil, fmt.Errorf("factors of more than %d: %w", l.MaxFactor, ErrExpansionTooLarge)
+	}
+	if l.MaxMoves > 0 && g.Length(l.MaxMoves+1) > l.MaxMoves {
+		return nil, fmt.Errorf("more than %d moves: %w", l.MaxMoves, ErrExpansionTooLarge)
+	}
+	return g.expand()
+}
+
+// depth returns how deeply groups nest in the group, not counting the group
+// of the whole algorithm, stopping at limit.
+func (g *Group) depth(limit int) int {
+	d := 0
+	for _, token := range g.Tokens {
+		if v, ok := token.(*Group); ok && d < limit {
+			d = max(d, min(v.depth(limit-1)+1, limit))
+		}
+	}
+	return d
+}
+
+// factor returns the largest product of the factors of the group and the
+// groups nested in it, stopping at limit.
+func (g *Group) factor(limit int) int {
+	f := max(g.Factor, 1)
+	inner := 1
+	for _, token := range g.Tokens {
+		if v, ok := token.(*Group); ok {
+			inner = max(inner, v.factor((limit+f-1)/f))
+		}
+	}
+	if inner > limit/f {
+		return limit
+	}
+	return min(f*inner, limit)
+}
+
+// expand expands the group without checking limits.
+func (g *Group) expand() ([]Move, error) {
+	var head []Move
+	var tail []Move
+	var separator *Separator
+
+	for _, token := range g.Tokens {
+		switch v := token.(type) {
+		case *Move:
+			if separator == nil {
+				head = append(head, *v)
+			} else {
+				tail = append(tail, *v)
+			}
+		case *Separator:
+			if separator != nil {
+				return nil, ErrMultipleSeparators
+			}
+			separator = v
+		case *Group:
+			tokens, err := v.expand()
+			if err != nil {
+				return nil, err
+			}
+
+			if separator == nil {
+				head = append(head, tokens...)
+			} else {
+				tail = append(tail, tokens...)
+			}
+		}
+	}
+
+	res := append(head, tail...)
+
+	if separator != nil && g.GroupType != GroupTypeComm {
+		return nil, ErrSeparatorGroup
+	}
+
+	// Conjugates have reversed head after tail
+	if separator == &SepConjugate {
+		res = append(res, ReverseMoves(head)...)
+	}
+
+	// Commutators have reversed head and tail after normal tail
+	if separator == &SepCommutator {
+		res = append(res, ReverseMoves(head)...)
+		res = append(res, ReverseMoves(tail)...)
+	}
+
+	out := res
+	if g.Factor > 1 {
+		out = make([]Move, 0, len(res)*g.Factor)
+		for i := 0; i < g.Factor; i++ {
+			out = append(out, res...)
+		}
+	}
+
+	return NormalizeMoves(out)
+}
+
+// Length returns the number of moves that Expand returns before moves that
+// cancel are left out, without expanding the group, such as 12 for
+// ((R U)2)3 or 8 for [R, U]2. It stops counting at limit, if positive, so
+// that deeply nested factors cannot overflow it.
+func (g *Group) Length(limit int) int {
+	var head, tail int
+	var separator *Separator
+	for _, token := range g.Tokens {
+		n := 0
+		switch v := token.(type) {
+		case *Move:
+			n = 1
+		case *Separator:
+			separator = v
+		case *Group:
+			n = v.Length(limit)
+		}
+		if separator == nil {
+			head += n
+		} else {
+			tail += n
+		}
+	}
+
+	n := head + tail
+	switch separator {
+	case &SepConjugate:
+		n += head
+	case &SepCommutator:
+		n += head + tail
+	}
+	if g.Factor > 1 {
+		if limit > 0 && n > limit/g.Factor {
+			return limit
+		}
+		n *= g.Factor
+	}
+	if limit > 0 {
+		n = min(n, limit)
+	}
+	return n
+}
+
+// Notation returns the group in WCA notation as ParseNotation reads it back,
+// such as "[R U: F]" or "(R U R' U')2", keeping its groups, commutators and
+// conjugates rather than expanding them as FormatMoves(g.Expand()) does.
+// Moves are written as Move.Notation writes them, without timestamps.
+func (g *Group) Notation() string {
+	var sb strings.Builder
+	for i, token := range g.Tokens {
+		switch v := token.(type) {
+		case *Separator:
+			sb.WriteRune(v.Separator)
+			continue
+		case *Move:
+			if i > 0 {
+				sb.WriteByte(' ')
+			}
+			sb.WriteString(v.Notation())
+		case *Group:
+			if i > 0 {
+				sb.WriteByte(' ')
+			}
+			sb.WriteString(v.Notation())
+		}
+	}
+	inner := sb.String()
+
+	switch g.GroupType {
+	case GroupTypeMove:
+		inner = "(" + inner + ")"
+	case GroupTypeComm:
+		inner = "[" + inner + "]"
+	}
+	if g.Factor > 1 {
+		inner += strconv.Itoa(g.Factor)
+	}
+	return inner
+}
+
+// Invert returns a new group that undoes the group, keeping its structure:
+// sequences are reversed with every move inverted, [A, B] becomes [B, A],
+// [A: B] becomes [A: B'] and factors stay, so that the inverse of
+// [R U: [R, U']] (R U)2 is (U' R')2 [R U: [U', R]]. Groups that Expand
+// rejects, such as with two separators, invert to groups that it rejects.
+func (g *Group) Invert() *Group {
+	out := &Group{Factor: g.Factor, GroupType: g.GroupType}
+
+	sep := -1
+	for i, token := range g.Tokens {
+		if _, ok := token.(*Separator); ok {
+			if sep >= 0 {
+				sep = -1
+				break
+			}
+			sep = i
+		}
+	}
+	if g.GroupType == GroupTypeComm && sep >= 0 {
+		head, tail := g.Tokens[:sep], g.Tokens[sep+1:]
+		if g.Tokens[sep] == &SepCommutator {
+			out.Tokens = append(append(copyTokens(tail), &SepCommutator), copyTokens(head)...)
+		} else {
+			out.Tokens = append(append(copyTokens(head), &SepConjugate), invertTokens(tail)...)
+		}
+		return out
+	}
+	out.Tokens = invertTokens(g.Tokens)
+	return out
+}
+
+// invertTokens returns the tokens in reverse order, each inverted.
+func invertTokens(tokens []Tokenizable) []Tokenizable {
+	out := make([]Tokenizable, 0, len(tokens))
+	for i := len(tokens) - 1; i >= 0; i-- {
+		switch v := tokens[i].(type) {
+		case *Move:
+			m := *v
+			m.Inverted = !m.Inverted
+			out = append(out, &m)
+		case *Group:
+			out = append(out, v.Invert())
+		default:
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// copyTokens returns a deep copy of the tokens, sharing the separators, which
+// Expand tells apart by address.
+func copyTokens(tokens []Tokenizable) []Tokenizable {
+	out := make([]Tokenizable, 0, len(tokens))
+	for _, token := range tokens {
+		switch v := token.(type) {
+		case *Move:
+			m := *v
+			out = append(out, &m)
+		case *Group:
+			g := *v
+			g.Tokens = copyTokens(v.Tokens)
+			out = append(out, &g)
+		default:
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func ReverseMoves(moves []Move) []Move {
+	out := reverse(moves)
+
+	for i, m := range out {
+		newMove := m
+		newMove.Inverted = !m.Inverted
+
+		out[i] = newMove
+	}
+
+	return out
+}
+
+func NormalizeMoves(moves []Move) ([]Move, error) {
+	normalized := make([]Move, 0, len(moves))
+
+	for _, t := range moves {
+		if len(normalized) == 0 {
+			normalized = append(normalized, t)
+			continue
+		}
+
+		lastIndex := len(normalized) - 1
+		lastMove := normalized[lastIndex]
+
+		combined, ok := lastMove.CombineMove(t)
+		if !ok {
+			normalized = append(normalized, t)
+			continue
+		}
+		if combined == nil {
+			normalized = normalized[:lastIndex]
+			continue
+		}
+
+		combined.Normalize()
+		normalized[lastIndex] = *combined
+	}
+
+	return normalized, nil
+}
+
+var (
+	Separators = map[byte]*Separator{
+		':': &SepConjugate,
+		',': &SepCommutator,
+	}
+
+	SepCommutator = Separator{','}
+	SepConjugate  = Separator{':'}
+)
+
+type Separator struct {
+	Separator rune
+}
+
+func (s *Separator) String() string {
+	return fmt.Sprintf("Separator: %s", string(s.Separator))
+}
+
+func (t *Separator) CombineMove(combo Move) (*Move, bool) {
+	return nil, false
+}
+
+type Move struct {
+	Slices    int // Layers turned from the face, or the last of a layer range
+	From      int // First layer of a layer range, such as 2 of 2-4Rw, or 0 from the face
+	Operator  rune
+	Wide      bool
+	Rotations int
+	Inverted  bool
+	Order     int // Turns per full revolution, 4 if zero
+
+	// Time is the time of the move since the start of a recording, set when
+	// Timed is true.
+	Time  time.Duration
+	Timed bool
+}
+
+func (t *Move) String() string {
+	s := fmt.Sprintf(
+		"Move: Slices=%d, Operator=%c, Wide=%t, Rotations=%d, Inverted=%t",
+		t.Slices, t.Operator, t.Wide, t.Rotations, t.Inverted,
+	)
+	if t.From > 0 {
+		s += fmt.Sprintf(", From=%d", t.From)
+	}
+	if t.Timed {
+		s += fmt.Sprintf(", Time=%s", t.Time)
+	}
+	return s
+}
+
+// CombineMove merges two compatible moves.
+// Returns the combined move or nil if they cancel out, and a bool indicating success.
+// Timed moves are separate turns of a recording and are never merged.
+func (t *Move) CombineMove(combo Move) (*Move, bool) {
+	if t.Timed || combo.Timed {
+		return nil, false
+	}
+	if t.Operator != combo.Operator || t.Slices != combo.Slices || t.From != combo.From || t.Wide != combo.Wide || t.order() != combo.order() {
+		return nil, false
+	}
+
+	out := *t
+	if out.Inverted == combo.Inverted {
+		out.Rotations += combo.Rotations
+	} else {
+		out.Rotations -= combo.Rotations
+	}
+
+	if out.Rotations%out.order() == 0 {
+		return nil, true
+	}
+
+	return &out, true
+}
+
+func (t *Move) order() int {
+	if t.Order == 0 {
+		return 4
+	}
+	return t.Order
+}
+
+// IsAny reports whether the move turns one of the operators.
+func (t *Move) IsAny(runes ...rune) bool {
+	return slices.Contains(runes, t.Operator)
+}
+
+func (t *Move) validate() error {
+	if t.IsAny('x', 'y', 'z') && (t.Slices > 0 || t.Wide) {
+		return ErrRotationMove
+	}
+
+	if t.From > 0 && (t.IsAny('M', 'E', 'S') || t.From > t.Slices) {
+		return ErrLayerRange
+	}
+
+	if t.Slices != 0 && !t.Wide {
+		return ErrSlicesMove
+	}
+	return nil
+}
+
+func (t *Move) Normalize() {
+	if t.Slices == 0 && t.Wide {
+		t.Slices = 2
+	}
+	if t.From == 1 {
+		t.From = 0
+	}
+
+	order := t.order()
+	t.Rotations = t.Rotations % order
+
+	if t.Rotations < 0 {
+		t.Rotations *= -1
+		t.Inverted = !t.Inverted
+	}
+
+	if t.Rotations == 0 {
+		t.Rotations = 1
+	} else if t.Rotations > order/2 {
+		t.Rotations = order - t.Rotations
+		t.Inverted = !t.Inverted
+	}
+}
+
+func (d *Dialect) extractToken(input string) (*Move, int, error) {
+	reToken := d.reToken
+	matches := reToken.FindStringSubmatch(input)
+
+	if len(matches) > 0 {
+		slices, err := strToInt(matches[reToken.SubexpIndex("slices")])
+		if err != nil {
+			return nil, 0, err
+		}
+
+		from, err := strToInt(strings.TrimSuffix(matches[reToken.SubexpIndex("from")], "-"))
+		if err != nil {
+			return nil, 0, err
+		}
+		if from > 0 && slices == 0 {
+			return nil, 0, ErrLayerRange
+		}
+
+		rotations, err := strToInt(matches[reToken.SubexpIndex("rotations")])
+		if err != nil {
+			return nil, 0, err
+		}
+
+		t := &Move{
+			Slices:    slices,
+			From:      from,
+			Operator:  rune(matches[reToken.SubexpIndex("face")][0]),
+			Wide:      matches[reToken.SubexpIndex("wide")] == "w",
+			Rotations: rotations,
+			Inverted:  matches[reToken.SubexpIndex("prime")] == "'",
+		}
+		if order := d.orders[t.Operator]; order != 4 {
+			t.Order = order
+		}
+
+		if stamp := matches[reToken.SubexpIndex("time")]; stamp != "" {
+			if !d.timed {
+				return nil, 0, ErrTimestamp
+			}
+			seconds, err := strconv.ParseFloat(stamp[1:], 64)
+			if err != nil {
+				return nil, 0, err
+			}
+			t.Time = time.Duration(math.Round(seconds * float64(time.Second)))
+			t.Timed = true
+		}
+
+		length := len(matches[0]) - len(matches[reToken.SubexpIndex("end")])
+		t.Normalize()
+		err = d.validate(t)
+
+		return t, length, err
+	}
+
+	return nil, 0, ErrTokenExtraction
+}
+
+// ParseNotation parses cube notation into a Group tree.
+func ParseNotation(input string) (*Group, error) {
+	return DialectCube.ParseNotation(input)
+}
+
+// ParseTimedNotation parses cube notation with an optional timestamp after
+// every move, see Dialect.Timed.
+func ParseTimedNotation(input string) (*Group, error) {
+	return DialectCube.Timed().ParseNotation(input)
+}
+
+// ParseNotation parses input with the tokenizer rules of the dialect,
+// skipping its comments, see StripComments. Errors are *ParseError, wrapping
+// the errors of this package, at the position in input even where Clean
+// changed it.
+func (d *Dialect) ParseNotation(input string) (*Group, error) {
+	input, err := StripComments(input)
+	if err != nil {
+		return nil, err
+	}
+	if d.strict {
+		return d.parse(input)
+	}
+	cleaned, offsets := clean(input)
+	group, err := d.parse(cleaned)
+	var perr *ParseError
+	if errors.As(err, &perr) {
+		if perr.Pos < len(offsets) {
+			perr.Pos = offsets[perr.Pos]
+		} else {
+			perr.Pos = len(input)
+		}
+	}
+	return group, err
+}
+
+// parse parses input as it is.
+func (d *Dialect) parse(input string) (*Group, error) {
+	stack := stack.New[Group]()
+	currentGroup := NewGroup(groupTypeNil)
+
+	for i := 0; i < len(input); i++ {
+		ch := input[i]
+
+		if ch == ' ' {
+			continue
+		}
+
+		if v, ok := OpenGroupTypes[ch]; ok {
+			stack.Push(currentGroup)
+			currentGroup = NewGroup(v)
+		} else if _, ok := CloseGroupTypes[ch]; ok {
+			group := currentGroup
+
+			if stack.Size() == 0 {
+				return nil, &ParseError{i, ErrUnexpectedGroupClosure}
+			}
+			currentGroup = stack.Pop()
+
+			i++
+			start := i
+			for i < len(input) && isDigit(input[i]) {
+				i++
+			}
+			if i > start {
+				factor, err := strToInt(input[start:i])
+				if err != nil {
+					return nil, &ParseError{start, ErrExpansionTooLarge}
+				}
+				group.Factor = factor
+			}
+
+			currentGroup.AddToken(&group)
+			i--
+		} else if v, ok := Separators[ch]; ok {
+			currentGroup.AddToken(v)
+		} else {
+			token, end, err := d.extractToken(input[i:])
+			if err != nil {
+				return nil, &ParseError{i, err}
+			}
+
+			currentGroup.AddToken(token)
+			i += end - 1
+		}
+	}
+
+	if stack.Size() > 0 {
+		return nil, &ParseError{len(input), ErrUnclosedGroup}
+	}
+
+	return &currentGroup, nil
+}
+
+// Notation returns the move in WCA notation, e.g. "3Rw'", and layer ranges
+// as "2-4Rw'". Half turns are written without a prime.
+func (t *Move) Notation() string {
+	var sb strings.Builder
+	if t.From > 0 {
+		fmt.Fprintf(&sb, "%d-%d", t.From, t.Slices)
+	} else if t.Wide && t.Slices != 0 && t.Slices != 2 {
+		sb.WriteString(strconv.Itoa(t.Slices))
+	}
+	sb.WriteRune(t.Operator)
+	if t.Wide {
+		sb.WriteByte('w')
+	}
+	if t.Rotations > 1 {
+		sb.WriteString(strconv.Itoa(t.Rotations))
+	}
+	if t.Inverted && t.Rotations*2 != t.order() {
+		sb.WriteByte('\'')
+	}
+	return sb.String()
+}
+
+// FormatMoves returns the moves in WCA notation, separated by spaces.
+func FormatMoves(moves []Move) string {
+	tokens := make([]string, len(moves))
+	for i := range moves {
+		tokens[i] = moves[i].Notation()
+	}
+	return strings.Join(tokens, " ")
+}
+
+// Plane is a plane through the middle of a cube that algorithms are mirrored
+// through, named by the slice in it.
+type Plane rune
+
+const (
+	PlaneM Plane = 'M' // Between L and R, mirroring left to right
+	PlaneE Plane = 'E' // Between U and D, mirroring top to bottom
+	PlaneS Plane = 'S' // Between F and B, mirroring front to back
+)
+
+// planeFaces holds the faces that a plane swaps, and the slice and rotation
+// about the axis through it, which turn the same way mirrored.
+var planeFaces = map[Plane][4]rune{
+	PlaneM: {'R', 'L', 'M', 'x'},
+	PlaneE: {'U', 'D', 'E', 'y'},
+	PlaneS: {'F', 'B', 'S', 'z'},
+}
+
+// mirror returns the move mirrored through the plane.
+func (t Move) mirror(p Plane) Move {
+	faces := planeFaces[p]
+	switch t.Operator {
+	case faces[0]:
+		t.Operator = faces[1]
+	case faces[1]:
+		t.Operator = faces[0]
+	}
+	if !t.IsAny(faces[2], faces[3]) {
+		t.Inverted = !t.Inverted
+	}
+	return t
+}
+
+// MirrorMoves returns the moves mirrored left to right, through the plane of
+// the M slice. R and L swap, and every turn except those around the x axis
+// turns the other way.
+func MirrorMoves(moves []Move) []Move {
+	return MirrorMovesThrough(moves, PlaneM)
+}
+
+// MirrorMovesThrough returns the moves mirrored through the plane: the faces
+// on either side of it swap, and every turn except the slice in it and the
+// rotation about its axis turns the other way.
+func MirrorMovesThrough(moves []Move, p Plane) []Move {
+	out := make([]Move, len(moves))
+	for i, m := range moves {
+		out[i] = m.mirror(p)
+	}
+	return out
+}
+
+// Mirror returns a new group of the group mirrored through the plane, such as
+// L' U' L for R U R' through PlaneM, move by move so that its groups,
+// commutators and conjugates stay as they are.
+func (g *Group) Mirror(p Plane) *Group {
+	out := &Group{Factor: g.Factor, GroupType: g.GroupType}
+	for _, token := range g.Tokens {
+		switch v := token.(type) {
+		case *Move:
+			m := v.mirror(p)
+			out.Tokens = append(out.Tokens, &m)
+		case *Group:
+			out.Tokens = append(out.Tokens, v.Mirror(p))
+		default:
+			out.Tokens = append(out.Tokens, v)
+		}
+	}
+	return out
+}
+
+// FormatTimedMoves returns the moves in the format of ParseTimedNotation, with
+// every timestamp in seconds to the millisecond.
+func FormatTimedMoves(moves []Move) string {
+	tokens := make([]string, len(moves))
+	for i := range moves {
+		tokens[i] = fmt.Sprintf("%s@%.3f", moves[i].Notation(), moves[i].Time.Seconds())
+	}
+	return strings.Join(tokens, " ")
+}
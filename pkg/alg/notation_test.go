@@ -0,0 +1,82 @@
+package alg
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGroupFactor(t *testing.T) {
+	tests := []struct {
+		input  string
+		factor int
+		moves  int
+	}{
+		{"(R U)2", 2, 4},
+		{"(R U)12", 12, 24},
+		{"[R, U]10", 10, 40},
+	}
+	for _, tt := range tests {
+		g, err := ParseNotation(tt.input)
+		if err != nil {
+			t.Fatalf("ParseNotation(%q): %v", tt.input, err)
+		}
+		group := g.Tokens[0].(*Group)
+		if group.Factor != tt.factor {
+			t.Errorf("ParseNotation(%q): factor %d, want %d", tt.input, group.Factor, tt.factor)
+		}
+		if got := g.Notation(); got != tt.input {
+			t.Errorf("ParseNotation(%q).Notation() = %q", tt.input, got)
+		}
+		moves, err := g.Expand()
+		if err != nil {
+			t.Fatalf("Expand(%q): %v", tt.input, err)
+		}
+		if len(moves) != tt.moves {
+			t.Errorf("Expand(%q): %d moves, want %d", tt.input, len(moves), tt.moves)
+		}
+	}
+}
+
+func TestLayerRange(t *testing.T) {
+	tests := []struct {
+		input    string
+		want     Move
+		notation string
+		err      error
+	}{
+		{input: "2-4Rw'", want: Move{From: 2, Slices: 4, Operator: 'R', Wide: true, Rotations: 1, Inverted: true}, notation: "2-4Rw'"},
+		{input: "10Rw", want: Move{Slices: 10, Operator: 'R', Wide: true, Rotations: 1}, notation: "10Rw"},
+		{input: "1-3Rw", want: Move{Slices: 3, Operator: 'R', Wide: true, Rotations: 1}, notation: "3Rw"},
+		{input: "1Rw", want: Move{Slices: 1, Operator: 'R', Wide: true, Rotations: 1}, notation: "1Rw"},
+		{input: "1-1Rw", want: Move{Slices: 1, Operator: 'R', Wide: true, Rotations: 1}, notation: "1Rw"},
+		{input: "3-2Rw", err: ErrLayerRange},
+		{input: "2-4R", err: ErrSlicesMove},
+		{input: "2-4M", err: ErrLayerRange},
+	}
+	for _, tt := range tests {
+		g, err := ParseNotation(tt.input)
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("ParseNotation(%q): error %v, want %v", tt.input, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("ParseNotation(%q): %v", tt.input, err)
+		}
+		m := *g.Tokens[0].(*Move)
+		if m != tt.want {
+			t.Errorf("ParseNotation(%q) = %+v, want %+v", tt.input, m, tt.want)
+		}
+		if got := g.Notation(); got != tt.notation {
+			t.Errorf("ParseNotation(%q).Notation() = %q, want %q", tt.input, got, tt.notation)
+		}
+		again, err := ParseNotation(tt.notation)
+		if err != nil {
+			t.Fatalf("ParseNotation(%q): %v", tt.notation, err)
+		}
+		if m := *again.Tokens[0].(*Move); m != tt.want {
+			t.Errorf("ParseNotation(%q) = %+v, want %+v as %q", tt.notation, m, tt.want, tt.input)
+		}
+	}
+}
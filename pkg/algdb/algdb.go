@@ -0,0 +1,120 @@
+// Package algdb imports algorithm databases, such as alg sheets exported from
+// a spreadsheet, and reports statistics on them.
+package algdb
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"io"
+	"strings"
+)
+
+var (
+	ErrColumns = errors.New("alg database rows need set, case and alg columns, and optionally learned and notes")
+	ErrLearned = errors.New("learned column must be yes, no or empty")
+)
+
+// Alg is an algorithm of a case.
+type Alg struct {
+	Notation string
+	Moves    []cube.Move
+}
+
+// Case is a case of an algorithm set with its algorithms, the preferred one
+// first, and what the owner of the database noted of it.
+type Case struct {
+	Set     string
+	Name    string
+	Algs    []Alg
+	Learned bool     // Whether any row of the case is marked learned
+	Notes   []string // The notes of the rows of the case, in order
+}
+
+// DB is an imported algorithm database.
+type DB struct {
+	Cases []*Case
+}
+
+// Import reads a database in CSV format with one algorithm per row, as
+// set,case,alg, optionally followed by learned, as yes, no or empty, and a
+// note. Rows of the same set and case add alternative algorithms. An optional
+// header row starting with "set" and lines starting with # are skipped.
+func Import(r io.Reader) (*DB, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	db := &DB{}
+	cases := map[[2]string]*Case{}
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 3 || len(record) > 5 {
+			return nil, fmt.Errorf("row %d: %w", row, ErrColumns)
+		}
+		if row == 1 && strings.EqualFold(record[0], "set") {
+			continue
+		}
+
+		group, err := cube.ParseNotation(record[2])
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+		moves, err := group.Expand()
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+
+		key := [2]string{record[0], record[1]}
+		c, ok := cases[key]
+		if !ok {
+			c = &Case{Set: record[0], Name: record[1]}
+			cases[key] = c
+			db.Cases = append(db.Cases, c)
+		}
+		c.Algs = append(c.Algs, Alg{Notation: record[2], Moves: moves})
+		if len(record) > 3 {
+			learned, err := parseLearned(record[3])
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %q: %w", row, record[3], err)
+			}
+			c.Learned = c.Learned || learned
+		}
+		if len(record) > 4 && strings.TrimSpace(record[4]) != "" {
+			c.Notes = append(c.Notes, strings.TrimSpace(record[4]))
+		}
+	}
+	return db, nil
+}
+
+// parseLearned parses the learned column of a row.
+func parseLearned(s string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "yes", "y", "x", "true", "1":
+		return true, nil
+	case "no", "n", "false", "0", "":
+		return false, nil
+	}
+	return false, ErrLearned
+}
+
+// Sets returns the names of the sets in the order of their first case.
+func (db *DB) Sets() []string {
+	var sets []string
+	seen := map[string]bool{}
+	for _, c := range db.Cases {
+		if !seen[c.Set] {
+			seen[c.Set] = true
+			sets = append(sets, c.Set)
+		}
+	}
+	return sets
+}
@@ -0,0 +1,204 @@
+package algdb
+
+import (
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"io"
+	"text/tabwriter"
+)
+
+// AlgStats holds the move counts of an algorithm.
+type AlgStats struct {
+	Notation  string
+	HTM       int // Half turn metric, counting slice turns twice
+	STM       int // Slice turn metric
+	Rotations int
+}
+
+// CaseStats holds the statistics of a case over its algorithms.
+type CaseStats struct {
+	Set, Case      string
+	Algs           []AlgStats
+	AvgHTM, AvgSTM float64
+	Rotations      int
+}
+
+// SetStats holds the statistics of a set over the preferred algorithm of
+// every case.
+type SetStats struct {
+	Set            string
+	Cases          int
+	Algs           int
+	AvgHTM, AvgSTM float64
+	Rotations      int
+}
+
+// AlgRef identifies an algorithm of a database.
+type AlgRef struct {
+	Set, Case string
+	Index     int
+}
+
+// Relation is how a duplicate algorithm follows from another.
+type Relation string
+
+const (
+	Same          Relation = "same"
+	Inverse       Relation = "inverse"
+	Mirror        Relation = "mirror"
+	MirrorInverse Relation = "mirror inverse"
+	Equivalent    Relation = "same up to AUF and rotation"
+)
+
+// Duplicate is an algorithm that repeats an earlier one up to mirroring and
+// inversion, or up to AUF and rotation.
+type Duplicate struct {
+	Alg, Of  AlgRef
+	Relation Relation
+}
+
+// Report holds the statistics of a database.
+type Report struct {
+	Cases      []CaseStats
+	Sets       []SetStats
+	Duplicates []Duplicate
+}
+
+// Count returns the move counts of an algorithm. Rotations are not counted as
+// turns.
+func Count(moves []cube.Move) AlgStats {
+	var s AlgStats
+	for _, m := range moves {
+		switch m.Operator {
+		case 'x', 'y', 'z':
+			s.Rotations++
+		case 'M', 'E', 'S':
+			s.HTM += 2
+			s.STM++
+		default:
+			s.HTM++
+			s.STM++
+		}
+	}
+	return s
+}
+
+// Stats computes the statistics of every case and set of the database, and
+// finds the algorithms that are the same as another one, or its inverse or
+// mirror.
+func Stats(db *DB) *Report {
+	r := &Report{}
+	sets := map[string]*SetStats{}
+	for _, name := range db.Sets() {
+		sets[name] = &SetStats{Set: name}
+	}
+
+	for _, c := range db.Cases {
+		cs := CaseStats{Set: c.Set, Case: c.Name}
+		for _, alg := range c.Algs {
+			s := Count(alg.Moves)
+			s.Notation = alg.Notation
+			cs.Algs = append(cs.Algs, s)
+			cs.AvgHTM += float64(s.HTM) / float64(len(c.Algs))
+			cs.AvgSTM += float64(s.STM) / float64(len(c.Algs))
+			cs.Rotations += s.Rotations
+		}
+		r.Cases = append(r.Cases, cs)
+
+		set := sets[c.Set]
+		set.Cases++
+		set.Algs += len(c.Algs)
+		if len(cs.Algs) > 0 {
+			set.AvgHTM += float64(cs.Algs[0].HTM)
+			set.AvgSTM += float64(cs.Algs[0].STM)
+			set.Rotations += cs.Algs[0].Rotations
+		}
+	}
+
+	for _, name := range db.Sets() {
+		set := sets[name]
+		set.AvgHTM /= float64(set.Cases)
+		set.AvgSTM /= float64(set.Cases)
+		r.Sets = append(r.Sets, *set)
+	}
+
+	r.Duplicates = duplicates(db)
+	return r
+}
+
+// duplicates compares the algorithms in their normalized form, so that
+// cancelling moves are ignored, and then in their canonical form, so that
+// the same solution done from another angle is caught too.
+func duplicates(db *DB) []Duplicate {
+	var out []Duplicate
+	seen, canonical := map[string]AlgRef{}, map[string]AlgRef{}
+	for _, c := range db.Cases {
+		for i, alg := range c.Algs {
+			ref := AlgRef{c.Set, c.Name, i}
+			inverse := cube.ReverseMoves(alg.Moves)
+			variants := []struct {
+				moves    []cube.Move
+				relation Relation
+			}{
+				{alg.Moves, Same},
+				{inverse, Inverse},
+				{cube.MirrorMoves(alg.Moves), Mirror},
+				{cube.MirrorMoves(inverse), MirrorInverse},
+			}
+
+			found := false
+			for _, v := range variants {
+				if of, ok := seen[key(v.moves)]; ok {
+					out = append(out, Duplicate{ref, of, v.relation})
+					found = true
+					break
+				}
+			}
+			canon := cube.FormatMoves(cube.CanonicalMoves(alg.Moves))
+			if of, ok := canonical[canon]; ok && !found {
+				out = append(out, Duplicate{ref, of, Equivalent})
+			}
+			if _, ok := seen[key(alg.Moves)]; !ok {
+				seen[key(alg.Moves)] = ref
+			}
+			if _, ok := canonical[canon]; !ok {
+				canonical[canon] = ref
+			}
+		}
+	}
+	return out
+}
+
+func key(moves []cube.Move) string {
+	normalized, _ := cube.NormalizeMoves(moves)
+	return cube.FormatMoves(normalized)
+}
+
+// WriteText writes the report as aligned tables of sets, cases and
+// duplicates.
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "SET\tCASES\tALGS\tHTM\tSTM\tROTATIONS")
+	for _, s := range r.Sets {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f\t%.1f\t%d\n", s.Set, s.Cases, s.Algs, s.AvgHTM, s.AvgSTM, s.Rotations)
+	}
+
+	fmt.Fprintln(tw, "\nSET\tCASE\tALGS\tHTM\tSTM\tROTATIONS")
+	for _, c := range r.Cases {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%.1f\t%.1f\t%d\n", c.Set, c.Case, len(c.Algs), c.AvgHTM, c.AvgSTM, c.Rotations)
+	}
+
+	if len(r.Duplicates) > 0 {
+		fmt.Fprintln(tw, "\nDUPLICATE\tOF\tRELATION")
+		for _, d := range r.Duplicates {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", d.Alg, d.Of, d.Relation)
+		}
+	}
+	return tw.Flush()
+}
+
+// String returns the reference as "set/case #n", counting from 1.
+func (a AlgRef) String() string {
+	return fmt.Sprintf("%s/%s #%d", a.Set, a.Case, a.Index+1)
+}
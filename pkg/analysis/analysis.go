@@ -0,0 +1,157 @@
+// Package analysis computes statistics about solutions and reconstructions.
+package analysis
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"io"
+	"time"
+)
+
+var (
+	ErrUntimed = errors.New("move without timestamp")
+)
+
+// DefaultPauseThreshold is the shortest gap between two moves counted as a
+// pause when Options.PauseThreshold is zero.
+const DefaultPauseThreshold = 500 * time.Millisecond
+
+type Options struct {
+	PauseThreshold time.Duration
+	Splitter       Splitter // SplitCFOP if nil
+}
+
+// PhaseReport holds the statistics of a single phase. Its duration runs from
+// the end of the previous phase, or the start of the solve, to its last move.
+type PhaseReport struct {
+	Name     string
+	Moves    int
+	Duration time.Duration
+	TPS      float64
+}
+
+// Pause is a gap between two moves of at least the pause threshold.
+type Pause struct {
+	Before   int // Index of the move after the pause
+	Duration time.Duration
+}
+
+// Report holds the statistics of a timed solve. Moves are counted without
+// cube rotations, and times are measured from the start of the solve.
+type Report struct {
+	Moves    int
+	Duration time.Duration
+	TPS      float64
+	Phases   []PhaseReport
+	Pauses   []Pause
+}
+
+// Analyze computes the turns per second of a timed solution overall and per
+// phase, and finds the pauses between moves.
+func Analyze(scramble, solution []cube.Move, opts Options) (*Report, error) {
+	for _, m := range solution {
+		if !m.Timed {
+			return nil, ErrUntimed
+		}
+	}
+
+	if opts.PauseThreshold == 0 {
+		opts.PauseThreshold = DefaultPauseThreshold
+	}
+	if opts.Splitter == nil {
+		opts.Splitter = SplitCFOP
+	}
+
+	phases, err := opts.Splitter(scramble, solution)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Moves: countTurns(solution)}
+	if len(solution) > 0 {
+		report.Duration = solution[len(solution)-1].Time
+	}
+	report.TPS = tps(report.Moves, report.Duration)
+
+	var phaseStart time.Duration
+	for _, p := range phases {
+		moves := solution[p.Start:p.End]
+		phaseEnd := phaseStart
+		if len(moves) > 0 {
+			phaseEnd = moves[len(moves)-1].Time
+		}
+
+		turns := countTurns(moves)
+		report.Phases = append(report.Phases, PhaseReport{
+			Name:     p.Name,
+			Moves:    turns,
+			Duration: phaseEnd - phaseStart,
+			TPS:      tps(turns, phaseEnd-phaseStart),
+		})
+		phaseStart = phaseEnd
+	}
+
+	for i := 1; i < len(solution); i++ {
+		if gap := solution[i].Time - solution[i-1].Time; gap >= opts.PauseThreshold {
+			report.Pauses = append(report.Pauses, Pause{Before: i, Duration: gap})
+		}
+	}
+
+	return report, nil
+}
+
+func countTurns(moves []cube.Move) int {
+	n := 0
+	for _, m := range moves {
+		if m.Operator != 'x' && m.Operator != 'y' && m.Operator != 'z' {
+			n++
+		}
+	}
+	return n
+}
+
+func tps(moves int, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(moves) / d.Seconds()
+}
+
+// WriteJSON writes the report as JSON, with all times in seconds.
+func (r *Report) WriteJSON(w io.Writer) error {
+	type phase struct {
+		Name     string  `json:"name"`
+		Moves    int     `json:"moves"`
+		Duration float64 `json:"duration"`
+		TPS      float64 `json:"tps"`
+	}
+	type pause struct {
+		Before   int     `json:"before"`
+		Duration float64 `json:"duration"`
+	}
+
+	out := struct {
+		Moves    int     `json:"moves"`
+		Duration float64 `json:"duration"`
+		TPS      float64 `json:"tps"`
+		Phases   []phase `json:"phases"`
+		Pauses   []pause `json:"pauses"`
+	}{
+		Moves:    r.Moves,
+		Duration: r.Duration.Seconds(),
+		TPS:      r.TPS,
+		Phases:   []phase{},
+		Pauses:   []pause{},
+	}
+	for _, p := range r.Phases {
+		out.Phases = append(out.Phases, phase{p.Name, p.Moves, p.Duration.Seconds(), p.TPS})
+	}
+	for _, p := range r.Pauses {
+		out.Pauses = append(out.Pauses, pause{p.Before, p.Duration.Seconds()})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -0,0 +1,158 @@
+package analysis
+
+import (
+	"github.com/larssont/go-cubic/pkg/cube"
+)
+
+type position [3]int
+
+// facePlanes holds the axis and coordinate of every face of a 3x3 cube.
+var facePlanes = map[cube.Face]struct{ axis, value int }{
+	cube.FaceUp:    {1, 2},
+	cube.FaceLeft:  {0, 0},
+	cube.FaceFront: {2, 2},
+	cube.FaceRight: {0, 2},
+	cube.FaceBack:  {2, 0},
+	cube.FaceDown:  {1, 0},
+}
+
+var opposites = map[cube.Face]cube.Face{
+	cube.FaceUp:    cube.FaceDown,
+	cube.FaceDown:  cube.FaceUp,
+	cube.FaceLeft:  cube.FaceRight,
+	cube.FaceRight: cube.FaceLeft,
+	cube.FaceFront: cube.FaceBack,
+	cube.FaceBack:  cube.FaceFront,
+}
+
+func (p position) on(f cube.Face) bool {
+	plane := facePlanes[f]
+	return p[plane.axis] == plane.value
+}
+
+// middles returns how many coordinates of the position are in the middle
+// layer: 0 for corners, 1 for edges and 2 for centers.
+func (p position) middles() int {
+	n := 0
+	for _, v := range p {
+		if v == 1 {
+			n++
+		}
+	}
+	return n
+}
+
+var positions = func() []position {
+	var out []position
+	for x := range dimension {
+		for y := range dimension {
+			for z := range dimension {
+				if p := (position{x, y, z}); p.middles() < 3 {
+					out = append(out, p)
+				}
+			}
+		}
+	}
+	return out
+}()
+
+func center(faces *cube.CubeFaces, f cube.Face) rune {
+	return faces.Face(f)[dimension*dimension/2]
+}
+
+// isAdjacent reports whether two faces share an edge.
+func isAdjacent(a, b cube.Face) bool {
+	return a != b && opposites[a] != b
+}
+
+// faceWithCenter returns the face whose center has the given color.
+func faceWithCenter(faces *cube.CubeFaces, color rune) cube.Face {
+	for f := range facePlanes {
+		if center(faces, f) == color {
+			return f
+		}
+	}
+	return cube.FaceUp
+}
+
+// isSolved reports whether every sticker of the piece at p matches the
+// center of its face.
+func isSolved(faces *cube.CubeFaces, p position) bool {
+	for _, s := range cube.StickersAt(dimension, p[0], p[1], p[2]) {
+		if faces.Face(s.Face)[s.Index] != center(faces, s.Face) {
+			return false
+		}
+	}
+	return true
+}
+
+func isCrossSolved(faces *cube.CubeFaces, _, bottom cube.Face) bool {
+	for _, p := range positions {
+		if p.on(bottom) && p.middles() == 1 && !isSolved(faces, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// solvedSlots counts the F2L slots whose corner and edge are both solved.
+func solvedSlots(faces *cube.CubeFaces, bottom cube.Face) int {
+	n := 0
+	for _, p := range positions {
+		// Every slot has one edge between two side faces.
+		if p.middles() != 1 || p.on(bottom) || p.on(opposites[bottom]) {
+			continue
+		}
+		corner := p
+		plane := facePlanes[bottom]
+		corner[plane.axis] = plane.value
+		if isSolved(faces, p) && isSolved(faces, corner) {
+			n++
+		}
+	}
+	return n
+}
+
+func slotsSolved(n int) func(*cube.CubeFaces, cube.Face, cube.Face) bool {
+	return func(faces *cube.CubeFaces, _, bottom cube.Face) bool {
+		return solvedSlots(faces, bottom) >= n
+	}
+}
+
+func isOLLSolved(faces *cube.CubeFaces, _, bottom cube.Face) bool {
+	last := opposites[bottom]
+	for _, color := range faces.Face(last) {
+		if color != center(faces, last) {
+			return false
+		}
+	}
+	return true
+}
+
+func isAllSolved(faces *cube.CubeFaces, _, _ cube.Face) bool {
+	for _, p := range positions {
+		if !isSolved(faces, p) {
+			return false
+		}
+	}
+	return true
+}
+
+var cfop = method{
+	stages: []stage{
+		{name: "Cross", done: isCrossSolved},
+		{name: "F2L 1", done: slotsSolved(1)},
+		{name: "F2L 2", done: slotsSolved(2)},
+		{name: "F2L 3", done: slotsSolved(3)},
+		{name: "F2L 4", done: slotsSolved(4)},
+		{name: "OLL", done: isOLLSolved},
+		{name: "PLL", done: isAllSolved},
+	},
+}
+
+// SplitCFOP splits a 3x3 solution into the Cross, four F2L pairs, OLL and
+// PLL. The cross color is the first one solved, and each phase ends at the
+// first move completing it.
+func SplitCFOP(scramble, solution []cube.Move) ([]Phase, error) {
+	return cfop.split(scramble, solution)
+}
@@ -0,0 +1,182 @@
+package analysis
+
+import (
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrCostEntry        = errors.New("cost table entries are written as move=cost")
+	ErrUnknownCostModel = errors.New("unknown cost model")
+)
+
+// MoveCost returns the cost of executing a move alone: its entry in Moves,
+// by the notation it normalizes to or else without the prime, or the cost
+// of its quarter turn with the Double and Wide multipliers, or Rotation for
+// a cube rotation. Rotations count as moves, as in the execution turn metric.
+func (model CostModel) MoveCost(m cube.Move) float64 {
+	m.Normalize()
+	notation := m.Notation()
+	if cost, ok := model.Moves[notation]; ok {
+		return cost
+	}
+	if cost, ok := model.Moves[strings.TrimSuffix(notation, "'")]; ok {
+		return cost
+	}
+	if isRotation(m) {
+		return model.Rotation
+	}
+	cost := model.Turns[m.Operator]
+	if m.Rotations%4 == 2 {
+		cost *= model.Double
+	}
+	if m.Wide {
+		cost *= model.Wide
+	}
+	return cost
+}
+
+// Weighted returns the sum of the costs of the moves, the metric of the cost
+// model without the regrips and awkward pairs that Ergonomics adds.
+func (model CostModel) Weighted(moves []cube.Move) float64 {
+	total := 0.0
+	for _, m := range moves {
+		total += model.MoveCost(m)
+	}
+	return total
+}
+
+// costFields maps the names of the fields of a cost table to the fields of a
+// model.
+var costFields = map[string]func(*CostModel) *float64{
+	"rotations": func(m *CostModel) *float64 { return &m.Rotation },
+	"double":    func(m *CostModel) *float64 { return &m.Double },
+	"wide":      func(m *CostModel) *float64 { return &m.Wide },
+	"regrips":   func(m *CostModel) *float64 { return &m.Regrip },
+	"awkward":   func(m *CostModel) *float64 { return &m.Awkward },
+}
+
+// ParseCostModel reads a cost table of the form "R=1.0, F2=1.8,
+// rotations=2.5" on top of DefaultCostModel. Entries are separated by commas
+// or lines, and # starts a comment to the end of its line, so that the table
+// can be kept in a file.
+//
+// A face or slice letter, such as R, sets the cost of its quarter turn,
+// which half and wide turns multiply. Any other move, such as F2, R' or x,
+// sets the cost of that move alone, and of its prime unless the prime has
+// an entry of its own, so that x sets x' too. The names rotations, double,
+// wide, regrips and awkward set the other fields of the model.
+func ParseCostModel(table string) (CostModel, error) {
+	model := DefaultCostModel.clone()
+	for _, line := range strings.Split(table, "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		for _, entry := range strings.Split(line, ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+			key, value, ok := strings.Cut(entry, "=")
+			if !ok {
+				return CostModel{}, fmt.Errorf("%q: %w", entry, ErrCostEntry)
+			}
+			key = strings.TrimSpace(key)
+			cost, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || cost < 0 {
+				return CostModel{}, fmt.Errorf("%q: %w", entry, ErrCostEntry)
+			}
+			if err := model.set(key, cost); err != nil {
+				return CostModel{}, fmt.Errorf("%q: %w", entry, err)
+			}
+		}
+	}
+	return model, nil
+}
+
+// set sets the cost of a key of a cost table.
+func (model *CostModel) set(key string, cost float64) error {
+	if field, ok := costFields[strings.ToLower(key)]; ok {
+		*field(model) = cost
+		return nil
+	}
+	if key == "" {
+		return ErrCostEntry
+	}
+	if _, ok := model.Turns[rune(key[0])]; ok && len(key) == 1 {
+		model.Turns[rune(key[0])] = cost
+		return nil
+	}
+	group, err := cube.ParseNotation(key)
+	if err != nil {
+		return err
+	}
+	moves, err := group.Expand()
+	if err != nil {
+		return err
+	}
+	if len(moves) != 1 {
+		return ErrCostEntry
+	}
+	m := moves[0]
+	m.Normalize()
+	model.Moves[m.Notation()] = cost
+	return nil
+}
+
+// clone returns a copy of the model whose maps can be changed on their own.
+func (model CostModel) clone() CostModel {
+	out := model
+	out.Turns = make(map[rune]float64, len(model.Turns))
+	for op, cost := range model.Turns {
+		out.Turns[op] = cost
+	}
+	out.Moves = make(map[string]float64, len(model.Moves))
+	for notation, cost := range model.Moves {
+		out.Moves[notation] = cost
+	}
+	return out
+}
+
+var (
+	costModelsMu sync.Mutex
+	costModels   = map[string]CostModel{"default": DefaultCostModel}
+)
+
+// RegisterCostModel makes a cost model available by name, such as one for
+// the hands of a solver, to LookupCostModel.
+func RegisterCostModel(name string, model CostModel) {
+	costModelsMu.Lock()
+	defer costModelsMu.Unlock()
+	costModels[name] = model.clone()
+}
+
+// LookupCostModel returns a registered cost model, or reads the name as a
+// cost table as ParseCostModel does if it contains an =.
+func LookupCostModel(name string) (CostModel, error) {
+	costModelsMu.Lock()
+	model, ok := costModels[name]
+	costModelsMu.Unlock()
+	if ok {
+		return model, nil
+	}
+	if strings.Contains(name, "=") {
+		return ParseCostModel(name)
+	}
+	return CostModel{}, fmt.Errorf("%q: %w", name, ErrUnknownCostModel)
+}
+
+// CostModelNames returns the names of the registered cost models.
+func CostModelNames() []string {
+	costModelsMu.Lock()
+	defer costModelsMu.Unlock()
+	names := make([]string, 0, len(costModels))
+	for name := range costModels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,198 @@
+package analysis
+
+import (
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/solve"
+	"slices"
+	"strings"
+)
+
+var ErrUnknownColor = errors.New("unknown cross color")
+
+// colorNames holds the names of the colors of a solved cube.
+var colorNames = map[rune]string{
+	'w': "white", 'y': "yellow", 'g': "green", 'b': "blue", 'r': "red", 'o': "orange",
+}
+
+// CrossEdge is where a cross edge is after the scramble.
+type CrossEdge struct {
+	Home string // The edge's home, cross face first, e.g. "DF"
+
+	// At names the position of the edge, starting with the face its cross
+	// sticker is on, e.g. "FR" for the cross sticker on the front of the FR
+	// edge.
+	At string
+}
+
+// Solved reports whether the edge is at home with the cross sticker on the
+// cross face.
+func (e CrossEdge) Solved() bool {
+	return e.Home == e.At
+}
+
+// CrossStep is a move of a cross solution with the edges it places.
+type CrossStep struct {
+	Move cube.Move
+
+	// Places holds the homes of the edges the move puts on the cross face
+	// next to the edges already placed, so that a turn of the cross face
+	// would solve them together.
+	Places []string
+}
+
+// CrossSolution is a cross solution with the F2L pairs it keeps.
+type CrossSolution struct {
+	Moves []cube.Move
+	Steps []CrossStep
+
+	// Kept counts the pairs joined in the scramble that are still joined
+	// after the cross, and Joined the pairs joined after the cross.
+	Kept, Joined int
+}
+
+// CrossPlan explains the cross of one color for inspection practice.
+type CrossPlan struct {
+	Color     rune
+	Face      cube.Face
+	Edges     []CrossEdge
+	Pairs     int // Pairs joined in the scramble
+	Solutions []CrossSolution
+}
+
+// PlanCross finds where the cross edges of the color are and up to n cross
+// solutions of at most eight moves, shortest first.
+func PlanCross(scramble []cube.Move, color rune, n int) (*CrossPlan, error) {
+	f := slices.Index(homeColors[:], color)
+	if f < 0 {
+		return nil, ErrUnknownColor
+	}
+	face := cube.Face(f)
+
+	state, err := solve.StateOf(scramble...)
+	if err != nil {
+		return nil, err
+	}
+	state = state.Reoriented()
+
+	before := joinedOf(state, color)
+	plan := &CrossPlan{
+		Color: color,
+		Face:  face,
+		Edges: crossEdges(state, face),
+		Pairs: len(before),
+	}
+
+	for _, moves := range solve.SolveCross(state, face, solve.CrossOptions{Limit: n}) {
+		sol := CrossSolution{Moves: moves}
+		s := state
+		placed := placedEdges(state, face)
+		for _, m := range moves {
+			next, err := solve.StateOf(m)
+			if err != nil {
+				return nil, err
+			}
+			s = s.Then(next)
+
+			step := CrossStep{Move: m}
+			now := placedEdges(s, face)
+			for i, home := range now {
+				if home != "" && placed[i] == "" {
+					step.Places = append(step.Places, home)
+				}
+			}
+			placed = now
+			sol.Steps = append(sol.Steps, step)
+		}
+
+		after := joinedOf(s, color)
+		sol.Joined = len(after)
+		for _, p := range before {
+			if slices.Contains(after, p) {
+				sol.Kept++
+			}
+		}
+		plan.Solutions = append(plan.Solutions, sol)
+	}
+	return plan, nil
+}
+
+func joinedOf(s solve.State, color rune) []pair {
+	var out []pair
+	for _, p := range joined(facesOf(s)) {
+		if p.cross == color {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// crossEdges returns the cross edges of the face in the state, in the order
+// of their cross stickers.
+func crossEdges(s solve.State, face cube.Face) []CrossEdge {
+	where := s.Inverse()
+	var edges []CrossEdge
+	for _, index := range []int{1, 3, 5, 7} {
+		sticker := int(face)*9 + index
+		edges = append(edges, CrossEdge{
+			Home: solve.PieceName(sticker),
+			At:   solve.PieceName(int(where[sticker])),
+		})
+	}
+	return edges
+}
+
+// placedEdges returns the homes of the cross edges solved after the turn of
+// the cross face that solves the most of them, and empty strings for the
+// others.
+func placedEdges(s solve.State, face cube.Face) []string {
+	var best []string
+	count := -1
+	for _, turn := range append([]solve.Generator{{State: solve.Solved}}, solve.FaceTurns[3*face:3*face+3]...) {
+		var homes []string
+		n := 0
+		for _, e := range crossEdges(s.Then(turn.State), face) {
+			if e.Solved() {
+				homes = append(homes, e.Home)
+				n++
+			} else {
+				homes = append(homes, "")
+			}
+		}
+		if n > count {
+			best, count = homes, n
+		}
+	}
+	return best
+}
+
+// String explains the plan, listing the edges and then every solution move
+// by move.
+func (p *CrossPlan) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s cross on %c, %d pairs joined\n", colorNames[p.Color], faceLetters[p.Face], p.Pairs)
+	for _, e := range p.Edges {
+		switch {
+		case e.Solved():
+			fmt.Fprintf(&sb, "  %s solved\n", e.Home)
+		default:
+			fmt.Fprintf(&sb, "  %s at %s, %s sticker on %c\n", e.Home, e.At, colorNames[p.Color], e.At[0])
+		}
+	}
+
+	for i, sol := range p.Solutions {
+		fmt.Fprintf(&sb, "%d. %s (%d moves), keeps %d of %d pairs, %d joined after\n",
+			i+1, cube.FormatMoves(sol.Moves), len(sol.Moves), sol.Kept, p.Pairs, sol.Joined)
+		for _, step := range sol.Steps {
+			line := "   " + step.Move.Notation()
+			if len(step.Places) > 0 {
+				line = fmt.Sprintf("%-7s places %s", line, strings.Join(step.Places, ", "))
+			}
+			sb.WriteString(line + "\n")
+		}
+	}
+	return sb.String()
+}
+
+const faceLetters = "ULFRBD"
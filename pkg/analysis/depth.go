@@ -0,0 +1,241 @@
+package analysis
+
+import (
+	"errors"
+	"github.com/larssont/go-cubic/pkg/solve"
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+)
+
+var ErrDepthSize = errors.New("depth sampling is for the 2x2 and 3x3")
+
+// DepthOptions configures SampleDepths.
+type DepthOptions struct {
+	Size    int        // 2 or 3, defaults to 3
+	Samples int        // Random states to solve, defaults to 100
+	Workers int        // Goroutines solving states, defaults to runtime.NumCPU
+	Rand    *rand.Rand // Source of the states, or the global one if nil
+
+	// MaxOptimal is the longest 3x3 solution that the search proves
+	// optimal, and Timeout the time it has for every state. States it
+	// cannot settle are bounded instead, from below by the depths it ruled
+	// out and from above by a two-phase solution. They default to 20 moves
+	// and a second. The 2x2 is always solved optimally.
+	MaxOptimal int
+	Timeout    time.Duration
+}
+
+// DepthBound is the optimal depth of a sampled state, known exactly when
+// Lower equals Upper.
+type DepthBound struct {
+	Lower, Upper int
+}
+
+// DepthReport is the distribution of the depths of uniformly random states,
+// for estimating how far states lie from solved, in moves counting half
+// turns as one.
+type DepthReport struct {
+	Size    int
+	Samples []DepthBound
+	Elapsed time.Duration
+}
+
+// DepthShare is the share of the states at a depth, with a 95% confidence
+// interval. States that are only bounded widen the interval of every depth
+// they may be at.
+type DepthShare struct {
+	Depth     int
+	Count     int // States known to be at the depth
+	Share     float64
+	Low, High float64
+}
+
+// z is the normal quantile of the 95% confidence intervals.
+const z = 1.959964
+
+// SampleDepths solves random states of the 2x2 or 3x3 optimally, or bounds
+// them when that takes too long, on several goroutines.
+func SampleDepths(opts DepthOptions) (*DepthReport, error) {
+	if opts.Size == 0 {
+		opts.Size = 3
+	}
+	if opts.Size != 2 && opts.Size != 3 {
+		return nil, ErrDepthSize
+	}
+	if opts.Samples <= 0 {
+		opts.Samples = 100
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.NumCPU()
+	}
+	if opts.MaxOptimal <= 0 {
+		opts.MaxOptimal = 20
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = time.Second
+	}
+
+	// The states are drawn up front, so that a seeded source gives the same
+	// sample however the workers share it. The corners of a random 3x3 state
+	// are a random 2x2 state.
+	start := time.Now()
+	states := make([]solve.State, opts.Samples)
+	for i := range states {
+		states[i] = solve.RandomState(opts.Rand)
+	}
+
+	r := &DepthReport{Size: opts.Size, Samples: make([]DepthBound, opts.Samples)}
+	errs := make([]error, opts.Workers)
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := range opts.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if errs[w] == nil {
+					r.Samples[i], errs[w] = depthOf(states[i], opts)
+				}
+			}
+		}()
+	}
+	for i := range states {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	r.Elapsed = time.Since(start)
+	return r, nil
+}
+
+// depthOf returns the depth of a state, deepening the optimal search until
+// it finds a solution or runs out of time.
+func depthOf(s solve.State, opts DepthOptions) (DepthBound, error) {
+	if opts.Size == 2 {
+		d, err := solve.PocketDistance(s)
+		return DepthBound{d, d}, err
+	}
+
+	deadline := time.Now().Add(opts.Timeout)
+	lower := 0
+	for k := 0; k <= opts.MaxOptimal; k++ {
+		left := time.Until(deadline)
+		if left <= 0 {
+			break
+		}
+		solution, err := solve.SolveWithin(s, k, solve.SolveOptions{Timeout: left})
+		if err == nil {
+			return DepthBound{len(solution.Moves), len(solution.Moves)}, nil
+		}
+		if !errors.Is(err, solve.ErrNoSolution) {
+			if errors.Is(err, solve.ErrBudget) {
+				break
+			}
+			return DepthBound{}, err
+		}
+		lower = k + 1
+	}
+	moves, err := solve.Solve(s, solve.SolveOptions{})
+	if err != nil {
+		return DepthBound{}, err
+	}
+	return DepthBound{lower, max(lower, len(moves))}, nil
+}
+
+// Exact returns the number of states whose depth is known.
+func (r *DepthReport) Exact() int {
+	n := 0
+	for _, b := range r.Samples {
+		if b.Lower == b.Upper {
+			n++
+		}
+	}
+	return n
+}
+
+// Distribution returns the share of every depth that a state of the sample
+// is or may be at, shallowest first.
+func (r *DepthReport) Distribution() []DepthShare {
+	known, possible := map[int]int{}, map[int]int{}
+	for _, b := range r.Samples {
+		if b.Lower == b.Upper {
+			known[b.Lower]++
+		}
+		for d := b.Lower; d <= b.Upper; d++ {
+			possible[d]++
+		}
+	}
+	depths := make([]int, 0, len(possible))
+	for d := range possible {
+		depths = append(depths, d)
+	}
+	slices.Sort(depths)
+
+	n := len(r.Samples)
+	var out []DepthShare
+	for _, d := range depths {
+		low, _ := wilson(known[d], n)
+		_, high := wilson(possible[d], n)
+		out = append(out, DepthShare{
+			Depth: d,
+			Count: known[d],
+			Share: float64(known[d]) / float64(n),
+			Low:   low,
+			High:  high,
+		})
+	}
+	return out
+}
+
+// Mean returns a 95% confidence interval of the mean depth, from the lower
+// bounds of the states to their upper bounds.
+func (r *DepthReport) Mean() (low, high float64) {
+	lowers := make([]float64, len(r.Samples))
+	uppers := make([]float64, len(r.Samples))
+	for i, b := range r.Samples {
+		lowers[i], uppers[i] = float64(b.Lower), float64(b.Upper)
+	}
+	mean, se := meanError(lowers)
+	low = mean - z*se
+	mean, se = meanError(uppers)
+	return low, mean + z*se
+}
+
+// meanError returns the mean of the values and its standard error.
+func meanError(values []float64) (mean, se float64) {
+	n := float64(len(values))
+	if n < 2 {
+		if n == 1 {
+			return values[0], 0
+		}
+		return 0, 0
+	}
+	for _, v := range values {
+		mean += v
+	}
+	mean /= n
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / (n - 1) / n)
+}
+
+// wilson returns the Wilson score interval of a proportion of k in n, which
+// unlike the normal one stays within 0 and 1 for rare depths.
+func wilson(k, n int) (low, high float64) {
+	if n == 0 {
+		return 0, 1
+	}
+	p, nf := float64(k)/float64(n), float64(n)
+	center := (p + z*z/(2*nf)) / (1 + z*z/nf)
+	spread := z / (1 + z*z/nf) * math.Sqrt(p*(1-p)/nf+z*z/(4*nf*nf))
+	return max(0, center-spread), min(1, center+spread)
+}
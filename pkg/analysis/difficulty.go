@@ -0,0 +1,134 @@
+package analysis
+
+import (
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/solve"
+	"slices"
+	"time"
+)
+
+// LuckyCross is the longest cross of a lucky scramble.
+const LuckyCross = 4
+
+// Difficulty holds features of a 3x3 scramble for filtering scrambles and
+// spotting lucky ones in sessions.
+type Difficulty struct {
+	// Cross holds the length of the optimal cross of every color.
+	Cross map[rune]int
+	// Pairs holds the number of F2L pairs already joined for every cross
+	// color.
+	Pairs map[rune]int
+	// BadEdges is the number of edges misoriented for F and B.
+	BadEdges int
+	// Estimate is the length of a two-phase solution, an upper bound of the
+	// optimal length.
+	Estimate int
+}
+
+// ScrambleDifficulty returns the difficulty features of a 3x3 scramble.
+func ScrambleDifficulty(scramble []cube.Move) (*Difficulty, error) {
+	state, err := solve.StateOf(scramble...)
+	if err != nil {
+		return nil, err
+	}
+	state = state.Reoriented()
+
+	solution, err := solve.Solve(state, solve.SolveOptions{MaxLength: 18, Timeout: 200 * time.Millisecond})
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Difficulty{
+		Cross:    map[rune]int{},
+		Pairs:    joinedPairs(facesOf(state)),
+		BadEdges: solve.BadEdges(state),
+		Estimate: len(solution),
+	}
+	for f, color := range homeColors {
+		d.Cross[color] = solve.CrossLength(state, cube.Face(f))
+	}
+	return d, nil
+}
+
+// BestCross returns the color with the shortest cross, preferring the one
+// with the most joined pairs among equally short crosses.
+func (d *Difficulty) BestCross() (color rune, moves int) {
+	moves = -1
+	for _, c := range homeColors {
+		n := d.Cross[c]
+		if moves < 0 || n < moves || n == moves && d.Pairs[c] > d.Pairs[color] {
+			color, moves = c, n
+		}
+	}
+	return color, moves
+}
+
+// Lucky reports whether some cross takes at most LuckyCross moves, or one
+// more with two pairs already joined.
+func (d *Difficulty) Lucky() bool {
+	for _, c := range homeColors {
+		n := d.Cross[c]
+		if n <= LuckyCross || n == LuckyCross+1 && d.Pairs[c] >= 2 {
+			return true
+		}
+	}
+	return false
+}
+
+// pair is an F2L pair of a corner and an edge, identified by the sorted
+// colors of the corner.
+type pair struct {
+	cross  rune // Color of the corner that the edge lacks
+	colors string
+}
+
+// joined returns the corners next to an edge with the same colors on both
+// faces they share.
+func joined(faces *cube.CubeFaces) []pair {
+	color := func(s cube.Sticker) rune {
+		return faces.Face(s.Face)[s.Index]
+	}
+
+	var pairs []pair
+	for _, corner := range positions {
+		if corner.middles() != 0 {
+			continue
+		}
+		for _, edge := range positions {
+			if edge.middles() != 1 {
+				continue
+			}
+
+			matches, shared := 0, 0
+			var cross rune
+			var colors []rune
+			for _, cs := range cube.StickersAt(dimension, corner[0], corner[1], corner[2]) {
+				colors = append(colors, color(cs))
+				if !edge.on(cs.Face) {
+					cross = color(cs)
+					continue
+				}
+				shared++
+				for _, es := range cube.StickersAt(dimension, edge[0], edge[1], edge[2]) {
+					if es.Face == cs.Face && color(es) == color(cs) {
+						matches++
+					}
+				}
+			}
+			if shared == 2 && matches == 2 {
+				slices.Sort(colors)
+				pairs = append(pairs, pair{cross, string(colors)})
+			}
+		}
+	}
+	return pairs
+}
+
+// joinedPairs counts the joined pairs by cross color.
+func joinedPairs(faces *cube.CubeFaces) map[rune]int {
+	counts := map[rune]int{}
+	for _, p := range joined(faces) {
+		counts[p.cross]++
+	}
+	return counts
+}
@@ -0,0 +1,151 @@
+package analysis
+
+import (
+	"github.com/larssont/go-cubic/pkg/cube"
+	"slices"
+)
+
+// CostModel assigns a cost to the parts of an algorithm that make it harder
+// to execute. Lower total costs mean more fingertrick friendly algorithms.
+// ParseCostModel reads one from a table, such as the costs measured for the
+// hands of a solver.
+type CostModel struct {
+	Moves    map[string]float64 // Cost of single moves by notation, over the others, see MoveCost
+	Turns    map[rune]float64   // Cost of a quarter turn per operator
+	Double   float64            // Multiplier for half turns
+	Wide     float64            // Multiplier for wide turns
+	Rotation float64            // Cost of a cube rotation
+	Regrip   float64            // Cost of a detected regrip
+	Awkward  float64            // Cost of an awkward pair of moves
+}
+
+// DefaultCostModel favours R, U and L turns, as in most speedsolving
+// algorithms.
+var DefaultCostModel = CostModel{
+	Turns: map[rune]float64{
+		'R': 1, 'U': 1, 'L': 1.2, 'F': 1.4, 'D': 1.5, 'B': 2.5,
+		'M': 1.5, 'E': 2.5, 'S': 2.5,
+	},
+	Double:   1.5,
+	Wide:     1.2,
+	Rotation: 2,
+	Regrip:   2,
+	Awkward:  1.5,
+}
+
+// Awkward is a pair of moves that is hard to execute in sequence.
+type Awkward struct {
+	Index  int // Index of the second move
+	Reason string
+}
+
+// ErgonomicsReport holds the execution heuristics of an algorithm.
+type ErgonomicsReport struct {
+	Turns     int
+	Rotations int
+	Regrips   int
+	Awkward   []Awkward
+	Cost      float64
+}
+
+func isRotation(m cube.Move) bool {
+	return m.Operator == 'x' || m.Operator == 'y' || m.Operator == 'z'
+}
+
+func isFrontBack(m cube.Move) bool {
+	return m.Operator == 'F' || m.Operator == 'B' || m.Operator == 'S'
+}
+
+// wrist returns which hand's wrist turns the move, if any.
+func wrist(m cube.Move) (rune, bool) {
+	switch m.Operator {
+	case 'R', 'L':
+		return m.Operator, true
+	}
+	return 0, false
+}
+
+// Ergonomics counts the rotations, regrips and awkward sequences of an
+// algorithm and scores it with the cost model.
+//
+// Regrips are estimated from wrist turns: a hand turning the R or L layer a
+// half turn away from its home grip has to regrip before the other hand
+// can continue. Consecutive F and B moves are awkward, as are repeated turns
+// of the same layer that could be combined.
+func Ergonomics(moves []cube.Move, model CostModel) *ErgonomicsReport {
+	report := &ErgonomicsReport{}
+	grips := map[rune]int{}
+
+	for i, m := range moves {
+		if isRotation(m) {
+			report.Rotations++
+			report.Cost += model.MoveCost(m)
+			clear(grips)
+			continue
+		}
+
+		report.Turns++
+		report.Cost += model.MoveCost(m)
+
+		hand, isWrist := wrist(m)
+		for h, offset := range grips {
+			if h != hand && (offset == 2 || offset == -2) {
+				report.Regrips++
+				report.Cost += model.Regrip
+				grips[h] = 0
+			}
+		}
+		if isWrist {
+			turns := m.Rotations
+			if m.Inverted {
+				turns = -turns
+			}
+			offset := grips[hand] + turns
+			if offset > 2 || offset < -2 {
+				report.Regrips++
+				report.Cost += model.Regrip
+				offset = turns
+			}
+			grips[hand] = offset
+		}
+
+		if i == 0 {
+			continue
+		}
+		prev := moves[i-1]
+		switch {
+		case isFrontBack(prev) && isFrontBack(m):
+			report.Awkward = append(report.Awkward, Awkward{i, "consecutive F/B moves"})
+		case prev.Operator == m.Operator && prev.Wide == m.Wide && prev.Slices == m.Slices && prev.From == m.From:
+			report.Awkward = append(report.Awkward, Awkward{i, "repeated turn of the same layer"})
+		default:
+			continue
+		}
+		report.Cost += model.Awkward
+	}
+
+	return report
+}
+
+// Rank scores every candidate algorithm with the cost model and returns their
+// indices from the most to the least fingertrick friendly.
+func Rank(candidates [][]cube.Move, model CostModel) []int {
+	costs := make([]float64, len(candidates))
+	order := make([]int, len(candidates))
+	for i, moves := range candidates {
+		costs[i] = Ergonomics(moves, model).Cost
+		order[i] = i
+	}
+
+	slices.SortStableFunc(order, func(a, b int) int {
+		switch {
+		case costs[a] < costs[b]:
+			return -1
+		case costs[a] > costs[b]:
+			return 1
+		}
+		return 0
+	})
+
+	return order
+}
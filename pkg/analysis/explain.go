@@ -0,0 +1,113 @@
+package analysis
+
+import (
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/solve"
+	"github.com/larssont/go-cubic/pkg/trainer"
+	"strings"
+)
+
+// cfopExplanations tell what the phases of SplitCFOP do, by their name.
+var cfopExplanations = map[string]string{
+	"Cross": "cross",
+	"OLL":   "orient last layer",
+	"PLL":   "permute last layer",
+}
+
+// ExplainCFOP splits a 3x3 solution as SplitCFOP does and annotates every
+// phase with what it does, such as "F2L pair 2" or "orient last layer: case
+// OLL 27". The cases of the last layer are named after the algorithms of
+// package trainer, up to U turns.
+func ExplainCFOP(scramble, solution []cube.Move) (solve.Solution, error) {
+	phases, err := SplitCFOP(scramble, solution)
+	if err != nil {
+		return solve.Solution{}, err
+	}
+	state, err := solve.StateOf(scramble...)
+	if err != nil {
+		return solve.Solution{}, err
+	}
+
+	out := solve.Solution{Moves: solution}
+	for _, p := range phases {
+		before, err := solve.StateOf(solution[:p.Start]...)
+		if err != nil {
+			return solve.Solution{}, err
+		}
+		before = state.Then(before).Reoriented()
+
+		text, ok := cfopExplanations[p.Name]
+		if !ok {
+			text = strings.Replace(p.Name, "F2L", "F2L pair", 1)
+		}
+		if p.Name == "OLL" || p.Name == "PLL" {
+			cases, done := trainer.OLL(), solve.FaceMask(cube.FaceUp).Matches
+			if p.Name == "PLL" {
+				cases, done = trainer.PLL(), func(s solve.State) bool { return s == solve.Solved }
+			}
+			if name, ok := recognize(before, p.Name, cases, done); ok {
+				text += ": " + name
+			}
+		}
+		out.Annotations = append(out.Annotations, solve.Annotation{Text: text, Start: p.Start, End: p.End})
+	}
+	return out, nil
+}
+
+// aufs are the states of the U turns, from none to U'.
+var aufs = func() [4]solve.State {
+	var out [4]solve.State
+	for i := range out {
+		out[i] = solve.Solved
+		for range i {
+			u, _ := solve.StateOf(cube.Move{Operator: 'U', Rotations: 1})
+			out[i] = out[i].Then(u)
+		}
+	}
+	return out
+}()
+
+// recognize returns the name of the case of the last layer that the state,
+// with the first two layers solved on some face, is in: the case whose
+// algorithm, after a U turn, leaves it done up to a U turn, with the cube
+// held with that face on D. Skips are named as such.
+func recognize(s solve.State, set string, cases []trainer.Case, done func(solve.State) bool) (string, bool) {
+	held := false
+	for k := range solve.Rotations {
+		if t := s.Conjugate(k); solve.F2LMask(cube.FaceDown).Matches(t) {
+			s, held = t, true
+			break
+		}
+	}
+	if !held {
+		return "", false
+	}
+	doneAUF := func(t solve.State) bool {
+		for _, u := range aufs {
+			if done(t.Then(u)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if doneAUF(s) {
+		return set + " skip", true
+	}
+	for _, c := range cases {
+		alg, err := solve.StateOf(c.Alg...)
+		if err != nil {
+			continue
+		}
+		for _, u := range aufs {
+			if doneAUF(s.Then(u).Then(alg).Reoriented()) {
+				name := c.Name
+				if !strings.HasPrefix(name, set) {
+					name = set + " " + name
+				}
+				return "case " + name, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,165 @@
+package analysis
+
+import (
+	"cmp"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/solve"
+	"slices"
+)
+
+// neutralCandidates is the number of shortest solutions of every start
+// compared for ergonomics.
+const neutralCandidates = 4
+
+// Start is a way to start a solve in one orientation, for color neutral
+// solvers choosing during inspection.
+type Start struct {
+	Method string // "CFOP" for a cross, "Roux" for a first block on L
+
+	// Bottom and Front are the colors on D and F after the rotation, which
+	// turns the cube from the orientation it was scrambled in.
+	Bottom, Front rune
+	Rotation      []cube.Move
+
+	// Solution is the cross or the block as turned after the rotation, and
+	// Cost its cost with the DefaultCostModel.
+	Solution []cube.Move
+	Cost     float64
+}
+
+func (s Start) String() string {
+	rotation := ""
+	if len(s.Rotation) > 0 {
+		rotation = " (" + cube.FormatMoves(s.Rotation) + ")"
+	}
+	return fmt.Sprintf("%s %s bottom, %s front%s: %s (%d moves, cost %.1f)",
+		s.Method, colorNames[s.Bottom], colorNames[s.Front], rotation,
+		cube.FormatMoves(s.Solution), len(s.Solution), s.Cost)
+}
+
+// frame is an orientation of the cube: what the rotation does to every
+// sticker, and the moves of the rotation.
+type frame struct {
+	turn     solve.State
+	rotation []cube.Move
+}
+
+// frames holds the 24 orientations with the shortest rotation to each.
+var frames = func() []frame {
+	var rotations []cube.Move
+	for _, op := range "xyz" {
+		for _, m := range []cube.Move{
+			{Operator: op, Rotations: 1},
+			{Operator: op, Rotations: 2},
+			{Operator: op, Rotations: 1, Inverted: true},
+		} {
+			rotations = append(rotations, m)
+		}
+	}
+
+	out := []frame{{turn: solve.Solved}}
+	for i := 0; i < len(out); i++ {
+		for _, m := range rotations {
+			moves := append(slices.Clone(out[i].rotation), m)
+			s, _ := solve.StateOf(moves...)
+			turn := s.Inverse()
+			if !slices.ContainsFunc(out, func(f frame) bool { return f.turn == turn }) {
+				out = append(out, frame{turn, moves})
+			}
+		}
+	}
+	return out
+}()
+
+// faceAfter returns the face that face f is on after the frame's rotation.
+func (f frame) faceAfter(face cube.Face) cube.Face {
+	return cube.Face(f.turn[int(face)*9+4] / 9)
+}
+
+// faceBefore returns the face that is on face f after the frame's rotation.
+func (f frame) faceBefore(face cube.Face) cube.Face {
+	return cube.Face(f.turn.Inverse()[int(face)*9+4] / 9)
+}
+
+// relabel returns face turns as they are turned after the frame's rotation.
+func (f frame) relabel(moves []cube.Move) []cube.Move {
+	out := slices.Clone(moves)
+	for i, m := range out {
+		if face := faceLetterIndex(m.Operator); face >= 0 {
+			out[i].Operator = rune(faceLetters[f.faceAfter(cube.Face(face))])
+		}
+	}
+	return out
+}
+
+func faceLetterIndex(op rune) int {
+	for i, l := range faceLetters {
+		if l == op {
+			return i
+		}
+	}
+	return -1
+}
+
+// ColorNeutral compares the starts of a 3x3 scramble in every orientation:
+// the cross of every color, in the orientation of the bottom color that turns
+// it most ergonomically, and the Roux first block on L for every bottom and
+// left color. Starts are sorted by method, then from the shortest and most
+// ergonomic.
+func ColorNeutral(scramble []cube.Move) ([]Start, error) {
+	state, err := solve.StateOf(scramble...)
+	if err != nil {
+		return nil, err
+	}
+	state = state.Reoriented()
+
+	var starts []Start
+	best := func(method string, f frame, solutions [][]cube.Move) Start {
+		start := Start{
+			Method:   method,
+			Bottom:   homeColors[f.faceBefore(cube.FaceDown)],
+			Front:    homeColors[f.faceBefore(cube.FaceFront)],
+			Rotation: f.rotation,
+		}
+		for i, moves := range solutions {
+			if len(moves) > len(solutions[0]) {
+				break
+			}
+			relabeled := f.relabel(moves)
+			if cost := Ergonomics(relabeled, DefaultCostModel).Cost; i == 0 || cost < start.Cost {
+				start.Solution, start.Cost = relabeled, cost
+			}
+		}
+		return start
+	}
+
+	for face := range cube.Face(6) {
+		solutions := solve.SolveCross(state, face, solve.CrossOptions{Limit: neutralCandidates})
+		var cross Start
+		for _, f := range frames {
+			if f.faceAfter(face) != cube.FaceDown {
+				continue
+			}
+			if start := best("CFOP", f, solutions); cross.Method == "" || start.Cost < cross.Cost {
+				cross = start
+			}
+		}
+		starts = append(starts, cross)
+	}
+
+	for _, f := range frames {
+		side, bottom := f.faceBefore(cube.FaceLeft), f.faceBefore(cube.FaceDown)
+		solutions := solve.SolveBlock(state, side, bottom, solve.CrossOptions{Limit: neutralCandidates})
+		starts = append(starts, best("Roux", f, solutions))
+	}
+
+	slices.SortStableFunc(starts, func(a, b Start) int {
+		return cmp.Or(
+			cmp.Compare(a.Method, b.Method),
+			cmp.Compare(len(a.Solution), len(b.Solution)),
+			cmp.Compare(a.Cost, b.Cost),
+		)
+	})
+	return starts, nil
+}
@@ -0,0 +1,52 @@
+package analysis
+
+import (
+	"github.com/larssont/go-cubic/pkg/cube"
+)
+
+// isBlockSolved reports whether the 1x2x3 block on the face and the bottom is
+// solved.
+func isBlockSolved(faces *cube.CubeFaces, f, bottom cube.Face) bool {
+	for _, p := range positions {
+		if p.on(f) && !p.on(opposites[bottom]) && !isSolved(faces, p) {
+			return false
+		}
+	}
+	return true
+}
+
+func isFirstBlockSolved(faces *cube.CubeFaces, side, bottom cube.Face) bool {
+	return isBlockSolved(faces, side, bottom)
+}
+
+func isSecondBlockSolved(faces *cube.CubeFaces, side, bottom cube.Face) bool {
+	return isBlockSolved(faces, opposites[side], bottom)
+}
+
+// isCMLLSolved reports whether the corners of the last layer are solved
+// relative to the blocks.
+func isCMLLSolved(faces *cube.CubeFaces, _, bottom cube.Face) bool {
+	for _, p := range positions {
+		if p.on(opposites[bottom]) && p.middles() == 0 && !isSolved(faces, p) {
+			return false
+		}
+	}
+	return true
+}
+
+var roux = method{
+	stages: []stage{
+		{name: "First block", done: isFirstBlockSolved},
+		{name: "Second block", done: isSecondBlockSolved},
+		{name: "CMLL", done: isCMLLSolved},
+		{name: "LSE", done: isAllSolved, exact: true},
+	},
+	slice: true,
+}
+
+// SplitRoux splits a 3x3 solution into the first block, second block, CMLL
+// and LSE. The blocks and CMLL may be complete with the M slice turned, which
+// is undone when checking them.
+func SplitRoux(scramble, solution []cube.Move) ([]Phase, error) {
+	return roux.split(scramble, solution)
+}
@@ -0,0 +1,259 @@
+package analysis
+
+import (
+	"errors"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/solve"
+	"strings"
+)
+
+var (
+	ErrNoFirstStage = errors.New("first stage of the method not found in solution")
+)
+
+const dimension = 3
+
+// Phase is a part of a solution, covering the moves from Start up to but not
+// including End.
+type Phase struct {
+	Name  string
+	Start int
+	End   int
+}
+
+// Splitter splits a solution into phases, given the scramble it solves.
+type Splitter func(scramble, solution []cube.Move) ([]Phase, error)
+
+// stage is a step of a solving method. Done reports whether it is complete
+// with the method's blocks built on the side and bottom faces.
+type stage struct {
+	name string
+	done func(faces *cube.CubeFaces, side, bottom cube.Face) bool
+
+	// exact stages are checked without turning the middle slice.
+	exact bool
+}
+
+// method is a solving method as the stages it completes in order.
+type method struct {
+	stages []stage
+
+	// slice allows stages to be complete with the middle slice parallel to
+	// the side face turned, as in Roux where centers move with M.
+	slice bool
+}
+
+// anchor holds the center colors of the side and bottom faces of a method,
+// fixed once its first stage is complete.
+type anchor struct {
+	side, bottom rune
+}
+
+// homeColors holds the color of every face of a solved cube.
+var homeColors = func() [6]rune {
+	var colors [6]rune
+	faces := cube.NewCube(dimension).Faces()
+	for f := range colors {
+		colors[f] = center(faces, cube.Face(f))
+	}
+	return colors
+}()
+
+// facesOf returns the faces of a cube in the given state.
+func facesOf(s solve.State) *cube.CubeFaces {
+	var colors [solve.NumStickers]rune
+	for i, p := range s {
+		colors[i] = homeColors[int(p)/(dimension*dimension)]
+	}
+
+	n := dimension * dimension
+	return &cube.CubeFaces{
+		Up:    colors[0*n : 1*n],
+		Left:  colors[1*n : 2*n],
+		Front: colors[2*n : 3*n],
+		Right: colors[3*n : 4*n],
+		Back:  colors[4*n : 5*n],
+		Down:  colors[5*n : 6*n],
+	}
+}
+
+// sliceStates holds a quarter turn of the middle slice parallel to every
+// face.
+var sliceStates = func() map[cube.Face]solve.State {
+	out := map[cube.Face]solve.State{}
+	for f, op := range map[cube.Face]rune{
+		cube.FaceLeft: 'M', cube.FaceRight: 'M',
+		cube.FaceUp: 'E', cube.FaceDown: 'E',
+		cube.FaceFront: 'S', cube.FaceBack: 'S',
+	} {
+		s, err := solve.StateOf(cube.Move{Operator: op, Rotations: 1})
+		if err != nil {
+			panic(err)
+		}
+		out[f] = s
+	}
+	return out
+}()
+
+// variants returns the faces of the state to check the stages of the method
+// on, with the middle slice parallel to the side turned if allowed.
+func (m method) variants(s solve.State, sides ...cube.Face) []*cube.CubeFaces {
+	out := []*cube.CubeFaces{facesOf(s)}
+	if !m.slice {
+		return out
+	}
+	for _, side := range sides {
+		turned := s
+		for range 3 {
+			turned = turned.Then(sliceStates[side])
+			out = append(out, facesOf(turned))
+		}
+	}
+	return out
+}
+
+// complete reports whether the stages up to and including i are complete.
+func (m method) complete(faces *cube.CubeFaces, i int, side, bottom cube.Face) bool {
+	if !isAdjacent(side, bottom) {
+		return false
+	}
+	for _, st := range m.stages[:i+1] {
+		if !st.done(faces, side, bottom) {
+			return false
+		}
+	}
+	return true
+}
+
+// split finds the first move completing every stage of the method. Stages
+// that are never completed are left out, and skipped stages are empty.
+func (m method) split(scramble, solution []cube.Move) ([]Phase, error) {
+	state, err := solve.StateOf(scramble...)
+	if err != nil {
+		return nil, err
+	}
+	states := []solve.State{state}
+	for _, mv := range solution {
+		s, err := solve.StateOf(mv)
+		if err != nil {
+			return nil, err
+		}
+		state = state.Then(s)
+		states = append(states, state)
+	}
+
+	var a anchor
+	first := -1
+	for k := 0; k < len(states) && first < 0; k++ {
+		for _, faces := range m.variants(states[k], cube.FaceLeft, cube.FaceUp, cube.FaceFront) {
+			if side, bottom, ok := m.findAnchor(faces); ok {
+				a = anchor{center(faces, side), center(faces, bottom)}
+				first = k
+				break
+			}
+		}
+	}
+	if first < 0 {
+		return nil, ErrNoFirstStage
+	}
+
+	phases := []Phase{{Name: m.stages[0].name, Start: 0, End: first}}
+	for i := 1; i < len(m.stages); i++ {
+		start := phases[len(phases)-1].End
+		end := -1
+		for k := start; k < len(states) && end < 0; k++ {
+			variants := []*cube.CubeFaces{facesOf(states[k])}
+			if !m.stages[i].exact {
+				variants = m.variants(states[k], faceWithCenter(variants[0], a.side))
+			}
+			for _, faces := range variants {
+				bottom := faceWithCenter(faces, a.bottom)
+				if m.complete(faces, i, faceWithCenter(faces, a.side), bottom) {
+					end = k
+					break
+				}
+			}
+		}
+		if end < 0 {
+			break
+		}
+		phases = append(phases, Phase{Name: m.stages[i].name, Start: start, End: end})
+	}
+
+	return phases, nil
+}
+
+func (m method) findAnchor(faces *cube.CubeFaces) (side, bottom cube.Face, ok bool) {
+	for side := range cube.Face(6) {
+		for bottom := range cube.Face(6) {
+			if m.complete(faces, 0, side, bottom) {
+				return side, bottom, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// Annotate writes a solution as a reconstruction with one line per phase,
+// each followed by a comment with its name.
+func Annotate(solution []cube.Move, phases []Phase) string {
+	var sb strings.Builder
+	end := 0
+	for _, p := range phases {
+		sb.WriteString(cube.FormatMoves(solution[p.Start:p.End]))
+		if p.End > p.Start {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString("// " + p.Name + "\n")
+		end = p.End
+	}
+	if end < len(solution) {
+		sb.WriteString(cube.FormatMoves(solution[end:]) + "\n")
+	}
+	return sb.String()
+}
+
+// MaskSplitter returns a splitter of phases given as partial states in the
+// format of solve.ParseMask, such as "cross", "f2l", "oll" and "solved", each
+// named by its expression and ending with the first move completing it. The
+// cube is not rotated to find them, so they are on the faces they name.
+func MaskSplitter(stages ...string) (Splitter, error) {
+	masks := make([]solve.Mask, len(stages))
+	for i, expr := range stages {
+		var err error
+		if masks[i], err = solve.ParseMask(expr); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(scramble, solution []cube.Move) ([]Phase, error) {
+		state, err := solve.StateOf(scramble...)
+		if err != nil {
+			return nil, err
+		}
+
+		var phases []Phase
+		start, i := 0, 0
+		for k := 0; k <= len(solution) && i < len(masks); {
+			if masks[i].Matches(state) {
+				phases = append(phases, Phase{Name: stages[i], Start: start, End: k})
+				start = k
+				i++
+				continue
+			}
+			if k == len(solution) {
+				break
+			}
+			s, err := solve.StateOf(solution[k])
+			if err != nil {
+				return nil, err
+			}
+			state = state.Then(s)
+			k++
+		}
+		if len(phases) == 0 {
+			return nil, ErrNoFirstStage
+		}
+		return phases, nil
+	}, nil
+}
@@ -0,0 +1,43 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/larssont/go-cubic/pkg/cube"
+)
+
+func moves(t *testing.T, input string) []cube.Move {
+	t.Helper()
+	g, err := cube.ParseNotation(input)
+	if err != nil {
+		t.Fatalf("ParseNotation(%q): %v", input, err)
+	}
+	m, err := g.Expand()
+	if err != nil {
+		t.Fatalf("Expand(%q): %v", input, err)
+	}
+	return m
+}
+
+// TestSplitTiedAnchor splits solutions whose first phase is complete on
+// several anchors at once, which must not pick one at random.
+func TestSplitTiedAnchor(t *testing.T) {
+	splitters := map[string]Splitter{"CFOP": SplitCFOP, "Roux": SplitRoux}
+	for name, split := range splitters {
+		scramble, solution := moves(t, "U2"), moves(t, "U2")
+		want, err := split(scramble, solution)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for range 200 {
+			got, err := split(scramble, solution)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("%s: split %v, then %v", name, want, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package bld
+
+import (
+	"errors"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/solve"
+	"slices"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrNoAlg         = errors.New("no algorithm for letters")
+	ErrUnknownAlgSet = errors.New("unknown alg set")
+)
+
+// AlgSet holds the algorithms of a blind method for one piece type.
+type AlgSet interface {
+	Type() PieceType
+	// Buffer returns the letter of the buffer sticker.
+	Buffer() rune
+	// Algs returns the algorithm for a letter pair of the memo, or for the
+	// single letter left at the end of an odd memo.
+	Algs(letters string) ([]cube.Move, error)
+}
+
+// ThreeStyle is an alg set with a commutator for every letter pair.
+type ThreeStyle struct {
+	Piece        PieceType
+	BufferLetter rune
+	Pairs        map[string][]cube.Move
+}
+
+func (s *ThreeStyle) Type() PieceType { return s.Piece }
+func (s *ThreeStyle) Buffer() rune    { return s.BufferLetter }
+
+func (s *ThreeStyle) Algs(letters string) ([]cube.Move, error) {
+	alg, ok := s.Pairs[letters]
+	if !ok {
+		return nil, ErrNoAlg
+	}
+	return alg, nil
+}
+
+// SingleTarget is an alg set solving one letter at a time, such as Old
+// Pochmann. Every algorithm swaps the buffer with its target, possibly with
+// a side effect on other pieces that a second target undoes.
+type SingleTarget struct {
+	Piece        PieceType
+	BufferLetter rune
+	Targets      map[rune][]cube.Move
+}
+
+func (s *SingleTarget) Type() PieceType { return s.Piece }
+func (s *SingleTarget) Buffer() rune    { return s.BufferLetter }
+
+func (s *SingleTarget) Algs(letters string) ([]cube.Move, error) {
+	var moves []cube.Move
+	for _, l := range letters {
+		alg, ok := s.Targets[l]
+		if !ok {
+			return nil, ErrNoAlg
+		}
+		moves = append(moves, alg...)
+	}
+	return moves, nil
+}
+
+// NewSingleTarget derives a single target alg set from a swap algorithm,
+// which exchanges the buffer with one other piece. The algorithm of every
+// target is a shortest setup bringing it to that piece, the swap, and the
+// inverse setup.
+func NewSingleTarget(t PieceType, buffer rune, swap []cube.Move) (*SingleTarget, error) {
+	bufSticker, err := Sticker(t, buffer)
+	if err != nil {
+		return nil, err
+	}
+	swapState, err := solve.StateOf(swap...)
+	if err != nil {
+		return nil, err
+	}
+
+	// The sticker taking the place of the buffer's is the swap target, and
+	// the rest of the swap is a side effect kept by every target.
+	spot := int(swapState[bufSticker])
+	exchange, err := solve.CycleState(solve.PieceName(bufSticker), solve.PieceName(spot))
+	if err != nil {
+		return nil, err
+	}
+	side := swapState.Then(exchange.Inverse())
+
+	set := &SingleTarget{Piece: t, BufferLetter: buffer, Targets: map[rune][]cube.Move{}}
+	wanted := map[solve.State]rune{}
+	for l := 'A'; l <= 'X'; l++ {
+		target, _ := Sticker(t, l)
+		if solve.SamePiece(target, bufSticker) {
+			continue
+		}
+		cycle, err := solve.CycleState(solve.PieceName(bufSticker), solve.PieceName(target))
+		if err != nil {
+			return nil, err
+		}
+		wanted[cycle.Then(side)] = l
+	}
+
+	// Search setups breadth first until every target has its shortest one.
+	type setup struct {
+		state solve.State
+		moves []cube.Move
+	}
+	level := []setup{{state: solve.Solved}}
+	for depth := 0; depth <= setupDepth && len(set.Targets) < len(wanted); depth++ {
+		var next []setup
+		for _, s := range level {
+			conj := s.state.Then(swapState).Then(s.state.Inverse())
+			if l, ok := wanted[conj]; ok && set.Targets[l] == nil {
+				set.Targets[l], _ = cube.NormalizeMoves(slices.Concat(s.moves, swap, cube.ReverseMoves(s.moves)))
+			}
+			if depth == setupDepth {
+				continue
+			}
+
+			for _, g := range setupTurns {
+				if n := len(s.moves); n > 0 && s.moves[n-1].Operator == g.Move.Operator {
+					continue
+				}
+				next = append(next, setup{s.state.Then(g.State), append(slices.Clip(s.moves), g.Move)})
+			}
+		}
+		level = next
+	}
+	return set, nil
+}
+
+// setupDepth is the longest setup searched by NewSingleTarget.
+const setupDepth = 4
+
+var setupTurns = slices.Concat(solve.FaceTurns, solve.SliceTurns)
+
+func mustSingleTarget(t PieceType, buffer rune, swap string) func() AlgSet {
+	return func() AlgSet {
+		group, err := cube.ParseNotation(swap)
+		if err != nil {
+			panic(err)
+		}
+		moves, err := group.Expand()
+		if err != nil {
+			panic(err)
+		}
+		set, err := NewSingleTarget(t, buffer, moves)
+		if err != nil {
+			panic(err)
+		}
+		return set
+	}
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]func() AlgSet{
+		// Old Pochmann with a modified Y-perm from UBL and a T-perm from UR.
+		"op-corners": sync.OnceValue(mustSingleTarget(Corner, 'A', "R U' R' U' R U R' F' R U R' U' R' F R")),
+		"op-edges":   sync.OnceValue(mustSingleTarget(Edge, 'B', "R U R' U' R' F R2 U' R' U' R U R' F'")),
+	}
+)
+
+// RegisterAlgSet makes an alg set available by name.
+func RegisterAlgSet(name string, set AlgSet) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = func() AlgSet { return set }
+}
+
+// LookupAlgSet returns a registered alg set.
+func LookupAlgSet(name string) (AlgSet, error) {
+	registryMu.Lock()
+	get, ok := registry[name]
+	registryMu.Unlock()
+	if !ok {
+		return nil, ErrUnknownAlgSet
+	}
+	return get(), nil
+}
+
+// AlgSetNames returns the names of the registered alg sets.
+func AlgSetNames() []string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
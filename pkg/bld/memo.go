@@ -0,0 +1,119 @@
+package bld
+
+import (
+	"errors"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/solve"
+	"strings"
+)
+
+var ErrMemoLoop = errors.New("memo does not converge")
+
+// maxTargets bounds the letters of a memo of one piece type, including cycle
+// breaks and twists.
+const maxTargets = 40
+
+// Memorize returns the memo of a scramble for the buffers of the alg sets.
+// Edges are traced first, and corners on the cube left by the edge algorithms
+// and the parity algorithm, as Execute runs them. A cycle breaks into the
+// first unsolved piece in letter order.
+func Memorize(scramble []cube.Move, opts TraceOptions) (Memo, error) {
+	state, err := solve.StateOf(scramble...)
+	if err != nil {
+		return Memo{}, err
+	}
+
+	var memo Memo
+	if opts.Edges != nil {
+		letters, err := targets(state, Edge, opts.Edges.Buffer())
+		if err != nil {
+			return Memo{}, err
+		}
+		memo.Edges = pairs(letters)
+
+		for _, entry := range memo.Edges {
+			moves, err := opts.Edges.Algs(entry)
+			if err != nil {
+				return Memo{}, err
+			}
+			effect, err := solve.StateOf(moves...)
+			if err != nil {
+				return Memo{}, err
+			}
+			state = state.Then(effect)
+		}
+		if len(letters)%2 == 1 {
+			parity, err := solve.StateOf(opts.Parity...)
+			if err != nil {
+				return Memo{}, err
+			}
+			state = state.Then(parity)
+		}
+	}
+
+	if opts.Corners != nil {
+		letters, err := targets(state, Corner, opts.Corners.Buffer())
+		if err != nil {
+			return Memo{}, err
+		}
+		memo.Corners = pairs(letters)
+	}
+	return memo, nil
+}
+
+// targets traces the pieces of a type from the buffer, swapping the buffer
+// with every target as Execute expects.
+func targets(state solve.State, t PieceType, bufLetter rune) (string, error) {
+	buffer, err := Sticker(t, bufLetter)
+	if err != nil {
+		return "", err
+	}
+
+	var letters strings.Builder
+	for range maxTargets {
+		target := int(state[buffer])
+		if solve.SamePiece(target, buffer) {
+			var ok bool
+			if target, ok = firstUnsolved(state, t, buffer); !ok {
+				return letters.String(), nil
+			}
+		}
+
+		letter, _ := Letter(t, target)
+		letters.WriteRune(letter)
+		swap, err := solve.CycleState(solve.PieceName(buffer), solve.PieceName(target))
+		if err != nil {
+			return "", err
+		}
+		state = state.Then(swap)
+	}
+	return "", ErrMemoLoop
+}
+
+// firstUnsolved returns the sticker of the first letter on an unsolved piece
+// other than the buffer.
+func firstUnsolved(state solve.State, t PieceType, buffer int) (int, bool) {
+	for l := 'A'; l <= 'X'; l++ {
+		s, _ := Sticker(t, l)
+		if !solve.SamePiece(s, buffer) && int(state[s]) != s {
+			return s, true
+		}
+	}
+	return 0, false
+}
+
+// pairs splits letters into pairs, leaving a single letter last for odd
+// counts.
+func pairs(letters string) []string {
+	var out []string
+	for i := 0; i < len(letters); i += 2 {
+		out = append(out, letters[i:min(i+2, len(letters))])
+	}
+	return out
+}
+
+// String returns the memo as it is read out, e.g. "edges: AB CD E; corners:
+// FG".
+func (m Memo) String() string {
+	return "edges: " + strings.Join(m.Edges, " ") + "; corners: " + strings.Join(m.Corners, " ")
+}
@@ -0,0 +1,66 @@
+// Package bld implements blindfolded solving with the Speffz lettering
+// scheme.
+package bld
+
+import (
+	"errors"
+	"github.com/larssont/go-cubic/pkg/solve"
+	"unicode"
+)
+
+var (
+	ErrUnknownLetter = errors.New("unknown letter")
+)
+
+type PieceType int
+
+const (
+	Corner PieceType = iota
+	Edge
+)
+
+func (t PieceType) String() string {
+	if t == Corner {
+		return "corner"
+	}
+	return "edge"
+}
+
+// spots holds the face indices of the four stickers lettered on every face,
+// clockwise from the top left or top.
+var spots = map[PieceType][4]int{
+	Corner: {0, 2, 8, 6},
+	Edge:   {1, 5, 7, 3},
+}
+
+// Sticker returns the sticker index, as in solve.State, of a Speffz letter.
+// Letters run clockwise over the faces in the order U L F R B D, so A is the
+// U sticker of UBL for corners and of UB for edges.
+func Sticker(t PieceType, letter rune) (int, error) {
+	i := int(unicode.ToUpper(letter) - 'A')
+	if i < 0 || i >= 24 {
+		return 0, ErrUnknownLetter
+	}
+	return i/4*9 + spots[t][i%4], nil
+}
+
+// Letter returns the Speffz letter of a sticker.
+func Letter(t PieceType, sticker int) (rune, bool) {
+	face, index := sticker/9, sticker%9
+	for i, spot := range spots[t] {
+		if spot == index {
+			return rune('A' + face*4 + i), true
+		}
+	}
+	return 0, false
+}
+
+// PieceName returns the name of the piece a letter is on, starting with the
+// lettered sticker, e.g. "UBL" for corner A.
+func PieceName(t PieceType, letter rune) (string, error) {
+	s, err := Sticker(t, letter)
+	if err != nil {
+		return "", err
+	}
+	return solve.PieceName(s), nil
+}
@@ -0,0 +1,150 @@
+package bld
+
+import (
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/solve"
+	"strings"
+)
+
+var (
+	ErrMemoPair = errors.New("memo entries must be letter pairs, except the last one")
+)
+
+// Memo holds the letter pairs to solve, edges first. The last entry of each
+// piece type may be a single letter, which leaves parity.
+type Memo struct {
+	Edges   []string
+	Corners []string
+}
+
+type TraceOptions struct {
+	Edges   AlgSet
+	Corners AlgSet
+	Parity  []cube.Move // Executed between edges and corners for odd memos, e.g. an Ra-perm for Old Pochmann
+}
+
+// Step is the execution of one memo entry.
+type Step struct {
+	Type    PieceType
+	Letters string
+	Moves   []cube.Move
+
+	// Problem is empty if the step went as expected, or describes how it
+	// diverged from the memo.
+	Problem string
+}
+
+// Trace is the blind solution synthesized from a memo.
+type Trace struct {
+	Solution []cube.Move
+	Steps    []Step
+	Solved   bool
+
+	// Divergence is the index of the first step with a problem, or -1.
+	Divergence int
+}
+
+// Execute synthesizes the blind solution of a memo with the alg sets, runs it
+// on the scrambled cube, and checks every step. A step diverges if a letter
+// is not the target of the piece in the buffer, or if its algorithm does not
+// cycle the buffer through its letters.
+func Execute(scramble []cube.Move, memo Memo, opts TraceOptions) (*Trace, error) {
+	actual, err := solve.StateOf(scramble...)
+	if err != nil {
+		return nil, err
+	}
+
+	trace := &Trace{Divergence: -1}
+	run := func(t PieceType, set AlgSet, entries []string) error {
+		bufLetter := set.Buffer()
+		buffer, err := Sticker(t, bufLetter)
+		if err != nil {
+			return err
+		}
+
+		for i, letters := range entries {
+			letters = strings.ToUpper(letters)
+			if len(letters) != 2 && !(len(letters) == 1 && i == len(entries)-1) {
+				return ErrMemoPair
+			}
+
+			moves, err := set.Algs(letters)
+			if err != nil {
+				return fmt.Errorf("%s %s: %w", t, letters, err)
+			}
+			effect, err := solve.StateOf(moves...)
+			if err != nil {
+				return err
+			}
+
+			// The memo is followed on a copy of the actual state, checking
+			// that every letter is where the piece in the buffer belongs.
+			state := actual
+			step := Step{Type: t, Letters: letters, Moves: moves}
+			want := solve.Solved
+			for _, l := range letters {
+				target, err := Sticker(t, l)
+				if err != nil {
+					return err
+				}
+
+				// The piece in the buffer must belong at the target, unless
+				// it is the buffer piece itself and the cycle breaks into a
+				// new one.
+				held := int(state[buffer])
+				if step.Problem == "" && held != target && !solve.SamePiece(held, buffer) {
+					got, _ := Letter(t, held)
+					step.Problem = fmt.Sprintf("buffer holds %c, not %c", got, l)
+				}
+				if solve.SamePiece(target, buffer) && step.Problem == "" {
+					step.Problem = fmt.Sprintf("target %c is on the buffer piece", l)
+				}
+
+				swap, err := solve.CycleState(solve.PieceName(buffer), solve.PieceName(target))
+				if err != nil {
+					return err
+				}
+				state = state.Then(swap)
+				want = want.Then(swap)
+			}
+
+			if len(letters) == 2 {
+				if effect != want && step.Problem == "" {
+					step.Problem = fmt.Sprintf("algorithm does not cycle %c%c%s", bufLetter, letters[0], letters[1:])
+				}
+			}
+
+			trace.Solution = append(trace.Solution, moves...)
+			trace.Steps = append(trace.Steps, step)
+			if step.Problem != "" && trace.Divergence < 0 {
+				trace.Divergence = len(trace.Steps) - 1
+			}
+			actual = actual.Then(effect)
+		}
+		return nil
+	}
+
+	if opts.Edges != nil {
+		if err := run(Edge, opts.Edges, memo.Edges); err != nil {
+			return nil, err
+		}
+	}
+	if n := len(memo.Edges); n > 0 && len(memo.Edges[n-1]) == 1 {
+		parity, err := solve.StateOf(opts.Parity...)
+		if err != nil {
+			return nil, err
+		}
+		trace.Solution = append(trace.Solution, opts.Parity...)
+		actual = actual.Then(parity)
+	}
+	if opts.Corners != nil {
+		if err := run(Corner, opts.Corners, memo.Corners); err != nil {
+			return nil, err
+		}
+	}
+
+	trace.Solved = actual == solve.Solved
+	return trace, nil
+}
@@ -0,0 +1,211 @@
+// Package bot answers chat messages that ask for cubes, for Discord and
+// Slack bots to build on. A message such as
+//
+//	!cube R U R' U'
+//	!cube 4x4 plan Rw U Rw'
+//
+// is answered with a PNG of the cube after the moves and a text of the
+// counts of the moves and, for the 3x3, a solution, sized for the limits of
+// chat services on attachments and messages.
+package bot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/analysis"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/solve"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNoMoves = errors.New("no moves given")
+	ErrTooLong = errors.New("algorithm longer than the limit")
+	ErrSize    = errors.New("cube size out of range")
+)
+
+// DefaultPrefix is the command of messages that ask for cubes.
+const DefaultPrefix = "!cube"
+
+// Options set how messages are answered. Zero fields take the defaults.
+type Options struct {
+	Prefix string      // Command of the messages, DefaultPrefix if empty
+	Scheme cube.Scheme // Colors of the cube, Western if zero
+
+	// Scale is that of cube.RenderPNG. Images larger than MaxImageBytes are
+	// drawn at half the scale until they fit, and left out if none does.
+	Scale         float64
+	MaxImageBytes int // 8 MiB by default
+	MaxText       int // Longest text in characters, 2000 by default
+	MaxMoves      int // Longest algorithm once its groups are expanded, 1000 by default
+	MaxSize       int // Largest cube, 17 by default
+
+	// SolveTime bounds the search for a solution of the 3x3, 500ms by
+	// default, or none if negative.
+	SolveTime time.Duration
+
+	// ImageURL is the address of a daemon.ImageHandler, such as
+	// https://cubes.example.com, that replies link to the same image at.
+	// Replies have no link if it is empty.
+	ImageURL string
+}
+
+func (o *Options) defaults() {
+	if o.Prefix == "" {
+		o.Prefix = DefaultPrefix
+	}
+	if o.Scale <= 0 {
+		o.Scale = cube.DefaultPNGScale
+	}
+	if o.MaxImageBytes <= 0 {
+		o.MaxImageBytes = 8 << 20
+	}
+	if o.MaxText <= 0 {
+		o.MaxText = 2000
+	}
+	if o.MaxMoves <= 0 {
+		o.MaxMoves = 1000
+	}
+	if o.MaxSize <= 0 {
+		o.MaxSize = 17
+	}
+	if o.SolveTime == 0 {
+		o.SolveTime = 500 * time.Millisecond
+	}
+}
+
+// Reply is the answer to a message: a text, and an image to attach or link
+// to.
+type Reply struct {
+	Text     string
+	Image    []byte // PNG, nil if it is larger than MaxImageBytes at any scale
+	Filename string // Of the image as an attachment
+	ImageURL string // Of the image at Options.ImageURL, if set
+}
+
+// Answer answers a message that starts with the prefix of the options, and
+// returns ok false for other messages, which bots ignore.
+func Answer(message string, opts Options) (reply Reply, ok bool, err error) {
+	opts.defaults()
+	message = strings.TrimSpace(message)
+	args, ok := strings.CutPrefix(message, opts.Prefix)
+	if !ok || (args != "" && !strings.ContainsAny(args[:1], " \t\n")) {
+		return Reply{}, false, nil
+	}
+	reply, err = Command(args, opts)
+	return reply, true, err
+}
+
+var sizeArg = regexp.MustCompile(`^(\d+)(?:x(\d+))?$`)
+
+// Command answers the arguments of a command, without its prefix, such as
+// the text of a Slack slash command: an optional size such as 4x4, an
+// optional view of net or plan, and the moves. Backticks around the moves,
+// of code in chat messages, are dropped.
+func Command(args string, opts Options) (Reply, error) {
+	opts.defaults()
+	fields := strings.Fields(strings.ReplaceAll(args, "`", " "))
+	size, view := 3, "net"
+	for len(fields) > 0 {
+		if m := sizeArg.FindStringSubmatch(fields[0]); m != nil && (m[2] == "" || m[1] == m[2]) {
+			size, _ = strconv.Atoi(m[1])
+		} else if fields[0] == "net" || fields[0] == "plan" {
+			view = fields[0]
+		} else {
+			break
+		}
+		fields = fields[1:]
+	}
+	if size < 1 || size > opts.MaxSize {
+		return Reply{}, fmt.Errorf("%dx%d: %w", size, size, ErrSize)
+	}
+	text := strings.Join(fields, " ")
+	if text == "" {
+		return Reply{}, ErrNoMoves
+	}
+	group, err := cube.ParseNotation(text)
+	if err != nil {
+		return Reply{}, err
+	}
+	if group.Length(opts.MaxMoves+1) > opts.MaxMoves {
+		return Reply{}, fmt.Errorf("%d moves: %w", opts.MaxMoves, ErrTooLong)
+	}
+	moves, err := group.Expand()
+	if err != nil {
+		return Reply{}, err
+	}
+	c := cube.NewCubeWith(size, cube.Options{Scheme: opts.Scheme})
+	if err := c.ExecuteMoves(moves...); err != nil {
+		return Reply{}, err
+	}
+
+	reply := Reply{Filename: fmt.Sprintf("cube-%dx%d.png", size, size)}
+	for scale := opts.Scale; scale >= 0.25; scale /= 2 {
+		var b bytes.Buffer
+		if err := c.RenderPNG(&b, cube.PNGOptions{Scale: scale, Plan: view == "plan"}); err != nil {
+			return Reply{}, err
+		}
+		if b.Len() <= opts.MaxImageBytes {
+			reply.Image = b.Bytes()
+			break
+		}
+	}
+	if opts.ImageURL != "" {
+		query := url.Values{"alg": {cube.FormatMoves(moves)}}
+		if view == "plan" {
+			query.Set("view", view)
+		}
+		if opts.Scheme.Name != "" && opts.Scheme.Name != cube.Western.Name {
+			query.Set("scheme", opts.Scheme.Name)
+		}
+		reply.ImageURL = fmt.Sprintf("%s/img/%dx%d.png?%s", strings.TrimSuffix(opts.ImageURL, "/"), size, size, query.Encode())
+	}
+
+	lines := []string{cube.FormatMoves(moves), counts(moves)}
+	if size == 3 && opts.SolveTime > 0 {
+		if state, err := solve.StateOf(moves...); err == nil {
+			solution, err := solve.Solve(state, solve.SolveOptions{Timeout: opts.SolveTime})
+			switch {
+			case err != nil:
+			case len(solution) == 0:
+				lines = append(lines, "Solved")
+			default:
+				lines = append(lines, fmt.Sprintf("Solution: %s (%d)", cube.FormatMoves(solution), len(solution)))
+			}
+		}
+	}
+	if reply.Image == nil {
+		lines = append(lines, "The image is too large to attach")
+	}
+	reply.Text = truncate(strings.Join(lines, "\n"), opts.MaxText)
+	return reply, nil
+}
+
+// counts returns the text of the counts of the moves.
+func counts(moves []cube.Move) string {
+	e := analysis.Ergonomics(moves, analysis.DefaultCostModel)
+	return fmt.Sprintf("%d %s, %d %s, %d %s, cost %.1f",
+		e.Turns, plural(e.Turns, "turn"), e.Rotations, plural(e.Rotations, "rotation"), e.Regrips, plural(e.Regrips, "regrip"), e.Cost)
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
+
+// truncate cuts a text to at most n characters, ending in an ellipsis if it
+// is cut.
+func truncate(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,94 @@
+package bot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+var ErrSignature = errors.New("request not signed by Slack")
+
+// SlackHandler answers the slash commands of a Slack app, such as /cube R U,
+// with Command. Slack shows images by their address only, so replies link
+// to Options.ImageURL, such as a daemon.ImageHandler next to the handler.
+type SlackHandler struct {
+	Options Options
+
+	// SigningSecret is the signing secret of the app, to check that requests
+	// come from Slack. Requests are not checked if it is empty.
+	SigningSecret string
+}
+
+// slackBlock is a block of a Slack message, a section of text or an image.
+type slackBlock struct {
+	Type     string     `json:"type"`
+	Text     *slackText `json:"text,omitempty"`
+	ImageURL string     `json:"image_url,omitempty"`
+	AltText  string     `json:"alt_text,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func (h *SlackHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 64<<10))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if h.SigningSecret != "" && !h.signed(r.Header, body) {
+		http.Error(w, ErrSignature.Error(), http.StatusUnauthorized)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Errors are answered to the user alone, and replies to the channel.
+	message := map[string]any{"response_type": "ephemeral"}
+	reply, err := Command(form.Get("text"), h.Options)
+	if err != nil {
+		message["text"] = err.Error()
+	} else {
+		message["response_type"] = "in_channel"
+		message["text"] = reply.Text
+		blocks := []slackBlock{{Type: "section", Text: &slackText{"plain_text", reply.Text}}}
+		if reply.ImageURL != "" {
+			blocks = append(blocks, slackBlock{Type: "image", ImageURL: reply.ImageURL, AltText: form.Get("text")})
+		}
+		message["blocks"] = blocks
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(message)
+}
+
+// signed reports whether a request carries the signature of Slack of its
+// body, made in the last five minutes.
+func (h *SlackHandler) signed(header http.Header, body []byte) bool {
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || time.Since(time.Unix(sec, 0)).Abs() > 5*time.Minute {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(h.SigningSecret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	want := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(header.Get("X-Slack-Signature")), []byte(want))
+}
@@ -0,0 +1,136 @@
+// Package clock models the Rubik's Clock puzzle and its WCA notation.
+package clock
+
+import "strconv"
+
+// Pins is a set of pins, as seen from the front of the puzzle.
+type Pins uint8
+
+const (
+	PinUL Pins = 1 << iota
+	PinUR
+	PinDL
+	PinDR
+
+	PinsAll = PinUL | PinUR | PinDL | PinDR
+)
+
+// quadrants holds the front dials connected by each pin when it is up. Dials
+// are numbered row by row from the top left.
+var quadrants = map[Pins][]int{
+	PinUL: {0, 1, 3, 4},
+	PinUR: {1, 2, 4, 5},
+	PinDL: {3, 4, 6, 7},
+	PinDR: {4, 5, 7, 8},
+}
+
+// twins maps each front corner dial to the back dial sharing its gear. The
+// back is numbered as seen from behind, so left and right are swapped.
+var twins = map[int]int{0: 2, 2: 0, 6: 8, 8: 6}
+
+const hours = 12
+
+// Clock holds the dials of both sides, each from 0 to 11 where 0 points to 12
+// o'clock, and the positions of the pins.
+type Clock struct {
+	front, back [9]int
+	pins        Pins // Pins that are up on the front side
+}
+
+func New() *Clock {
+	return &Clock{}
+}
+
+// Front returns the front dials, row by row from the top left.
+func (c *Clock) Front() [9]int {
+	return c.front
+}
+
+// Back returns the back dials, row by row from the top left as seen from behind.
+func (c *Clock) Back() [9]int {
+	return c.back
+}
+
+// Pins returns the pins that are up on the front side.
+func (c *Clock) Pins() Pins {
+	return c.pins
+}
+
+func (c *Clock) IsSolved() bool {
+	return c.front == [9]int{} && c.back == [9]int{}
+}
+
+// ExecuteMove sets the pins of the move and turns the front dials connected
+// to the up pins, or turns the puzzle over if the move is a flip.
+func (c *Clock) ExecuteMove(move Move) error {
+	if move.Flip {
+		c.flip()
+		return nil
+	}
+
+	c.pins = move.Pins
+
+	turned := map[int]bool{}
+	for pin, dials := range quadrants {
+		if c.pins&pin == 0 {
+			continue
+		}
+		for _, d := range dials {
+			turned[d] = true
+		}
+	}
+
+	for d := range turned {
+		c.front[d] = mod(c.front[d]+move.Amount, hours)
+		if twin, ok := twins[d]; ok {
+			c.back[twin] = mod(c.back[twin]-move.Amount, hours)
+		}
+	}
+	return nil
+}
+
+// Apply executes a single move, see ExecuteMove.
+func (c *Clock) Apply(move Move) error {
+	return c.ExecuteMove(move)
+}
+
+// State returns the front and back dials as hex digits followed by the up
+// pins of the front as a hex digit.
+func (c *Clock) State() string {
+	const digits = "0123456789ab"
+
+	state := make([]byte, 0, 19)
+	for _, d := range c.front {
+		state = append(state, digits[d])
+	}
+	for _, d := range c.back {
+		state = append(state, digits[d])
+	}
+	return string(state) + strconv.FormatUint(uint64(c.pins), 16)
+}
+
+func (c *Clock) ExecuteMoves(moves ...Move) error {
+	for _, m := range moves {
+		if err := c.ExecuteMove(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flip turns the puzzle over around the vertical axis. Pins that were up are
+// down on the other side, and left and right swap.
+func (c *Clock) flip() {
+	c.front, c.back = c.back, c.front
+	c.pins = c.pins.otherSide()
+}
+
+// otherSide returns the pins that are up when seen from the other side.
+func (p Pins) otherSide() Pins {
+	mirrored := p&(PinUL|PinDL)<<1 | p&(PinUR|PinDR)>>1
+	return PinsAll &^ mirrored
+}
+
+func mod(a, n int) int {
+	return ((a % n) + n) % n
+}
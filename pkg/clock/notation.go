@@ -0,0 +1,86 @@
+package clock
+
+import (
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/alg"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrTokenExtraction = errors.New("token extraction")
+)
+
+var (
+	reTurn = regexp.MustCompile(`^(UR|DR|DL|UL|U|R|D|L|ALL)(\d{1,2})([+-])$`)
+	rePin  = regexp.MustCompile(`^(UR|DR|DL|UL)$`)
+)
+
+var pinGroups = map[string]Pins{
+	"UL":  PinUL,
+	"UR":  PinUR,
+	"DL":  PinDL,
+	"DR":  PinDR,
+	"U":   PinUL | PinUR,
+	"R":   PinUR | PinDR,
+	"D":   PinDL | PinDR,
+	"L":   PinUL | PinDL,
+	"ALL": PinsAll,
+}
+
+// Move sets the pins and turns a wheel next to an up pin, or turns the puzzle
+// over. A move with an Amount of zero only sets the pins.
+type Move struct {
+	Pins   Pins // Pins that are up, as seen from the front
+	Amount int  // Hours turned clockwise, negative for counterclockwise
+	Flip   bool // Turn the puzzle over to the back (y2)
+}
+
+func (m *Move) String() string {
+	return fmt.Sprintf("Move: Pins=%04b, Amount=%d, Flip=%t", m.Pins, m.Amount, m.Flip)
+}
+
+// ParseNotation parses WCA Clock notation such as "UR5+ ALL2- y2 U3+ UR DL".
+// Consecutive pin names, as used at the end of a scramble, combine into a
+// single move that only sets the pins. The Unicode lookalikes that alg.Clean
+// replaces are accepted.
+func ParseNotation(input string) ([]Move, error) {
+	var moves []Move
+	pinsOnly := false
+
+	for _, token := range strings.Fields(alg.Clean(input)) {
+		if token == "y2" {
+			moves = append(moves, Move{Flip: true})
+			pinsOnly = false
+			continue
+		}
+
+		if m := reTurn.FindStringSubmatch(token); m != nil {
+			amount, err := strconv.Atoi(m[2])
+			if err != nil {
+				return nil, err
+			}
+			if m[3] == "-" {
+				amount = -amount
+			}
+			moves = append(moves, Move{Pins: pinGroups[m[1]], Amount: amount})
+			pinsOnly = false
+			continue
+		}
+
+		if rePin.MatchString(token) {
+			if !pinsOnly {
+				moves = append(moves, Move{})
+				pinsOnly = true
+			}
+			moves[len(moves)-1].Pins |= pinGroups[token]
+			continue
+		}
+
+		return nil, ErrTokenExtraction
+	}
+
+	return moves, nil
+}
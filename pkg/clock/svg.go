@@ -0,0 +1,63 @@
+package clock
+
+import (
+	"github.com/larssont/go-cubic/pkg/internal/svg"
+	"io"
+	"math"
+)
+
+const (
+	svgDial    = 20.0 // Dial radius
+	svgSpacing = 56.0 // Distance between dial centers
+	svgMargin  = 10.0
+)
+
+var svgSide = 2*svgSpacing + 2*svgDial + 2*svgMargin
+
+// RenderSVG draws the front and the back of the clock side by side, with the
+// pins shown between the dials of each side. Up pins are drawn in yellow.
+func (c *Clock) RenderSVG(w io.Writer) error {
+	out := svg.NewWriter(w, 2*svgSide+3*svgMargin, svgSide+2*svgMargin)
+
+	drawSide(out, c.front, c.pins, svg.Point{X: svgMargin, Y: svgMargin}, "#1f4e9c")
+	drawSide(out, c.back, c.pins.otherSide(), svg.Point{X: 2*svgMargin + svgSide, Y: svgMargin}, "#5d9ee8")
+
+	return out.Close()
+}
+
+func drawSide(out *svg.Writer, dials [9]int, pins Pins, origin svg.Point, body string) {
+	out.Polygon(body,
+		origin,
+		svg.Point{X: origin.X + svgSide, Y: origin.Y},
+		svg.Point{X: origin.X + svgSide, Y: origin.Y + svgSide},
+		svg.Point{X: origin.X, Y: origin.Y + svgSide},
+	)
+
+	first := svgMargin + svgDial
+	for i, hour := range dials {
+		center := svg.Point{
+			X: origin.X + first + float64(i%3)*svgSpacing,
+			Y: origin.Y + first + float64(i/3)*svgSpacing,
+		}
+		out.Circle("#ffffff", center, svgDial)
+
+		angle := float64(hour) * 2 * math.Pi / hours
+		tip := svg.Point{
+			X: center.X + math.Sin(angle)*svgDial*0.8,
+			Y: center.Y - math.Cos(angle)*svgDial*0.8,
+		}
+		out.Line(center, tip, 3)
+	}
+
+	for i, pin := range []Pins{PinUL, PinUR, PinDL, PinDR} {
+		center := svg.Point{
+			X: origin.X + first + (float64(i%2)+0.5)*svgSpacing,
+			Y: origin.Y + first + (float64(i/2)+0.5)*svgSpacing,
+		}
+		fill := "#333333"
+		if pins&pin != 0 {
+			fill = "#ffdd00"
+		}
+		out.Circle(fill, center, svgDial/4)
+	}
+}
@@ -0,0 +1,200 @@
+// Package config reads and writes the defaults of the command line, such as
+// the cube size and color scheme, from a file of "key = value" lines in the
+// user's config directory, $XDG_CONFIG_HOME/go-cubic/config on Linux.
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrLine  = errors.New("settings are written as key = value")
+	ErrKey   = errors.New("unknown setting")
+	ErrValue = errors.New("invalid value of setting")
+)
+
+// Key is a setting of the file.
+type Key struct {
+	Name  string
+	Usage string
+	check func(value string) error // Nil for any value
+}
+
+// Keys holds the settings in the order they are written.
+var Keys = []Key{
+	{"size", "size of the cubes of commands", checkSize},
+	{"scheme", "color scheme, as for -scheme", checkScheme},
+	{"dialect", "notation of moves given to commands: wca, or timed for a timestamp after every move", checkOneOf("wca", "timed")},
+	{"input", "notation pasted from web pages and PDFs: lenient to accept typographic primes, dashes and spaces, or strict", checkOneOf("lenient", "strict")},
+	{"theme", "drawing of stickers: color, or patterns to mark the colors with shapes", checkOneOf("color", "patterns")},
+	{"cost", "per-move cost table of -cost: a registered name such as default, a file, or entries such as R=1, F2=1.8, rotations=2.5", nil},
+	{"keymap", "file of key bindings of interactive simulators, see package keymap", nil},
+	{"out", "directory of the files that commands write", nil},
+	{"data", "directory of the history of sessions, solves, replays and trainer cases, see package store", nil},
+	{"tables", "directory of pruning tables written by solve.SaveTables", nil},
+}
+
+func checkSize(value string) error {
+	if n, err := strconv.Atoi(value); err != nil || n < 1 {
+		return ErrValue
+	}
+	return nil
+}
+
+func checkScheme(value string) error {
+	if _, err := cube.LookupScheme(value); err != nil {
+		return fmt.Errorf("%w: %w", ErrValue, err)
+	}
+	return nil
+}
+
+func checkOneOf(values ...string) func(string) error {
+	return func(value string) error {
+		for _, v := range values {
+			if value == v {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: want one of %s", ErrValue, strings.Join(values, ", "))
+	}
+}
+
+// key returns the setting of the name.
+func key(name string) (Key, error) {
+	for _, k := range Keys {
+		if k.Name == name {
+			return k, nil
+		}
+	}
+	return Key{}, fmt.Errorf("%q: %w", name, ErrKey)
+}
+
+// Config is the values of the settings that are set.
+type Config map[string]string
+
+// Path returns the path of the config file: $GO_CUBIC_CONFIG if set, and
+// else the file config in the go-cubic directory of the user's config
+// directory.
+func Path() (string, error) {
+	if path := os.Getenv("GO_CUBIC_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "go-cubic", "config"), nil
+}
+
+// Load reads settings, one per line as "key = value". Lines starting with
+// # are comments.
+func Load(r io.Reader) (Config, error) {
+	c := Config{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: %w", line, ErrLine)
+		}
+		if err := c.Set(strings.TrimSpace(name), strings.TrimSpace(value)); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return c, scanner.Err()
+}
+
+// LoadFile loads the config file at the path, with no settings if there is
+// no file.
+func LoadFile(path string) (Config, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+// Write writes the settings that are set in the order of Keys, in the format
+// of Load.
+func (c Config) Write(w io.Writer) error {
+	for _, k := range Keys {
+		if value, ok := c[k.Name]; ok {
+			if _, err := fmt.Fprintf(w, "%s = %s\n", k.Name, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteFile writes the settings to the path, making its directory if
+// needed.
+func (c Config) WriteFile(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := c.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Set sets a setting, checking the name and value.
+func (c Config) Set(name, value string) error {
+	k, err := key(name)
+	if err != nil {
+		return err
+	}
+	if k.check != nil {
+		if err := k.check(value); err != nil {
+			return fmt.Errorf("%s = %s: %w", name, value, err)
+		}
+	}
+	c[name] = value
+	return nil
+}
+
+// Unset removes a setting, so that commands use their own default.
+func (c Config) Unset(name string) error {
+	if _, err := key(name); err != nil {
+		return err
+	}
+	delete(c, name)
+	return nil
+}
+
+// String returns the setting of the name, or def if it is not set.
+func (c Config) String(name, def string) string {
+	if value, ok := c[name]; ok {
+		return value
+	}
+	return def
+}
+
+// Int returns the setting of the name as a number, or def if it is not set.
+func (c Config) Int(name string, def int) int {
+	if n, err := strconv.Atoi(c[name]); err == nil {
+		return n
+	}
+	return def
+}
@@ -0,0 +1,59 @@
+package cube
+
+// Axis is an axis that layers turn about, named after the rotation about it:
+// x from Left to Right, y from Down to Up and z from Back to Front, as the
+// coordinates of a Position.
+type Axis rune
+
+const (
+	AxisX Axis = 'x'
+	AxisY Axis = 'y'
+	AxisZ Axis = 'z'
+)
+
+// Turn is how a move turns the cube, for animating it: the pieces in a block
+// of layers rotate about an axis through the middle of the cube, from where
+// they are before the move to where they are after it.
+type Turn struct {
+	Move               Move
+	Axis               Axis
+	MinLayer, MaxLayer int // The layers turned, as coordinates along the axis
+
+	// Angle is the angle of the turn in degrees, counterclockwise seen from
+	// the positive end of the axis, as the right-hand rule has it, such as
+	// -90 for R and U, 90 for L and -180 for U2.
+	Angle float64
+
+	Pieces   []Position // Where the pieces turned are before the turn
+	Stickers []Sticker  // Where the stickers of those pieces are before the turn
+}
+
+// TurnOf returns how the move would turn the cube as it is, without turning
+// it. Moves that turn nothing, such as M on even cubes, have no pieces and
+// an angle of 0.
+func (c *Cube) TurnOf(m Move) (Turn, error) {
+	t, ok, err := c.turnOf(m)
+	if err != nil {
+		return Turn{}, err
+	}
+	out := Turn{Move: m, Axis: Axis("xyz"[t.axis])}
+	if !ok {
+		return out, nil
+	}
+	out.MinLayer, out.MaxLayer = t.layerMin, t.layerMax
+	// Turns that are clockwise to turnAxis are counterclockwise about the
+	// axis, except about y, whose tiles it turns the other way round.
+	out.Angle = 90 * float64(t.rotations)
+	if t.clockwise == (t.axis == axisY) {
+		out.Angle = -out.Angle
+	}
+	for _, p := range c.pieces {
+		pos := Position{p.x.coordinate, p.y.coordinate, p.z.coordinate}
+		if l := pos[t.axis]; l < t.layerMin || l > t.layerMax {
+			continue
+		}
+		out.Pieces = append(out.Pieces, pos)
+		out.Stickers = append(out.Stickers, StickersAt(c.Dimension(), pos[0], pos[1], pos[2])...)
+	}
+	return out, nil
+}
@@ -0,0 +1,58 @@
+package cube
+
+import "strings"
+
+// CanonicalMoves returns the normal form of an algorithm up to AUF and
+// rotations, so that algorithms solving a case the same way from another
+// angle share it: the rotations are taken out by turning the faces they
+// bring around in their place, the turns of U at either end are left out,
+// and of the algorithm as done from the four sides around y, the one that
+// turns the faces of canonicalOrder first is taken, which favors R and U.
+func CanonicalMoves(moves []Move) []Move {
+	var plain, rotation []Move
+	for _, m := range moves {
+		if m.IsAny('x', 'y', 'z') {
+			rotation = append(rotation, m)
+			continue
+		}
+		// The face a move turns after the rotations is the one that the
+		// rotations undone bring to it.
+		plain = append(plain, RemapMoves([]Move{m}, ReverseMoves(rotation)...)...)
+	}
+	plain, _ = NormalizeMoves(plain)
+	for len(plain) > 0 && isAUF(plain[0]) {
+		plain = plain[1:]
+	}
+	for len(plain) > 0 && isAUF(plain[len(plain)-1]) {
+		plain = plain[:len(plain)-1]
+	}
+
+	best := plain
+	y := []Move{}
+	for range 3 {
+		y = append(y, Move{Operator: 'y', Rotations: 1})
+		if turned := RemapMoves(plain, y...); canonicalKey(turned) < canonicalKey(best) {
+			best = turned
+		}
+	}
+	return best
+}
+
+// isAUF reports whether the move turns the U layer alone.
+func isAUF(m Move) bool {
+	return m.Operator == 'U' && !m.Wide
+}
+
+// canonicalOrder ranks the faces for CanonicalMoves, in the order that
+// algorithms prefer to turn them.
+const canonicalOrder = "RUFLDBMES"
+
+// canonicalKey returns a key of the moves that sorts by canonicalOrder, then
+// as FormatMoves writes them.
+func canonicalKey(moves []Move) string {
+	var key []byte
+	for _, m := range moves {
+		key = append(key, byte(strings.IndexRune(canonicalOrder, m.Operator)+'0'))
+	}
+	return string(key) + FormatMoves(moves)
+}
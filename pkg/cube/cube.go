@@ -36,9 +36,75 @@ type turn struct {
 	clockwise bool
 }
 
+// Face identifies a face of the cube, in the same order as CubeFaces.All.
+type Face int
+
+const (
+	FaceUp Face = iota
+	FaceLeft
+	FaceFront
+	FaceRight
+	FaceBack
+	FaceDown
+)
+
+// Sticker identifies a sticker by its face and its index in CubeFaces.
+type Sticker struct {
+	Face  Face
+	Index int
+}
+
+// stickerIndex returns the index within face f of the sticker of the piece
+// at (x, y, z), for a cube whose highest coordinate is max.
+func stickerIndex(f Face, max, x, y, z int) int {
+	numSide := max + 1
+	switch f {
+	case FaceUp:
+		return x + z*numSide
+	case FaceDown:
+		return (max-z)*numSide + x
+	case FaceLeft:
+		return (max-y)*numSide + z
+	case FaceRight:
+		return (max-y)*numSide + (max - z)
+	case FaceBack:
+		return (max - x) + (max-y)*numSide
+	default:
+		return x + (max-y)*numSide
+	}
+}
+
+// StickersAt returns the stickers of the piece at (x, y, z) on a cube of the
+// given dimension, in the order of CubeFaces.All.
+func StickersAt(dimension, x, y, z int) []Sticker {
+	max := dimension - 1
+
+	var stickers []Sticker
+	add := func(f Face, onFace bool) {
+		if onFace {
+			stickers = append(stickers, Sticker{f, stickerIndex(f, max, x, y, z)})
+		}
+	}
+
+	add(FaceUp, y == max)
+	add(FaceLeft, x == 0)
+	add(FaceFront, z == max)
+	add(FaceRight, x == max)
+	add(FaceBack, z == 0)
+	add(FaceDown, y == 0)
+	return stickers
+}
+
 type Cube struct {
 	max    int
 	pieces []*piece
+	scheme Scheme
+	hooks  hooks
+}
+
+// Options configures NewCubeWith.
+type Options struct {
+	Scheme Scheme // Defaults to Western
 }
 
 type CubeFaces struct {
@@ -54,6 +120,11 @@ func (cf *CubeFaces) All() []*[]rune {
 	return []*[]rune{&cf.Up, &cf.Left, &cf.Front, &cf.Right, &cf.Back, &cf.Down}
 }
 
+// Face returns the stickers of a single face.
+func (cf *CubeFaces) Face(f Face) []rune {
+	return *cf.All()[f]
+}
+
 func (cf *CubeFaces) Validate() error {
 	if !isEqualLength(cf.All()) {
 		return ErrFaceLengthsDiffer
@@ -82,36 +153,32 @@ func (c *Cube) Faces() *CubeFaces {
 	back := make([]rune, numTiles)
 
 	for _, p := range c.pieces {
+		x, y, z := p.x.coordinate, p.y.coordinate, p.z.coordinate
+
 		// Up face (y == max)
-		if p.y.coordinate == c.max {
-			index := p.x.coordinate + p.z.coordinate*numSide
-			up[index] = p.y.color
+		if y == c.max {
+			up[stickerIndex(FaceUp, c.max, x, y, z)] = p.y.color
 		}
 		// Down face (y == 0)
-		if p.y.coordinate == 0 {
-			index := (c.max-p.z.coordinate)*numSide + p.x.coordinate
-			down[index] = p.y.color
+		if y == 0 {
+			down[stickerIndex(FaceDown, c.max, x, y, z)] = p.y.color
 		}
 		// Left face (x == 0)
-		if p.x.coordinate == 0 {
-			index := (c.max-p.y.coordinate)*numSide + p.z.coordinate
-			left[index] = p.x.color
+		if x == 0 {
+			left[stickerIndex(FaceLeft, c.max, x, y, z)] = p.x.color
 		}
 		// Right face (x == max)
-		if p.x.coordinate == c.max {
-			index := (c.max-p.y.coordinate)*numSide + (c.max - p.z.coordinate)
-			right[index] = p.x.color
+		if x == c.max {
+			right[stickerIndex(FaceRight, c.max, x, y, z)] = p.x.color
 		}
 
 		// Back face (z == 0)
-		if p.z.coordinate == 0 {
-			index := (c.max - p.x.coordinate) + (c.max-p.y.coordinate)*numSide
-			back[index] = p.z.color
+		if z == 0 {
+			back[stickerIndex(FaceBack, c.max, x, y, z)] = p.z.color
 		}
 		// Front face (z == max)
-		if p.z.coordinate == c.max {
-			index := p.x.coordinate + (c.max-p.y.coordinate)*numSide
-			front[index] = p.z.color
+		if z == c.max {
+			front[stickerIndex(FaceFront, c.max, x, y, z)] = p.z.color
 		}
 	}
 
@@ -126,6 +193,14 @@ func (c *Cube) Faces() *CubeFaces {
 }
 
 func NewCube(size int) *Cube {
+	return NewCubeWith(size, Options{})
+}
+
+// NewCubeWith returns a solved cube of the size with the options.
+func NewCubeWith(size int, opts Options) *Cube {
+	if opts.Scheme.Faces == ([6]rune{}) {
+		opts.Scheme = Western
+	}
 	n := pow(size, 3) - pow(size-2, 3)
 
 	pieces := make([]*piece, 0, n)
@@ -146,7 +221,7 @@ func NewCube(size int) *Cube {
 				if isInner(x, y, z) {
 					continue
 				}
-				pieces = append(pieces, newPiece(max, x, y, z))
+				pieces = append(pieces, newPiece(max, opts.Scheme.Faces, x, y, z))
 			}
 		}
 	}
@@ -154,10 +229,13 @@ func NewCube(size int) *Cube {
 	return &Cube{
 		max:    max,
 		pieces: pieces,
+		scheme: opts.Scheme,
 	}
 }
 
-func newPiece(max int, x, y, z int) *piece {
+// newPiece returns the piece at home at (x, y, z), with the colors of the
+// faces indexed by Face.
+func newPiece(max int, faces [6]rune, x, y, z int) *piece {
 	colorize := func(coord int, c0, cMax rune) rune {
 		if coord == 0 {
 			return c0
@@ -168,9 +246,9 @@ func newPiece(max int, x, y, z int) *piece {
 	}
 
 	return &piece{
-		x: newTile(x, colorize(x, 'o', 'r')),
-		y: newTile(y, colorize(y, 'y', 'w')),
-		z: newTile(z, colorize(z, 'b', 'g')),
+		x: newTile(x, colorize(x, faces[FaceLeft], faces[FaceRight])),
+		y: newTile(y, colorize(y, faces[FaceDown], faces[FaceUp])),
+		z: newTile(z, colorize(z, faces[FaceBack], faces[FaceFront])),
 	}
 }
 
@@ -213,55 +291,106 @@ func (c *Cube) turn(turn turn) *Cube {
 	return c
 }
 
+// ExecuteMove turns the cube, and calls the hooks of OnMove before and those of
+// OnTurnComplete after the move.
 func (c *Cube) ExecuteMove(move Move) error {
+	if !c.hooks.active() {
+		return c.executeMove(move)
+	}
+	if move.Wide && move.Slices > c.Dimension() {
+		return ErrSliceParam
+	}
+	return c.hooks.run(c, move)
+}
+
+func (c *Cube) executeMove(move Move) error {
+	t, ok, err := c.turnOf(move)
+	if err != nil || !ok {
+		return err
+	}
+	c.turn(t)
+	return nil
+}
+
+// turnOf returns the turn of the layers that the move makes, or false for
+// moves that turn nothing, such as M on even cubes.
+func (c *Cube) turnOf(move Move) (turn, bool, error) {
 	layerMin := c.max
 	layerMax := c.max
 
 	if move.Wide {
 		layerMin -= move.Slices - 1
 	}
+	if move.From > 1 {
+		layerMax -= move.From - 1
+	}
 
 	if layerMin < 0 {
-		return ErrSliceParam
+		return turn{}, false, ErrSliceParam
 	}
 
-	if move.isAny('L', 'B', 'D', 'E') {
+	if move.IsAny('L', 'B', 'D', 'E') {
 		layerMin, layerMax = c.max-layerMax, c.max-layerMin
-	} else if move.isAny('x', 'y', 'z') {
+	} else if move.IsAny('x', 'y', 'z') {
 		layerMin = 0
 		layerMax = c.max
 	}
 
 	clockwise := !move.Inverted
-	if move.isAny('R', 'F', 'D', 'E', 'S', 'x', 'z') {
+	if move.IsAny('R', 'F', 'D', 'E', 'S', 'x', 'z') {
 		clockwise = !clockwise
 	}
 
 	axis := axisX
-	if move.isAny('F', 'B', 'S', 'z') {
+	if move.IsAny('F', 'B', 'S', 'z') {
 		axis = axisZ
-	} else if move.isAny('U', 'D', 'E', 'y') {
+	} else if move.IsAny('U', 'D', 'E', 'y') {
 		axis = axisY
 	}
 
-	if move.isAny('M', 'E', 'S') {
+	if move.IsAny('M', 'E', 'S') {
 		if c.max%2 == 1 {
-			return nil
+			return turn{axis: axis}, false, nil
 		}
 
 		layerMin = c.max / 2
 		layerMax = layerMin
 	}
 
-	c.turn(
-		turn{
-			axis:      axis,
-			layerMin:  layerMin,
-			layerMax:  layerMax,
-			rotations: move.Rotations,
-			clockwise: clockwise,
-		})
-	return nil
+	return turn{
+		axis:      axis,
+		layerMin:  layerMin,
+		layerMax:  layerMax,
+		rotations: move.Rotations,
+		clockwise: clockwise,
+	}, true, nil
+}
+
+// Apply executes a single move, see ExecuteMove.
+func (c *Cube) Apply(move Move) error {
+	return c.ExecuteMove(move)
+}
+
+// IsSolved reports whether every face has a single color, in any orientation.
+func (c *Cube) IsSolved() bool {
+	for _, f := range c.Faces().All() {
+		for _, color := range *f {
+			if color != (*f)[0] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// State returns the sticker colors of the faces in the order Up, Left, Front,
+// Right, Back, Down.
+func (c *Cube) State() string {
+	var state []rune
+	for _, f := range c.Faces().All() {
+		state = append(state, *f...)
+	}
+	return string(state)
 }
 
 func (c *Cube) ExecuteMoves(moves ...Move) error {
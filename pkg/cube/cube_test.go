@@ -0,0 +1,38 @@
+package cube
+
+import "testing"
+
+func TestLayerRangeMove(t *testing.T) {
+	tests := []struct {
+		size        int
+		input, want string
+	}{
+		{7, "2-4Rw", "4Rw R'"},
+		{7, "2-4Rw'", "R 4Rw'"},
+		{7, "3-5Uw2", "5Uw2 2Uw2"},
+		{5, "2-3Fw", "3Fw F'"},
+	}
+	for _, tt := range tests {
+		a, b := NewCube(tt.size), NewCube(tt.size)
+		for c, input := range map[*Cube]string{a: tt.input, b: tt.want} {
+			g, err := ParseNotation("L D' B2 " + input)
+			if err != nil {
+				t.Fatalf("ParseNotation(%q): %v", input, err)
+			}
+			moves, err := g.Expand()
+			if err != nil {
+				t.Fatalf("Expand(%q): %v", input, err)
+			}
+			if err := c.ExecuteMoves(moves...); err != nil {
+				t.Fatalf("ExecuteMoves(%q): %v", input, err)
+			}
+		}
+		d, err := Diff(a, b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !d.Equal() {
+			t.Errorf("%dx%d: %s differs from %s: %s", tt.size, tt.size, tt.input, tt.want, d)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package cube
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrDimensionsDiffer = errors.New("cubes of different sizes")
+
+// PieceDiff is a piece that is at another position, or turned otherwise, in
+// one state than in another.
+type PieceDiff struct {
+	Kind PieceKind
+	Home Position
+	From Position // Position in the first state
+	To   Position // Position in the second state
+
+	// Twist is the orientation in the second state less the one in the
+	// first, see Piece: clockwise twists of a corner from 0 to 2, or 1 for an
+	// edge or a wing that flipped.
+	Twist int
+}
+
+// StateDiff tells two states of a cube apart, sticker by sticker and piece by
+// piece, such as what an algorithm changes.
+type StateDiff struct {
+	Dimension int
+
+	// Stickers are those of another color, in the order of CubeFaces.All.
+	Stickers []Sticker
+
+	// Pieces are those moved or turned, in the order of Pieces. Pieces that
+	// look alike, such as the centers of big cubes, count when they swap.
+	Pieces []PieceDiff
+
+	// Cycles is the permutation of the moved pieces: the piece at every
+	// position of a cycle goes to the next one, and the last to the first.
+	Cycles [][]Position
+}
+
+// Diff compares the states of two cubes of the same size, such as a cube
+// before and after an algorithm. Pieces are told apart by where they started,
+// so both cubes should come from the same start. Diff returns
+// ErrDimensionsDiffer if the sizes differ.
+func Diff(a, b *Cube) (StateDiff, error) {
+	if a.Dimension() != b.Dimension() {
+		return StateDiff{}, fmt.Errorf("%dx%d and %dx%d: %w", a.Dimension(), a.Dimension(), b.Dimension(), b.Dimension(), ErrDimensionsDiffer)
+	}
+	d := StateDiff{Dimension: a.Dimension()}
+
+	facesA, facesB := a.Faces().All(), b.Faces().All()
+	for f := range facesA {
+		for i, color := range *facesA[f] {
+			if (*facesB[f])[i] != color {
+				d.Stickers = append(d.Stickers, Sticker{Face(f), i})
+			}
+		}
+	}
+
+	to := map[Position]Position{}
+	for p := range a.Pieces() {
+		q, ok := b.FindPiece(p.Home)
+		if !ok {
+			continue
+		}
+		modulus := 3
+		if p.Kind != Corner {
+			modulus = 2
+		}
+		twist := 0
+		if p.Kind != Center {
+			twist = ((q.Orientation-p.Orientation)%modulus + modulus) % modulus
+		}
+		if p.Position != q.Position || twist != 0 {
+			d.Pieces = append(d.Pieces, PieceDiff{p.Kind, p.Home, p.Position, q.Position, twist})
+		}
+		if p.Position != q.Position {
+			to[p.Position] = q.Position
+		}
+	}
+
+	for _, p := range d.Pieces {
+		start, ok := to[p.From]
+		if !ok {
+			continue
+		}
+		cycle := []Position{p.From}
+		for pos := start; pos != p.From; pos = to[pos] {
+			cycle = append(cycle, pos)
+		}
+		for _, pos := range cycle {
+			delete(to, pos)
+		}
+		d.Cycles = append(d.Cycles, cycle)
+	}
+	return d, nil
+}
+
+// Equal reports whether the states show the same colors everywhere.
+func (d StateDiff) Equal() bool {
+	return len(d.Stickers) == 0
+}
+
+// String returns the cycles and the turned pieces by the names of their
+// positions, such as "(UFR UBR UBL) twisted UFR+ flipped UF, 12 stickers".
+func (d StateDiff) String() string {
+	if len(d.Pieces) == 0 {
+		return fmt.Sprintf("%d stickers", len(d.Stickers))
+	}
+	c := NewCube(d.Dimension)
+	var parts []string
+	for _, cycle := range d.Cycles {
+		names := make([]string, len(cycle))
+		for i, pos := range cycle {
+			names[i] = c.nameAt(pos)
+		}
+		parts = append(parts, "("+strings.Join(names, " ")+")")
+	}
+	var twisted, flipped []string
+	for _, p := range d.Pieces {
+		switch {
+		case p.Twist == 0:
+		case p.Kind == Corner:
+			twisted = append(twisted, c.nameAt(p.To)+[]string{"", "+", "-"}[p.Twist])
+		default:
+			flipped = append(flipped, c.nameAt(p.To))
+		}
+	}
+	if len(twisted) > 0 {
+		parts = append(parts, "twisted "+strings.Join(twisted, " "))
+	}
+	if len(flipped) > 0 {
+		parts = append(parts, "flipped "+strings.Join(flipped, " "))
+	}
+	return fmt.Sprintf("%s, %d stickers", strings.Join(parts, " "), len(d.Stickers))
+}
@@ -0,0 +1,37 @@
+package cube
+
+// Permutation returns the effect of the moves on the stickers of a cube of
+// the given dimension. Stickers are indexed through the faces in the order of
+// CubeFaces.All, and after the moves, sticker i holds the sticker that started
+// at index p[i].
+func Permutation(dimension int, moves ...Move) ([]int, error) {
+	c := NewCube(dimension)
+	numTiles := dimension * dimension
+
+	for _, p := range c.pieces {
+		x, y, z := p.x.coordinate, p.y.coordinate, p.z.coordinate
+		for _, s := range StickersAt(dimension, x, y, z) {
+			label := rune(int(s.Face)*numTiles + s.Index)
+			switch s.Face {
+			case FaceLeft, FaceRight:
+				p.x.color = label
+			case FaceUp, FaceDown:
+				p.y.color = label
+			default:
+				p.z.color = label
+			}
+		}
+	}
+
+	if err := c.ExecuteMoves(moves...); err != nil {
+		return nil, err
+	}
+
+	perm := make([]int, 0, 6*numTiles)
+	for _, f := range c.Faces().All() {
+		for _, label := range *f {
+			perm = append(perm, int(label))
+		}
+	}
+	return perm, nil
+}
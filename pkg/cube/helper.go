@@ -1,24 +1,9 @@
 package cube
 
 import (
-	"log"
 	"math"
-	"strconv"
-	"time"
 )
 
-func reverse[T any](original []T) (reversed []T) {
-	reversed = make([]T, len(original))
-	copy(reversed, original)
-
-	for i := len(reversed)/2 - 1; i >= 0; i-- {
-		tmp := len(reversed) - 1 - i
-		reversed[i], reversed[tmp] = reversed[tmp], reversed[i]
-	}
-
-	return
-}
-
 func isEqualLength[T any](slices ...[]T) bool {
 	if len(slices) == 0 {
 		return true
@@ -39,15 +24,6 @@ func isPerfectSquare(x int) bool {
 	return s*s == x
 }
 
-func isDigit(ch byte) bool {
-	return '0' <= ch && ch <= '9'
-}
-
-func timeTrack(start time.Time, name string) {
-	elapsed := time.Since(start)
-	log.Printf("%s took %d ns", name, elapsed.Nanoseconds())
-}
-
 func pow(v, exp int) int {
 	x := 1
 	for range exp {
@@ -55,14 +31,3 @@ func pow(v, exp int) int {
 	}
 	return x
 }
-
-func strToInt(s string) (int, error) {
-	if s == "" {
-		return 0, nil
-	}
-	value, err := strconv.Atoi(s)
-	if err != nil {
-		return 0, err
-	}
-	return value, nil
-}
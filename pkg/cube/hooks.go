@@ -0,0 +1,84 @@
+package cube
+
+// MoveEvent is a move executed on a cube, with the states before and after it
+// in the format of Cube.State, and the stickers whose colors it changed.
+type MoveEvent struct {
+	Move          Move
+	Before, After string
+	Changed       []Sticker
+}
+
+type hook[T any] struct {
+	id int
+	fn func(T)
+}
+
+// hooks holds the callbacks of a cube in the order they were added.
+type hooks struct {
+	next     int
+	move     []hook[Move]
+	complete []hook[MoveEvent]
+}
+
+func (h *hooks) active() bool {
+	return len(h.move) > 0 || len(h.complete) > 0
+}
+
+// run executes the move between the callbacks. The states of the event are
+// only computed for callbacks of OnTurnComplete.
+func (h *hooks) run(c *Cube, move Move) error {
+	for _, hk := range h.move {
+		hk.fn(move)
+	}
+	if len(h.complete) == 0 {
+		return c.executeMove(move)
+	}
+
+	event := MoveEvent{Move: move, Before: c.State()}
+	if err := c.executeMove(move); err != nil {
+		return err
+	}
+	event.After = c.State()
+
+	before, after := []rune(event.Before), []rune(event.After)
+	n := c.Dimension() * c.Dimension()
+	for i := range after {
+		if before[i] != after[i] {
+			event.Changed = append(event.Changed, Sticker{Face(i / n), i % n})
+		}
+	}
+	for _, hk := range h.complete {
+		hk.fn(event)
+	}
+	return nil
+}
+
+// add appends a callback and returns the function that removes it.
+func add[T any](h *hooks, list *[]hook[T], fn func(T)) (cancel func()) {
+	h.next++
+	id := h.next
+	*list = append(*list, hook[T]{id, fn})
+	return func() {
+		for i, hk := range *list {
+			if hk.id == id {
+				*list = append((*list)[:i:i], (*list)[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// OnMove calls fn with every move before the cube executes it, such as
+// Recorder.Move of package replay to record the moves of a solve. Moves that
+// the cube cannot execute are not passed. It returns the function that
+// removes the callback.
+func (c *Cube) OnMove(fn func(Move)) (cancel func()) {
+	return add(&c.hooks, &c.hooks.move, fn)
+}
+
+// OnTurnComplete calls fn with every move after the cube executed it, with
+// the states before and after the move. It returns the function that removes
+// the callback.
+func (c *Cube) OnTurnComplete(fn func(MoveEvent)) (cancel func()) {
+	return add(&c.hooks, &c.hooks.complete, fn)
+}
@@ -0,0 +1,122 @@
+package cube
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var (
+	ErrPieceBounds  = errors.New("piece outside the cube or inside it")
+	ErrPieceOverlap = errors.New("two pieces at one position")
+	ErrPieceColors  = errors.New("piece colors differ from its home")
+	ErrStickerCount = errors.New("color count differs from a face")
+	ErrParity       = errors.New("permutation parity cannot be reached by moves")
+)
+
+// CheckInvariants checks the consistency of a cube, for fuzzing and for
+// states from untrusted input: every piece is on the surface at its own
+// position and has the colors of its home, every color covers a face worth of
+// stickers, and for odd dimensions the corners, the middle edges and the
+// middle centers have an even permutation together. It does not check the
+// twist of the corners or the flip of the edges.
+func CheckInvariants(c *Cube) error {
+	at := map[[3]int]bool{}
+	for i, p := range c.pieces {
+		pos := [3]int{p.x.coordinate, p.y.coordinate, p.z.coordinate}
+		if !c.onSurface(pos) {
+			return fmt.Errorf("piece %d at %v: %w", i, pos, ErrPieceBounds)
+		}
+		if at[pos] {
+			return fmt.Errorf("piece %d at %v: %w", i, pos, ErrPieceOverlap)
+		}
+		at[pos] = true
+
+		for _, t := range []*tile{p.x, p.y, p.z} {
+			if (t.color != 0) != (t.coordinate == 0 || t.coordinate == c.max) {
+				return fmt.Errorf("piece %d at %v: %w", i, pos, ErrPieceColors)
+			}
+		}
+		home := newPiece(c.max, c.scheme.Faces, p.x.coordinateStart, p.y.coordinateStart, p.z.coordinateStart)
+		if !slices.Equal(colorsOf(p), colorsOf(home)) {
+			return fmt.Errorf("piece %d at %v: %w", i, pos, ErrPieceColors)
+		}
+	}
+	if want := pow(c.Dimension(), 3) - pow(max(c.Dimension()-2, 0), 3); len(c.pieces) != want {
+		return fmt.Errorf("%d pieces instead of %d: %w", len(c.pieces), want, ErrPieceBounds)
+	}
+
+	// A 1x1 cube keeps only three colors on its single piece.
+	if c.max == 0 {
+		return nil
+	}
+	counts := map[rune]int{}
+	for _, f := range c.Faces().All() {
+		for _, color := range *f {
+			counts[color]++
+		}
+	}
+	for color, n := range counts {
+		if n != c.Dimension()*c.Dimension() {
+			return fmt.Errorf("%d stickers of %q: %w", n, color, ErrStickerCount)
+		}
+	}
+
+	if c.max%2 == 0 && c.middleParity()%2 != 0 {
+		return ErrParity
+	}
+	return nil
+}
+
+// onSurface reports whether a position is within the cube and not inside it.
+func (c *Cube) onSurface(pos [3]int) bool {
+	surface := false
+	for _, coord := range pos {
+		if coord < 0 || coord > c.max {
+			return false
+		}
+		surface = surface || coord == 0 || coord == c.max
+	}
+	return surface
+}
+
+// colorsOf returns the colors of a piece, sorted.
+func colorsOf(p *piece) []rune {
+	colors := []rune{p.x.color, p.y.color, p.z.color}
+	slices.Sort(colors)
+	return colors
+}
+
+// middleParity returns the number of transpositions of the corners, the
+// middle edges and the middle centers of an odd cube, which every quarter
+// turn changes by an even number.
+func (c *Cube) middleParity() int {
+	// The middle pieces have no coordinate other than 0, max and the middle.
+	middle := func(pos [3]int) bool {
+		for _, coord := range pos {
+			if coord != 0 && coord != c.max && coord != c.max/2 {
+				return false
+			}
+		}
+		return true
+	}
+
+	n := 0
+	next := map[[3]int][3]int{}
+	for _, p := range c.pieces {
+		home := [3]int{p.x.coordinateStart, p.y.coordinateStart, p.z.coordinateStart}
+		if middle(home) {
+			next[home] = [3]int{p.x.coordinate, p.y.coordinate, p.z.coordinate}
+		}
+	}
+	seen := map[[3]int]bool{}
+	for start := range next {
+		for pos := start; !seen[pos]; pos = next[pos] {
+			seen[pos] = true
+			if next[pos] != start {
+				n++
+			}
+		}
+	}
+	return n
+}
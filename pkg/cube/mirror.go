@@ -0,0 +1,206 @@
+package cube
+
+import (
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/internal/svg"
+	"io"
+	"math"
+	"strings"
+)
+
+// DefaultMirrorThickness is the thickness of the outer layers of a classic
+// mirror blocks puzzle, per axis x, y, z and per side (low, high). The middle
+// layers are always 1 thick.
+var DefaultMirrorThickness = [3][2]float64{
+	{0.7, 1.3},
+	{1.4, 0.6},
+	{0.9, 1.1},
+}
+
+// MirrorCube is a 3x3 mirror blocks puzzle, where every piece has the same
+// color and pieces are told apart by their dimensions instead. It is turned by
+// the same engine as Cube, but its tiles are marked with the home axis of the
+// piece extent they carry instead of a color.
+type MirrorCube struct {
+	cube      *Cube
+	thickness [3][2]float64
+}
+
+// MirrorBox is the extent of a piece along the x, y and z axes, measured from
+// the center of the puzzle.
+type MirrorBox struct {
+	Min, Max [3]float64
+}
+
+var axisMarkers = [3]rune{'x', 'y', 'z'}
+
+func NewMirrorCube(thickness [3][2]float64) *MirrorCube {
+	c := NewCube(3)
+	for _, p := range c.pieces {
+		for i, t := range p.tiles() {
+			t.color = axisMarkers[i]
+		}
+	}
+	return &MirrorCube{cube: c, thickness: thickness}
+}
+
+func (p *piece) tiles() [3]*tile {
+	return [3]*tile{p.x, p.y, p.z}
+}
+
+func (m *MirrorCube) ExecuteMove(move Move) error {
+	return m.cube.ExecuteMove(move)
+}
+
+func (m *MirrorCube) ExecuteMoves(moves ...Move) error {
+	return m.cube.ExecuteMoves(moves...)
+}
+
+// Apply executes a single move, see ExecuteMove.
+func (m *MirrorCube) Apply(move Move) error {
+	return m.ExecuteMove(move)
+}
+
+// box returns the current extent of the piece. Along each axis the piece
+// spans the middle layer, or reaches from the middle layer to the outer
+// surface of its home layer along the home axis that now points this way.
+func (m *MirrorCube) box(p *piece) MirrorBox {
+	var b MirrorBox
+	tiles := p.tiles()
+
+	for axis, t := range tiles {
+		home := strings.IndexRune("xyz", t.color)
+		homeSide := tiles[home].coordinateStart / 2
+
+		switch t.coordinate {
+		case 0:
+			b.Min[axis], b.Max[axis] = -0.5-m.thickness[home][homeSide], -0.5
+		case 1:
+			b.Min[axis], b.Max[axis] = -0.5, 0.5
+		default:
+			b.Min[axis], b.Max[axis] = 0.5, 0.5+m.thickness[home][homeSide]
+		}
+	}
+	return b
+}
+
+// Boxes returns the current extent of every piece.
+func (m *MirrorCube) Boxes() []MirrorBox {
+	boxes := make([]MirrorBox, len(m.cube.pieces))
+	for i, p := range m.cube.pieces {
+		boxes[i] = m.box(p)
+	}
+	return boxes
+}
+
+// IsSolved reports whether the puzzle has a flat surface on every side, which
+// is the case in any orientation and with any twist of the centers.
+func (m *MirrorCube) IsSolved() bool {
+	var outer [3][2]map[float64]bool
+	for axis := range outer {
+		outer[axis] = [2]map[float64]bool{{}, {}}
+	}
+
+	for _, b := range m.Boxes() {
+		for axis := range 3 {
+			if b.Min[axis] < -0.5 {
+				outer[axis][0][round(b.Min[axis])] = true
+			}
+			if b.Max[axis] > 0.5 {
+				outer[axis][1][round(b.Max[axis])] = true
+			}
+		}
+	}
+
+	for _, sides := range outer {
+		for _, depths := range sides {
+			if len(depths) > 1 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// State returns the extent of the piece at every position, ordered by position.
+func (m *MirrorCube) State() string {
+	boxes := make(map[[3]int]MirrorBox, len(m.cube.pieces))
+	for _, p := range m.cube.pieces {
+		boxes[[3]int{p.x.coordinate, p.y.coordinate, p.z.coordinate}] = m.box(p)
+	}
+
+	var sb strings.Builder
+	for x := range 3 {
+		for y := range 3 {
+			for z := range 3 {
+				if b, ok := boxes[[3]int{x, y, z}]; ok {
+					fmt.Fprintf(&sb, "%g:%g:%g:%g:%g:%g;",
+						round(b.Min[0]), round(b.Max[0]), round(b.Min[1]), round(b.Max[1]), round(b.Min[2]), round(b.Max[2]))
+				}
+			}
+		}
+	}
+	return sb.String()
+}
+
+func round(f float64) float64 {
+	return math.Round(f*1e6) / 1e6
+}
+
+// mirrorView describes how a face is drawn: the axis it looks along, and the
+// axes and directions of its columns and rows, matching Faces.
+type mirrorView struct {
+	axis, side       int
+	colAxis, rowAxis int
+	colSign, rowSign float64
+	netCol, netRow   float64
+}
+
+var mirrorViews = []mirrorView{
+	{int(axisY), 1, int(axisX), int(axisZ), 1, 1, 1, 0},
+	{int(axisX), 0, int(axisZ), int(axisY), 1, -1, 0, 1},
+	{int(axisZ), 1, int(axisX), int(axisY), 1, -1, 1, 1},
+	{int(axisX), 1, int(axisZ), int(axisY), -1, -1, 2, 1},
+	{int(axisZ), 0, int(axisX), int(axisY), -1, -1, 3, 1},
+	{int(axisY), 0, int(axisX), int(axisZ), 1, -1, 1, 2},
+}
+
+const (
+	svgMirrorScale = 25.0
+	svgMirrorCell  = 4.0 // Size of a face view in puzzle units
+)
+
+// RenderSVG draws the net of the puzzle with every face showing the outline
+// of its pieces. Pieces are shaded by how far they stick out of that face, so
+// the shape-shifted silhouette can be read from the drawing.
+func (m *MirrorCube) RenderSVG(w io.Writer) error {
+	cell := svgMirrorCell * svgMirrorScale
+	out := svg.NewWriter(w, 4*cell+2*svgMargin, 3*cell+2*svgMargin)
+
+	boxes := m.Boxes()
+	for _, v := range mirrorViews {
+		center := svg.Point{X: svgMargin + (v.netCol+0.5)*cell, Y: svgMargin + (v.netRow+0.5)*cell}
+
+		for _, b := range boxes {
+			depth := b.Max[v.axis]
+			if v.side == 0 {
+				depth = -b.Min[v.axis]
+			}
+			if depth <= 0.5 {
+				continue
+			}
+
+			x0, x1 := b.Min[v.colAxis]*v.colSign, b.Max[v.colAxis]*v.colSign
+			y0, y1 := b.Min[v.rowAxis]*v.rowSign, b.Max[v.rowAxis]*v.rowSign
+			corner := func(x, y float64) svg.Point {
+				return svg.Point{X: center.X + x*svgMirrorScale, Y: center.Y + y*svgMirrorScale}
+			}
+
+			shade := uint8(math.Max(0, math.Min(255, 90+(depth-0.5)*90)))
+			fill := fmt.Sprintf("#%02x%02x%02x", shade, shade, shade)
+			out.Polygon(fill, corner(x0, y0), corner(x1, y0), corner(x1, y1), corner(x0, y1))
+		}
+	}
+
+	return out.Close()
+}
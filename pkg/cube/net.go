@@ -0,0 +1,82 @@
+package cube
+
+// Cell is the column and row of a face in a net, counted in faces.
+type Cell struct {
+	Col, Row int
+}
+
+// Layout places the faces of a net on a grid, indexed by Face.
+type Layout [6]Cell
+
+var (
+	// CrossLayout has Up above Front, Left, Front, Right and Back in a row,
+	// and Down below Front.
+	CrossLayout = Layout{
+		FaceUp:    {1, 0},
+		FaceLeft:  {0, 1},
+		FaceFront: {1, 1},
+		FaceRight: {2, 1},
+		FaceBack:  {3, 1},
+		FaceDown:  {1, 2},
+	}
+
+	// RowLayout has the faces in a single row, in the order of Face.
+	RowLayout = Layout{{0, 0}, {1, 0}, {2, 0}, {3, 0}, {4, 0}, {5, 0}}
+)
+
+// Rect is the square of a sticker in a net.
+type Rect struct {
+	X, Y, Size float64
+}
+
+// Center returns the middle of the square.
+func (r Rect) Center() (x, y float64) {
+	return r.X + r.Size/2, r.Y + r.Size/2
+}
+
+// Net maps the stickers of a cube to the coordinates RenderNetSVG draws them
+// at, so that other renderers and annotations line up with its images. Rows
+// and columns of a face are counted as in CubeFaces, whose index of a sticker
+// is row times the dimension plus column.
+type Net struct {
+	Dimension int
+	Layout    Layout
+}
+
+// face returns the side of a face and the gap after it.
+func (n Net) face() float64 {
+	return float64(n.Dimension)*svgSticker + svgGap
+}
+
+// Size returns the width and height of the net with its margins.
+func (n Net) Size() (width, height float64) {
+	var cols, rows int
+	for _, c := range n.Layout {
+		cols, rows = max(cols, c.Col+1), max(rows, c.Row+1)
+	}
+	return float64(cols)*n.face() + 2*svgMargin, float64(rows)*n.face() + 2*svgMargin
+}
+
+// Rect returns the square of the sticker at the row and column of face f.
+func (n Net) Rect(f Face, row, col int) Rect {
+	c := n.Layout[f]
+	return Rect{
+		X:    svgMargin + float64(c.Col)*n.face() + float64(col)*svgSticker,
+		Y:    svgMargin + float64(c.Row)*n.face() + float64(row)*svgSticker,
+		Size: svgSticker,
+	}
+}
+
+// StickerAt returns the sticker drawn at a point, and false for points
+// between the stickers or outside the net.
+func (n Net) StickerAt(x, y float64) (f Face, row, col int, ok bool) {
+	for f, c := range n.Layout {
+		left := svgMargin + float64(c.Col)*n.face()
+		top := svgMargin + float64(c.Row)*n.face()
+		side := float64(n.Dimension) * svgSticker
+		if x >= left && x < left+side && y >= top && y < top+side {
+			return Face(f), int((y - top) / svgSticker), int((x - left) / svgSticker), true
+		}
+	}
+	return 0, 0, 0, false
+}
@@ -0,0 +1,177 @@
+package cube
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var ErrOrientation = errors.New("orientation needs the colors of two adjacent faces")
+
+// Orientation is the way a cube is held, by the colors on Up and Front.
+// Scrambles, states and solutions are written with the home orientation of
+// the scheme, where every face has its own color of Scheme.Faces, and an
+// orientation rewrites them for a cube held another way.
+type Orientation struct {
+	Up, Front rune
+}
+
+// WCAOrientation is the orientation of WCA scrambles, white on top and green
+// in front.
+var WCAOrientation = Orientation{Up: 'w', Front: 'g'}
+
+// ParseOrientation parses an orientation as the colors on Up and Front, such
+// as "wg" for white on top and green in front.
+func ParseOrientation(s string) (Orientation, error) {
+	colors := []rune(strings.ToLower(strings.TrimSpace(s)))
+	if len(colors) != 2 {
+		return Orientation{}, ErrOrientation
+	}
+	return Orientation{Up: colors[0], Front: colors[1]}, nil
+}
+
+func (o Orientation) String() string {
+	return string([]rune{o.Up, o.Front})
+}
+
+// faceLetters are the operators of the face turns, indexed by Face.
+const faceLetters = "ULFRBD"
+
+// rotations are the candidate moves of Rotation.
+var rotations = func() []Move {
+	var moves []Move
+	for _, op := range "yxz" {
+		moves = append(moves,
+			Move{Operator: op, Rotations: 1},
+			Move{Operator: op, Rotations: 1, Inverted: true},
+			Move{Operator: op, Rotations: 2},
+		)
+	}
+	return moves
+}()
+
+// facesAfter returns the face that every face is on after the moves, indexed
+// by Face.
+func facesAfter(moves []Move) [6]Face {
+	c := NewCubeWith(3, Options{Scheme: Scheme{Faces: [6]rune([]rune(faceLetters))}})
+	c.ExecuteMoves(moves...)
+	faces := c.Faces()
+	var after [6]Face
+	for f := range Face(6) {
+		after[strings.IndexRune(faceLetters, faces.Face(f)[4])] = f
+	}
+	return after
+}
+
+// Rotation returns the shortest rotation from the home orientation of the
+// scheme to the orientation, such as "x2" for yellow on top and blue in
+// front with the western scheme.
+func (o Orientation) Rotation(s Scheme) ([]Move, error) {
+	up, front := slices.Index(s.Faces[:], o.Up), slices.Index(s.Faces[:], o.Front)
+	if up < 0 || front < 0 {
+		return nil, ErrOrientation
+	}
+
+	candidates := [][]Move{nil}
+	for _, m := range rotations {
+		candidates = append(candidates, []Move{m})
+	}
+	for _, first := range rotations {
+		for _, second := range rotations {
+			if first.Operator != second.Operator {
+				candidates = append(candidates, []Move{first, second})
+			}
+		}
+	}
+	for _, moves := range candidates {
+		after := facesAfter(moves)
+		if after[up] == FaceUp && after[front] == FaceFront {
+			return moves, nil
+		}
+	}
+	return nil, ErrOrientation
+}
+
+// Reframe returns moves written for the home orientation of the scheme as
+// they are turned on a cube held in the orientation: every turn keeps its
+// direction, and turns the face, slice or axis its layers are on after the
+// rotation. Other moves are kept.
+func (o Orientation) Reframe(moves []Move, s Scheme) ([]Move, error) {
+	rotation, err := o.Rotation(s)
+	if err != nil {
+		return nil, err
+	}
+	return RemapMoves(moves, rotation...), nil
+}
+
+// Remap returns the move that turns the layers that the move turns, named
+// as they are after the rotation, such as F for R after y, and E' for S after
+// x. It keeps its direction about those layers, and remaps slices and
+// rotations alike. Moves of no face, and rotations other than x, y and z,
+// leave the move as it is.
+func Remap(move, rotation Move) Move {
+	if !rotation.IsAny('x', 'y', 'z') {
+		return move
+	}
+	quarters := rotation.Rotations % 4
+	if rotation.Inverted {
+		quarters = (4 - quarters) % 4
+	}
+	return reframed([]Move{move}, rotationFaces()[rotation.Operator][quarters])[0]
+}
+
+// RemapMoves returns the moves remapped by the rotations in turn, see Remap.
+func RemapMoves(moves []Move, rotations ...Move) []Move {
+	return reframed(moves, facesAfter(rotations))
+}
+
+// rotationFaces holds facesAfter of x, y and z, by their quarter turns
+// clockwise.
+var rotationFaces = sync.OnceValue(func() map[rune][4][6]Face {
+	faces := map[rune][4][6]Face{}
+	for _, op := range "xyz" {
+		var after [4][6]Face
+		for q := range 4 {
+			after[q] = facesAfter([]Move{{Operator: op, Rotations: q}})
+		}
+		faces[op] = after
+	}
+	return faces
+})
+
+// reframed returns the moves turned on a cube whose faces are on the faces
+// of after, see Reframe.
+func reframed(moves []Move, after [6]Face) []Move {
+	// Slices and rotations turn as one of the faces, and become the slice or
+	// the rotation of the face it is on, inverted for the opposite face.
+	follows := map[rune]Face{'M': FaceLeft, 'E': FaceDown, 'S': FaceFront, 'x': FaceRight, 'y': FaceUp, 'z': FaceFront}
+	slicesOf := map[Face]Move{
+		FaceLeft: {Operator: 'M'}, FaceRight: {Operator: 'M', Inverted: true},
+		FaceDown: {Operator: 'E'}, FaceUp: {Operator: 'E', Inverted: true},
+		FaceFront: {Operator: 'S'}, FaceBack: {Operator: 'S', Inverted: true},
+	}
+	axesOf := map[Face]Move{
+		FaceRight: {Operator: 'x'}, FaceLeft: {Operator: 'x', Inverted: true},
+		FaceUp: {Operator: 'y'}, FaceDown: {Operator: 'y', Inverted: true},
+		FaceFront: {Operator: 'z'}, FaceBack: {Operator: 'z', Inverted: true},
+	}
+
+	out := slices.Clone(moves)
+	for i, m := range out {
+		var to Move
+		switch {
+		case strings.ContainsRune(faceLetters, m.Operator):
+			to = Move{Operator: rune(faceLetters[after[strings.IndexRune(faceLetters, m.Operator)]])}
+		case m.IsAny('M', 'E', 'S'):
+			to = slicesOf[after[follows[m.Operator]]]
+		case m.IsAny('x', 'y', 'z'):
+			to = axesOf[after[follows[m.Operator]]]
+		default:
+			continue
+		}
+		out[i].Operator = to.Operator
+		out[i].Inverted = m.Inverted != to.Inverted
+	}
+	return out
+}
@@ -0,0 +1,174 @@
+package cube
+
+import (
+	"slices"
+	"strings"
+)
+
+// The checks below are the stages of CFOP with the cross on D, or on any face
+// for IsCrossSolved, and the last layer on U. They compare every sticker with
+// the center of its face, so they hold in any orientation of the centers.
+
+// faceColors returns the color of the center of every face, indexed by Face.
+func (c *Cube) faceColors() [6]rune {
+	middle := c.Dimension() / 2
+	var colors [6]rune
+	faces := c.Faces()
+	for f := range Face(6) {
+		colors[f] = faces.Face(f)[middle*c.Dimension()+middle]
+	}
+	return colors
+}
+
+// isOn reports whether the position is on the face.
+func (c *Cube) isOn(pos Position, f Face) bool {
+	switch f {
+	case FaceUp:
+		return pos[1] == c.max
+	case FaceDown:
+		return pos[1] == 0
+	case FaceLeft:
+		return pos[0] == 0
+	case FaceRight:
+		return pos[0] == c.max
+	case FaceFront:
+		return pos[2] == c.max
+	}
+	return pos[2] == 0
+}
+
+// nameAt returns the name of a position by the faces it is on, U or D first,
+// then F or B, then R or L, such as "UFR" or "DF".
+func (c *Cube) nameAt(pos Position) string {
+	var name []byte
+	for _, f := range []Face{FaceUp, FaceDown, FaceFront, FaceBack, FaceRight, FaceLeft} {
+		if c.isOn(pos, f) {
+			name = append(name, faceLetters[f])
+		}
+	}
+	return string(name)
+}
+
+// appendName appends the name of the position unless it is there already, as
+// wings and centers of big cubes share the names of their positions.
+func (c *Cube) appendName(names []string, pos Position) []string {
+	if name := c.nameAt(pos); !slices.Contains(names, name) {
+		return append(names, name)
+	}
+	return names
+}
+
+// solvedAt reports whether every sticker of the piece shows the color of its
+// face.
+func solvedAt(p Piece, colors [6]rune) bool {
+	for f, color := range p.Colors {
+		if color != 0 && color != colors[f] {
+			return false
+		}
+	}
+	return true
+}
+
+// IsCrossSolved reports whether the edges of the face are solved, and returns
+// the names of the positions of those that are not, such as "DF".
+func (c *Cube) IsCrossSolved(f Face) (bool, []string) {
+	colors := c.faceColors()
+	var remaining []string
+	for p := range c.Pieces() {
+		if (p.Kind == Edge || p.Kind == Wing) && c.isOn(p.Position, f) && !solvedAt(p, colors) {
+			remaining = c.appendName(remaining, p.Position)
+		}
+	}
+	return len(remaining) == 0, remaining
+}
+
+// slotName returns the name of the F2L slot between two side faces, such as
+// "FR", in any order of the letters, and false for other names.
+func slotName(name string) (string, bool) {
+	name = strings.ToUpper(name)
+	if len(name) != 2 {
+		return "", false
+	}
+	for _, slot := range []string{"FR", "FL", "BR", "BL"} {
+		if name == slot || name == string([]byte{slot[1], slot[0]}) {
+			return slot, true
+		}
+	}
+	return "", false
+}
+
+// IsF2LSolved reports whether the first two layers are solved with the F2L
+// slots, and returns the slots that are not, such as "FR". Slots are named by
+// their middle edge, and default to all four, and the pieces below U outside
+// of all four slots, such as the cross on D, must be solved too. Unknown slot
+// names are returned as they are and are never solved.
+func (c *Cube) IsF2LSolved(slots ...string) (bool, []string) {
+	if len(slots) == 0 {
+		slots = []string{"FR", "FL", "BR", "BL"}
+	}
+
+	colors := c.faceColors()
+	unsolved, others := map[string]bool{}, false
+	for p := range c.Pieces() {
+		if c.isOn(p.Position, FaceUp) || solvedAt(p, colors) {
+			continue
+		}
+		if slot, ok := slotName(strings.TrimPrefix(c.nameAt(p.Position), "D")); ok {
+			unsolved[slot] = true
+		} else {
+			others = true
+		}
+	}
+
+	var remaining []string
+	for _, s := range slots {
+		slot, ok := slotName(s)
+		if !ok || unsolved[slot] {
+			remaining = append(remaining, s)
+		}
+	}
+	return !others && len(remaining) == 0, remaining
+}
+
+// IsOLLDone reports whether the U face has a single color, and returns the
+// names of the positions of the pieces that do not show it on U.
+func (c *Cube) IsOLLDone() (bool, []string) {
+	colors := c.faceColors()
+	var remaining []string
+	for p := range c.Pieces() {
+		if c.isOn(p.Position, FaceUp) && p.Colors[FaceUp] != colors[FaceUp] {
+			remaining = c.appendName(remaining, p.Position)
+		}
+	}
+	return len(remaining) == 0, remaining
+}
+
+// IsPLLDone reports whether the cube is solved up to a turn of U, and returns
+// the quarter turns of U clockwise that solve it, from 0 to 3.
+func (c *Cube) IsPLLDone() (bool, int) {
+	if ok, _ := c.IsF2LSolved(); !ok {
+		return false, 0
+	}
+	if ok, _ := c.IsOLLDone(); !ok {
+		return false, 0
+	}
+
+	// U moves the top row of every side face to the next one in this order.
+	sides := []Face{FaceFront, FaceLeft, FaceBack, FaceRight}
+	colors := c.faceColors()
+	faces := c.Faces()
+	for turns := range 4 {
+		done := true
+		for i, f := range sides {
+			want := colors[sides[(i+turns)%4]]
+			row := faces.Face(f)[:c.Dimension()]
+			if slices.ContainsFunc(row, func(color rune) bool { return color != want }) {
+				done = false
+			}
+		}
+		if done {
+			return true, turns
+		}
+	}
+	return false, 0
+}
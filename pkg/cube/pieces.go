@@ -0,0 +1,257 @@
+package cube
+
+import (
+	"errors"
+	"iter"
+	"slices"
+	"strings"
+)
+
+var ErrPosition = errors.New("no piece position of that name")
+
+// Position is a piece position by its x, y and z coordinates, from Left to
+// Right, Down to Up and Back to Front, each from 0 to the dimension minus 1.
+type Position [3]int
+
+// PieceKind is the type of a piece, by the number of faces it is on and
+// whether it is in the middle of a face or an edge.
+type PieceKind int
+
+const (
+	Corner PieceKind = iota
+	Edge             // The middle edges of odd cubes
+	Wing             // The other edge pieces
+	Center
+)
+
+// Piece is a piece of a cube with where it is and where it started.
+type Piece struct {
+	Kind     PieceKind
+	Position Position
+	Home     Position
+
+	// Orientation counts the clockwise twists of a corner, from 0 to 2, and
+	// whether an edge or a wing is flipped, 0 or 1. The reference sticker of
+	// a piece has its U or D color, or else its F or B color, and is not
+	// twisted or flipped on U or D, or on F or B for positions between them.
+	// Centers have orientation 0.
+	Orientation int
+
+	// Colors holds the color the piece shows on every face, indexed by Face,
+	// and 0 for the faces it is not on.
+	Colors [6]rune
+}
+
+// ColorSet returns the colors of the piece, sorted, such as "grw" for the
+// white, green and red corner.
+func (p Piece) ColorSet() string {
+	var colors []rune
+	for _, c := range p.Colors {
+		if c != 0 {
+			colors = append(colors, c)
+		}
+	}
+	slices.Sort(colors)
+	return string(colors)
+}
+
+// pieceOf returns the piece as seen from outside.
+func (c *Cube) pieceOf(p *piece) Piece {
+	out := Piece{
+		Kind:        c.kindAt(p.x.coordinateStart, p.y.coordinateStart, p.z.coordinateStart),
+		Position:    Position{p.x.coordinate, p.y.coordinate, p.z.coordinate},
+		Home:        Position{p.x.coordinateStart, p.y.coordinateStart, p.z.coordinateStart},
+		Orientation: c.orientation(p),
+	}
+	for _, s := range StickersAt(c.Dimension(), p.x.coordinate, p.y.coordinate, p.z.coordinate) {
+		switch s.Face {
+		case FaceLeft, FaceRight:
+			out.Colors[s.Face] = p.x.color
+		case FaceUp, FaceDown:
+			out.Colors[s.Face] = p.y.color
+		default:
+			out.Colors[s.Face] = p.z.color
+		}
+	}
+	return out
+}
+
+// kindAt returns the kind of the piece at a position on the surface.
+func (c *Cube) kindAt(x, y, z int) PieceKind {
+	outer, middle := 0, false
+	for _, coord := range []int{x, y, z} {
+		if coord == 0 || coord == c.max {
+			outer++
+		} else if coord*2 == c.max {
+			middle = true
+		}
+	}
+	switch {
+	case outer == 3 || c.max == 0:
+		return Corner
+	case outer == 2 && middle:
+		return Edge
+	case outer == 2:
+		return Wing
+	}
+	return Center
+}
+
+// orientation returns the orientation of a piece, see Piece.
+func (c *Cube) orientation(p *piece) int {
+	tiles := []*tile{p.x, p.y, p.z}
+	outer := func(t *tile, start bool) bool {
+		coord := t.coordinate
+		if start {
+			coord = t.coordinateStart
+		}
+		return coord == 0 || coord == c.max
+	}
+
+	// The reference axis is y for pieces on U or D, and else z, both at
+	// home for the color and at the position for the sticker.
+	ref := func(start bool) int {
+		if outer(p.y, start) {
+			return 1
+		}
+		return 2
+	}
+	home := newPiece(c.max, c.scheme.Faces, p.x.coordinateStart, p.y.coordinateStart, p.z.coordinateStart)
+	color := []*tile{home.x, home.y, home.z}[ref(true)].color
+	at := slices.IndexFunc(tiles, func(t *tile) bool { return t.color == color })
+
+	switch c.kindAt(p.x.coordinate, p.y.coordinate, p.z.coordinate) {
+	case Corner:
+		if c.max == 0 {
+			return 0
+		}
+		// Going clockwise from y, the axes are y, x, z at the corners where
+		// an even number of coordinates are 0, and else y, z, x.
+		order := []int{1, 0, 2}
+		if (p.x.coordinate == 0) != (p.y.coordinate == 0) != (p.z.coordinate == 0) {
+			order = []int{1, 2, 0}
+		}
+		return slices.Index(order, at)
+	case Edge, Wing:
+		if at == ref(false) {
+			return 0
+		}
+		return 1
+	}
+	return 0
+}
+
+// PieceAt returns the piece at a position, and false if no piece is there.
+func (c *Cube) PieceAt(pos Position) (Piece, bool) {
+	for _, p := range c.pieces {
+		if p.x.coordinate == pos[0] && p.y.coordinate == pos[1] && p.z.coordinate == pos[2] {
+			return c.pieceOf(p), true
+		}
+	}
+	return Piece{}, false
+}
+
+// FindPiece returns the piece that started at a position, and false if no
+// piece did.
+func (c *Cube) FindPiece(home Position) (Piece, bool) {
+	for _, p := range c.pieces {
+		if p.x.coordinateStart == home[0] && p.y.coordinateStart == home[1] && p.z.coordinateStart == home[2] {
+			return c.pieceOf(p), true
+		}
+	}
+	return Piece{}, false
+}
+
+// FindColors returns the pieces with the colors in any order, such as "wgr"
+// for the white, green and red corner. Big cubes have several pieces of the
+// same colors, such as wings and centers.
+func (c *Cube) FindColors(colors string) []Piece {
+	want := []rune(colors)
+	slices.Sort(want)
+
+	var found []Piece
+	for _, p := range c.pieces {
+		if piece := c.pieceOf(p); piece.ColorSet() == string(want) {
+			found = append(found, piece)
+		}
+	}
+	return found
+}
+
+// Pieces returns the pieces of the cube, which the iterators below filter by
+// kind.
+func (c *Cube) Pieces() iter.Seq[Piece] {
+	return func(yield func(Piece) bool) {
+		for _, p := range c.pieces {
+			if !yield(c.pieceOf(p)) {
+				return
+			}
+		}
+	}
+}
+
+func (c *Cube) piecesOf(kind PieceKind) iter.Seq[Piece] {
+	return func(yield func(Piece) bool) {
+		for p := range c.Pieces() {
+			if p.Kind == kind && !yield(p) {
+				return
+			}
+		}
+	}
+}
+
+// Corners returns the corners of the cube.
+func (c *Cube) Corners() iter.Seq[Piece] {
+	return c.piecesOf(Corner)
+}
+
+// Edges returns the middle edges of an odd cube.
+func (c *Cube) Edges() iter.Seq[Piece] {
+	return c.piecesOf(Edge)
+}
+
+// Wings returns the edge pieces of a big cube other than the middle edges.
+func (c *Cube) Wings() iter.Seq[Piece] {
+	return c.piecesOf(Wing)
+}
+
+// Centers returns the center pieces of the cube.
+func (c *Cube) Centers() iter.Seq[Piece] {
+	return c.piecesOf(Center)
+}
+
+// PositionOf returns the position named by the faces it is on, such as "UFR"
+// for the corner between Up, Front and Right, "UF" for the edge between Up
+// and Front, or "U" for the center of Up. Edges and centers are the middle
+// ones, which only odd dimensions have.
+func PositionOf(dimension int, name string) (Position, error) {
+	max := dimension - 1
+	if name == "" || max%2 == 1 && len(name) < 3 {
+		return Position{}, ErrPosition
+	}
+	pos := Position{max / 2, max / 2, max / 2}
+	set := [3]bool{}
+	for _, letter := range strings.ToUpper(name) {
+		axis, coord := 0, 0
+		switch letter {
+		case 'L':
+		case 'R':
+			coord = max
+		case 'D':
+			axis = 1
+		case 'U':
+			axis, coord = 1, max
+		case 'B':
+			axis = 2
+		case 'F':
+			axis, coord = 2, max
+		default:
+			return Position{}, ErrPosition
+		}
+		if set[axis] {
+			return Position{}, ErrPosition
+		}
+		set[axis], pos[axis] = true, coord
+	}
+	return pos, nil
+}
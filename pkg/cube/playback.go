@@ -0,0 +1,56 @@
+package cube
+
+import "io"
+
+// Playback is an algorithm shown on a cube put in place by a setup, as on
+// alg.cubing.net: the setup, such as a scramble or the inverse of a case, is
+// applied at once, and the algorithm is stepped through move by move.
+type Playback struct {
+	Dimension int
+	Setup     []Move
+	Alg       []Move
+	Scheme    Scheme // Defaults to Western
+}
+
+// Cube returns the cube after the setup and the first step moves of the
+// algorithm, or after the whole algorithm for steps out of range such as -1.
+func (p Playback) Cube(step int) (*Cube, error) {
+	if step < 0 || step > len(p.Alg) {
+		step = len(p.Alg)
+	}
+	c := NewCubeWith(p.Dimension, Options{Scheme: p.Scheme})
+	if err := c.ExecuteMoves(p.Setup...); err != nil {
+		return nil, err
+	}
+	if err := c.ExecuteMoves(p.Alg[:step]...); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// States returns the state of the cube after the setup and after every move
+// of the algorithm, in the format of Cube.State.
+func (p Playback) States() ([]string, error) {
+	c, err := p.Cube(0)
+	if err != nil {
+		return nil, err
+	}
+	states := []string{c.State()}
+	for _, m := range p.Alg {
+		if err := c.ExecuteMove(m); err != nil {
+			return nil, err
+		}
+		states = append(states, c.State())
+	}
+	return states, nil
+}
+
+// RenderSVG draws the cube after the setup and the first step moves of the
+// algorithm, see Cube.
+func (p Playback) RenderSVG(w io.Writer, step int) error {
+	c, err := p.Cube(step)
+	if err != nil {
+		return err
+	}
+	return c.RenderSVG(w)
+}
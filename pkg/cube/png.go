@@ -0,0 +1,46 @@
+package cube
+
+import (
+	"github.com/larssont/go-cubic/pkg/internal/raster"
+	"io"
+)
+
+// DefaultPNGScale is the scale of RenderPNG unless set: the pixels of a unit
+// of the SVG drawings, so that a sticker is 40 pixels wide.
+const DefaultPNGScale = 2
+
+// PNGOptions set how RenderPNG draws a cube.
+type PNGOptions struct {
+	Scale float64            // Pixels a unit of the SVG drawings, DefaultPNGScale if not positive
+	Plan  bool               // The view of RenderPlanSVG rather than the net of RenderSVG
+	Shown func(Sticker) bool // The stickers drawn in their colors, as for RenderMaskedSVG, all if nil
+}
+
+// pngCanvas returns the canvas of RenderPNG.
+func pngCanvas(dimension int, opts PNGOptions) *raster.Canvas {
+	if opts.Scale <= 0 {
+		opts.Scale = DefaultPNGScale
+	}
+	width, height := SVGSize(dimension)
+	if opts.Plan {
+		width, height = PlanSVGSize(dimension)
+	}
+	return raster.NewCanvas(width, height, opts.Scale)
+}
+
+// RenderPNG draws the cube as RenderSVG, RenderMaskedSVG or RenderPlanSVG
+// do, as a PNG image with a transparent background, for places that do not
+// show SVG such as chat services.
+func (c *Cube) RenderPNG(w io.Writer, opts PNGOptions) error {
+	out := pngCanvas(c.Dimension(), opts)
+	var err error
+	if opts.Plan {
+		err = c.drawPlan(out, opts.Shown)
+	} else {
+		err = c.drawNet(out, CrossLayout, opts.Shown, NoAnnotation)
+	}
+	if err != nil {
+		return err
+	}
+	return out.WritePNG(w)
+}
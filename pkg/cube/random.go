@@ -0,0 +1,163 @@
+package cube
+
+import (
+	"math/rand/v2"
+)
+
+// rotation is a rotation of the whole cube, as a matrix on positions
+// relative to the center of the cube.
+type rotation [3][3]int
+
+// wholeRotations returns the 24 rotations of the whole cube.
+func wholeRotations() []rotation {
+	var out []rotation
+	for _, perm := range [][3]int{{0, 1, 2}, {0, 2, 1}, {1, 0, 2}, {1, 2, 0}, {2, 0, 1}, {2, 1, 0}} {
+		for signs := range 8 {
+			var r rotation
+			for row, col := range perm {
+				r[row][col] = 1 - 2*(signs>>row&1)
+			}
+			if r.det() == 1 {
+				out = append(out, r)
+			}
+		}
+	}
+	return out
+}
+
+func (r rotation) det() int {
+	return r[0][0]*(r[1][1]*r[2][2]-r[1][2]*r[2][1]) -
+		r[0][1]*(r[1][0]*r[2][2]-r[1][2]*r[2][0]) +
+		r[0][2]*(r[1][0]*r[2][1]-r[1][1]*r[2][0])
+}
+
+func (r rotation) apply(v [3]int) [3]int {
+	var out [3]int
+	for row := range 3 {
+		for col := range 3 {
+			out[row] += r[row][col] * v[col]
+		}
+	}
+	return out
+}
+
+// centered returns twice a position relative to the center of the cube, so
+// that it is whole for even dimensions too.
+func (c *Cube) centered(pos [3]int) [3]int {
+	return [3]int{2*pos[0] - c.max, 2*pos[1] - c.max, 2*pos[2] - c.max}
+}
+
+// place moves the piece from its home as the rotation moves the whole cube,
+// with its colors turned along.
+func (c *Cube) place(p *piece, r rotation) {
+	home := [3]int{p.x.coordinateStart, p.y.coordinateStart, p.z.coordinateStart}
+	solved := newPiece(c.max, c.scheme.Faces, home[0], home[1], home[2])
+	colors := []rune{solved.x.color, solved.y.color, solved.z.color}
+	at := r.apply(c.centered(home))
+	for axis, t := range []*tile{p.x, p.y, p.z} {
+		t.coordinate = (at[axis] + c.max) / 2
+		for from, v := range r[axis] {
+			if v != 0 {
+				t.color = colors[from]
+			}
+		}
+	}
+}
+
+// RandomState returns a cube of the size in a uniformly random state that
+// moves can reach, with the middle centers of odd cubes in place. Unlike a
+// scramble of random moves it is not biased towards states near solved. A
+// nil rng uses the global source of math/rand/v2.
+func RandomState(size int, rng *rand.Rand) *Cube {
+	perm, intN := rand.Perm, rand.IntN
+	if rng != nil {
+		perm, intN = rng.Perm, rng.IntN
+	}
+	c := NewCube(size)
+	rots := wholeRotations()
+
+	// The pieces fall into orbits of the positions that rotations of the
+	// whole cube take them to, and only trade places within them. The
+	// corners come first, at (0, 0, 0).
+	byHome := map[[3]int]*piece{}
+	for _, p := range c.pieces {
+		byHome[c.centered([3]int{p.x.coordinateStart, p.y.coordinateStart, p.z.coordinateStart})] = p
+	}
+	seen := map[[3]int]bool{}
+	cornerParity := 0
+	for _, p := range c.pieces {
+		home := c.centered([3]int{p.x.coordinateStart, p.y.coordinateStart, p.z.coordinateStart})
+		if seen[home] {
+			continue
+		}
+		var orbit [][3]int
+		for _, r := range rots {
+			if at := r.apply(home); !seen[at] {
+				seen[at] = true
+				orbit = append(orbit, at)
+			}
+		}
+		kind := c.kindAt(p.x.coordinateStart, p.y.coordinateStart, p.z.coordinateStart)
+		if kind == Center && len(orbit) == 6 {
+			continue
+		}
+
+		// The corners and the middle edges have the same parity, while
+		// the wings and the centers take any permutation.
+		targets := perm(len(orbit))
+		switch kind {
+		case Corner:
+			cornerParity = permParity(targets)
+		case Edge:
+			if permParity(targets) != cornerParity {
+				targets[0], targets[1] = targets[1], targets[0]
+			}
+		}
+
+		// Corners have three rotations to a position and middle edges
+		// two, one per twist or flip; the other pieces have one.
+		var choices []rotation
+		var last *piece
+		for i, from := range orbit {
+			choices = choices[:0]
+			for _, r := range rots {
+				if r.apply(from) == orbit[targets[i]] {
+					choices = append(choices, r)
+				}
+			}
+			last = byHome[from]
+			c.place(last, choices[intN(len(choices))])
+		}
+
+		// The twists of the corners add up to a multiple of 3 and the flips
+		// of the middle edges to a multiple of 2, which the last one fixes.
+		mod := map[PieceKind]int{Corner: 3, Edge: 2}[kind]
+		for i := 0; mod != 0 && c.orbitOrientation(orbit, byHome)%mod != 0; i++ {
+			c.place(last, choices[i])
+		}
+	}
+	return c
+}
+
+// orbitOrientation returns the sum of the orientations of the pieces at home
+// in the orbit.
+func (c *Cube) orbitOrientation(orbit [][3]int, byHome map[[3]int]*piece) int {
+	n := 0
+	for _, home := range orbit {
+		n += c.orientation(byHome[home])
+	}
+	return n
+}
+
+// permParity returns 1 for odd permutations and 0 for even ones.
+func permParity(p []int) int {
+	n := 0
+	for i := range p {
+		for j := i + 1; j < len(p); j++ {
+			if p[i] > p[j] {
+				n++
+			}
+		}
+	}
+	return n % 2
+}
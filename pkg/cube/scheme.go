@@ -0,0 +1,183 @@
+package cube
+
+import (
+	"errors"
+	"github.com/larssont/go-cubic/pkg/internal/svg"
+	"regexp"
+	"strings"
+)
+
+var ErrScheme = errors.New("unknown color scheme")
+
+// Scheme is a color scheme: the color of every face of a solved cube, and the
+// fill every color is drawn with.
+type Scheme struct {
+	Name     string
+	Faces    [6]rune // Indexed by Face
+	Fills    map[rune]string
+	Patterns bool // Marks the colors with shapes in SVG drawings
+}
+
+var (
+	// Western is the scheme of most cubes, with blue, orange and yellow
+	// clockwise around a corner.
+	Western = Scheme{
+		Name:  "western",
+		Faces: [6]rune{'w', 'o', 'g', 'r', 'b', 'y'},
+		Fills: svg.Palette,
+	}
+
+	// Japanese swaps blue and yellow, so blue is opposite white.
+	Japanese = Scheme{
+		Name:  "japanese",
+		Faces: [6]rune{'w', 'o', 'g', 'r', 'y', 'b'},
+		Fills: svg.Palette,
+	}
+
+	// Colorblind is the western scheme in colors that viewers with
+	// deuteranopia or protanopia tell apart: a dark wine red beside a light
+	// orange, and a bluish green beside a deep blue.
+	Colorblind = Scheme{
+		Name:  "colorblind",
+		Faces: Western.Faces,
+		Fills: map[rune]string{
+			'w': "#ffffff",
+			'y': "#f0e442",
+			'g': "#009e73",
+			'b': "#0047ab",
+			'r': "#882255",
+			'o': "#e69f00",
+		},
+	}
+
+	// HighContrast is the western scheme in saturated colors spread in
+	// lightness, from white and yellow down to red and blue.
+	HighContrast = Scheme{
+		Name:  "high-contrast",
+		Faces: Western.Faces,
+		Fills: map[rune]string{
+			'w': "#ffffff",
+			'y': "#ffff00",
+			'g': "#00c853",
+			'b': "#0026b3",
+			'r': "#b00000",
+			'o': "#ff9100",
+		},
+	}
+
+	// Stickerless is the western scheme in the shades of stickerless
+	// plastic.
+	Stickerless = Scheme{
+		Name:  "stickerless",
+		Faces: Western.Faces,
+		Fills: map[rune]string{
+			'w': "#f4f4f4",
+			'y': "#fee440",
+			'g': "#1ebd3c",
+			'b': "#1f7bff",
+			'r': "#e8192c",
+			'o': "#ff7b1c",
+		},
+	}
+)
+
+// Schemes holds the built-in schemes by name. BOY, for the blue, orange and
+// yellow corner, is another name of the western scheme, and deuteranopia and
+// protanopia of the colorblind scheme.
+var Schemes = map[string]Scheme{
+	"western":       Western,
+	"boy":           Western,
+	"japanese":      Japanese,
+	"stickerless":   Stickerless,
+	"colorblind":    Colorblind,
+	"deuteranopia":  Colorblind,
+	"protanopia":    Colorblind,
+	"high-contrast": HighContrast,
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// LookupScheme returns a built-in scheme by name, or a custom palette given
+// as six hex colors for the faces U, L, F, R, B and D of the western scheme,
+// such as "#fff,#f80,#0f0,#f00,#00f,#ff0".
+func LookupScheme(name string) (Scheme, error) {
+	if s, ok := Schemes[strings.ToLower(name)]; ok {
+		return s, nil
+	}
+
+	hexes := strings.Split(name, ",")
+	if len(hexes) != 6 {
+		return Scheme{}, ErrScheme
+	}
+	s := Scheme{Name: name, Faces: Western.Faces, Fills: map[rune]string{}}
+	for f, hex := range hexes {
+		hex = strings.TrimSpace(hex)
+		if !hexColor.MatchString(hex) {
+			return Scheme{}, ErrScheme
+		}
+		s.Fills[s.Faces[f]] = hex
+	}
+	return s, nil
+}
+
+// Fill returns the fill of a color, or gray for unknown colors.
+func (s Scheme) Fill(color rune) string {
+	if fill, ok := s.Fills[color]; ok {
+		return fill
+	}
+	return "#808080"
+}
+
+// WithPatterns returns the scheme with the colors marked by shapes in SVG
+// drawings, for viewers who cannot tell all of them apart.
+func (s Scheme) WithPatterns() Scheme {
+	s.Patterns = true
+	return s
+}
+
+// style returns how the stickers of the scheme are drawn.
+func (s Scheme) style() svg.Style {
+	fills := s.Fills
+	if fills == nil {
+		fills = map[rune]string{}
+	}
+	return svg.Style{Fills: fills, Patterns: s.Patterns}
+}
+
+// Convert returns a state in the format of Cube.State with the colors of the
+// scheme replaced by the colors of the same faces in another scheme, such as
+// for a state imported from a cube with a different scheme. Other runes are
+// kept.
+func (s Scheme) Convert(state string, to Scheme) string {
+	colors := map[rune]rune{}
+	for f, color := range s.Faces {
+		colors[color] = to.Faces[f]
+	}
+	return strings.Map(func(r rune) rune {
+		if c, ok := colors[r]; ok {
+			return c
+		}
+		return r
+	}, state)
+}
+
+// Scheme returns the color scheme of the cube.
+func (c *Cube) Scheme() Scheme {
+	return c.scheme
+}
+
+// Recolor changes the colors of the cube to another scheme, face by face.
+func (c *Cube) Recolor(to Scheme) {
+	colors := map[rune]rune{}
+	for f, color := range c.scheme.Faces {
+		colors[color] = to.Faces[f]
+	}
+	for _, p := range c.pieces {
+		for _, t := range []*tile{p.x, p.y, p.z} {
+			if color, ok := colors[t.color]; ok {
+				t.color = color
+			}
+		}
+	}
+	c.scheme = to
+}
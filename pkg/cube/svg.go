@@ -0,0 +1,218 @@
+package cube
+
+import (
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/internal/svg"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	svgSticker = 20.0
+	svgMargin  = 10.0
+	svgGap     = 4.0
+
+	svgMasked = "#404040" // Fill of the stickers a mask hides
+)
+
+// SVGSize returns the width and height of the net drawn by RenderSVG for a
+// cube of the given dimension.
+func SVGSize(dimension int) (width, height float64) {
+	return Net{dimension, CrossLayout}.Size()
+}
+
+// RenderSVG draws the cube as a cross-shaped net with Up above Front, Left,
+// Front, Right and Back in a row, and Down below Front.
+func (c *Cube) RenderSVG(w io.Writer) error {
+	return c.RenderNetSVG(w, CrossLayout)
+}
+
+// RenderNetSVG draws the cube as a net of the layout, with every sticker at
+// its Rect.
+func (c *Cube) RenderNetSVG(w io.Writer, layout Layout) error {
+	return c.renderNet(w, layout, nil, NoAnnotation)
+}
+
+// Annotation is text drawn over the stickers of a net.
+type Annotation int
+
+const (
+	NoAnnotation Annotation = iota
+
+	// FaceLetters labels every sticker with the face its color belongs to,
+	// as in facelet strings.
+	FaceLetters
+
+	// StickerIndices labels every sticker with its Sticker.Index.
+	StickerIndices
+
+	// SpeffzLetters labels the corner stickers, and the middle edge stickers
+	// of odd cubes, with the location letters of the Speffz scheme of
+	// blindfolded solving, A to X clockwise from the top left or top over the
+	// faces in the order U L F R B D.
+	SpeffzLetters
+)
+
+var ErrAnnotation = errors.New("unknown annotation")
+
+// annotations holds the annotations by name.
+var annotations = map[string]Annotation{
+	"none":    NoAnnotation,
+	"faces":   FaceLetters,
+	"indices": StickerIndices,
+	"speffz":  SpeffzLetters,
+}
+
+// ParseAnnotation parses "none", "faces", "indices" or "speffz".
+func ParseAnnotation(name string) (Annotation, error) {
+	a, ok := annotations[strings.ToLower(name)]
+	if !ok {
+		return NoAnnotation, fmt.Errorf("%q: %w", name, ErrAnnotation)
+	}
+	return a, nil
+}
+
+// RenderAnnotatedSVG draws the cube as a net of the layout with the text of
+// the annotation over its stickers.
+func (c *Cube) RenderAnnotatedSVG(w io.Writer, layout Layout, a Annotation) error {
+	return c.renderNet(w, layout, nil, a)
+}
+
+// RenderMaskedSVG draws the cube as RenderSVG does, with the stickers that
+// are not shown in a dark gray, such as those outside of a partial state.
+func (c *Cube) RenderMaskedSVG(w io.Writer, shown func(Sticker) bool) error {
+	return c.renderNet(w, CrossLayout, shown, NoAnnotation)
+}
+
+// canvas is what cubes are drawn on: an svg.Writer, or a raster.Canvas for
+// RenderPNG.
+type canvas interface {
+	Polygon(fill string, points ...svg.Point)
+	Sticker(st svg.Style, color rune, points ...svg.Point)
+	Label(at svg.Point, size float64, text string)
+	Close() error
+}
+
+// renderNet draws the net of the layout, hiding the stickers that are not
+// shown unless shown is nil, with the annotation.
+func (c *Cube) renderNet(w io.Writer, layout Layout, shown func(Sticker) bool, a Annotation) error {
+	width, height := Net{c.Dimension(), layout}.Size()
+	return c.drawNet(svg.NewWriter(w, width, height), layout, shown, a)
+}
+
+// drawNet draws the net of renderNet on a canvas of its size.
+func (c *Cube) drawNet(out canvas, layout Layout, shown func(Sticker) bool, a Annotation) error {
+	net := Net{c.Dimension(), layout}
+	faces := c.Faces()
+	for f, stickers := range faces.All() {
+		for i, color := range *stickers {
+			r := net.Rect(Face(f), i/c.Dimension(), i%c.Dimension())
+			points := []svg.Point{
+				{X: r.X, Y: r.Y},
+				{X: r.X + r.Size, Y: r.Y},
+				{X: r.X + r.Size, Y: r.Y + r.Size},
+				{X: r.X, Y: r.Y + r.Size},
+			}
+			if shown != nil && !shown(Sticker{Face(f), i}) {
+				out.Polygon(svgMasked, points...)
+				continue
+			}
+			out.Sticker(c.scheme.style(), color, points...)
+		}
+	}
+
+	for f, stickers := range faces.All() {
+		for i, color := range *stickers {
+			text := c.annotation(a, Sticker{Face(f), i}, color)
+			if text == "" {
+				continue
+			}
+			r := net.Rect(Face(f), i/c.Dimension(), i%c.Dimension())
+			out.Label(svg.Point{X: r.X + r.Size/2, Y: r.Y + r.Size/2}, r.Size*0.5, text)
+		}
+	}
+
+	return out.Close()
+}
+
+// annotation returns the text of the annotation of a sticker of the color,
+// or nothing for stickers it leaves out.
+func (c *Cube) annotation(a Annotation, s Sticker, color rune) string {
+	n := c.Dimension()
+	switch a {
+	case FaceLetters:
+		for f, home := range c.scheme.Faces {
+			if home == color {
+				return faceLetters[f : f+1]
+			}
+		}
+	case StickerIndices:
+		return strconv.Itoa(s.Index)
+	case SpeffzLetters:
+		if n < 2 {
+			return ""
+		}
+		spots := [][4]int{{0, n - 1, n*n - 1, n * (n - 1)}}
+		if mid := n / 2; n%2 == 1 {
+			spots = append(spots, [4]int{mid, mid*n + n - 1, n*(n-1) + mid, mid * n})
+		}
+		for _, spots := range spots {
+			for i, spot := range spots {
+				if s.Index == spot {
+					return string(rune('A' + int(s.Face)*4 + i))
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// svgStrip is the depth of the side stickers of RenderPlanSVG, as a share
+// of the width of a sticker.
+const svgStrip = 0.4
+
+// PlanSVGSize returns the width and height of the view drawn by
+// RenderPlanSVG for a cube of the given dimension, which is square.
+func PlanSVGSize(dimension int) (width, height float64) {
+	size := float64(dimension)*svgSticker + 2*svgStrip*svgSticker + 2*svgMargin
+	return size, size
+}
+
+// RenderPlanSVG draws the Up face from above, with the top row of every side
+// as a strip around it and the back at the top, as last layer cases are
+// shown. Stickers that are not shown are dark gray, unless shown is nil.
+func (c *Cube) RenderPlanSVG(w io.Writer, shown func(Sticker) bool) error {
+	width, height := PlanSVGSize(c.Dimension())
+	return c.drawPlan(svg.NewWriter(w, width, height), shown)
+}
+
+// drawPlan draws the view of RenderPlanSVG on a canvas of its size.
+func (c *Cube) drawPlan(out canvas, shown func(Sticker) bool) error {
+	n := c.Dimension()
+	strip := svgStrip * svgSticker
+	face := svgMargin + strip
+	end := face + float64(n)*svgSticker
+
+	faces := c.Faces()
+	rect := func(f Face, i int, x, y, w, h float64) {
+		points := []svg.Point{{X: x, Y: y}, {X: x + w, Y: y}, {X: x + w, Y: y + h}, {X: x, Y: y + h}}
+		if shown != nil && !shown(Sticker{f, i}) {
+			out.Polygon(svgMasked, points...)
+			return
+		}
+		out.Sticker(c.scheme.style(), faces.Face(f)[i], points...)
+	}
+	for i := range n {
+		along := face + float64(i)*svgSticker
+		rect(FaceBack, n-1-i, along, svgMargin, svgSticker, strip)
+		rect(FaceFront, i, along, end, svgSticker, strip)
+		rect(FaceLeft, i, svgMargin, along, strip, svgSticker)
+		rect(FaceRight, n-1-i, end, along, strip, svgSticker)
+	}
+	for i := range n * n {
+		rect(FaceUp, i, face+float64(i%n)*svgSticker, face+float64(i/n)*svgSticker, svgSticker, svgSticker)
+	}
+	return out.Close()
+}
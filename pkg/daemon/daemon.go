@@ -0,0 +1,430 @@
+// Package daemon serves the solvers and cubes of go-cubic to other programs,
+// such as editor plugins and scripts, over a local socket. A long-running
+// server builds the pruning tables once rather than at every invocation, and
+// holds cubes that clients turn move by move.
+//
+// The protocol is JSON, one object per line: clients send requests of a
+// method and its parameters, and the server answers every request, in order,
+// with its result or an error and the id of the request:
+//
+//	{"id": 1, "method": "optimal", "params": {"moves": "R U F"}}
+//	{"id": 1, "result": {"solution": "F' U' R'", "length": 3, ...}}
+//
+// The methods are ping, solve, optimal and goal for the 3x3 solvers,
+// cube.new, cube.apply, cube.state and cube.close for cubes held by the
+// server, and metrics for the counts of package metrics.
+//
+// ImageHandler serves images of cubes over HTTP next to the socket, for web
+// pages to link to.
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/metrics"
+	"github.com/larssont/go-cubic/pkg/solve"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	ErrMethod  = errors.New("unknown method")
+	ErrNoCube  = errors.New("no cube of that id")
+	ErrNoInput = errors.New("neither moves nor facelets given")
+)
+
+// Request is a call of a method of the server.
+type Request struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is the answer to a request, with either a result or an error.
+type Response struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Result any             `json:"result,omitempty"`
+	Error  *Error          `json:"error,omitempty"`
+}
+
+// Error is a failed request. Codes are "request" for requests that do not
+// decode, "method", "parse" for notation, "invalid" for states the cube
+// cannot have, "no-cube", "timeout", "no-solution", "limit" for requests
+// beyond the Limits of the server and "error" for others.
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Server answers requests, from any number of connections at once.
+type Server struct {
+	// Limits bound the requests, DefaultLimits unless changed before the
+	// server serves.
+	Limits Limits
+
+	mu      sync.Mutex
+	cubes   map[string]*cube.Cube
+	nextID  int
+	limiter *limiter
+}
+
+func NewServer() *Server {
+	return &Server{Limits: DefaultLimits, cubes: map[string]*cube.Cube{}, limiter: newLimiter()}
+}
+
+// Serve answers the connections of the listener until it is closed.
+func (s *Server) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.ServeConn(conn)
+	}
+}
+
+// ServeConn answers the requests of the connection until it closes.
+func (s *Server) ServeConn(conn net.Conn) {
+	defer conn.Close()
+	client, ok := clientOf(conn.RemoteAddr().String())
+	if !ok {
+		client = fmt.Sprintf("conn %p", conn)
+		defer s.limiter.forget(client)
+	}
+	size := s.Limits.MaxRequestBytes
+	if size <= 0 {
+		size = 1 << 20
+	}
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(nil, size)
+	enc := json.NewEncoder(conn)
+	for scanner.Scan() {
+		var req Request
+		var resp Response
+		switch err := json.Unmarshal(scanner.Bytes(), &req); {
+		case err != nil:
+			resp.Error = &Error{"request", err.Error()}
+		case !s.limiter.allow(client, s.Limits):
+			resp = Response{ID: req.ID, Error: &Error{"limit", ErrRateLimit.Error()}}
+			observeRequest(req.Method, "limit", time.Now())
+		default:
+			resp = s.Handle(req)
+		}
+		if err := enc.Encode(resp); err != nil {
+			return
+		}
+	}
+	if errors.Is(scanner.Err(), bufio.ErrTooLong) {
+		enc.Encode(Response{Error: &Error{"limit", fmt.Sprintf("%d bytes: %v", size, ErrTooLarge)}})
+	}
+}
+
+// Handle answers a request.
+func (s *Server) Handle(req Request) (resp Response) {
+	resp.ID = req.ID
+	defer func(start time.Time) {
+		code := ""
+		if resp.Error != nil {
+			code = resp.Error.Code
+		}
+		observeRequest(req.Method, code, start)
+	}(time.Now())
+	handle, ok := methods[req.Method]
+	if !ok {
+		resp.Error = &Error{"method", fmt.Sprintf("%q: %v", req.Method, ErrMethod)}
+		return resp
+	}
+	params := req.Params
+	if len(params) == 0 {
+		params = json.RawMessage("{}")
+	}
+	result, err := handle(s, params)
+	if err != nil {
+		resp.Error = &Error{code(err), err.Error()}
+		return resp
+	}
+	resp.Result = result
+	return resp
+}
+
+// methods holds the handlers of the methods by name, which decode their
+// parameters.
+var methods = map[string]func(s *Server, params json.RawMessage) (any, error){
+	"ping":       (*Server).ping,
+	"solve":      (*Server).solve,
+	"optimal":    (*Server).optimal,
+	"goal":       (*Server).goal,
+	"cube.new":   (*Server).newCube,
+	"cube.apply": (*Server).apply,
+	"cube.state": (*Server).cubeState,
+	"cube.close": (*Server).closeCube,
+	"metrics":    (*Server).metrics,
+}
+
+// code returns the code of the error of a method.
+func code(err error) string {
+	var parse *cube.ParseError
+	var syntax *json.SyntaxError
+	var typ *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntax), errors.As(err, &typ), errors.Is(err, ErrNoInput):
+		return "request"
+	case errors.Is(err, ErrTooLarge), errors.Is(err, ErrTooLong), errors.Is(err, ErrRateLimit), errors.Is(err, ErrTooMany):
+		return "limit"
+	case errors.As(err, &parse):
+		return "parse"
+	case errors.Is(err, ErrNoCube):
+		return "no-cube"
+	case errors.Is(err, solve.ErrBudget):
+		return "timeout"
+	case errors.Is(err, solve.ErrNoSolution):
+		return "no-solution"
+	case errors.Is(err, solve.ErrInvalidState), errors.Is(err, solve.ErrFacelets), errors.Is(err, cube.ErrSliceParam):
+		return "invalid"
+	}
+	return "error"
+}
+
+// input is the 3x3 state of a request, as a scramble or as facelets in the
+// format of solve.ParseFacelets.
+type input struct {
+	Moves    string `json:"moves"`
+	Facelets string `json:"facelets"`
+}
+
+func (in input) state(l Limits) (solve.State, error) {
+	if in.Facelets != "" {
+		return solve.ParseFacelets(in.Facelets)
+	}
+	if in.Moves == "" {
+		return solve.State{}, ErrNoInput
+	}
+	moves, err := l.parseMoves(in.Moves)
+	if err != nil {
+		return solve.State{}, err
+	}
+	s, err := solve.StateOf(moves...)
+	if err != nil {
+		return solve.State{}, err
+	}
+	return s.Reoriented(), nil
+}
+
+func (s *Server) ping(json.RawMessage) (any, error) {
+	return map[string]bool{"ok": true}, nil
+}
+
+// metrics returns the families of metrics.Default, of the daemon and of the
+// solvers.
+func (s *Server) metrics(json.RawMessage) (any, error) {
+	return metrics.Default.Families(), nil
+}
+
+// solved is the result of the solving methods.
+type solved struct {
+	Solution string `json:"solution"`
+	Length   int    `json:"length"`
+	Nodes    int    `json:"nodes"`
+	Elapsed  string `json:"elapsed"`
+	Stopped  string `json:"stopped"`
+}
+
+func newSolved(solution solve.Solution) solved {
+	return solved{
+		Solution: cube.FormatMoves(solution.Moves),
+		Length:   len(solution.Moves),
+		Nodes:    solution.Nodes,
+		Elapsed:  solution.Elapsed.String(),
+		Stopped:  solution.Stopped.String(),
+	}
+}
+
+// solve solves with the two-phase solver, see solve.SolveOptions.
+func (s *Server) solve(params json.RawMessage) (any, error) {
+	var p struct {
+		input
+		TimeoutMS     int  `json:"timeout_ms"`
+		MaxLength     int  `json:"max_length"`
+		KeepImproving bool `json:"keep_improving"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	state, err := p.state(s.Limits)
+	if err != nil {
+		return nil, err
+	}
+	solution, err := solve.SolveDetailed(state, solve.SolveOptions{
+		MaxLength:     p.MaxLength,
+		Timeout:       s.Limits.solveTime(time.Duration(p.TimeoutMS) * time.Millisecond),
+		KeepImproving: p.KeepImproving,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newSolved(solution), nil
+}
+
+// optimal returns an optimal solution of at most max moves, see
+// solve.SolveWithin.
+func (s *Server) optimal(params json.RawMessage) (any, error) {
+	var p struct {
+		input
+		Max       int `json:"max"`
+		TimeoutMS int `json:"timeout_ms"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	state, err := p.state(s.Limits)
+	if err != nil {
+		return nil, err
+	}
+	if p.Max <= 0 {
+		p.Max = 12
+	}
+	solution, err := solve.SolveWithin(state, p.Max, solve.SolveOptions{Timeout: s.Limits.solveTime(time.Duration(p.TimeoutMS) * time.Millisecond)})
+	if err != nil {
+		return nil, err
+	}
+	return newSolved(solution), nil
+}
+
+// goal returns the shortest solutions to a goal of solve.ParseGoal.
+func (s *Server) goal(params json.RawMessage) (any, error) {
+	var p struct {
+		input
+		Goal     string `json:"goal"`
+		MaxMoves int    `json:"max_moves"`
+		Limit    int    `json:"limit"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	state, err := p.state(s.Limits)
+	if err != nil {
+		return nil, err
+	}
+	g, err := solve.ParseGoal(p.Goal)
+	if err != nil {
+		return nil, err
+	}
+	solutions := []string{}
+	for _, moves := range solve.SolveGoal(state, g, nil, solve.CrossOptions{MaxMoves: p.MaxMoves, Limit: p.Limit, MaxTime: s.Limits.solveTime(0)}) {
+		solutions = append(solutions, cube.FormatMoves(moves))
+	}
+	return map[string][]string{"solutions": solutions}, nil
+}
+
+// cubeResult is the result of the methods of cubes.
+type cubeResult struct {
+	ID     string `json:"id"`
+	Size   int    `json:"size"`
+	State  string `json:"state"` // In the format of cube.Cube.State
+	Solved bool   `json:"solved"`
+}
+
+func newCubeResult(id string, c *cube.Cube) cubeResult {
+	return cubeResult{ID: id, Size: c.Dimension(), State: c.State(), Solved: c.IsSolved()}
+}
+
+type cubeParams struct {
+	ID    string `json:"id"`
+	Size  int    `json:"size"`
+	Moves string `json:"moves"`
+}
+
+// lookup returns the cube of the id, locked until unlock is called.
+func (s *Server) lookup(params json.RawMessage) (p cubeParams, c *cube.Cube, unlock func(), err error) {
+	if err := json.Unmarshal(params, &p); err != nil {
+		return p, nil, nil, err
+	}
+	s.mu.Lock()
+	c, ok := s.cubes[p.ID]
+	if !ok {
+		s.mu.Unlock()
+		return p, nil, nil, fmt.Errorf("%q: %w", p.ID, ErrNoCube)
+	}
+	return p, c, s.mu.Unlock, nil
+}
+
+// newCube starts a solved cube of the size, 3 by default, after the moves if
+// any.
+func (s *Server) newCube(params json.RawMessage) (any, error) {
+	var p cubeParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Size <= 0 {
+		p.Size = 3
+	}
+	if s.Limits.MaxSize > 0 && p.Size > s.Limits.MaxSize {
+		return nil, fmt.Errorf("size %d: %w", p.Size, ErrTooLarge)
+	}
+	c := cube.NewCube(p.Size)
+	if p.Moves != "" {
+		moves, err := s.Limits.parseMoves(p.Moves)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.ExecuteMoves(moves...); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Limits.MaxCubes > 0 && len(s.cubes) >= s.Limits.MaxCubes {
+		return nil, fmt.Errorf("%d cubes: %w", len(s.cubes), ErrTooMany)
+	}
+	s.nextID++
+	id := strconv.Itoa(s.nextID)
+	s.cubes[id] = c
+	observeCubes(len(s.cubes))
+	return newCubeResult(id, c), nil
+}
+
+// apply turns a cube by the moves.
+func (s *Server) apply(params json.RawMessage) (any, error) {
+	p, c, unlock, err := s.lookup(params)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	moves, err := s.Limits.parseMoves(p.Moves)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.ExecuteMoves(moves...); err != nil {
+		return nil, err
+	}
+	return newCubeResult(p.ID, c), nil
+}
+
+func (s *Server) cubeState(params json.RawMessage) (any, error) {
+	p, c, unlock, err := s.lookup(params)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return newCubeResult(p.ID, c), nil
+}
+
+func (s *Server) closeCube(params json.RawMessage) (any, error) {
+	p, c, unlock, err := s.lookup(params)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	delete(s.cubes, p.ID)
+	observeCubes(len(s.cubes))
+	return newCubeResult(p.ID, c), nil
+}
@@ -0,0 +1,238 @@
+package daemon
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/solve"
+	"github.com/zyedidia/generic/cache"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrImagePath = errors.New("images are served at /img/NxN.svg or /img/NxN.png")
+	ErrImageSize = errors.New("cube size out of range of images")
+	ErrView      = errors.New("unknown view")
+	ErrStage     = errors.New("stages are masks of the 3x3")
+)
+
+// MaxImageSize is the largest cube that ImageHandler draws.
+const MaxImageSize = 17
+
+// DefaultImageCache is the number of images that NewImageHandler keeps.
+const DefaultImageCache = 1024
+
+// stages are the names of the masks of the stage parameter, as in
+// VisualCube, besides the expressions of solve.ParseMask.
+var stages = map[string]string{
+	"cross": "cross",
+	"fl":    "D layer",
+	"f2l":   "f2l",
+	"f2l-1": "f2l-1",
+	"oll":   "f2l + oll",
+	"ll":    "solved",
+	"pll":   "solved",
+	"zbll":  "solved",
+	"1lll":  "solved",
+}
+
+// image is a drawn image, its content type and its entity tag.
+type image struct {
+	data        []byte
+	contentType string
+	etag        string
+}
+
+// ImageHandler serves SVG images of cubes over HTTP, for web pages to link
+// to as to VisualCube, at paths such as
+//
+//	/img/3x3.svg?alg=R+U+R'+U'&stage=oll&view=plan
+//
+// and the same images as PNG at /img/3x3.png, for chat services and other
+// places that do not show SVG.
+//
+// The parameters are alg, the moves to apply, or case, the moves that the
+// shown state is solved by; stage, a name such as cross, f2l, oll or pll or
+// a mask of solve.ParseMask whose stickers are shown and the rest grayed
+// out; view, net by default or plan for the Up face from above; and scheme,
+// as for cube.LookupScheme. Images are the same for the same parameters, so
+// responses carry an ETag and may be cached, and the handler keeps the most
+// recently served images.
+type ImageHandler struct {
+	// Limits bound the requests, DefaultLimits unless changed before the
+	// handler serves. MaxSize is at most MaxImageSize.
+	Limits Limits
+
+	mu      sync.Mutex
+	cache   *cache.Cache[string, image]
+	limiter *limiter
+}
+
+// NewImageHandler returns a handler that keeps up to capacity images, or
+// DefaultImageCache if capacity is not positive.
+func NewImageHandler(capacity int) *ImageHandler {
+	if capacity <= 0 {
+		capacity = DefaultImageCache
+	}
+	return &ImageHandler{Limits: DefaultLimits, cache: cache.New[string, image](capacity), limiter: newLimiter()}
+}
+
+var imagePath = regexp.MustCompile(`^/img/(\d+)x(\d+)\.(svg|png)$`)
+
+func (h *ImageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		h.error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if client, ok := clientOf(r.RemoteAddr); ok && !h.limiter.allow(client, h.Limits) {
+		w.Header().Set("Retry-After", "1")
+		h.error(w, ErrRateLimit.Error(), http.StatusTooManyRequests)
+		return
+	}
+	if h.Limits.MaxRequestBytes > 0 && len(r.URL.RequestURI()) > h.Limits.MaxRequestBytes {
+		h.error(w, ErrTooLarge.Error(), http.StatusRequestURITooLong)
+		return
+	}
+	query := r.URL.Query()
+	// Encode sorts the parameters, so that their order shares an image.
+	key := r.URL.Path + "?" + query.Encode()
+
+	h.mu.Lock()
+	img, ok := h.cache.Get(key)
+	h.mu.Unlock()
+	observeImageCache(ok)
+	if !ok {
+		start := time.Now()
+		var err error
+		img, err = drawImage(r.URL.Path, query, h.Limits)
+		if err != nil {
+			h.error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		observeRender(start)
+		h.mu.Lock()
+		h.cache.Put(key, img)
+		h.mu.Unlock()
+	}
+
+	w.Header().Set("ETag", img.etag)
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	if match := r.Header.Get("If-None-Match"); match != "" && strings.Contains(match, img.etag) {
+		observeImage(http.StatusNotModified)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", img.contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(img.data)))
+	observeImage(http.StatusOK)
+	if r.Method == http.MethodGet {
+		w.Write(img.data)
+	}
+}
+
+// error answers a request with an error and counts it.
+func (h *ImageHandler) error(w http.ResponseWriter, message string, status int) {
+	observeImage(status)
+	http.Error(w, message, status)
+}
+
+// drawImage draws the image of a path and its parameters.
+func drawImage(path string, query url.Values, l Limits) (image, error) {
+	m := imagePath.FindStringSubmatch(path)
+	if m == nil || m[1] != m[2] {
+		return image{}, ErrImagePath
+	}
+	size, err := strconv.Atoi(m[1])
+	maxSize := MaxImageSize
+	if l.MaxSize > 0 {
+		maxSize = min(maxSize, l.MaxSize)
+	}
+	if err != nil || size < 1 || size > maxSize {
+		return image{}, fmt.Errorf("%dx%d: %w", size, size, ErrImageSize)
+	}
+
+	scheme := cube.Western
+	if name := query.Get("scheme"); name != "" {
+		if scheme, err = cube.LookupScheme(name); err != nil {
+			return image{}, err
+		}
+	}
+	var moves []cube.Move
+	if text := query.Get("case"); text != "" {
+		solution, err := l.parseMoves(text)
+		if err != nil {
+			return image{}, err
+		}
+		moves = cube.ReverseMoves(solution)
+	}
+	if text := query.Get("alg"); text != "" {
+		alg, err := l.parseMoves(text)
+		if err != nil {
+			return image{}, err
+		}
+		moves = append(moves, alg...)
+	}
+	c := cube.NewCubeWith(size, cube.Options{Scheme: scheme})
+	if err := c.ExecuteMoves(moves...); err != nil {
+		return image{}, err
+	}
+
+	// The stickers of a stage are those of its pieces wherever the moves
+	// took them, as the stage is of the solved cube.
+	var shown func(cube.Sticker) bool
+	if stage := query.Get("stage"); stage != "" {
+		if size != 3 {
+			return image{}, ErrStage
+		}
+		expr, ok := stages[strings.ToLower(stage)]
+		if !ok {
+			expr = stage
+		}
+		mask, err := solve.ParseMask(expr)
+		if err != nil {
+			return image{}, err
+		}
+		state, err := solve.StateOf(moves...)
+		if err != nil {
+			return image{}, err
+		}
+		shown = func(st cube.Sticker) bool {
+			home := int(state[int(st.Face)*9+st.Index])
+			return mask.Shows(cube.Sticker{Face: cube.Face(home / 9), Index: home % 9})
+		}
+	}
+
+	view := query.Get("view")
+	if view != "" && view != "net" && view != "plan" {
+		return image{}, fmt.Errorf("%q: %w", view, ErrView)
+	}
+	var b bytes.Buffer
+	img := image{contentType: "image/svg+xml"}
+	switch {
+	case m[3] == "png":
+		img.contentType = "image/png"
+		err = c.RenderPNG(&b, cube.PNGOptions{Plan: view == "plan", Shown: shown})
+	case view == "plan":
+		err = c.RenderPlanSVG(&b, shown)
+	case shown == nil:
+		err = c.RenderSVG(&b)
+	default:
+		err = c.RenderMaskedSVG(&b, shown)
+	}
+	if err != nil {
+		return image{}, err
+	}
+	sum := sha256.Sum256(b.Bytes())
+	img.data, img.etag = b.Bytes(), `"`+hex.EncodeToString(sum[:8])+`"`
+	return img, nil
+}
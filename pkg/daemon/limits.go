@@ -0,0 +1,136 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"net"
+	"sync"
+	"time"
+)
+
+var (
+	ErrTooLarge  = errors.New("request larger than the limit")
+	ErrTooLong   = errors.New("algorithm longer than the limit")
+	ErrRateLimit = errors.New("too many requests")
+	ErrTooMany   = errors.New("too many cubes held")
+)
+
+// Limits bound what requests may ask of the server, so that a public
+// deployment cannot be overwhelmed by huge requests, algorithms that repeat
+// groups of groups such as ((R U)9)9 nested many times, long searches or
+// floods of requests. Zero fields set no limit.
+type Limits struct {
+	MaxRequestBytes int           // Longest request line, or URL of an image
+	MaxMoves        int           // Longest algorithm once its groups are expanded
+	MaxSize         int           // Largest cube of cube.new
+	MaxCubes        int           // Most cubes held at once
+	MaxSolveTime    time.Duration // Longest search, whatever timeout a request asks for
+
+	// Rate is the requests a second that every client may make, by its IP
+	// address or, for connections of a unix socket, by connection, with
+	// bursts of up to Burst requests.
+	Rate  float64
+	Burst int
+}
+
+// DefaultLimits are the limits of NewServer and NewImageHandler, which suit
+// a local server, and bound it against clients that misbehave.
+var DefaultLimits = Limits{
+	MaxRequestBytes: 64 << 10,
+	MaxMoves:        10000,
+	MaxSize:         33,
+	MaxCubes:        1000,
+	MaxSolveTime:    30 * time.Second,
+}
+
+// parseMoves parses and expands an algorithm of at most MaxMoves moves,
+// returning ErrTooLong rather than expanding a longer one.
+func (l Limits) parseMoves(text string) ([]cube.Move, error) {
+	group, err := cube.ParseNotation(text)
+	if err != nil {
+		return nil, err
+	}
+	if l.MaxMoves > 0 && group.Length(l.MaxMoves+1) > l.MaxMoves {
+		return nil, fmt.Errorf("%d moves: %w", l.MaxMoves, ErrTooLong)
+	}
+	return group.Expand()
+}
+
+// solveTime returns the timeout of a search that a request asks for, or
+// MaxSolveTime if it asks for none or more.
+func (l Limits) solveTime(requested time.Duration) time.Duration {
+	if l.MaxSolveTime > 0 && (requested <= 0 || requested > l.MaxSolveTime) {
+		return l.MaxSolveTime
+	}
+	return requested
+}
+
+// bucket is a token bucket of the requests of a client.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// limiter limits the rate of requests by client, with a token bucket each.
+type limiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+func newLimiter() *limiter {
+	return &limiter{buckets: map[string]*bucket{}, now: time.Now}
+}
+
+// allow reports whether the client may make a request now, and takes it
+// from its bucket if so.
+func (rl *limiter) allow(client string, l Limits) bool {
+	if l.Rate <= 0 {
+		return true
+	}
+	burst := float64(max(l.Burst, 1))
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	b, ok := rl.buckets[client]
+	if !ok {
+		// Buckets that have filled up again are the same as new ones, so
+		// they are dropped when the map grows.
+		if len(rl.buckets) >= 4096 {
+			for key, old := range rl.buckets {
+				if now.Sub(old.last).Seconds()*l.Rate >= burst {
+					delete(rl.buckets, key)
+				}
+			}
+		}
+		b = &bucket{tokens: burst, last: now}
+		rl.buckets[client] = b
+	}
+	b.tokens = min(burst, b.tokens+now.Sub(b.last).Seconds()*l.Rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// forget drops the bucket of a client that is gone, such as a connection
+// that closed.
+func (rl *limiter) forget(client string) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	delete(rl.buckets, client)
+}
+
+// clientOf returns the client of a remote address: its IP address, or ok
+// false for addresses of no IP, such as of unix sockets.
+func clientOf(addr string) (client string, ok bool) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil || net.ParseIP(host) == nil {
+		return "", false
+	}
+	return host, true
+}
@@ -0,0 +1,45 @@
+package daemon
+
+import (
+	"github.com/larssont/go-cubic/pkg/metrics"
+	"strconv"
+	"time"
+)
+
+// observeRequest counts a request of the socket answered with the code, ""
+// for success, since start.
+func observeRequest(method, code string, start time.Time) {
+	if _, ok := methods[method]; !ok {
+		method = "unknown"
+	}
+	if code == "" {
+		code = "ok"
+	}
+	m := metrics.Default
+	m.Counter("cubic_daemon_requests_total", "Requests of the daemon socket, by method and error code.", "method", method, "code", code).Inc()
+	m.Histogram("cubic_daemon_request_seconds", "Time to answer the requests of the daemon socket.", nil, "method", method).Observe(time.Since(start).Seconds())
+}
+
+// observeCubes records the number of cubes held.
+func observeCubes(n int) {
+	metrics.Default.Gauge("cubic_daemon_cubes", "Cubes held by the daemon.").Set(float64(n))
+}
+
+// observeImage counts an image request answered with the HTTP status.
+func observeImage(status int) {
+	metrics.Default.Counter("cubic_image_requests_total", "Requests of images, by HTTP status.", "code", strconv.Itoa(status)).Inc()
+}
+
+// observeRender records the time to draw an image since start.
+func observeRender(start time.Time) {
+	metrics.Default.Histogram("cubic_image_render_seconds", "Time to draw the images missing from the cache.", nil).Observe(time.Since(start).Seconds())
+}
+
+// observeImageCache counts a lookup of the image cache.
+func observeImageCache(hit bool) {
+	if hit {
+		metrics.Default.Counter("cubic_image_cache_hits_total", "Images served from the cache.").Inc()
+	} else {
+		metrics.Default.Counter("cubic_image_cache_misses_total", "Images drawn as they were missing from the cache.").Inc()
+	}
+}
@@ -0,0 +1,241 @@
+// Package explore keeps a branching history of moves from a scramble, for
+// fewest moves attempts and "what if" lessons that try several continuations
+// and compare them.
+package explore
+
+import (
+	"errors"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"slices"
+)
+
+var (
+	ErrForeignNode = errors.New("node belongs to another tree")
+	ErrAtRoot      = errors.New("already at the root")
+)
+
+// Node is a state of the tree, reached by the moves of its parent and its
+// own. The root holds no moves and is the scrambled cube.
+type Node struct {
+	Label string
+
+	tree     *Tree
+	parent   *Node
+	children []*Node
+	moves    []cube.Move
+	state    string // in the format of Cube.State
+}
+
+// Parent returns the node the moves were applied to, and nil for the root.
+func (n *Node) Parent() *Node {
+	return n.parent
+}
+
+// Children returns the branches tried from the node, in the order they were
+// made.
+func (n *Node) Children() []*Node {
+	return slices.Clone(n.children)
+}
+
+// Moves returns the moves from the parent to the node.
+func (n *Node) Moves() []cube.Move {
+	return slices.Clone(n.moves)
+}
+
+// State returns the state of the cube at the node in the format of
+// Cube.State.
+func (n *Node) State() string {
+	return n.state
+}
+
+// Path returns the moves from the root to the node.
+func (n *Node) Path() []cube.Move {
+	var path []cube.Move
+	for _, a := range n.ancestors() {
+		path = append(path, a.moves...)
+	}
+	return path
+}
+
+// ancestors returns the nodes from the root to n, both included.
+func (n *Node) ancestors() []*Node {
+	var out []*Node
+	for a := n; a != nil; a = a.parent {
+		out = append(out, a)
+	}
+	slices.Reverse(out)
+	return out
+}
+
+// Tree is a branching history of moves. Applying moves makes a new branch
+// from the current node, or follows the one made with the same moves before.
+type Tree struct {
+	dimension int
+	scramble  []cube.Move
+	root      *Node
+	current   *Node
+}
+
+// New returns a tree of a cube of the dimension after the scramble, at its
+// root.
+func New(dimension int, scramble []cube.Move) (*Tree, error) {
+	c := cube.NewCube(dimension)
+	if err := c.ExecuteMoves(scramble...); err != nil {
+		return nil, err
+	}
+	t := &Tree{dimension: dimension, scramble: slices.Clone(scramble)}
+	t.root = &Node{Label: "scramble", tree: t, state: c.State()}
+	t.current = t.root
+	return t, nil
+}
+
+// Root returns the node of the scrambled cube.
+func (t *Tree) Root() *Node {
+	return t.root
+}
+
+// Current returns the node moves are applied to.
+func (t *Tree) Current() *Node {
+	return t.current
+}
+
+// Cube returns the cube at a node.
+func (t *Tree) Cube(n *Node) (*cube.Cube, error) {
+	if n.tree != t {
+		return nil, ErrForeignNode
+	}
+	c := cube.NewCube(t.dimension)
+	if err := c.ExecuteMoves(t.scramble...); err != nil {
+		return nil, err
+	}
+	if err := c.ExecuteMoves(n.Path()...); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Apply applies moves to the current node and moves to the result, a new
+// branch unless the node has one with the same moves.
+func (t *Tree) Apply(moves ...cube.Move) (*Node, error) {
+	for _, child := range t.current.children {
+		if slices.EqualFunc(child.moves, moves, sameMove) {
+			t.current = child
+			return child, nil
+		}
+	}
+
+	c, err := t.Cube(t.current)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.ExecuteMoves(moves...); err != nil {
+		return nil, err
+	}
+	child := &Node{tree: t, parent: t.current, moves: slices.Clone(moves), state: c.State()}
+	t.current.children = append(t.current.children, child)
+	t.current = child
+	return child, nil
+}
+
+// sameMove compares moves without their times.
+func sameMove(a, b cube.Move) bool {
+	a.Time, a.Timed, b.Time, b.Timed = 0, false, 0, false
+	return a == b
+}
+
+// Back moves to the parent of the current node, keeping the branch.
+func (t *Tree) Back() error {
+	if t.current.parent == nil {
+		return ErrAtRoot
+	}
+	t.current = t.current.parent
+	return nil
+}
+
+// Goto moves to a node of the tree.
+func (t *Tree) Goto(n *Node) error {
+	if n.tree != t {
+		return ErrForeignNode
+	}
+	t.current = n
+	return nil
+}
+
+// Find returns the first node with the label, searching depth first from the
+// root, and nil if there is none.
+func (t *Tree) Find(label string) *Node {
+	stack := []*Node{t.root}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if n.Label == label {
+			return n
+		}
+		for i := len(n.children) - 1; i >= 0; i-- {
+			stack = append(stack, n.children[i])
+		}
+	}
+	return nil
+}
+
+// Remove removes a node and its branches. The current node moves to the
+// parent if it was removed.
+func (t *Tree) Remove(n *Node) error {
+	if n.tree != t {
+		return ErrForeignNode
+	}
+	if n.parent == nil {
+		return ErrAtRoot
+	}
+	if slices.Contains(t.current.ancestors(), n) {
+		t.current = n.parent
+	}
+	n.parent.children = slices.DeleteFunc(n.parent.children, func(c *Node) bool { return c == n })
+	n.tree = nil
+	return nil
+}
+
+// Diff is the difference between two nodes.
+type Diff struct {
+	// Common is the last node both nodes descend from.
+	Common *Node
+
+	// Moves go from the first node to the second: back to Common by the
+	// inverse moves, then forward.
+	Moves []cube.Move
+
+	// Changed holds the stickers whose colors differ.
+	Changed []cube.Sticker
+}
+
+// Diff returns the difference from node a to node b.
+func (t *Tree) Diff(a, b *Node) (Diff, error) {
+	if a.tree != t || b.tree != t {
+		return Diff{}, ErrForeignNode
+	}
+
+	pathA, pathB := a.ancestors(), b.ancestors()
+	i := 0
+	for i < len(pathA) && i < len(pathB) && pathA[i] == pathB[i] {
+		i++
+	}
+	d := Diff{Common: pathA[i-1]}
+
+	var back []cube.Move
+	for _, n := range pathA[i:] {
+		back = append(back, n.moves...)
+	}
+	d.Moves = cube.ReverseMoves(back)
+	for _, n := range pathB[i:] {
+		d.Moves = append(d.Moves, n.moves...)
+	}
+
+	before, after := []rune(a.state), []rune(b.state)
+	n := t.dimension * t.dimension
+	for i := range after {
+		if before[i] != after[i] {
+			d.Changed = append(d.Changed, cube.Sticker{Face: cube.Face(i / n), Index: i % n})
+		}
+	}
+	return d, nil
+}
@@ -0,0 +1,109 @@
+// Package geom contains the vector math used to derive sticker permutations
+// for puzzles whose turns are not aligned with the x, y and z axes.
+package geom
+
+import "math"
+
+const eps = 1e-6
+
+type Vec struct {
+	X, Y, Z float64
+}
+
+func (v Vec) Add(o Vec) Vec {
+	return Vec{v.X + o.X, v.Y + o.Y, v.Z + o.Z}
+}
+
+func (v Vec) Sub(o Vec) Vec {
+	return Vec{v.X - o.X, v.Y - o.Y, v.Z - o.Z}
+}
+
+func (v Vec) Scale(f float64) Vec {
+	return Vec{v.X * f, v.Y * f, v.Z * f}
+}
+
+func (v Vec) Dot(o Vec) float64 {
+	return v.X*o.X + v.Y*o.Y + v.Z*o.Z
+}
+
+func (v Vec) Cross(o Vec) Vec {
+	return Vec{
+		v.Y*o.Z - v.Z*o.Y,
+		v.Z*o.X - v.X*o.Z,
+		v.X*o.Y - v.Y*o.X,
+	}
+}
+
+func (v Vec) Len() float64 {
+	return math.Sqrt(v.Dot(v))
+}
+
+func (v Vec) Unit() Vec {
+	return v.Scale(1 / v.Len())
+}
+
+func (v Vec) Near(o Vec) bool {
+	return v.Sub(o).Len() < eps
+}
+
+// Rotate rotates v around the unit axis by angle radians, counterclockwise
+// when looking at the origin from the tip of axis.
+func (v Vec) Rotate(axis Vec, angle float64) Vec {
+	cos, sin := math.Cos(angle), math.Sin(angle)
+	return v.Scale(cos).
+		Add(axis.Cross(v).Scale(sin)).
+		Add(axis.Scale(axis.Dot(v) * (1 - cos)))
+}
+
+// Centroid returns the mean of the given points.
+func Centroid(points ...Vec) Vec {
+	var sum Vec
+	for _, p := range points {
+		sum = sum.Add(p)
+	}
+	return sum.Scale(1 / float64(len(points)))
+}
+
+// Slot is the location of a single sticker on a puzzle surface.
+type Slot struct {
+	Pos    Vec
+	Normal Vec
+}
+
+// Permutation returns where every slot ends up after rotating all slots whose
+// position lies beyond depth along the axis. The result maps each slot index
+// to its destination index.
+func Permutation(slots []Slot, axis Vec, depth, angle float64) []int {
+	axis = axis.Unit()
+	perm := make([]int, len(slots))
+
+	for i, s := range slots {
+		if s.Pos.Dot(axis) < depth {
+			perm[i] = i
+			continue
+		}
+
+		rotated := Slot{s.Pos.Rotate(axis, angle), s.Normal.Rotate(axis, angle)}
+		perm[i] = -1
+		for j, t := range slots {
+			if rotated.Pos.Near(t.Pos) && rotated.Normal.Near(t.Normal) {
+				perm[i] = j
+				break
+			}
+		}
+		if perm[i] < 0 {
+			panic("geom: rotation does not map slots onto slots")
+		}
+	}
+
+	return perm
+}
+
+// Apply moves every element of state to the index given by perm.
+func Apply[T any](state []T, perm []int) []T {
+	out := make([]T, len(state))
+	for i, v := range state {
+		out[perm[i]] = v
+	}
+	return out
+}
@@ -0,0 +1,465 @@
+// Package qr encodes text as QR codes, of versions 1 to 10 in byte mode, for
+// scrambles and links printed on sheets.
+package qr
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+var ErrTooLong = errors.New("text too long for a QR code")
+
+// Level is the error correction level of a code.
+type Level int
+
+const (
+	// Low recovers about 7% of the codewords.
+	Low Level = iota
+	// Medium recovers about 15% of the codewords.
+	Medium
+)
+
+// formatBits are the bits of the levels in the format information.
+var formatBits = [...]int{Low: 1, Medium: 0}
+
+// block is the layout of the codewords of a version at a level: blocks of
+// data codewords, each followed by ec codewords, with the blocks of group 2
+// one codeword longer than those of group 1.
+type block struct {
+	ec             int
+	group1, group2 int // Number of blocks
+	data           int // Data codewords of the blocks of group 1
+}
+
+// blocks holds the layouts of versions 1 to 10 by level.
+var blocks = [...][10]block{
+	Low: {
+		{7, 1, 0, 19}, {10, 1, 0, 34}, {15, 1, 0, 55}, {20, 1, 0, 80}, {26, 1, 0, 108},
+		{18, 2, 0, 68}, {20, 2, 0, 78}, {24, 2, 0, 97}, {30, 2, 0, 116}, {18, 2, 2, 68},
+	},
+	Medium: {
+		{10, 1, 0, 16}, {16, 1, 0, 28}, {26, 1, 0, 44}, {18, 2, 0, 32}, {24, 2, 0, 43},
+		{16, 4, 0, 27}, {18, 4, 0, 31}, {22, 2, 2, 38}, {22, 3, 2, 36}, {26, 4, 1, 43},
+	},
+}
+
+// alignment holds the centers of the alignment patterns of every version on
+// both axes.
+var alignment = [...][]int{
+	nil, {6, 18}, {6, 22}, {6, 26}, {6, 30},
+	{6, 34}, {6, 22, 38}, {6, 24, 42}, {6, 26, 46}, {6, 28, 50},
+}
+
+func (b block) dataLen() int {
+	return b.group1*b.data + b.group2*(b.data+1)
+}
+
+// Code is a QR code, a square of dark and light modules.
+type Code struct {
+	size     int
+	modules  [][]bool
+	function [][]bool // Modules of the patterns, format and version
+}
+
+// Encode returns the smallest code of the text at the level, or ErrTooLong
+// if even version 10 cannot hold it.
+func Encode(text string, level Level) (*Code, error) {
+	for version := 1; version <= len(alignment); version++ {
+		b := blocks[level][version-1]
+		countBits := 8
+		if version >= 10 {
+			countBits = 16
+		}
+		if 4+countBits+8*len(text) > 8*b.dataLen() {
+			continue
+		}
+
+		var bits bitBuffer
+		bits.append(0b0100, 4)
+		bits.append(len(text), countBits)
+		for i := range len(text) {
+			bits.append(int(text[i]), 8)
+		}
+		capacity := 8 * b.dataLen()
+		bits.append(0, min(4, capacity-len(bits)))
+		bits.append(0, (8-len(bits)%8)%8)
+		for pad := 0xEC; len(bits) < capacity; pad ^= 0xEC ^ 0x11 {
+			bits.append(pad, 8)
+		}
+
+		c := newCode(version)
+		c.drawFunctions(version)
+		c.drawCodewords(interleave(bits.bytes(), b))
+		c.applyBestMask(level, version)
+		return c, nil
+	}
+	return nil, fmt.Errorf("%d bytes: %w", len(text), ErrTooLong)
+}
+
+// Size is the number of modules on a side, without the quiet zone.
+func (c *Code) Size() int {
+	return c.size
+}
+
+// Dark reports whether the module of the column and row is dark.
+func (c *Code) Dark(x, y int) bool {
+	return c.modules[y][x]
+}
+
+// WriteSVG writes the code as an SVG image of a side of size pixels with a
+// quiet zone of four modules.
+func (c *Code) WriteSVG(w io.Writer, size float64) error {
+	const quiet = 4
+	n := c.size + 2*quiet
+	if _, err := fmt.Fprintf(w,
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%g" height="%g" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+"\n"+
+			`<rect width="%d" height="%d" fill="#fff"/>`+"\n"+`<path fill="#000" d="`,
+		size, size, n, n, n, n); err != nil {
+		return err
+	}
+	for y := range c.size {
+		for x := range c.size {
+			if c.modules[y][x] {
+				if _, err := fmt.Fprintf(w, "M%d %dh1v1h-1z", x+quiet, y+quiet); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	_, err := io.WriteString(w, "\"/>\n</svg>\n")
+	return err
+}
+
+type bitBuffer []bool
+
+func (b *bitBuffer) append(value, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, value>>i&1 == 1)
+	}
+}
+
+func (b bitBuffer) bytes() []byte {
+	out := make([]byte, len(b)/8)
+	for i, bit := range b {
+		if bit {
+			out[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return out
+}
+
+// interleave splits the data into the blocks of the layout, adds their error
+// correction, and returns the codewords in the order they are placed: the
+// data of every block in turns, then their error correction the same way.
+func interleave(data []byte, b block) []byte {
+	divisor := rsDivisor(b.ec)
+	var datas, ecs [][]byte
+	for i := range b.group1 + b.group2 {
+		n := b.data
+		if i >= b.group1 {
+			n++
+		}
+		datas = append(datas, data[:n])
+		ecs = append(ecs, rsRemainder(data[:n], divisor))
+		data = data[n:]
+	}
+
+	var out []byte
+	for i := range b.data + 1 {
+		for _, d := range datas {
+			if i < len(d) {
+				out = append(out, d[i])
+			}
+		}
+	}
+	for i := range b.ec {
+		for _, ec := range ecs {
+			out = append(out, ec[i])
+		}
+	}
+	return out
+}
+
+func newCode(version int) *Code {
+	size := 17 + 4*version
+	c := &Code{size: size, modules: make([][]bool, size), function: make([][]bool, size)}
+	for y := range size {
+		c.modules[y] = make([]bool, size)
+		c.function[y] = make([]bool, size)
+	}
+	return c
+}
+
+func (c *Code) set(x, y int, dark bool) {
+	c.modules[y][x] = dark
+	c.function[y][x] = true
+}
+
+// drawFunctions draws the finder, timing and alignment patterns and the
+// version information, and reserves the modules of the format information.
+func (c *Code) drawFunctions(version int) {
+	for i := range c.size {
+		c.set(6, i, i%2 == 0)
+		c.set(i, 6, i%2 == 0)
+	}
+
+	for _, corner := range [][2]int{{3, 3}, {c.size - 4, 3}, {3, c.size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := corner[0]+dx, corner[1]+dy
+				if x < 0 || x >= c.size || y < 0 || y >= c.size {
+					continue
+				}
+				d := max(abs(dx), abs(dy))
+				c.set(x, y, d != 2 && d != 4)
+			}
+		}
+	}
+
+	centers := alignment[version-1]
+	last := len(centers) - 1
+	for i, cx := range centers {
+		for j, cy := range centers {
+			// Skip the corners of the finder patterns.
+			if i == 0 && j == 0 || i == 0 && j == last || i == last && j == 0 {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					c.set(cx+dx, cy+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	c.drawFormat(0)
+	if version >= 7 {
+		rem := version
+		for range 12 {
+			rem = rem<<1 ^ rem>>11*0x1F25
+		}
+		bits := version<<12 | rem
+		for i := range 18 {
+			dark := bits>>i&1 == 1
+			a, b := c.size-11+i%3, i/3
+			c.set(a, b, dark)
+			c.set(b, a, dark)
+		}
+	}
+}
+
+// drawFormat draws both copies of the format information bits and the dark
+// module beside them.
+func (c *Code) drawFormat(bits int) {
+	bit := func(i int) bool { return bits>>i&1 == 1 }
+	for i := range 6 {
+		c.set(8, i, bit(i))
+	}
+	c.set(8, 7, bit(6))
+	c.set(8, 8, bit(7))
+	c.set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		c.set(14-i, 8, bit(i))
+	}
+
+	for i := range 8 {
+		c.set(c.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		c.set(8, c.size-15+i, bit(i))
+	}
+	c.set(8, c.size-8, true)
+}
+
+// drawCodewords places the bits of the codewords in pairs of columns from
+// the right, up and down in turns, around the function modules.
+func (c *Code) drawCodewords(codewords []byte) {
+	i := 0
+	for right := c.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := range c.size {
+			y := vert
+			if upward {
+				y = c.size - 1 - vert
+			}
+			for j := range 2 {
+				x := right - j
+				if c.function[y][x] || i >= 8*len(codewords) {
+					continue
+				}
+				c.modules[y][x] = codewords[i/8]>>(7-i%8)&1 == 1
+				i++
+			}
+		}
+	}
+}
+
+// masks tell which modules of the column and row each mask pattern flips.
+var masks = [8]func(x, y int) bool{
+	func(x, y int) bool { return (x+y)%2 == 0 },
+	func(x, y int) bool { return y%2 == 0 },
+	func(x, y int) bool { return x%3 == 0 },
+	func(x, y int) bool { return (x+y)%3 == 0 },
+	func(x, y int) bool { return (x/3+y/2)%2 == 0 },
+	func(x, y int) bool { return x*y%2+x*y%3 == 0 },
+	func(x, y int) bool { return (x*y%2+x*y%3)%2 == 0 },
+	func(x, y int) bool { return ((x+y)%2+x*y%3)%2 == 0 },
+}
+
+func (c *Code) applyMask(mask int) {
+	for y := range c.size {
+		for x := range c.size {
+			if !c.function[y][x] && masks[mask](x, y) {
+				c.modules[y][x] = !c.modules[y][x]
+			}
+		}
+	}
+}
+
+// applyBestMask applies the mask of the lowest penalty along with its format
+// information.
+func (c *Code) applyBestMask(level Level, version int) {
+	best, bestPenalty := 0, -1
+	for mask := range masks {
+		c.applyMask(mask)
+		c.drawFormat(format(level, mask))
+		if p := c.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+		c.applyMask(mask)
+	}
+	c.applyMask(best)
+	c.drawFormat(format(level, best))
+}
+
+// format returns the 15 bits of the format information: the level and mask,
+// their BCH error correction, and the fixed mask of the standard.
+func format(level Level, mask int) int {
+	data := formatBits[level]<<3 | mask
+	rem := data
+	for range 10 {
+		rem = rem<<1 ^ rem>>9*0x537
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+// penalty scores the code by the rules of the standard, lower for codes
+// easier to scan: runs of one color, 2x2 blocks, patterns that look like
+// finders, and an imbalance of dark and light.
+func (c *Code) penalty() int {
+	penalty := 0
+	line := func(at func(i int) bool) {
+		run := 1
+		for i := 1; i <= c.size; i++ {
+			if i < c.size && at(i) == at(i-1) {
+				run++
+				continue
+			}
+			if run >= 5 {
+				penalty += 3 + run - 5
+			}
+			run = 1
+		}
+		finder := []bool{true, false, true, true, true, false, true}
+		for i := 0; i+7 <= c.size; i++ {
+			match := true
+			for j, dark := range finder {
+				if at(i+j) != dark {
+					match = false
+					break
+				}
+			}
+			if match && (light(at, i-4, i, c.size) || light(at, i+7, i+11, c.size)) {
+				penalty += 40
+			}
+		}
+	}
+	for y := range c.size {
+		line(func(x int) bool { return c.modules[y][x] })
+	}
+	for x := range c.size {
+		line(func(y int) bool { return c.modules[y][x] })
+	}
+
+	dark := 0
+	for y := range c.size {
+		for x := range c.size {
+			if c.modules[y][x] {
+				dark++
+			}
+			if x > 0 && y > 0 {
+				m := c.modules[y][x]
+				if c.modules[y-1][x] == m && c.modules[y][x-1] == m && c.modules[y-1][x-1] == m {
+					penalty += 3
+				}
+			}
+		}
+	}
+	total := c.size * c.size
+	penalty += abs(dark*20-total*10) / total * 10
+	return penalty
+}
+
+// light reports whether the modules from i to j are light, counting those
+// outside the code as light.
+func light(at func(i int) bool, i, j, size int) bool {
+	for ; i < j; i++ {
+		if i >= 0 && i < size && at(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// rsDivisor returns the generator polynomial of Reed-Solomon codes of the
+// degree, without its leading coefficient, highest powers first.
+func rsDivisor(degree int) []byte {
+	out := make([]byte, degree)
+	out[degree-1] = 1
+	root := byte(1)
+	for range degree {
+		for j := range degree {
+			out[j] = gfMul(out[j], root)
+			if j+1 < degree {
+				out[j] ^= out[j+1]
+			}
+		}
+		root = gfMul(root, 2)
+	}
+	return out
+}
+
+// rsRemainder returns the error correction codewords of the data.
+func rsRemainder(data, divisor []byte) []byte {
+	out := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ out[0]
+		copy(out, out[1:])
+		out[len(out)-1] = 0
+		for i, coef := range divisor {
+			out[i] ^= gfMul(coef, factor)
+		}
+	}
+	return out
+}
+
+// gfMul multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1.
+func gfMul(a, b byte) byte {
+	var out int
+	for i := 7; i >= 0; i-- {
+		out = out<<1 ^ out>>7*0x11D
+		out ^= int(b>>i&1) * int(a)
+	}
+	return byte(out)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,160 @@
+// Package raster draws the polygons of the puzzle renderers into images, so
+// that the drawings of package svg can also be written as PNG, such as for
+// chat services that do not show SVG.
+package raster
+
+import (
+	"github.com/larssont/go-cubic/pkg/internal/svg"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Canvas is an image drawn with the coordinates of an SVG drawing, times a
+// scale. It draws polygons and stickers as svg.Writer does, and leaves out
+// text and the marks of patterns.
+type Canvas struct {
+	img   *image.RGBA
+	scale float64
+}
+
+// NewCanvas returns a transparent canvas of a drawing of the width and
+// height, at scale pixels per unit.
+func NewCanvas(width, height, scale float64) *Canvas {
+	w, h := int(math.Ceil(width*scale)), int(math.Ceil(height*scale))
+	return &Canvas{img: image.NewRGBA(image.Rect(0, 0, w, h)), scale: scale}
+}
+
+// Image returns the image drawn so far.
+func (c *Canvas) Image() *image.RGBA {
+	return c.img
+}
+
+// Polygon fills the convex polygon of the points, with a black outline of a
+// unit wide, half inside it, as svg.Writer.Polygon draws.
+func (c *Canvas) Polygon(fill string, points ...svg.Point) {
+	c.fill(points, color.RGBA{A: 255})
+	c.fill(inset(points, 0.5), ParseColor(fill))
+}
+
+// Fill fills the convex polygon of the points in the color, without an
+// outline.
+func (c *Canvas) Fill(col color.Color, points ...svg.Point) {
+	c.fill(points, color.RGBAModel.Convert(col).(color.RGBA))
+}
+
+// Clear makes every pixel transparent, to draw the next frame.
+func (c *Canvas) Clear() {
+	clear(c.img.Pix)
+}
+
+// Sticker fills a sticker of the color in the style.
+func (c *Canvas) Sticker(st svg.Style, col rune, points ...svg.Point) {
+	c.Polygon(st.Fill(col), points...)
+}
+
+// Label draws nothing, as canvases have no fonts.
+func (c *Canvas) Label(at svg.Point, size float64, text string) {}
+
+// Close does nothing, for canvases to stand in for an svg.Writer.
+func (c *Canvas) Close() error {
+	return nil
+}
+
+// WritePNG encodes the image as PNG.
+func (c *Canvas) WritePNG(w io.Writer) error {
+	return png.Encode(w, c.img)
+}
+
+// inset returns the convex polygon of the points with every side moved in
+// by d, or nil if nothing is left of it.
+func inset(points []svg.Point, d float64) []svg.Point {
+	n := len(points)
+	area := 0.0
+	for i, p := range points {
+		q := points[(i+1)%n]
+		area += p.X*q.Y - q.X*p.Y
+	}
+	if area == 0 {
+		return nil
+	}
+	// The inward normal of a side is to its left or right by the winding.
+	side := func(i int) (svg.Point, svg.Point) {
+		p, q := points[i], points[(i+1)%n]
+		dx, dy := q.X-p.X, q.Y-p.Y
+		l := math.Hypot(dx, dy)
+		nx, ny := -dy/l*d, dx/l*d
+		if area < 0 {
+			nx, ny = -nx, -ny
+		}
+		return svg.Point{X: p.X + nx, Y: p.Y + ny}, svg.Point{X: q.X + nx, Y: q.Y + ny}
+	}
+	out := make([]svg.Point, n)
+	for i := range n {
+		a0, a1 := side((i + n - 1) % n)
+		b0, b1 := side(i)
+		ax, ay, bx, by := a1.X-a0.X, a1.Y-a0.Y, b1.X-b0.X, b1.Y-b0.Y
+		den := ax*by - ay*bx
+		if den == 0 {
+			out[i] = b0
+			continue
+		}
+		t := ((b0.X-a0.X)*by - (b0.Y-a0.Y)*bx) / den
+		out[i] = svg.Point{X: a0.X + t*ax, Y: a0.Y + t*ay}
+	}
+	// A polygon narrower than the inset turns inside out.
+	turned := 0.0
+	for i, p := range out {
+		q := out[(i+1)%n]
+		turned += p.X*q.Y - q.X*p.Y
+	}
+	if turned*area <= 0 {
+		return nil
+	}
+	return out
+}
+
+// fill paints the pixels whose centers are inside the convex polygon.
+func (c *Canvas) fill(points []svg.Point, col color.RGBA) {
+	if len(points) < 3 || col.A == 0 {
+		return
+	}
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, p := range points {
+		minY, maxY = min(minY, p.Y*c.scale), max(maxY, p.Y*c.scale)
+	}
+	bounds := c.img.Bounds()
+	for y := max(int(math.Floor(minY)), bounds.Min.Y); y < min(int(math.Ceil(maxY)), bounds.Max.Y); y++ {
+		center := float64(y) + 0.5
+		left, right := math.Inf(1), math.Inf(-1)
+		for i, p := range points {
+			q := points[(i+1)%len(points)]
+			y0, y1 := p.Y*c.scale, q.Y*c.scale
+			if (center < y0) == (center < y1) {
+				continue
+			}
+			x := p.X*c.scale + (center-y0)/(y1-y0)*(q.X-p.X)*c.scale
+			left, right = min(left, x), max(right, x)
+		}
+		for x := max(int(math.Round(left)), bounds.Min.X); x < min(int(math.Round(right)), bounds.Max.X); x++ {
+			c.img.SetRGBA(x, y, col)
+		}
+	}
+}
+
+// ParseColor parses a fill of the form #rgb or #rrggbb, or returns gray.
+func ParseColor(fill string) color.RGBA {
+	hex := strings.TrimPrefix(fill, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 6 || err != nil {
+		return color.RGBA{0x80, 0x80, 0x80, 255}
+	}
+	return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 255}
+}
@@ -0,0 +1,177 @@
+// Package svg contains a minimal SVG writer shared by the puzzle renderers.
+package svg
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// Palette maps sticker color runes to fill colors.
+var Palette = map[rune]string{
+	'w': "#ffffff",
+	'y': "#ffff00",
+	'g': "#00ff00",
+	'b': "#0000ff",
+	'r': "#ff0000",
+	'o': "#ff8800",
+}
+
+// Fill returns the palette color for the rune, or gray for unknown runes.
+func Fill(color rune) string {
+	if fill, ok := Palette[color]; ok {
+		return fill
+	}
+	return "#808080"
+}
+
+// Style is how stickers are drawn: the fill of every color, and with
+// Patterns a mark of every color inside its stickers, so that colors told
+// apart by hue alone, such as red and orange or green and blue, also differ
+// in shape.
+type Style struct {
+	Fills    map[rune]string // Palette if nil
+	Patterns bool
+}
+
+// Fill returns the fill of the color, or gray for unknown colors.
+func (st Style) Fill(color rune) string {
+	if st.Fills == nil {
+		return Fill(color)
+	}
+	if fill, ok := st.Fills[color]; ok {
+		return fill
+	}
+	return "#808080"
+}
+
+type Point struct {
+	X, Y float64
+}
+
+// Writer writes SVG elements and remembers the first write error.
+type Writer struct {
+	w   io.Writer
+	err error
+}
+
+// NewWriter writes the opening svg tag for an image of the given size.
+func NewWriter(w io.Writer, width, height float64) *Writer {
+	s := &Writer{w: w}
+	s.printf(
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%g" height="%g" viewBox="0 0 %g %g">`+"\n",
+		width, height, width, height,
+	)
+	return s
+}
+
+func (s *Writer) printf(format string, args ...any) {
+	if s.err != nil {
+		return
+	}
+	_, s.err = fmt.Fprintf(s.w, format, args...)
+}
+
+func (s *Writer) Polygon(fill string, points ...Point) {
+	coords := make([]string, len(points))
+	for i, p := range points {
+		coords[i] = fmt.Sprintf("%.2f,%.2f", p.X, p.Y)
+	}
+	s.printf(`<polygon points="%s" fill="%s" stroke="#000" stroke-width="1"/>`+"\n", strings.Join(coords, " "), fill)
+}
+
+func (s *Writer) Circle(fill string, center Point, radius float64) {
+	s.printf(`<circle cx="%.2f" cy="%.2f" r="%.2f" fill="%s" stroke="#000" stroke-width="1"/>`+"\n", center.X, center.Y, radius, fill)
+}
+
+func (s *Writer) Line(from, to Point, width float64) {
+	s.printf(`<line x1="%.2f" y1="%.2f" x2="%.2f" y2="%.2f" stroke="#000" stroke-width="%g"/>`+"\n", from.X, from.Y, to.X, to.Y, width)
+}
+
+// Text writes text centered at the point.
+func (s *Writer) Text(at Point, size float64, text string) {
+	var escaped strings.Builder
+	xml.EscapeText(&escaped, []byte(text))
+	s.printf(`<text x="%.2f" y="%.2f" font-size="%g" font-family="sans-serif" text-anchor="middle">%s</text>`+"\n", at.X, at.Y, size, escaped.String())
+}
+
+// Label writes text centered on the point, in black with a white outline so
+// that it reads on any fill.
+func (s *Writer) Label(at Point, size float64, text string) {
+	var escaped strings.Builder
+	xml.EscapeText(&escaped, []byte(text))
+	s.printf(`<text x="%.2f" y="%.2f" font-size="%g" font-family="sans-serif" font-weight="bold" text-anchor="middle" dominant-baseline="central" fill="#000" stroke="#fff" stroke-width="%g" paint-order="stroke">%s</text>`+"\n", at.X, at.Y, size, size/6, escaped.String())
+}
+
+// Embed writes another SVG image, such as the output of a RenderSVG method,
+// with its top left corner at the point.
+func (s *Writer) Embed(at Point, image []byte) {
+	s.printf(`<g transform="translate(%.2f,%.2f)">`+"\n", at.X, at.Y)
+	s.printf("%s", image)
+	s.printf("</g>\n")
+}
+
+// Sticker draws a sticker of the color in the style.
+func (s *Writer) Sticker(st Style, color rune, points ...Point) {
+	s.Polygon(st.Fill(color), points...)
+	if st.Patterns {
+		s.mark(color, points)
+	}
+}
+
+// mark draws the mark of the color at the centroid of the points: none for
+// white, a ring for yellow, and a horizontal, diagonal or vertical bar or a
+// cross for red, orange, green and blue.
+func (s *Writer) mark(color rune, points []Point) {
+	var c Point
+	for _, p := range points {
+		c.X += p.X / float64(len(points))
+		c.Y += p.Y / float64(len(points))
+	}
+	r := 0.0
+	for _, p := range points {
+		r += math.Hypot(p.X-c.X, p.Y-c.Y) / float64(len(points))
+	}
+	r *= 0.35
+	width := max(1, r/4)
+
+	bar := func(dx, dy float64) {
+		s.Line(Point{c.X - dx*r, c.Y - dy*r}, Point{c.X + dx*r, c.Y + dy*r}, width)
+	}
+	switch color {
+	case 'y':
+		s.printf(`<circle cx="%.2f" cy="%.2f" r="%.2f" fill="none" stroke="#000" stroke-width="%g"/>`+"\n", c.X, c.Y, r*0.7, width)
+	case 'r':
+		bar(1, 0)
+	case 'o':
+		bar(math.Sqrt2/2, -math.Sqrt2/2)
+	case 'g':
+		bar(0, 1)
+	case 'b':
+		bar(1, 0)
+		bar(0, 1)
+	}
+}
+
+// Close writes the closing svg tag and returns the first error encountered.
+func (s *Writer) Close() error {
+	s.printf("</svg>\n")
+	return s.err
+}
+
+// Shrink moves the points towards their centroid by factor.
+func Shrink(factor float64, points ...Point) []Point {
+	var c Point
+	for _, p := range points {
+		c.X += p.X / float64(len(points))
+		c.Y += p.Y / float64(len(points))
+	}
+
+	out := make([]Point, len(points))
+	for i, p := range points {
+		out[i] = Point{c.X + (p.X-c.X)*factor, c.Y + (p.Y-c.Y)*factor}
+	}
+	return out
+}
@@ -0,0 +1,122 @@
+// Package keymap maps keyboard keys to moves for interactive simulators.
+package keymap
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrBinding = errors.New("bindings are written as key = move")
+
+// Keymap maps keys to the move they perform.
+type Keymap map[rune]cube.Move
+
+// defaults are the keyboard bindings of the csTimer virtual cube. The home
+// row turns U with the index fingers, and rotations and wide moves sit on the
+// outer keys.
+var defaults = map[rune]string{
+	'i': "R", 'k': "R'", 'd': "L", 'e': "L'",
+	'j': "U", 'f': "U'", 's': "D", 'l': "D'",
+	'h': "F", 'g': "F'", 'w': "B", 'o': "B'",
+	'u': "Rw", 'm': "Rw'", 'v': "Lw", 'r': "Lw'",
+	',': "Uw", 'c': "Uw'", 'z': "Dw", '/': "Dw'",
+	'5': "M", '6': "M", 'x': "M'", '.': "M'",
+	't': "x", 'y': "x", 'b': "x'", 'n': "x'",
+	';': "y", 'a': "y'", 'p': "z", 'q': "z'",
+}
+
+// Default returns the csTimer bindings: j and f turn U, i and k turn R, and so
+// on.
+func Default() Keymap {
+	k := Keymap{}
+	for key, notation := range defaults {
+		m, err := parseMove(notation)
+		if err != nil {
+			panic(err)
+		}
+		k[key] = m
+	}
+	return k
+}
+
+func parseMove(notation string) (cube.Move, error) {
+	group, err := cube.ParseNotation(notation)
+	if err != nil {
+		return cube.Move{}, err
+	}
+	moves, err := group.Expand()
+	if err != nil {
+		return cube.Move{}, err
+	}
+	if len(moves) != 1 {
+		return cube.Move{}, fmt.Errorf("%q: %w", notation, ErrBinding)
+	}
+	return moves[0], nil
+}
+
+// Load reads bindings on top of the defaults, one per line as "key = move",
+// e.g. "j = U". A move of "none" removes the binding of a key, and lines
+// starting with # are comments.
+func Load(r io.Reader) (Keymap, error) {
+	k := Default()
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		key, notation, ok := strings.Cut(text, "=")
+		key, notation = strings.TrimSpace(key), strings.TrimSpace(notation)
+		if !ok || utf8.RuneCountInString(key) != 1 || notation == "" {
+			return nil, fmt.Errorf("line %d: %w", line, ErrBinding)
+		}
+
+		r, _ := utf8.DecodeRuneInString(key)
+		if notation == "none" {
+			delete(k, r)
+			continue
+		}
+		m, err := parseMove(notation)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		k[r] = m
+	}
+	return k, scanner.Err()
+}
+
+// LoadFile loads bindings from a config file, see Load.
+func LoadFile(path string) (Keymap, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+// Move returns the move bound to the key.
+func (k Keymap) Move(key rune) (cube.Move, bool) {
+	m, ok := k[key]
+	return m, ok
+}
+
+// Write writes the bindings in the format of Load, sorted by key.
+func (k Keymap) Write(w io.Writer) error {
+	for _, key := range slices.Sorted(maps.Keys(k)) {
+		m := k[key]
+		if _, err := fmt.Fprintf(w, "%c = %s\n", key, m.Notation()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,282 @@
+// Package metrics counts what the solvers and servers of go-cubic do, such
+// as requests served, images rendered and nodes searched, for programs to
+// read in process and for operators to scrape in the text format of
+// Prometheus.
+//
+// Metrics are series of a family, named as in Prometheus and told apart by
+// pairs of label names and values:
+//
+//	metrics.Default.Counter("cubic_daemon_requests_total", "Requests of the daemon.", "method", "solve").Inc()
+//
+// returns the same series for the same name and labels every time, creating
+// it the first time.
+package metrics
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Kind is the type of a family of metrics.
+type Kind int
+
+const (
+	CounterKind   Kind = iota // Values that only grow, such as requests served
+	GaugeKind                 // Values that go up and down, such as cubes held
+	HistogramKind             // Observations counted in buckets, such as latencies
+)
+
+func (k Kind) String() string {
+	switch k {
+	case GaugeKind:
+		return "gauge"
+	case HistogramKind:
+		return "histogram"
+	}
+	return "counter"
+}
+
+func (k Kind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// DefaultBuckets are the upper bounds of the buckets of histograms created
+// without any, suited to durations in seconds from a millisecond to a
+// minute.
+var DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10, 60}
+
+// value is a float64 updated atomically.
+type value struct {
+	bits atomic.Uint64
+}
+
+func (v *value) add(x float64) {
+	for {
+		old := v.bits.Load()
+		if v.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+x)) {
+			return
+		}
+	}
+}
+
+func (v *value) get() float64 {
+	return math.Float64frombits(v.bits.Load())
+}
+
+// Counter is a series that only grows.
+type Counter struct {
+	v value
+}
+
+// Add adds x, which must not be negative.
+func (c *Counter) Add(x float64) {
+	if x > 0 {
+		c.v.add(x)
+	}
+}
+
+func (c *Counter) Inc() {
+	c.v.add(1)
+}
+
+func (c *Counter) Value() float64 {
+	return c.v.get()
+}
+
+// Gauge is a series that goes up and down.
+type Gauge struct {
+	v value
+}
+
+func (g *Gauge) Set(x float64) {
+	g.v.bits.Store(math.Float64bits(x))
+}
+
+func (g *Gauge) Add(x float64) {
+	g.v.add(x)
+}
+
+func (g *Gauge) Value() float64 {
+	return g.v.get()
+}
+
+// Histogram counts observations in buckets of upper bounds, along with
+// their count and sum.
+type Histogram struct {
+	mu     sync.Mutex
+	bounds []float64
+	counts []uint64 // Of each bucket alone, not cumulative
+	count  uint64
+	sum    float64
+}
+
+func (h *Histogram) Observe(x float64) {
+	i, _ := slices.BinarySearch(h.bounds, x)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if i < len(h.counts) {
+		h.counts[i]++
+	}
+	h.count++
+	h.sum += x
+}
+
+// Bucket is the number of observations of a histogram of at most an upper
+// bound, including those of the buckets below.
+type Bucket struct {
+	UpperBound float64 `json:"le"`
+	Count      uint64  `json:"count"`
+}
+
+// Series is a snapshot of one series of a family. Value is that of counters
+// and gauges, and Count, Sum and Buckets those of histograms.
+type Series struct {
+	Labels  []string `json:"labels,omitempty"` // Pairs of names and values
+	Value   float64  `json:"value"`
+	Count   uint64   `json:"count,omitempty"`
+	Sum     float64  `json:"sum,omitempty"`
+	Buckets []Bucket `json:"buckets,omitempty"`
+}
+
+// Label returns the value of a label of the series, or "" if it has none of
+// the name.
+func (s Series) Label(name string) string {
+	for i := 0; i+1 < len(s.Labels); i += 2 {
+		if s.Labels[i] == name {
+			return s.Labels[i+1]
+		}
+	}
+	return ""
+}
+
+// Family is a snapshot of the series of a metric.
+type Family struct {
+	Name   string   `json:"name"`
+	Help   string   `json:"help"`
+	Kind   Kind     `json:"type"`
+	Series []Series `json:"series"`
+}
+
+// family is a metric and its series by their labels.
+type family struct {
+	help    string
+	kind    Kind
+	buckets []float64
+	series  map[string]*series
+}
+
+type series struct {
+	labels    []string
+	counter   *Counter
+	gauge     *Gauge
+	histogram *Histogram
+}
+
+// Registry holds families of metrics.
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]*family
+}
+
+func NewRegistry() *Registry {
+	return &Registry{families: map[string]*family{}}
+}
+
+// Default is the registry that the packages of go-cubic record their
+// metrics in.
+var Default = NewRegistry()
+
+// get returns the series of the labels of a family, creating either. It
+// panics if the family exists of another kind, or if the labels are not in
+// pairs, as those are mistakes of the program rather than of its input.
+func (r *Registry) get(name, help string, kind Kind, buckets []float64, labels []string) *series {
+	if len(labels)%2 != 0 {
+		panic(fmt.Sprintf("metrics: %s: labels %q not in pairs of names and values", name, labels))
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f, ok := r.families[name]
+	if !ok {
+		if kind == HistogramKind && len(buckets) == 0 {
+			buckets = DefaultBuckets
+		}
+		f = &family{help: help, kind: kind, buckets: slices.Sorted(slices.Values(buckets)), series: map[string]*series{}}
+		r.families[name] = f
+	}
+	if f.kind != kind {
+		panic(fmt.Sprintf("metrics: %s is a %s, not a %s", name, f.kind, kind))
+	}
+
+	key := strings.Join(labels, "\xff")
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labels: slices.Clone(labels)}
+		switch kind {
+		case CounterKind:
+			s.counter = &Counter{}
+		case GaugeKind:
+			s.gauge = &Gauge{}
+		case HistogramKind:
+			s.histogram = &Histogram{bounds: f.buckets, counts: make([]uint64, len(f.buckets))}
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// Counter returns the counter of the name and labels, given as pairs of
+// names and values.
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	return r.get(name, help, CounterKind, nil, labels).counter
+}
+
+// Gauge returns the gauge of the name and labels.
+func (r *Registry) Gauge(name, help string, labels ...string) *Gauge {
+	return r.get(name, help, GaugeKind, nil, labels).gauge
+}
+
+// Histogram returns the histogram of the name and labels. The buckets of the
+// first call of a name are those of the family, DefaultBuckets if nil.
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	return r.get(name, help, HistogramKind, buckets, labels).histogram
+}
+
+// Families returns a snapshot of every family, sorted by name, with their
+// series sorted by labels.
+func (r *Registry) Families() []Family {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	families := make([]Family, 0, len(r.families))
+	for name, f := range r.families {
+		family := Family{Name: name, Help: f.help, Kind: f.kind}
+		for _, key := range slices.Sorted(maps.Keys(f.series)) {
+			s := f.series[key]
+			snapshot := Series{Labels: s.labels}
+			switch {
+			case s.counter != nil:
+				snapshot.Value = s.counter.Value()
+			case s.gauge != nil:
+				snapshot.Value = s.gauge.Value()
+			default:
+				h := s.histogram
+				h.mu.Lock()
+				snapshot.Count, snapshot.Sum = h.count, h.sum
+				var total uint64
+				for i, bound := range h.bounds {
+					total += h.counts[i]
+					snapshot.Buckets = append(snapshot.Buckets, Bucket{bound, total})
+				}
+				h.mu.Unlock()
+			}
+			family.Series = append(family.Series, snapshot)
+		}
+		families = append(families, family)
+	}
+	slices.SortFunc(families, func(a, b Family) int { return strings.Compare(a.Name, b.Name) })
+	return families
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// WriteText writes every family of the registry in the text exposition
+// format of Prometheus.
+func (r *Registry) WriteText(w io.Writer) error {
+	b := bufio.NewWriter(w)
+	for _, f := range r.Families() {
+		b.WriteString("# HELP " + f.Name + " " + escapeHelp.Replace(f.Help) + "\n")
+		b.WriteString("# TYPE " + f.Name + " " + f.Kind.String() + "\n")
+		for _, s := range f.Series {
+			if f.Kind != HistogramKind {
+				writeSample(b, f.Name, s.Labels, s.Value)
+				continue
+			}
+			for _, bucket := range s.Buckets {
+				writeSample(b, f.Name+"_bucket", append(s.Labels[:len(s.Labels):len(s.Labels)], "le", formatFloat(bucket.UpperBound)), float64(bucket.Count))
+			}
+			writeSample(b, f.Name+"_bucket", append(s.Labels[:len(s.Labels):len(s.Labels)], "le", "+Inf"), float64(s.Count))
+			writeSample(b, f.Name+"_sum", s.Labels, s.Sum)
+			writeSample(b, f.Name+"_count", s.Labels, float64(s.Count))
+		}
+	}
+	return b.Flush()
+}
+
+var (
+	escapeHelp  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	escapeLabel = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func writeSample(b *bufio.Writer, name string, labels []string, v float64) {
+	b.WriteString(name)
+	if len(labels) > 0 {
+		b.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(labels[i] + `="` + escapeLabel.Replace(labels[i+1]) + `"`)
+		}
+		b.WriteByte('}')
+	}
+	b.WriteString(" " + formatFloat(v) + "\n")
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// Handler serves the registry in the text format of Prometheus, for a
+// /metrics endpoint to scrape.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteText(w)
+	})
+}
@@ -0,0 +1,125 @@
+// Package puzzle defines the interface shared by all puzzle models, so that
+// tools can be written once for every puzzle.
+package puzzle
+
+import (
+	"errors"
+	"github.com/larssont/go-cubic/pkg/clock"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/pyraminx"
+	"github.com/larssont/go-cubic/pkg/skewb"
+	"github.com/larssont/go-cubic/pkg/square1"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnknownPuzzle = errors.New("unknown puzzle")
+)
+
+// Puzzle is a puzzle model whose notation is made of moves of type M.
+type Puzzle[M any] interface {
+	Apply(move M) error
+	IsSolved() bool
+
+	// State returns an encoding of the puzzle state, which is equal for two
+	// puzzles of the same type exactly when their states are equal.
+	State() string
+
+	RenderSVG(w io.Writer) error
+}
+
+var (
+	_ Puzzle[cube.Move]    = (*cube.Cube)(nil)
+	_ Puzzle[cube.Move]    = (*cube.MirrorCube)(nil)
+	_ Puzzle[cube.Move]    = (*pyraminx.Pyraminx)(nil)
+	_ Puzzle[cube.Move]    = (*skewb.Skewb)(nil)
+	_ Puzzle[square1.Move] = (*square1.Square1)(nil)
+	_ Puzzle[clock.Move]   = (*clock.Clock)(nil)
+)
+
+// ApplyAll applies the moves in order, stopping at the first error.
+func ApplyAll[M any](p Puzzle[M], moves ...M) error {
+	for _, m := range moves {
+		if err := p.Apply(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Notated is a puzzle together with the parser for its notation, which hides
+// the move type from callers that only deal with text.
+type Notated interface {
+	ApplyNotation(input string) error
+	IsSolved() bool
+	State() string
+	RenderSVG(w io.Writer) error
+}
+
+type notated[M any] struct {
+	Puzzle[M]
+	parse func(string) ([]M, error)
+}
+
+func (n *notated[M]) ApplyNotation(input string) error {
+	moves, err := n.parse(input)
+	if err != nil {
+		return err
+	}
+	return ApplyAll(n.Puzzle, moves...)
+}
+
+// WithNotation pairs a puzzle with the parser of its notation.
+func WithNotation[M any](p Puzzle[M], parse func(string) ([]M, error)) Notated {
+	return &notated[M]{p, parse}
+}
+
+// Expanded turns a parser producing a Group tree into one producing moves.
+func Expanded(parse func(string) (*cube.Group, error)) func(string) ([]cube.Move, error) {
+	return func(input string) ([]cube.Move, error) {
+		group, err := parse(input)
+		if err != nil {
+			return nil, err
+		}
+		return group.Expand()
+	}
+}
+
+var constructors = map[string]func() Notated{
+	"mirror": func() Notated {
+		return WithNotation(cube.NewMirrorCube(cube.DefaultMirrorThickness), Expanded(cube.ParseNotation))
+	},
+	"pyraminx": func() Notated { return WithNotation(pyraminx.New(), Expanded(pyraminx.ParseNotation)) },
+	"skewb":    func() Notated { return WithNotation(skewb.New(), Expanded(skewb.ParseNotation)) },
+	"square1":  func() Notated { return WithNotation(square1.New(), square1.ParseNotation) },
+	"clock":    func() Notated { return WithNotation(clock.New(), clock.ParseNotation) },
+}
+
+// New returns a solved puzzle by name. Cubes of 2x2 and up are named by their
+// size, such as "3x3" or "7x7", and other puzzles by "mirror", "pyraminx",
+// "skewb", "square1" and "clock".
+func New(name string) (Notated, error) {
+	if newPuzzle, ok := constructors[name]; ok {
+		return newPuzzle(), nil
+	}
+
+	size, rest, ok := strings.Cut(name, "x")
+	n, err := strconv.Atoi(size)
+	if !ok || err != nil || rest != size || n < 2 {
+		return nil, ErrUnknownPuzzle
+	}
+	return WithNotation(cube.NewCube(n), Expanded(cube.ParseNotation)), nil
+}
+
+// Names returns the names accepted by New, with cubes up to 7x7.
+func Names() []string {
+	names := []string{"2x2", "3x3", "4x4", "5x5", "6x6", "7x7"}
+	for name := range constructors {
+		names = append(names, name)
+	}
+	slices.Sort(names[6:])
+	return names
+}
@@ -0,0 +1,246 @@
+// Package pyraminx models the Pyraminx puzzle and its WCA notation.
+package pyraminx
+
+import (
+	"errors"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/internal/geom"
+	"github.com/larssont/go-cubic/pkg/internal/svg"
+	"math"
+	"slices"
+)
+
+var (
+	ErrUnsupportedMove = errors.New("move not supported on pyraminx")
+	ErrWideMove        = errors.New("slices or wide on pyraminx")
+)
+
+// Dialect accepts the layer moves U, L, R, B and the tip moves u, l, r, b.
+var Dialect = cube.NewDialect(map[rune]int{
+	'U': 3, 'L': 3, 'R': 3, 'B': 3,
+	'u': 3, 'l': 3, 'r': 3, 'b': 3,
+}, validate)
+
+var (
+	vertexU = geom.Vec{X: 0, Y: 3, Z: 0}
+	vertexL = geom.Vec{X: -math.Sqrt(6), Y: -1, Z: math.Sqrt(2)}
+	vertexR = geom.Vec{X: math.Sqrt(6), Y: -1, Z: math.Sqrt(2)}
+	vertexB = geom.Vec{X: 0, Y: -1, Z: -2 * math.Sqrt(2)}
+)
+
+// Height of a face in the net, which has side 1
+var height = math.Sqrt(3) / 2
+
+const (
+	layer = 1.0 / 3 // Distance from the center to the layer cut along a vertex axis
+	tip   = 5.0 / 3 // Distance from the center to the tip cut along a vertex axis
+)
+
+// face describes a face by its corners, listed in the same order in space and
+// in the net so that the stickers can be mapped between them.
+type face struct {
+	color   rune
+	corners [3]geom.Vec
+	net     [3]svg.Point
+}
+
+var faces = [4]face{
+	{'g', [3]geom.Vec{vertexU, vertexL, vertexR}, [3]svg.Point{{X: 1, Y: 0}, {X: 0.5, Y: height}, {X: 1.5, Y: height}}},
+	{'r', [3]geom.Vec{vertexB, vertexU, vertexL}, [3]svg.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0.5, Y: height}}},
+	{'b', [3]geom.Vec{vertexU, vertexB, vertexR}, [3]svg.Point{{X: 1, Y: 0}, {X: 2, Y: 0}, {X: 1.5, Y: height}}},
+	{'y', [3]geom.Vec{vertexL, vertexR, vertexB}, [3]svg.Point{{X: 0.5, Y: height}, {X: 1.5, Y: height}, {X: 1, Y: 2 * height}}},
+}
+
+const stickersPerFace = 9
+
+type sticker struct {
+	slot    geom.Slot
+	polygon []svg.Point
+}
+
+var (
+	stickers []sticker
+	moves    = map[rune][]int{}
+)
+
+func init() {
+	for _, f := range faces {
+		stickers = append(stickers, faceStickers(f)...)
+	}
+
+	slots := make([]geom.Slot, len(stickers))
+	for i, s := range stickers {
+		slots[i] = s.slot
+	}
+
+	clockwise := -2 * math.Pi / 3
+	for _, v := range []struct {
+		layer, tip rune
+		vertex     geom.Vec
+	}{
+		{'U', 'u', vertexU},
+		{'L', 'l', vertexL},
+		{'R', 'r', vertexR},
+		{'B', 'b', vertexB},
+	} {
+		moves[v.layer] = geom.Permutation(slots, v.vertex, layer, clockwise)
+		moves[v.tip] = geom.Permutation(slots, v.vertex, tip, clockwise)
+	}
+}
+
+// faceStickers splits the face into 9 triangles, sorted row by row as they
+// appear in the net.
+func faceStickers(f face) []sticker {
+	type grid struct{ i, j int }
+
+	var triangles [][3]grid
+	for i := 0; i < 3; i++ {
+		for j := 0; i+j < 3; j++ {
+			triangles = append(triangles, [3]grid{{i, j}, {i + 1, j}, {i, j + 1}})
+			if i+j < 2 {
+				triangles = append(triangles, [3]grid{{i + 1, j}, {i, j + 1}, {i + 1, j + 1}})
+			}
+		}
+	}
+
+	weights := func(g grid) (float64, float64, float64) {
+		b, c := float64(g.i)/3, float64(g.j)/3
+		return 1 - b - c, b, c
+	}
+
+	normal := geom.Centroid(f.corners[:]...).Unit()
+	out := make([]sticker, 0, stickersPerFace)
+	for _, t := range triangles {
+		points := make([]geom.Vec, 3)
+		polygon := make([]svg.Point, 3)
+		for k, g := range t {
+			wa, wb, wc := weights(g)
+			points[k] = f.corners[0].Scale(wa).Add(f.corners[1].Scale(wb)).Add(f.corners[2].Scale(wc))
+			polygon[k] = svg.Point{
+				X: f.net[0].X*wa + f.net[1].X*wb + f.net[2].X*wc,
+				Y: f.net[0].Y*wa + f.net[1].Y*wb + f.net[2].Y*wc,
+			}
+		}
+		out = append(out, sticker{geom.Slot{Pos: geom.Centroid(points...), Normal: normal}, polygon})
+	}
+
+	row := func(s sticker) int {
+		top := math.Min(s.polygon[0].Y, math.Min(s.polygon[1].Y, s.polygon[2].Y))
+		return int(math.Round(top / (height / 3)))
+	}
+	centerX := func(s sticker) float64 {
+		return (s.polygon[0].X + s.polygon[1].X + s.polygon[2].X) / 3
+	}
+	slices.SortFunc(out, func(a, b sticker) int {
+		if ra, rb := row(a), row(b); ra != rb {
+			return ra - rb
+		}
+		if centerX(a) < centerX(b) {
+			return -1
+		}
+		return 1
+	})
+
+	return out
+}
+
+func validate(m *cube.Move) error {
+	if m.Slices > 0 || m.Wide {
+		return ErrWideMove
+	}
+	return nil
+}
+
+// ParseNotation parses Pyraminx notation into a Group tree.
+func ParseNotation(input string) (*cube.Group, error) {
+	return Dialect.ParseNotation(input)
+}
+
+type Pyraminx struct {
+	stickers []rune
+}
+
+// Faces holds the stickers of each face, row by row from the top of the net.
+type Faces struct {
+	Front []rune
+	Left  []rune
+	Right []rune
+	Down  []rune
+}
+
+func (f *Faces) All() [][]rune {
+	return [][]rune{f.Front, f.Left, f.Right, f.Down}
+}
+
+func New() *Pyraminx {
+	p := &Pyraminx{stickers: make([]rune, 0, len(stickers))}
+	for _, f := range faces {
+		for range stickersPerFace {
+			p.stickers = append(p.stickers, f.color)
+		}
+	}
+	return p
+}
+
+func (p *Pyraminx) Faces() *Faces {
+	face := func(i int) []rune {
+		return slices.Clone(p.stickers[i*stickersPerFace : (i+1)*stickersPerFace])
+	}
+
+	return &Faces{
+		Front: face(0),
+		Left:  face(1),
+		Right: face(2),
+		Down:  face(3),
+	}
+}
+
+func (p *Pyraminx) IsSolved() bool {
+	for _, f := range p.Faces().All() {
+		for _, color := range f {
+			if color != f[0] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (p *Pyraminx) ExecuteMove(move cube.Move) error {
+	perm, ok := moves[move.Operator]
+	if !ok {
+		return ErrUnsupportedMove
+	}
+	if err := validate(&move); err != nil {
+		return err
+	}
+
+	turns := move.Rotations % 3
+	if move.Inverted {
+		turns = (3 - turns) % 3
+	}
+
+	for range turns {
+		p.stickers = geom.Apply(p.stickers, perm)
+	}
+	return nil
+}
+
+// Apply executes a single move, see ExecuteMove.
+func (p *Pyraminx) Apply(move cube.Move) error {
+	return p.ExecuteMove(move)
+}
+
+// State returns the sticker colors of all faces in the order of Faces.
+func (p *Pyraminx) State() string {
+	return string(p.stickers)
+}
+
+func (p *Pyraminx) ExecuteMoves(moves ...cube.Move) error {
+	for _, m := range moves {
+		if err := p.ExecuteMove(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
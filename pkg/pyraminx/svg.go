@@ -0,0 +1,35 @@
+package pyraminx
+
+import (
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/internal/svg"
+	"io"
+)
+
+const (
+	svgScale  = 120.0
+	svgMargin = 10.0
+)
+
+// RenderSVG draws the net of the Pyraminx with the front face in the middle,
+// the left and right faces beside it and the down face below it.
+func (p *Pyraminx) RenderSVG(w io.Writer) error {
+	return p.RenderSVGWith(w, cube.Western)
+}
+
+// RenderSVGWith draws the net as RenderSVG does, in the fills and patterns of
+// the scheme.
+func (p *Pyraminx) RenderSVGWith(w io.Writer, scheme cube.Scheme) error {
+	style := svg.Style{Fills: scheme.Fills, Patterns: scheme.Patterns}
+	s := svg.NewWriter(w, 2*svgScale+2*svgMargin, 2*height*svgScale+2*svgMargin)
+
+	for i, st := range stickers {
+		points := make([]svg.Point, len(st.polygon))
+		for k, pt := range st.polygon {
+			points[k] = svg.Point{X: svgMargin + pt.X*svgScale, Y: svgMargin + pt.Y*svgScale}
+		}
+		s.Sticker(style, p.stickers[i], svg.Shrink(0.9, points...)...)
+	}
+
+	return s.Close()
+}
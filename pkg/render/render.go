@@ -0,0 +1,147 @@
+// Package render writes the cube as an HTML page, with its template and
+// stylesheet built into the package, so that it works from any directory.
+// Pages may also be written with a template of their own, which is given a
+// Page.
+package render
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/base64"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/solve"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+//go:embed cube.tmpl
+var cubeTemplate string
+
+//go:embed style.css
+var Style []byte
+
+var page = template.Must(template.New("cube").Parse(cubeTemplate))
+
+// Options configures HTML.
+type Options struct {
+	Standalone bool // Inlines the stylesheet, for a page without static/style.css
+	NetImage   bool // Adds the net of the cube as an image in a data URI
+
+	// Solution, if set, is listed under the cube a segment a line, with
+	// its annotations, see solve.Solution.Segments.
+	Solution *solve.Solution
+
+	// Moves are the moves that led to the cube, for Page.Moves and
+	// Page.Metrics.
+	Moves []cube.Move
+
+	// Template, if set, writes the page instead of the built-in one. It is
+	// executed with a Page, see ParseTemplate.
+	Template *template.Template
+}
+
+// Page is the data that templates of HTML are executed with. Its fields are
+// kept, so that templates of users go on working.
+type Page struct {
+	Faces     *cube.CubeFaces   // The stickers of every face, such as {{range .Faces.Up}}
+	Dimension int               // The number of stickers along an edge
+	Fills     map[string]string // The fill of every color, such as {{index .Fills "W"}}
+	Moves     string            // The moves in WCA notation
+	Metrics   Metrics           // The move counts of the moves
+	Style     template.CSS      // The stylesheet, if Standalone
+	Net       template.URL      // The net as a data URI, if NetImage
+	Steps     []Step            // The lines of the solution, if any
+}
+
+// Step is a line of the solution of a page.
+type Step struct {
+	Moves, Text string
+}
+
+// Metrics are the move counts of moves in the usual metrics. Rotations are
+// not counted as turns.
+type Metrics struct {
+	HTM       int // Half turn metric, counting slice turns twice
+	QTM       int // Quarter turn metric, counting half turns twice
+	STM       int // Slice turn metric
+	Rotations int
+}
+
+// Count returns the move counts of the moves.
+func Count(moves []cube.Move) Metrics {
+	var m Metrics
+	for _, move := range moves {
+		order := move.Order
+		if order == 0 {
+			order = 4
+		}
+		quarters := move.Rotations % order
+		quarters = min(quarters, order-quarters)
+		switch move.Operator {
+		case 'x', 'y', 'z':
+			m.Rotations++
+		case 'M', 'E', 'S':
+			m.HTM += 2
+			m.QTM += 2 * quarters
+			m.STM++
+		default:
+			m.HTM++
+			m.QTM += quarters
+			m.STM++
+		}
+	}
+	return m
+}
+
+// ParseTemplate parses the text of a template of a page, named name in
+// errors, such as its file. The template is executed with a Page.
+func ParseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Parse(text)
+}
+
+// HTML writes the cube as a page of its six faces. Unless Standalone, the
+// page links static/style.css next to it, see WriteStatic.
+func HTML(w io.Writer, c *cube.Cube, opts Options) error {
+	fills := map[string]string{}
+	for color := range c.Scheme().Fills {
+		fills[string(color)] = c.Scheme().Fill(color)
+	}
+
+	data := Page{
+		Faces:     c.Faces(),
+		Dimension: c.Dimension(),
+		Fills:     fills,
+		Moves:     cube.FormatMoves(opts.Moves),
+		Metrics:   Count(opts.Moves),
+	}
+	if opts.Standalone {
+		data.Style = template.CSS(Style)
+	}
+	if opts.NetImage {
+		var b bytes.Buffer
+		if err := c.RenderSVG(&b); err != nil {
+			return err
+		}
+		data.Net = template.URL("data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString(b.Bytes()))
+	}
+	if opts.Solution != nil {
+		for _, seg := range opts.Solution.Segments() {
+			data.Steps = append(data.Steps, Step{cube.FormatMoves(seg.Moves), seg.Text})
+		}
+	}
+	if opts.Template != nil {
+		return opts.Template.Execute(w, data)
+	}
+	return page.ExecuteTemplate(w, "cube", data)
+}
+
+// WriteStatic writes the stylesheet that pages of HTML link to into the
+// static directory of dir.
+func WriteStatic(dir string) error {
+	if err := os.MkdirAll(filepath.Join(dir, "static"), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "static", "style.css"), Style, 0o644)
+}
@@ -0,0 +1,152 @@
+package replay
+
+import (
+	_ "embed"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"html/template"
+	"io"
+	"time"
+)
+
+//go:embed player.tmpl
+var playerTemplate string
+
+var player = template.Must(template.New("player").Parse(playerTemplate))
+
+// stepTime is the time between the moves of an algorithm played by
+// RenderPlaybackHTML at normal speed.
+const stepTime = 500 * time.Millisecond
+
+// page is the data of the player template.
+type page struct {
+	Dimension int      `json:"dimension"`
+	Setup     string   `json:"setup"`
+	Frames    []string `json:"frames"`
+	Starts    []int64  `json:"starts,omitempty"`
+	Times     []int64  `json:"times"`
+	Easing    Easing   `json:"easing"`
+	Moves     []string `json:"moves"`
+	Time      int64    `json:"time"`
+	Result    string   `json:"result"`
+}
+
+// lane is a cube of the player, of its own solve.
+type lane struct {
+	Name string `json:"name"`
+	Data page   `json:"data"`
+}
+
+// RenderHTML writes a page that plays the solve back in real time, or scaled
+// by speed, with a running timer. The scramble is applied at once, and every
+// frame shows the cube after one more move.
+func (r *Replay) RenderHTML(w io.Writer, speed float64) error {
+	d, err := r.page()
+	if err != nil {
+		return err
+	}
+	return render(w, r.ID, speed, cube.Western, false, lane{Data: d})
+}
+
+// page returns the frames of the solve, at the times of its moves.
+func (r *Replay) page() (page, error) {
+	d, err := newPage(cube.Playback{Dimension: r.Dimension, Setup: r.Scramble, Alg: r.Moves}, stepTiming)
+	if err != nil {
+		return page{}, err
+	}
+	d.Starts, d.Times = nil, nil
+	for _, m := range r.Moves {
+		d.Times = append(d.Times, m.Time.Milliseconds())
+	}
+	d.Time = r.Result.Time.Milliseconds()
+	d.Result = r.Result.String()
+	return d, nil
+}
+
+// RenderPlaybackHTML writes a page that applies the setup of the playback at
+// once and then plays the algorithm a move every half second, or scaled by
+// speed, in the colors of its scheme.
+func RenderPlaybackHTML(w io.Writer, p cube.Playback, speed float64) error {
+	return RenderTimedPlaybackHTML(w, p, stepTiming, speed)
+}
+
+// RenderTimedPlaybackHTML writes a page as RenderPlaybackHTML does, with the
+// moves taking the times of the timing, and the stickers blending from the
+// cube before a move to the cube after it by its easing.
+func RenderTimedPlaybackHTML(w io.Writer, p cube.Playback, t Timing, speed float64) error {
+	d, err := newPage(p, t)
+	if err != nil {
+		return err
+	}
+	return render(w, cube.FormatMoves(p.Alg), speed, p.Scheme, false, lane{Data: d})
+}
+
+// newPage returns the frames of a playback, with the moves timed by t.
+func newPage(p cube.Playback, t Timing) (page, error) {
+	frames, err := p.States()
+	if err != nil {
+		return page{}, err
+	}
+	d := page{
+		Dimension: p.Dimension,
+		Setup:     cube.FormatMoves(p.Setup),
+		Frames:    frames,
+		Easing:    t.Easing,
+	}
+	starts, ends := t.Schedule(p.Alg)
+	for i, m := range p.Alg {
+		d.Starts = append(d.Starts, starts[i].Milliseconds())
+		d.Times = append(d.Times, ends[i].Milliseconds())
+		d.Moves = append(d.Moves, m.Notation())
+	}
+	if len(ends) > 0 {
+		d.Time = ends[len(ends)-1].Milliseconds()
+	}
+	return d, nil
+}
+
+// render writes the player of the lanes, side by side, with the gap between
+// the first two if gap is set.
+func render(w io.Writer, title string, speed float64, scheme cube.Scheme, gap bool, lanes ...lane) error {
+	if speed <= 0 {
+		speed = 1
+	}
+
+	palette := map[string]string{}
+	if scheme.Fills == nil {
+		scheme = cube.Western
+	}
+	for color, fill := range scheme.Fills {
+		palette[string(color)] = fill
+	}
+
+	return player.Execute(w, struct {
+		Title     string
+		Faces     []string
+		Speed     float64
+		Dimension int
+		Lanes     []lane
+		Gap       bool
+		Palette   map[string]string
+	}{
+		Title:     title,
+		Faces:     []string{"up", "left", "front", "right", "back", "down"},
+		Speed:     speed,
+		Dimension: lanes[0].Data.Dimension,
+		Lanes:     lanes,
+		Gap:       gap,
+		Palette:   palette,
+	})
+}
+
+// String returns the result as a time with two decimals, with "+" after
+// times with a two second penalty, or "DNF".
+func (r Result) String() string {
+	switch r.Penalty {
+	case DNF:
+		return "DNF"
+	case PlusTwo:
+		return fmt.Sprintf("%.2f+", r.Time.Seconds()+2)
+	}
+	return fmt.Sprintf("%.2f", r.Time.Seconds())
+}
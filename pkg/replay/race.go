@@ -0,0 +1,148 @@
+package replay
+
+import (
+	"github.com/larssont/go-cubic/pkg/cube"
+	"io"
+	"time"
+)
+
+// Race races a live solve against an opponent: the replay of an earlier
+// solve of the same scramble, such as from a store, or a Ghost of a solution
+// played at a pace. The live solve is timed by the SmartTimer of the race,
+// which the moves of the cube go to.
+type Race struct {
+	*SmartTimer
+	Opponent *Replay
+}
+
+// NewRace returns a race against the replay, of a solve of its scramble.
+func NewRace(opponent *Replay) (*Race, error) {
+	t, err := NewSmartTimer(opponent.Dimension, opponent.Scramble)
+	if err != nil {
+		return nil, err
+	}
+	return &Race{SmartTimer: t, Opponent: opponent}, nil
+}
+
+// Ghost returns a replay of a solution of the scramble played with the
+// timing, such as DefaultTiming.AtTPS(solution, 5) for the solution of a
+// solver at five moves a second, every move timed at its end.
+func Ghost(dimension int, scramble, solution []cube.Move, t Timing) (*Replay, error) {
+	c := cube.NewCube(dimension)
+	if err := c.ExecuteMoves(scramble...); err != nil {
+		return nil, err
+	}
+	if err := c.ExecuteMoves(solution...); err != nil {
+		return nil, err
+	}
+	_, ends := t.Schedule(solution)
+	r := &Replay{
+		ID:        newID(),
+		Dimension: dimension,
+		Scramble:  scramble,
+		Result:    Result{Solved: c.IsSolved()},
+		Recorded:  time.Now(),
+	}
+	if !r.Result.Solved {
+		r.Result.Penalty = DNF
+	}
+	for i, m := range solution {
+		m.Time, m.Timed = ends[i], true
+		r.Moves = append(r.Moves, m)
+	}
+	if len(ends) > 0 {
+		r.Result.Time = ends[len(ends)-1]
+	}
+	return r, nil
+}
+
+// Standing is a race at a time since its start.
+type Standing struct {
+	Elapsed                  time.Duration
+	You, Opponent            *cube.Cube
+	YourMoves, OpponentMoves int
+	YouDone, OpponentDone    bool
+
+	// Gap is how far the live solve is behind the opponent, or ahead if
+	// negative: how much later its last move came than the move of the same
+	// number of the opponent, and at least how long ago the opponent
+	// finished. Once the live solve is done, it is the difference of the
+	// times.
+	Gap time.Duration
+}
+
+// Standing returns the race at now: nothing has happened before the first
+// move of the live solve, which starts the race, and the opponent plays as
+// far as the time since then.
+func (r *Race) Standing(now time.Time) (Standing, error) {
+	var s Standing
+	var yours []cube.Move
+	switch {
+	case r.replay != nil:
+		yours = r.replay.Moves
+		s.Elapsed, s.YouDone = r.replay.Result.Time, true
+	case !r.recorder.start.IsZero():
+		yours = r.recorder.moves
+		s.Elapsed = now.Sub(r.recorder.start)
+	}
+	s.YourMoves = len(yours)
+
+	opponent := r.Opponent
+	for _, m := range opponent.Moves {
+		if m.Time > s.Elapsed {
+			break
+		}
+		s.OpponentMoves++
+	}
+	s.OpponentDone = s.Elapsed >= opponent.Result.Time && s.Elapsed > 0
+
+	var err error
+	if s.You, err = cubeAfter(opponent, yours); err != nil {
+		return Standing{}, err
+	}
+	if s.Opponent, err = cubeAfter(opponent, opponent.Moves[:s.OpponentMoves]); err != nil {
+		return Standing{}, err
+	}
+
+	switch {
+	case s.YouDone:
+		s.Gap = s.Elapsed - opponent.Result.Time
+	case s.YourMoves > len(opponent.Moves):
+		s.Gap = s.Elapsed - opponent.Result.Time
+	case s.YourMoves > 0:
+		s.Gap = yours[s.YourMoves-1].Time - opponent.Moves[s.YourMoves-1].Time
+	}
+	if !s.YouDone && s.OpponentDone {
+		s.Gap = max(s.Gap, s.Elapsed-opponent.Result.Time)
+	}
+	return s, nil
+}
+
+// cubeAfter returns the cube of the scramble of the replay after the moves.
+func cubeAfter(r *Replay, moves []cube.Move) (*cube.Cube, error) {
+	c := cube.NewCube(r.Dimension)
+	if err := c.ExecuteMoves(r.Scramble...); err != nil {
+		return nil, err
+	}
+	if err := c.ExecuteMoves(moves...); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// RenderRaceHTML writes a page that plays two solves of the same scramble
+// side by side from the same start, such as a race and its opponent, with
+// their timers and the gap of the first behind the second, or scaled by
+// speed.
+func RenderRaceHTML(w io.Writer, you, opponent *Replay, speed float64) error {
+	var lanes []lane
+	for _, r := range []*Replay{you, opponent} {
+		d, err := r.page()
+		if err != nil {
+			return err
+		}
+		lanes = append(lanes, lane{Data: d})
+	}
+	lanes[0].Name, lanes[1].Name = "You", "Opponent"
+	return render(w, "race", speed, cube.Western, true, lanes...)
+}
@@ -0,0 +1,128 @@
+// Package replay records timed solves, from an interactive simulator or a
+// smart cube, and stores them for later analysis and rendering.
+package replay
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"time"
+)
+
+var (
+	ErrNotStarted = errors.New("recording not started")
+	ErrVersion    = errors.New("unsupported replay version")
+)
+
+// Version is the version of the replay format written by this package.
+const Version = 1
+
+// Penalty is a WCA penalty of a solve.
+type Penalty string
+
+const (
+	NoPenalty Penalty = ""
+	PlusTwo   Penalty = "+2"
+	DNF       Penalty = "DNF"
+)
+
+// Result is the outcome of a solve.
+type Result struct {
+	Time    time.Duration
+	Solved  bool
+	Penalty Penalty
+}
+
+// Counted returns the time of the result with its penalty, and false if the
+// solve does not count, as a DNF or unsolved.
+func (r Result) Counted() (time.Duration, bool) {
+	switch {
+	case !r.Solved || r.Penalty == DNF:
+		return 0, false
+	case r.Penalty == PlusTwo:
+		return r.Time + 2*time.Second, true
+	}
+	return r.Time, true
+}
+
+// Replay is a recorded solve. Moves carry their time since the start of the
+// solve.
+type Replay struct {
+	ID        string
+	Dimension int
+	Scramble  []cube.Move
+	Moves     []cube.Move
+	Result    Result
+	Recorded  time.Time
+}
+
+// newID returns a random identifier of 16 hex digits.
+func newID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// Recorder records the moves of a solve as they are made.
+type Recorder struct {
+	dimension int
+	scramble  []cube.Move
+	moves     []cube.Move
+	start     time.Time
+	now       func() time.Time
+}
+
+// NewRecorder returns a recorder for a solve of a cube of the given dimension
+// after the scramble.
+func NewRecorder(dimension int, scramble []cube.Move) *Recorder {
+	return &Recorder{dimension: dimension, scramble: scramble, now: time.Now}
+}
+
+// Start starts the timer of the solve. Without a call to Start the timer
+// starts with the first move.
+func (r *Recorder) Start() {
+	r.start = r.now()
+}
+
+// Move records a move at the current time.
+func (r *Recorder) Move(m cube.Move) {
+	if r.start.IsZero() {
+		r.Start()
+	}
+	m.Time = r.now().Sub(r.start)
+	m.Timed = true
+	r.moves = append(r.moves, m)
+}
+
+// Finish stops the timer and returns the replay, which is solved if the moves
+// solve the scramble.
+func (r *Recorder) Finish() (*Replay, error) {
+	if r.start.IsZero() {
+		return nil, ErrNotStarted
+	}
+	end := r.now()
+
+	c := cube.NewCube(r.dimension)
+	if err := c.ExecuteMoves(r.scramble...); err != nil {
+		return nil, err
+	}
+	if err := c.ExecuteMoves(r.moves...); err != nil {
+		return nil, err
+	}
+
+	penalty := NoPenalty
+	if !c.IsSolved() {
+		penalty = DNF
+	}
+	return &Replay{
+		ID:        newID(),
+		Dimension: r.dimension,
+		Scramble:  r.scramble,
+		Moves:     r.moves,
+		Result:    Result{Time: end.Sub(r.start), Solved: c.IsSolved(), Penalty: penalty},
+		Recorded:  r.start,
+	}, nil
+}
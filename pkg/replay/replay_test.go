@@ -0,0 +1,26 @@
+package replay
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResultCounted(t *testing.T) {
+	tests := []struct {
+		result  Result
+		want    time.Duration
+		counted bool
+	}{
+		{Result{Time: 9 * time.Second, Solved: true}, 9 * time.Second, true},
+		{Result{Time: 9 * time.Second, Solved: true, Penalty: PlusTwo}, 11 * time.Second, true},
+		{Result{Time: 9 * time.Second, Solved: true, Penalty: DNF}, 0, false},
+		{Result{Time: 9 * time.Second, Penalty: DNF}, 0, false},
+		{Result{Time: 9 * time.Second}, 0, false},
+	}
+	for _, tt := range tests {
+		got, counted := tt.result.Counted()
+		if got != tt.want || counted != tt.counted {
+			t.Errorf("%+v.Counted() = %v, %t, want %v, %t", tt.result, got, counted, tt.want, tt.counted)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package replay
+
+import (
+	"github.com/larssont/go-cubic/pkg/cube"
+)
+
+// Phase is where a SmartTimer is in a solve.
+type Phase int
+
+const (
+	Scrambling Phase = iota // Waiting for the cube to reach the scrambled state
+	Ready                   // Scrambled, waiting for the first move of the solve
+	Solving                 // Timing the solve
+	Done                    // Solved, or stopped
+)
+
+func (p Phase) String() string {
+	switch p {
+	case Scrambling:
+		return "scrambling"
+	case Ready:
+		return "ready"
+	case Solving:
+		return "solving"
+	}
+	return "done"
+}
+
+// SmartTimer times a solve on a cube that reports its moves as they are
+// made, such as a smart cube: it follows the cube while it is scrambled,
+// starts the timer on the first move once the cube is in the state of the
+// scramble, whichever moves brought it there, and stops it on the move that
+// solves the cube, recording every move of the solve with its time.
+type SmartTimer struct {
+	scrambled *cube.Cube
+	cube      *cube.Cube
+	recorder  *Recorder
+	phase     Phase
+	replay    *Replay
+}
+
+// NewSmartTimer returns a timer of a solve of the scramble, on a cube of the
+// dimension that is solved to start with.
+func NewSmartTimer(dimension int, scramble []cube.Move) (*SmartTimer, error) {
+	scrambled := cube.NewCube(dimension)
+	if err := scrambled.ExecuteMoves(scramble...); err != nil {
+		return nil, err
+	}
+	t := &SmartTimer{
+		scrambled: scrambled,
+		cube:      cube.NewCube(dimension),
+		recorder:  NewRecorder(dimension, scramble),
+	}
+	t.phase = t.scrambling()
+	return t, nil
+}
+
+// scrambling returns the phase of the cube before the solve.
+func (t *SmartTimer) scrambling() Phase {
+	if d, err := cube.Diff(t.cube, t.scrambled); err == nil && d.Equal() {
+		return Ready
+	}
+	return Scrambling
+}
+
+// Phase returns where the timer is in the solve.
+func (t *SmartTimer) Phase() Phase {
+	return t.phase
+}
+
+// Move applies a move reported by the cube, and returns the phase after it.
+// A move of a cube that is ready starts the timer, and a move that solves
+// the cube stops it. Moves after the solve are ignored.
+func (t *SmartTimer) Move(m cube.Move) (Phase, error) {
+	if t.phase == Done {
+		return t.phase, nil
+	}
+	if err := t.cube.ExecuteMoves(m); err != nil {
+		return t.phase, err
+	}
+	if t.phase == Scrambling {
+		t.phase = t.scrambling()
+		return t.phase, nil
+	}
+
+	t.recorder.Move(m)
+	t.phase = Solving
+	if t.cube.IsSolved() {
+		return t.Stop()
+	}
+	return t.phase, nil
+}
+
+// Stop stops a solve before the cube is solved, as a DNF, or does nothing
+// if the timer has not started. It returns the phase after it.
+func (t *SmartTimer) Stop() (Phase, error) {
+	if t.phase != Solving {
+		return t.phase, nil
+	}
+	r, err := t.recorder.Finish()
+	if err != nil {
+		return t.phase, err
+	}
+	t.replay, t.phase = r, Done
+	return t.phase, nil
+}
+
+// Replay returns the recorded solve once the timer is done, with every move
+// timed from the first one, or nil before.
+func (t *SmartTimer) Replay() *Replay {
+	return t.replay
+}
@@ -0,0 +1,149 @@
+package replay
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+var ErrNotFound = errors.New("replay not found")
+
+// file is the on-disk format of a replay. Moves are stored as notation, the
+// solution with a timestamp after every move as read by
+// cube.ParseTimedNotation, and durations in milliseconds.
+type file struct {
+	Version   int    `json:"version"`
+	ID        string `json:"id"`
+	Dimension int    `json:"dimension"`
+	Scramble  string `json:"scramble"`
+	Solution  string `json:"solution"`
+	Result    struct {
+		Time    int64   `json:"time_ms"`
+		Solved  bool    `json:"solved"`
+		Penalty Penalty `json:"penalty,omitempty"`
+	} `json:"result"`
+	Recorded time.Time `json:"recorded"`
+}
+
+func (r *Replay) MarshalJSON() ([]byte, error) {
+	f := file{
+		Version:   Version,
+		ID:        r.ID,
+		Dimension: r.Dimension,
+		Scramble:  cube.FormatMoves(r.Scramble),
+		Solution:  cube.FormatTimedMoves(r.Moves),
+		Recorded:  r.Recorded,
+	}
+	f.Result.Time = r.Result.Time.Milliseconds()
+	f.Result.Solved = r.Result.Solved
+	f.Result.Penalty = r.Result.Penalty
+	return json.Marshal(f)
+}
+
+func (r *Replay) UnmarshalJSON(data []byte) error {
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+	if f.Version != Version {
+		return ErrVersion
+	}
+
+	scramble, err := expand(cube.ParseNotation(f.Scramble))
+	if err != nil {
+		return err
+	}
+	moves, err := expand(cube.ParseTimedNotation(f.Solution))
+	if err != nil {
+		return err
+	}
+
+	*r = Replay{
+		ID:        f.ID,
+		Dimension: f.Dimension,
+		Scramble:  scramble,
+		Moves:     moves,
+		Result: Result{
+			Time:    time.Duration(f.Result.Time) * time.Millisecond,
+			Solved:  f.Result.Solved,
+			Penalty: f.Result.Penalty,
+		},
+		Recorded: f.Recorded,
+	}
+	return nil
+}
+
+func expand(group *cube.Group, err error) ([]cube.Move, error) {
+	if err != nil {
+		return nil, err
+	}
+	return group.Expand()
+}
+
+// Store keeps replays as JSON files named by their ID in a directory.
+type Store struct {
+	dir string
+}
+
+// NewStore returns a store in the directory, which is created if needed.
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Store{dir: dir}, nil
+}
+
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// Save writes the replay, replacing any replay with the same ID.
+func (s *Store) Save(r *Replay) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path(r.ID), data, 0o644)
+}
+
+// Load reads the replay with the ID.
+func (s *Store) Load(id string) (*Replay, error) {
+	if strings.ContainsAny(id, `/\`) {
+		return nil, ErrNotFound
+	}
+	data, err := os.ReadFile(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Replay{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// IDs returns the IDs of the stored replays in sorted order.
+func (s *Store) IDs() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, e := range entries {
+		if id, ok := strings.CutSuffix(e.Name(), ".json"); ok && !e.IsDir() {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
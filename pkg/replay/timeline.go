@@ -0,0 +1,158 @@
+package replay
+
+import (
+	"encoding/json"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"io"
+	"time"
+)
+
+// Keyframe is a move of a timeline: when it starts and ends, and how it
+// turns the cube.
+type Keyframe struct {
+	Start, End time.Duration
+	cube.Turn
+}
+
+// Timeline is the animation of a playback by a timing, move by move, for
+// engines that tween the turns rather than step from cube to cube, such as
+// three.js scenes or GIF encoders.
+type Timeline struct {
+	Playback  cube.Playback
+	Easing    Easing
+	Keyframes []Keyframe
+}
+
+// NewTimeline returns the timeline of the playback played with the timing.
+func NewTimeline(p cube.Playback, t Timing) (*Timeline, error) {
+	c, err := p.Cube(0)
+	if err != nil {
+		return nil, err
+	}
+	tl := &Timeline{Playback: p, Easing: t.Easing}
+	starts, ends := t.Schedule(p.Alg)
+	for i, m := range p.Alg {
+		turn, err := c.TurnOf(m)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.ExecuteMove(m); err != nil {
+			return nil, err
+		}
+		tl.Keyframes = append(tl.Keyframes, Keyframe{starts[i], ends[i], turn})
+	}
+	return tl, nil
+}
+
+// Duration returns the time of the whole timeline.
+func (tl *Timeline) Duration() time.Duration {
+	if len(tl.Keyframes) == 0 {
+		return 0
+	}
+	return tl.Keyframes[len(tl.Keyframes)-1].End
+}
+
+// Frame is the cube at a moment of a timeline: the cube after the moves that
+// have ended, and the turn under way, if any, with how far it has turned.
+type Frame struct {
+	Cube  *cube.Cube
+	Move  int       // The number of moves that have ended
+	Turn  *Keyframe // The move under way, or nil between moves
+	Angle float64   // The angle the pieces of the Turn have turned so far, eased
+}
+
+// At returns the frame of the timeline at the time from its start.
+func (tl *Timeline) At(at time.Duration) (Frame, error) {
+	f := Frame{}
+	for f.Move < len(tl.Keyframes) && tl.Keyframes[f.Move].End <= at {
+		f.Move++
+	}
+	c, err := tl.Playback.Cube(f.Move)
+	if err != nil {
+		return Frame{}, err
+	}
+	f.Cube = c
+	if f.Move < len(tl.Keyframes) && tl.Keyframes[f.Move].Start <= at {
+		k := &tl.Keyframes[f.Move]
+		f.Turn = k
+		f.Angle = k.Angle * tl.Easing.Ease(k.progress(at))
+	}
+	return f, nil
+}
+
+// progress returns the share of the time of the keyframe that has passed at
+// the time, from 0 to 1.
+func (k *Keyframe) progress(at time.Duration) float64 {
+	span := k.End - k.Start
+	if span <= 0 || at >= k.End {
+		return 1
+	}
+	return max(0, float64(at-k.Start)/float64(span))
+}
+
+// timelineJSON is a timeline as WriteJSON writes it, with times in
+// milliseconds and stickers as indices into the states of Cube.State.
+type timelineJSON struct {
+	Dimension int            `json:"dimension"`
+	Setup     string         `json:"setup"`
+	State     string         `json:"state"`
+	Easing    Easing         `json:"easing"`
+	Duration  int64          `json:"duration"`
+	Keyframes []keyframeJSON `json:"keyframes"`
+}
+
+type keyframeJSON struct {
+	Move     string          `json:"move"`
+	Start    int64           `json:"start"`
+	End      int64           `json:"end"`
+	Axis     string          `json:"axis"`
+	Layers   [2]int          `json:"layers"`
+	Angle    float64         `json:"angle"`
+	Pieces   []cube.Position `json:"pieces"`
+	Stickers []int           `json:"stickers"`
+	State    string          `json:"state"`
+}
+
+// WriteJSON writes the timeline as JSON for engines in other languages: the
+// state of the cube after the setup, and for every move its times in
+// milliseconds, axis, layers, angle in degrees, the positions of the pieces
+// it turns and the indices of their stickers in the state before it, and the
+// state after it.
+func (tl *Timeline) WriteJSON(w io.Writer) error {
+	c, err := tl.Playback.Cube(0)
+	if err != nil {
+		return err
+	}
+	n := tl.Playback.Dimension * tl.Playback.Dimension
+	out := timelineJSON{
+		Dimension: tl.Playback.Dimension,
+		Setup:     cube.FormatMoves(tl.Playback.Setup),
+		State:     c.State(),
+		Easing:    tl.Easing,
+		Duration:  tl.Duration().Milliseconds(),
+		Keyframes: []keyframeJSON{},
+	}
+	for _, k := range tl.Keyframes {
+		if err := c.ExecuteMove(k.Move); err != nil {
+			return err
+		}
+		kj := keyframeJSON{
+			Move:     k.Move.Notation(),
+			Start:    k.Start.Milliseconds(),
+			End:      k.End.Milliseconds(),
+			Axis:     string(k.Axis),
+			Layers:   [2]int{k.MinLayer, k.MaxLayer},
+			Angle:    k.Angle,
+			Pieces:   append([]cube.Position{}, k.Pieces...),
+			Stickers: []int{},
+			State:    c.State(),
+		}
+		for _, s := range k.Stickers {
+			kj.Stickers = append(kj.Stickers, int(s.Face)*n+s.Index)
+		}
+		out.Keyframes = append(out.Keyframes, kj)
+	}
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(out)
+}
@@ -0,0 +1,140 @@
+package replay
+
+import (
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"slices"
+	"time"
+)
+
+var ErrEasing = errors.New("unknown easing")
+
+// Easing is how a player eases a move from the cube before it to the cube
+// after it.
+type Easing string
+
+const (
+	EaseNone   Easing = "none"        // The cube changes at the end of the move
+	EaseLinear Easing = "linear"      // The stickers blend at an even pace
+	EaseInOut  Easing = "ease-in-out" // The stickers blend slowly at first, fast in the middle and slowly at the end, as a hand turns
+)
+
+// Easings are the easings of players.
+var Easings = []Easing{EaseNone, EaseLinear, EaseInOut}
+
+// ParseEasing returns the easing of the name, such as linear.
+func ParseEasing(name string) (Easing, error) {
+	if !slices.Contains(Easings, Easing(name)) {
+		return "", fmt.Errorf("%q: %w", name, ErrEasing)
+	}
+	return Easing(name), nil
+}
+
+// Ease returns the share of the way from the cube before a move to the cube
+// after it at the share of the time of the move that has passed, both from 0
+// to 1, as the players ease it.
+func (e Easing) Ease(progress float64) float64 {
+	progress = min(max(progress, 0), 1)
+	switch e {
+	case EaseLinear:
+		return progress
+	case EaseInOut:
+		if progress < 0.5 {
+			return 2 * progress * progress
+		}
+		return 1 - 2*(1-progress)*(1-progress)
+	}
+	if progress < 1 {
+		return 0
+	}
+	return 1
+}
+
+// Timing is how long the moves of an animation take, by kind, so that
+// playback looks like a person turning the cube rather than a metronome.
+// Wide moves take the time of face turns.
+type Timing struct {
+	QuarterTurn time.Duration // Of a face, such as R
+	Slice       time.Duration // Of a quarter of a middle slice, such as M
+	Rotation    time.Duration // Of a quarter rotation of the whole cube, such as y
+
+	// HalfTurn is the time of a half turn, such as R2, as a factor of that of
+	// a quarter turn of the same kind. Hands turn half turns in one motion,
+	// so it is less than 2.
+	HalfTurn float64
+
+	Pause  time.Duration // Between moves
+	Easing Easing
+}
+
+// DefaultTiming looks like a fluent demonstration of an algorithm, about
+// four turns a second.
+var DefaultTiming = Timing{
+	QuarterTurn: 200 * time.Millisecond,
+	Slice:       250 * time.Millisecond,
+	Rotation:    300 * time.Millisecond,
+	HalfTurn:    1.6,
+	Pause:       50 * time.Millisecond,
+	Easing:      EaseInOut,
+}
+
+// stepTiming plays a move every stepTime without easing, as the player did
+// before timings.
+var stepTiming = Timing{QuarterTurn: stepTime, Slice: stepTime, Rotation: stepTime, HalfTurn: 1, Easing: EaseNone}
+
+// Duration returns the time of a move, without the pause after it.
+func (t Timing) Duration(m cube.Move) time.Duration {
+	quarter := t.QuarterTurn
+	switch m.Operator {
+	case 'x', 'y', 'z':
+		quarter = t.Rotation
+	case 'M', 'E', 'S':
+		quarter = t.Slice
+	}
+	order := m.Order
+	if order == 0 {
+		order = 4
+	}
+	turns := m.Rotations % order
+	turns = min(turns, order-turns)
+	if turns <= 1 {
+		return quarter
+	}
+	half := t.HalfTurn
+	if half <= 0 {
+		half = 2
+	}
+	return time.Duration(float64(quarter) * half * float64(turns) / 2)
+}
+
+// Schedule returns when every move starts and ends, from the start of the
+// animation.
+func (t Timing) Schedule(moves []cube.Move) (starts, ends []time.Duration) {
+	var at time.Duration
+	for i, m := range moves {
+		if i > 0 {
+			at += t.Pause
+		}
+		starts = append(starts, at)
+		at += t.Duration(m)
+		ends = append(ends, at)
+	}
+	return starts, ends
+}
+
+// AtTPS returns the timing scaled so that the moves play at tps moves a
+// second, pauses included, keeping the pace of the kinds of moves to one
+// another, such as to mimic the turning speed of a solver.
+func (t Timing) AtTPS(moves []cube.Move, tps float64) Timing {
+	_, ends := t.Schedule(moves)
+	if len(ends) == 0 || ends[len(ends)-1] <= 0 || tps <= 0 {
+		return t
+	}
+	want := time.Duration(float64(len(moves)) / tps * float64(time.Second))
+	scale := float64(want) / float64(ends[len(ends)-1])
+	for _, d := range []*time.Duration{&t.QuarterTurn, &t.Slice, &t.Rotation, &t.Pause} {
+		*d = time.Duration(float64(*d) * scale)
+	}
+	return t
+}
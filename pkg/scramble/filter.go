@@ -0,0 +1,160 @@
+package scramble
+
+import (
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/solve"
+	"math/rand/v2"
+	"strings"
+)
+
+var (
+	ErrFiltered = errors.New("no scramble passed the filters")
+	ErrEvent    = errors.New("unknown WCA event")
+)
+
+// Filter tells scrambles fit to use from those to draw again, such as those
+// that solve in a few moves. Custom filters are a name, under which Filtered
+// counts the scrambles they reject, and a predicate.
+type Filter struct {
+	Name   string
+	Accept func(size int, moves []cube.Move) (bool, error)
+}
+
+// MinOptimal rejects 2x2 and 3x3 scrambles whose state solves in fewer than
+// n moves, counting half turns as one. The search is optimal, so n much above
+// 12 takes long on the 3x3. Other sizes pass.
+func MinOptimal(n int) Filter {
+	return Filter{
+		Name: fmt.Sprintf("min-optimal-%d", n),
+		Accept: func(size int, moves []cube.Move) (bool, error) {
+			if size != 2 && size != 3 {
+				return true, nil
+			}
+			s, err := solve.StateOf(moves...)
+			if err != nil {
+				return false, err
+			}
+			if size == 2 {
+				d, err := solve.PocketDistance(s)
+				return d >= n, err
+			}
+			_, err = solve.SolveWithin(s, n-1, solve.SolveOptions{})
+			if errors.Is(err, solve.ErrNoSolution) {
+				return true, nil
+			}
+			return false, err
+		},
+	}
+}
+
+// NoSolvedCross rejects scrambles that leave the edges of any face solved
+// against the colors of the centers. The 2x2, without edges, passes.
+func NoSolvedCross() Filter {
+	return Filter{
+		Name: "no-solved-cross",
+		Accept: func(size int, moves []cube.Move) (bool, error) {
+			if size < 3 {
+				return true, nil
+			}
+			c := cube.NewCube(size)
+			if err := c.ExecuteMoves(moves...); err != nil {
+				return false, err
+			}
+			for f := range cube.Face(6) {
+				if solved, _ := c.IsCrossSolved(f); solved {
+					return false, nil
+				}
+			}
+			return true, nil
+		},
+	}
+}
+
+// wcaMinimums holds the fewest moves that a scramble of the WCA events of
+// cubes must take to solve, by event ID, or 0 where the regulations set
+// none.
+var wcaMinimums = map[string]int{
+	"222": 4, "333": 2, "333oh": 2, "333bf": 2, "333fm": 2, "333mbf": 2,
+	"444": 0, "444bf": 0, "555": 0, "555bf": 0, "666": 0, "777": 0,
+}
+
+// WCA returns the filters that the regulations set for scrambles of an event
+// of cubes, by its ID such as "333" or "222".
+func WCA(event string) ([]Filter, error) {
+	n, ok := wcaMinimums[strings.ToLower(event)]
+	if !ok {
+		return nil, fmt.Errorf("%q: %w", event, ErrEvent)
+	}
+	if n == 0 {
+		return nil, nil
+	}
+	return []Filter{MinOptimal(n)}, nil
+}
+
+// defaultMaxTries bounds the scrambles that Filtered draws by default.
+const defaultMaxTries = 1000
+
+// FilterOptions configures Filtered.
+type FilterOptions struct {
+	MaxTries int          // Scrambles to draw before giving up, 1000 by default
+	Rand     *rand.Rand   // Source of the scrambles, or the global one if nil
+	Stats    *FilterStats // If set, counts the scrambles drawn and rejected
+}
+
+// FilterStats counts the scrambles of one or more calls to Filtered.
+type FilterStats struct {
+	Drawn    int
+	Rejected map[string]int // By the name of the first filter to reject them
+}
+
+// RejectionRate returns the share of the scrambles drawn that the filter
+// rejected.
+func (s *FilterStats) RejectionRate(name string) float64 {
+	if s.Drawn == 0 {
+		return 0
+	}
+	return float64(s.Rejected[name]) / float64(s.Drawn)
+}
+
+// Filtered returns a scramble of Cube that every filter accepts, in order,
+// drawing again as long as one rejects it. It returns ErrFiltered, naming the
+// filter that rejected the last one, if none passes within MaxTries.
+func Filtered(size int, filters []Filter, opts FilterOptions) ([]cube.Move, error) {
+	if opts.MaxTries <= 0 {
+		opts.MaxTries = defaultMaxTries
+	}
+	stats := opts.Stats
+	if stats == nil {
+		stats = &FilterStats{}
+	}
+	if stats.Rejected == nil {
+		stats.Rejected = map[string]int{}
+	}
+
+	rejected := ""
+	for range opts.MaxTries {
+		moves, err := Cube(size, opts.Rand)
+		if err != nil {
+			return nil, err
+		}
+		stats.Drawn++
+		rejected = ""
+		for _, f := range filters {
+			ok, err := f.Accept(size, moves)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", f.Name, err)
+			}
+			if !ok {
+				rejected = f.Name
+				stats.Rejected[f.Name]++
+				break
+			}
+		}
+		if rejected == "" {
+			return moves, nil
+		}
+	}
+	return nil, fmt.Errorf("%d tries, last rejected by %s: %w", opts.MaxTries, rejected, ErrFiltered)
+}
@@ -0,0 +1,105 @@
+package scramble
+
+import (
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/solve"
+	"math/rand/v2"
+	"strings"
+)
+
+var (
+	ErrFMCNotation = errors.New("not allowed in fewest moves solutions")
+	ErrFMCLength   = errors.New("fewest moves solutions have at most 80 moves")
+	ErrFMCUnsolved = errors.New("solution does not solve the scramble")
+)
+
+// FMCMaxMoves is the longest allowed fewest moves solution.
+const FMCMaxMoves = 80
+
+// fmcPadding starts and ends every fewest moves scramble, so that the
+// scramble cannot be short.
+var fmcPadding = []cube.Move{
+	{Operator: 'R', Rotations: 1, Inverted: true},
+	{Operator: 'U', Rotations: 1, Inverted: true},
+	{Operator: 'F', Rotations: 1},
+}
+
+// FMC returns a fewest moves scramble of a uniformly random state, which
+// starts and ends with R' U' F as the WCA requires. The moves in between
+// cancel with neither. A nil rng uses the global source of math/rand/v2.
+func FMC(rng *rand.Rand) ([]cube.Move, error) {
+	padding, err := solve.StateOf(fmcPadding...)
+	if err != nil {
+		return nil, err
+	}
+	undo := padding.Inverse()
+
+	for {
+		target := solve.RandomState(rng)
+		inner, err := State(undo.Then(target).Then(undo))
+		if err != nil {
+			return nil, err
+		}
+
+		// F and B commute, so either would merge with the F before it, and
+		// likewise R and L with the R' after.
+		if n := len(inner); n == 0 || isAxis(inner[0], "FB") || isAxis(inner[n-1], "RL") {
+			continue
+		}
+
+		moves := append([]cube.Move{}, fmcPadding...)
+		moves = append(moves, inner...)
+		return append(moves, fmcPadding...), nil
+	}
+}
+
+func isAxis(m cube.Move, faces string) bool {
+	return strings.ContainsRune(faces, m.Operator)
+}
+
+// VerifyFMC checks a fewest moves solution of the scramble and returns its
+// official move count. Solutions are plain outer and wide turns and
+// rotations, without slice moves or brackets. Rotations are not counted, and
+// every other move counts one, half turns included.
+func VerifyFMC(scramble []cube.Move, solution string) (int, error) {
+	var moves []cube.Move
+	count := 0
+	for i, token := range strings.Fields(solution) {
+		if strings.ContainsAny(token, "[](),:") {
+			return 0, fmt.Errorf("move %d %q: %w", i+1, token, ErrFMCNotation)
+		}
+		group, err := cube.ParseNotation(token)
+		if err != nil {
+			return 0, fmt.Errorf("move %d %q: %w", i+1, token, err)
+		}
+		parsed, err := group.Expand()
+		if err != nil {
+			return 0, err
+		}
+
+		for _, m := range parsed {
+			switch m.Operator {
+			case 'M', 'E', 'S':
+				return 0, fmt.Errorf("move %d %q: %w", i+1, token, ErrFMCNotation)
+			case 'x', 'y', 'z':
+			default:
+				count++
+			}
+			moves = append(moves, m)
+		}
+	}
+	if count > FMCMaxMoves {
+		return count, ErrFMCLength
+	}
+
+	c := cube.NewCube(3)
+	if err := c.ExecuteMoves(append(append([]cube.Move{}, scramble...), moves...)...); err != nil {
+		return count, err
+	}
+	if !c.IsSolved() {
+		return count, ErrFMCUnsolved
+	}
+	return count, nil
+}
@@ -0,0 +1,134 @@
+package scramble
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"sync"
+)
+
+var ErrNotUnique = errors.New("no scramble unique in the scope")
+
+// MaxUniqueAttempts is how many scrambles Unique draws before giving up.
+const MaxUniqueAttempts = 100
+
+// History remembers the scrambles issued in scopes, such as a practice
+// session or an event of a competition, by the keys of their states. The
+// Store of package store keeps one on disk.
+type History interface {
+	// Issue records the key in the scope and reports true, or reports false
+	// if it was issued in the scope before.
+	Issue(scope, key string) (bool, error)
+}
+
+// MemoryHistory is a History for the life of a program. Its methods are safe
+// for concurrent use.
+type MemoryHistory struct {
+	mu     sync.Mutex
+	issued map[string]map[string]bool
+}
+
+// NewMemoryHistory returns an empty MemoryHistory.
+func NewMemoryHistory() *MemoryHistory {
+	return &MemoryHistory{issued: map[string]map[string]bool{}}
+}
+
+func (h *MemoryHistory) Issue(scope, key string) (bool, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	keys := h.issued[scope]
+	if keys == nil {
+		keys = map[string]bool{}
+		h.issued[scope] = keys
+	}
+	if keys[key] {
+		return false, nil
+	}
+	keys[key] = true
+	return true, nil
+}
+
+// Forget forgets the scrambles issued in the scope.
+func (h *MemoryHistory) Forget(scope string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.issued, scope)
+}
+
+// rotations are the 24 rotations of a cube: a face to the top, then a turn
+// about the vertical axis.
+var rotations = func() [][]cube.Move {
+	ups := [][]cube.Move{
+		nil,
+		{{Operator: 'x', Rotations: 1}},
+		{{Operator: 'x', Rotations: 2}},
+		{{Operator: 'x', Rotations: 1, Inverted: true}},
+		{{Operator: 'z', Rotations: 1}},
+		{{Operator: 'z', Rotations: 1, Inverted: true}},
+	}
+	var out [][]cube.Move
+	for _, up := range ups {
+		for turns := range 4 {
+			rotation := append([]cube.Move{}, up...)
+			if turns > 0 {
+				rotation = append(rotation, cube.Move{Operator: 'y', Rotations: turns})
+			}
+			out = append(out, rotation)
+		}
+	}
+	return out
+}()
+
+// StateKey returns the key of the state that a scramble leaves a cube of the
+// size in, by its stickers. Scrambles that leave the same state held in
+// another orientation, such as ending in a rotation or not, have the same
+// key: the state is keyed held with the corner that starts at down, back and
+// left there, untwisted.
+func StateKey(size int, moves []cube.Move) (string, error) {
+	if size < 1 {
+		return "", ErrSize
+	}
+	state := ""
+	for _, rotation := range rotations {
+		c := cube.NewCube(size)
+		if err := c.ExecuteMoves(moves...); err != nil {
+			return "", err
+		}
+		if err := c.ExecuteMoves(rotation...); err != nil {
+			return "", err
+		}
+		if p, _ := c.FindPiece(cube.Position{}); p.Position == (cube.Position{}) && p.Orientation == 0 {
+			state = c.State()
+			break
+		}
+	}
+	sum := sha256.Sum256(fmt.Appendf(nil, "%d:%s", size, state))
+	return hex.EncodeToString(sum[:16]), nil
+}
+
+// Unique draws scrambles of the size with generate until one leaves a state
+// not issued in the scope of the history, issues it and returns it. It
+// returns ErrNotUnique after MaxUniqueAttempts scrambles, as for generators
+// of few scrambles.
+func Unique(h History, scope string, size int, generate func() ([]cube.Move, error)) ([]cube.Move, error) {
+	for range MaxUniqueAttempts {
+		moves, err := generate()
+		if err != nil {
+			return nil, err
+		}
+		key, err := StateKey(size, moves)
+		if err != nil {
+			return nil, err
+		}
+		ok, err := h.Issue(scope, key)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return moves, nil
+		}
+	}
+	return nil, fmt.Errorf("%q: %d attempts: %w", scope, MaxUniqueAttempts, ErrNotUnique)
+}
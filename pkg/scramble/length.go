@@ -0,0 +1,90 @@
+package scramble
+
+import (
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/solve"
+	"time"
+)
+
+var ErrLengthSize = errors.New("solution lengths of 2x2 and 3x3 scrambles only")
+
+// Distance is how many moves a scramble takes to solve, counting half turns
+// as one: exactly if Optimal, else between AtLeast and AtMost, as far as the
+// searches got.
+type Distance struct {
+	AtLeast, AtMost int
+	Optimal         bool
+}
+
+func (d Distance) String() string {
+	if d.Optimal {
+		return fmt.Sprintf("%d moves", d.AtMost)
+	}
+	return fmt.Sprintf("%d to %d moves", d.AtLeast, d.AtMost)
+}
+
+// LengthOptions configures SolutionLength.
+type LengthOptions struct {
+	// Timeout bounds the two-phase search for a short solution of a 3x3
+	// scramble, and then the optimal search for a shorter one, each.
+	// Defaults to a second.
+	Timeout time.Duration
+
+	// MaxDepth, if set, ends the optimal search at solutions of that many
+	// moves, leaving longer lengths bounded. The search is optimal, so
+	// depths much above 12 take long.
+	MaxDepth int
+}
+
+// SolutionLength returns how many moves the state of the scramble takes to
+// solve, so that practice sets can be binned by difficulty. The 2x2 is
+// solved optimally. On the 3x3, the two-phase solver bounds the length from
+// above, and an optimal search for a shorter solution, depth by depth, from
+// below until it finds one or runs out of time.
+func SolutionLength(size int, moves []cube.Move, opts LengthOptions) (Distance, error) {
+	if size != 2 && size != 3 {
+		return Distance{}, ErrLengthSize
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = time.Second
+	}
+	s, err := solve.StateOf(moves...)
+	if err != nil {
+		return Distance{}, err
+	}
+	if size == 2 {
+		d, err := solve.PocketDistance(s)
+		return Distance{d, d, true}, err
+	}
+
+	upper, err := solve.Solve(s, solve.SolveOptions{Timeout: opts.Timeout, KeepImproving: true})
+	if err != nil {
+		return Distance{}, err
+	}
+	dist := Distance{AtMost: len(upper)}
+	deadline := time.Now().Add(opts.Timeout)
+	for depth := 0; depth < dist.AtMost; depth++ {
+		if opts.MaxDepth > 0 && depth > opts.MaxDepth {
+			return dist, nil
+		}
+		left := time.Until(deadline)
+		if left <= 0 {
+			return dist, nil
+		}
+		solution, err := solve.SolveWithin(s, depth, solve.SolveOptions{Timeout: left})
+		switch {
+		case errors.Is(err, solve.ErrNoSolution):
+			dist.AtLeast = depth + 1
+		case errors.Is(err, solve.ErrBudget):
+			return dist, nil
+		case err != nil:
+			return Distance{}, err
+		default:
+			return Distance{len(solution.Moves), len(solution.Moves), true}, nil
+		}
+	}
+	dist.Optimal = true
+	return dist, nil
+}
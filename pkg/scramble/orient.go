@@ -0,0 +1,39 @@
+package scramble
+
+import (
+	"github.com/larssont/go-cubic/pkg/cube"
+	"math/rand/v2"
+)
+
+// opposite holds the opposite face of every face, indexed by cube.Face.
+var opposite = [6]cube.Face{cube.FaceDown, cube.FaceRight, cube.FaceBack, cube.FaceLeft, cube.FaceFront, cube.FaceUp}
+
+// RandomOrientation returns one of the 24 orientations of a cube colored
+// with the scheme, uniformly at random, and the shortest rotation from the
+// home orientation of the scheme to it. A nil rng uses the global source of
+// math/rand/v2.
+func RandomOrientation(s cube.Scheme, rng *rand.Rand) (cube.Orientation, []cube.Move) {
+	intN := rand.IntN
+	if rng != nil {
+		intN = rng.IntN
+	}
+	up := cube.Face(intN(6))
+	var fronts []cube.Face
+	for f := range cube.Face(6) {
+		if f != up && f != opposite[up] {
+			fronts = append(fronts, f)
+		}
+	}
+	o := cube.Orientation{Up: s.Faces[up], Front: s.Faces[fronts[intN(len(fronts))]]}
+	rotation, _ := o.Rotation(s)
+	return o, rotation
+}
+
+// Orient prefixes a scramble with the rotation to a random orientation, as
+// blindfolded scrambles are so that solvers cannot count on a color on top,
+// and for color neutral practice. It returns the orientation that the cube
+// is held in for the rest of the scramble, by the colors of the scheme.
+func Orient(moves []cube.Move, s cube.Scheme, rng *rand.Rand) ([]cube.Move, cube.Orientation) {
+	o, rotation := RandomOrientation(s, rng)
+	return append(rotation, moves...), o
+}
@@ -0,0 +1,116 @@
+// Package scramble generates scrambles for cubes of any size.
+package scramble
+
+import (
+	"errors"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/solve"
+	"math/rand/v2"
+)
+
+var ErrSize = errors.New("cube size must be at least 2")
+
+// lengths holds the length of random move scrambles by cube size, as in WCA
+// scrambles.
+var lengths = map[int]int{2: 11, 4: 40, 5: 60, 6: 80, 7: 100}
+
+// Length returns the number of moves of a random move scramble of the size.
+func Length(size int) int {
+	if n, ok := lengths[size]; ok {
+		return n
+	}
+	return 20 * (size - 2)
+}
+
+// MinSolution is the fewest moves that the state of a 3x3 scramble takes to
+// solve. As in WCA scrambles, states solvable in fewer are drawn again.
+const MinSolution = 2
+
+// Cube returns a scramble of a cube of the given size. 3x3 scrambles reach a
+// uniformly random state of at least MinSolution moves, and are solutions of
+// the two-phase solver. Other sizes are random moves. A nil rng uses the
+// global source of math/rand/v2.
+func Cube(size int, rng *rand.Rand) ([]cube.Move, error) {
+	switch {
+	case size < 2:
+		return nil, ErrSize
+	case size == 3:
+		for {
+			s := solve.RandomState(rng)
+			if _, err := solve.SolveWithin(s, MinSolution-1, solve.SolveOptions{}); errors.Is(err, solve.ErrNoSolution) {
+				return State(s)
+			}
+		}
+	}
+	return RandomMoves(size, Length(size), rng), nil
+}
+
+// State returns a scramble reaching the 3x3 state.
+func State(s solve.State) ([]cube.Move, error) {
+	solution, err := solve.Solve(s, solve.SolveOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return cube.ReverseMoves(solution), nil
+}
+
+// faces are the faces turned by random moves.
+const faces = "URFDLB"
+
+// RandomMoves returns length random outer and wide turns of a cube of the
+// given size. No move turns layers that an earlier move on the same axis
+// turned, unless a move on another axis came in between. 2x2 scrambles only
+// turn R, U and F.
+func RandomMoves(size, length int, rng *rand.Rand) []cube.Move {
+	intN := rand.IntN
+	if rng != nil {
+		intN = rng.IntN
+	}
+
+	// Every layer can be turned from either side, so the deepest turns are
+	// left to U, R and F on even cubes.
+	type layer struct {
+		face  rune
+		width int
+	}
+	var layers []layer
+	for i, f := range faces {
+		if size == 2 && i >= 3 {
+			break
+		}
+		depth := size / 2
+		if size%2 == 0 && i >= 3 {
+			depth--
+		}
+		for w := 1; w <= depth; w++ {
+			layers = append(layers, layer{f, w})
+		}
+	}
+
+	axis := map[rune]int{'U': 0, 'D': 0, 'R': 1, 'L': 1, 'F': 2, 'B': 2}
+	var moves []cube.Move
+	turned := map[layer]bool{}
+	last := -1
+	for len(moves) < length {
+		l := layers[intN(len(layers))]
+		if axis[l.face] == last && turned[l] {
+			continue
+		}
+		if axis[l.face] != last {
+			clear(turned)
+			last = axis[l.face]
+		}
+		turned[l] = true
+
+		m := cube.Move{Operator: l.face, Rotations: 1 + intN(2)}
+		if m.Rotations == 1 {
+			m.Inverted = intN(2) == 1
+		}
+		if l.width > 1 {
+			m.Wide = true
+			m.Slices = l.width
+		}
+		moves = append(moves, m)
+	}
+	return moves
+}
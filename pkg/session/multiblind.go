@@ -0,0 +1,111 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/bld"
+	"math/rand/v2"
+	"time"
+)
+
+var ErrMultiResult = errors.New("solved cubes exceed attempted cubes")
+
+// Multi-blind time limits of the WCA regulations.
+const (
+	MultiTimePerCube = 10 * time.Minute
+	MultiTimeLimit   = time.Hour
+)
+
+// MultiBlind is a multi-blind attempt at several 3x3 cubes.
+type MultiBlind struct {
+	*Session
+}
+
+// NewMultiBlind returns a multi-blind attempt with n scrambled 3x3 cubes.
+func NewMultiBlind(n int, rng *rand.Rand) (*MultiBlind, error) {
+	sizes := make([]int, n)
+	for i := range sizes {
+		sizes[i] = 3
+	}
+	s, err := New("Multi-blind", sizes, rng)
+	if err != nil {
+		return nil, err
+	}
+	return &MultiBlind{s}, nil
+}
+
+// TimeLimit returns the time limit of the attempt, ten minutes per cube and
+// at most an hour.
+func (m *MultiBlind) TimeLimit() time.Duration {
+	return min(time.Duration(len(m.Puzzles))*MultiTimePerCube, MultiTimeLimit)
+}
+
+// Memos returns the memo of every cube, see bld.Memorize.
+func (m *MultiBlind) Memos(opts bld.TraceOptions) ([]bld.Memo, error) {
+	var memos []bld.Memo
+	for i, p := range m.Puzzles {
+		memo, err := bld.Memorize(p.Scramble, opts)
+		if err != nil {
+			return nil, fmt.Errorf("cube %d: %w", i+1, err)
+		}
+		memos = append(memos, memo)
+	}
+	return memos, nil
+}
+
+// Result returns the result of the cubes as they are now.
+func (m *MultiBlind) Result() MultiResult {
+	r := MultiResult{Attempted: len(m.Puzzles), Time: m.Time()}
+	for _, p := range m.Puzzles {
+		if p.Cube.IsSolved() {
+			r.Solved++
+		}
+	}
+	return r
+}
+
+// MultiResult is the result of a multi-blind attempt.
+type MultiResult struct {
+	Solved    int
+	Attempted int
+	Time      time.Duration
+}
+
+// NewMultiResult returns a result entered by hand, such as "9/10 in 54:13".
+func NewMultiResult(solved, attempted int, t time.Duration) (MultiResult, error) {
+	if solved < 0 || solved > attempted {
+		return MultiResult{}, ErrMultiResult
+	}
+	return MultiResult{solved, attempted, t}, nil
+}
+
+// Points returns the solved cubes minus the unsolved ones.
+func (r MultiResult) Points() int {
+	return r.Solved - (r.Attempted - r.Solved)
+}
+
+// DNF reports whether the result does not count, which is the case with
+// fewer points than zero or fewer than two cubes solved.
+func (r MultiResult) DNF() bool {
+	return r.Points() < 0 || r.Solved < 2
+}
+
+// Encode returns the result in the WCA format 0DDTTTTTMM, with DD 99 minus
+// the points, TTTTT the time in seconds and MM the missed cubes.
+func (r MultiResult) Encode() int {
+	if r.DNF() {
+		return -1
+	}
+	seconds := int(r.Time.Round(time.Second) / time.Second)
+	return (99-r.Points())*10_000_000 + min(seconds, 99999)*100 + r.Attempted - r.Solved
+}
+
+// String returns the result as "9/10 54:13", or "DNF (1/2 10:00)".
+func (r MultiResult) String() string {
+	seconds := int(r.Time.Round(time.Second) / time.Second)
+	s := fmt.Sprintf("%d/%d %d:%02d", r.Solved, r.Attempted, seconds/60, seconds%60)
+	if r.DNF() {
+		return "DNF (" + s + ")"
+	}
+	return s
+}
@@ -0,0 +1,169 @@
+// Package session tracks attempts made of several scrambled cubes, such as
+// relays, which are timed as one.
+package session
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/internal/svg"
+	"github.com/larssont/go-cubic/pkg/scramble"
+	"io"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrIndex = errors.New("no cube at index")
+	ErrRelay = errors.New("relays are written as 2-4")
+)
+
+// Puzzle is one scrambled cube of a session.
+type Puzzle struct {
+	Dimension int
+	Scramble  []cube.Move
+	Cube      *cube.Cube
+
+	// Solved is the time from the start of the session to the move that
+	// solved the cube, or zero while it is unsolved.
+	Solved time.Duration
+}
+
+// Session is an attempt at a set of cubes timed together.
+type Session struct {
+	Event   string
+	Puzzles []*Puzzle
+
+	start, end time.Time
+	now        func() time.Time
+}
+
+// New returns a session with a scrambled cube of every size.
+func New(event string, sizes []int, rng *rand.Rand) (*Session, error) {
+	s := &Session{Event: event, now: time.Now}
+	for _, size := range sizes {
+		moves, err := scramble.Cube(size, rng)
+		if err != nil {
+			return nil, err
+		}
+		c := cube.NewCube(size)
+		if err := c.ExecuteMoves(moves...); err != nil {
+			return nil, err
+		}
+		s.Puzzles = append(s.Puzzles, &Puzzle{Dimension: size, Scramble: moves, Cube: c})
+	}
+	return s, nil
+}
+
+// Relay returns a session with one cube of every size from the first to the
+// last, such as 2-4 for a 2x2, 3x3 and 4x4 relay.
+func Relay(first, last int, rng *rand.Rand) (*Session, error) {
+	if first < 2 || last < first {
+		return nil, ErrRelay
+	}
+	var sizes []int
+	for size := first; size <= last; size++ {
+		sizes = append(sizes, size)
+	}
+	return New(fmt.Sprintf("%d-%d relay", first, last), sizes, rng)
+}
+
+// ParseRelay parses the sizes of a relay, such as "2-7".
+func ParseRelay(s string) (first, last int, err error) {
+	a, b, ok := strings.Cut(s, "-")
+	if !ok {
+		return 0, 0, ErrRelay
+	}
+	if first, err = strconv.Atoi(a); err != nil {
+		return 0, 0, ErrRelay
+	}
+	if last, err = strconv.Atoi(b); err != nil || first < 2 || last < first {
+		return 0, 0, ErrRelay
+	}
+	return first, last, nil
+}
+
+// Start starts the timer. Without a call to Start the timer starts with the
+// first move.
+func (s *Session) Start() {
+	s.start = s.now()
+}
+
+// Apply applies moves to cube i, and records when the cube is solved.
+func (s *Session) Apply(i int, moves ...cube.Move) error {
+	if i < 0 || i >= len(s.Puzzles) {
+		return ErrIndex
+	}
+	if s.start.IsZero() {
+		s.Start()
+	}
+
+	p := s.Puzzles[i]
+	if err := p.Cube.ExecuteMoves(moves...); err != nil {
+		return err
+	}
+	if p.Cube.IsSolved() && p.Solved == 0 {
+		p.Solved = s.now().Sub(s.start)
+	} else if !p.Cube.IsSolved() {
+		p.Solved = 0
+	}
+
+	if s.Done() {
+		s.end = s.now()
+	}
+	return nil
+}
+
+// Done reports whether every cube is solved.
+func (s *Session) Done() bool {
+	for _, p := range s.Puzzles {
+		if !p.Cube.IsSolved() {
+			return false
+		}
+	}
+	return true
+}
+
+// Time returns the combined time of the session, which runs until every cube
+// is solved.
+func (s *Session) Time() time.Duration {
+	switch {
+	case s.start.IsZero():
+		return 0
+	case s.Done() && !s.end.IsZero():
+		return s.end.Sub(s.start)
+	}
+	return s.now().Sub(s.start)
+}
+
+const svgCaption = 20.0
+
+// RenderSVG draws the cubes side by side, each captioned with its size.
+func (s *Session) RenderSVG(w io.Writer) error {
+	var images [][]byte
+	var width, height float64
+	for _, p := range s.Puzzles {
+		var buf bytes.Buffer
+		if err := p.Cube.RenderSVG(&buf); err != nil {
+			return err
+		}
+		images = append(images, buf.Bytes())
+
+		netWidth, netHeight := cube.SVGSize(p.Dimension)
+		width += netWidth
+		height = max(height, netHeight)
+	}
+
+	out := svg.NewWriter(w, width, height+svgCaption)
+	x := 0.0
+	for i, p := range s.Puzzles {
+		netWidth, _ := cube.SVGSize(p.Dimension)
+		out.Text(svg.Point{X: x + netWidth/2, Y: svgCaption - 4}, 14, fmt.Sprintf("%dx%d", p.Dimension, p.Dimension))
+		out.Embed(svg.Point{X: x, Y: svgCaption}, images[i])
+		x += netWidth
+	}
+	return out.Close()
+}
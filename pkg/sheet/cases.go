@@ -0,0 +1,176 @@
+package sheet
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"github.com/larssont/go-cubic/pkg/algdb"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/trainer"
+	"html/template"
+	"io"
+	"strings"
+)
+
+var ErrNoCases = errors.New("no cases in the sets")
+
+//go:embed cases.tmpl
+var casesTemplate string
+
+var cases = template.Must(template.New("cases").Parse(casesTemplate))
+
+// hidden is drawn in gray by trainer.LastLayer.RenderSVG.
+const hidden = '.'
+
+// caseMasks hide the stickers that do not tell the cases of a set apart:
+// the sides of OLL cases, which are recognized by the top color alone, and
+// the edges of CMLL and COLL cases.
+var caseMasks = map[string]func(ll *trainer.LastLayer){
+	"oll":  showTopColor,
+	"ocll": showTopColor,
+	"cmll": hideEdges,
+	"coll": hideEdges,
+}
+
+func showTopColor(ll *trainer.LastLayer) {
+	top := ll.Up[4]
+	for _, s := range []*string{&ll.Up, &ll.Front, &ll.Right, &ll.Back, &ll.Left} {
+		*s = strings.Map(func(r rune) rune {
+			if r != rune(top) {
+				return hidden
+			}
+			return r
+		}, *s)
+	}
+}
+
+func hideEdges(ll *trainer.LastLayer) {
+	up := []byte(ll.Up)
+	for _, i := range []int{1, 3, 5, 7} {
+		up[i] = hidden
+	}
+	ll.Up = string(up)
+	for _, s := range []*string{&ll.Front, &ll.Right, &ll.Back, &ll.Left} {
+		side := []byte(*s)
+		side[1] = hidden
+		*s = string(side)
+	}
+}
+
+// CaseOptions configures RenderCaseSheet.
+type CaseOptions struct {
+	Title   string      // Defaults to the name of the set
+	Columns int         // Defaults to 3
+	Scheme  cube.Scheme // Fills and patterns, defaults to those of cube.Western
+}
+
+// Learned selects the cases of an alg sheet by whether they are marked
+// learned in the database.
+type Learned int
+
+const (
+	AllCases       Learned = iota
+	LearnedCases           // Such as to review before a competition
+	UnlearnedCases         // Such as to learn next
+)
+
+// AlgSheetOptions configures RenderAlgSheet.
+type AlgSheetOptions struct {
+	CaseOptions // The title defaults to the set, or to "Algorithms" for several
+
+	Sets    []string // Sets to show in order, every set of the database if empty
+	Learned Learned
+}
+
+// RenderCaseSheet writes every case of the set of the database, such as
+// "OLL" or "PLL", as its last layer seen from above with its name and its
+// algorithms, the preferred one first. The stickers that do not matter to a
+// set, such as the sides of OLL cases, are gray.
+func RenderCaseSheet(w io.Writer, db *algdb.DB, set string, opts CaseOptions) error {
+	return RenderAlgSheet(w, db, AlgSheetOptions{CaseOptions: opts, Sets: []string{set}})
+}
+
+// RenderAlgSheet writes the cases of the sets of a personal database as
+// RenderCaseSheet does, a section a set, with the notes of every case and a
+// mark on those learned. The page is laid out for A4 paper, to print or save
+// as PDF from a browser.
+func RenderAlgSheet(w io.Writer, db *algdb.DB, opts AlgSheetOptions) error {
+	if opts.Columns <= 0 {
+		opts.Columns = 3
+	}
+	sets := opts.Sets
+	if len(sets) == 0 {
+		sets = db.Sets()
+	}
+	if opts.Title == "" {
+		opts.Title = "Algorithms"
+		if len(sets) == 1 {
+			opts.Title = sets[0]
+		}
+	}
+
+	type entry struct {
+		Name    string
+		Algs    []string
+		Notes   []string
+		Learned bool
+		Diagram template.HTML
+	}
+	type section struct {
+		Name  string
+		Cases []entry
+	}
+	var sections []section
+	for _, set := range sets {
+		mask := caseMasks[strings.ToLower(set)]
+		sec := section{Name: set}
+		for _, c := range db.Cases {
+			if !strings.EqualFold(c.Set, set) || len(c.Algs) == 0 {
+				continue
+			}
+			if opts.Learned == LearnedCases && !c.Learned || opts.Learned == UnlearnedCases && c.Learned {
+				continue
+			}
+			ll := lastLayer(cube.ReverseMoves(c.Algs[0].Moves))
+			if mask != nil {
+				mask(&ll)
+			}
+			var b bytes.Buffer
+			if err := ll.RenderSVGWith(&b, opts.Scheme); err != nil {
+				return err
+			}
+			e := entry{Name: c.Name, Notes: c.Notes, Learned: c.Learned, Diagram: template.HTML(b.String())}
+			for _, alg := range c.Algs {
+				e.Algs = append(e.Algs, alg.Notation)
+			}
+			sec.Cases = append(sec.Cases, e)
+		}
+		if len(sec.Cases) > 0 {
+			sections = append(sections, sec)
+		}
+	}
+	if len(sections) == 0 {
+		return ErrNoCases
+	}
+
+	return cases.Execute(w, struct {
+		Title    string
+		Columns  int
+		Sections []section
+		Headings bool
+	}{opts.Title, opts.Columns, sections, len(sets) > 1})
+}
+
+// lastLayer returns the last layer of a cube after the moves.
+func lastLayer(moves []cube.Move) trainer.LastLayer {
+	c := cube.NewCube(3)
+	c.ExecuteMoves(moves...)
+	faces := c.Faces()
+	return trainer.LastLayer{
+		Up:    string(faces.Up),
+		Front: string(faces.Front[:3]),
+		Right: string(faces.Right[:3]),
+		Back:  string(faces.Back[:3]),
+		Left:  string(faces.Left[:3]),
+	}
+}
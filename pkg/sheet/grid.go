@@ -0,0 +1,75 @@
+package sheet
+
+import (
+	_ "embed"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"html/template"
+	"io"
+)
+
+//go:embed grid.tmpl
+var gridTemplate string
+
+var grid = template.Must(template.New("grid").Parse(gridTemplate))
+
+// Cell is a scramble or algorithm of a grid, drawn as the cube of the size
+// after its moves.
+type Cell struct {
+	Size  int
+	Moves []cube.Move
+	Note  string // Such as the optimal length, left out if empty
+}
+
+// GridOptions configures RenderGrid.
+type GridOptions struct {
+	Options
+	Columns int // Defaults to 3
+	PerPage int // Cells of a page, defaults to 4 rows of Columns
+}
+
+// RenderGrid writes the cells on pages of a grid, every cell captioned with
+// its number, from 1, its note and its moves, for practice packets and alg
+// sheets.
+func RenderGrid(w io.Writer, cells []Cell, opts GridOptions) error {
+	if opts.Columns <= 0 {
+		opts.Columns = 3
+	}
+	if opts.PerPage <= 0 {
+		opts.PerPage = 4 * opts.Columns
+	}
+
+	type cell struct {
+		Index   int
+		Moves   string
+		Note    string
+		Diagram template.HTML
+		QR      template.HTML
+	}
+	var pages [][]cell
+	for i, c := range cells {
+		diagram, err := netSVG(c.Size, c.Moves, opts.Scheme)
+		if err != nil {
+			return err
+		}
+		code, err := qrSVG(opts.QR, c.Size, c.Moves)
+		if err != nil {
+			return err
+		}
+		if i%opts.PerPage == 0 {
+			pages = append(pages, nil)
+		}
+		pages[len(pages)-1] = append(pages[len(pages)-1], cell{
+			Index:   i + 1,
+			Moves:   cube.FormatMoves(c.Moves),
+			Note:    c.Note,
+			Diagram: diagram,
+			QR:      code,
+		})
+	}
+
+	return grid.Execute(w, struct {
+		Title   string
+		Columns int
+		Pages   [][]cell
+	}{opts.Title, opts.Columns, pages})
+}
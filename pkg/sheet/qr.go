@@ -0,0 +1,93 @@
+package sheet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/internal/qr"
+	"html/template"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// QRContent is what the QR codes beside scrambles encode, so that printed
+// sheets can be scanned into a phone timer.
+type QRContent int
+
+const (
+	NoQR       QRContent = iota
+	QRScramble           // The moves of the scramble
+	QRLink               // A link to the scramble on alg.cubing.net
+)
+
+var ErrQRContent = errors.New("unknown QR content")
+
+// qrContents holds the names of the QR contents.
+var qrContents = map[string]QRContent{
+	"none":     NoQR,
+	"scramble": QRScramble,
+	"link":     QRLink,
+}
+
+// ParseQRContent parses "none", "scramble" or "link".
+func ParseQRContent(name string) (QRContent, error) {
+	content, ok := qrContents[strings.ToLower(name)]
+	if !ok {
+		return NoQR, fmt.Errorf("%q: %w", name, ErrQRContent)
+	}
+	return content, nil
+}
+
+// qrSize is the side of the QR codes of sheets in pixels.
+const qrSize = 90
+
+// AlgCubingLink returns the link to the cube of the size after the moves on
+// alg.cubing.net.
+func AlgCubingLink(size int, moves []cube.Move) string {
+	setup := strings.NewReplacer(" ", "_", "'", "-").Replace(cube.FormatMoves(moves))
+	link := "https://alg.cubing.net/?setup=" + url.QueryEscape(setup)
+	if size != 3 {
+		n := strconv.Itoa(size)
+		link += "&puzzle=" + n + "x" + n + "x" + n
+	}
+	return link
+}
+
+// WriteQRSVG writes a QR code of the text as an SVG image of a side of size
+// pixels. Texts of up to 213 bytes are encoded with medium error correction,
+// and up to 271 bytes with low.
+func WriteQRSVG(w io.Writer, text string, size float64) error {
+	code, err := qr.Encode(text, qr.Medium)
+	if errors.Is(err, qr.ErrTooLong) {
+		code, err = qr.Encode(text, qr.Low)
+	}
+	if err != nil {
+		return err
+	}
+	return code.WriteSVG(w, size)
+}
+
+// qrSVG returns the QR code of the content for the moves, or nothing for
+// NoQR and for scrambles too long to encode, such as those of big cubes.
+func qrSVG(content QRContent, size int, moves []cube.Move) (template.HTML, error) {
+	var text string
+	switch content {
+	case QRScramble:
+		text = cube.FormatMoves(moves)
+	case QRLink:
+		text = AlgCubingLink(size, moves)
+	default:
+		return "", nil
+	}
+	var b bytes.Buffer
+	if err := WriteQRSVG(&b, text, qrSize); err != nil {
+		if errors.Is(err, qr.ErrTooLong) {
+			return "", nil
+		}
+		return "", err
+	}
+	return template.HTML(b.String()), nil
+}
@@ -0,0 +1,110 @@
+// Package sheet lays out scrambles and cases with their diagrams as HTML
+// pages for printing, such as scramble sheets of competitions. Browsers
+// print them to PDF.
+package sheet
+
+import (
+	"bytes"
+	_ "embed"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"html/template"
+	"io"
+	"strconv"
+)
+
+//go:embed scrambles.tmpl
+var scramblesTemplate string
+
+var scrambles = template.Must(template.New("scrambles").Parse(scramblesTemplate))
+
+// Event is the puzzle of a group of scrambles, such as "3x3x3 Cube" of size
+// 3.
+type Event struct {
+	Name string
+	Size int
+}
+
+// Group is the scrambles of a group of a round of an event, numbered from 1,
+// and its extra scrambles, numbered E1 on.
+type Group struct {
+	Event     Event
+	Round     int    // Left out if zero
+	Name      string // Such as "A", left out if empty
+	Scrambles [][]cube.Move
+	Extras    [][]cube.Move
+}
+
+// Options configures the rendering of sheets.
+type Options struct {
+	Title  string      // Such as the name of the competition
+	Scheme cube.Scheme // Defaults to cube.Western
+	QR     QRContent   // QR codes beside the scrambles, none by default
+}
+
+// row is a scramble of a sheet.
+type row struct {
+	Label   string
+	Moves   string
+	Diagram template.HTML
+	QR      template.HTML
+}
+
+// RenderScrambleSheet writes a page for every group, with a header of the
+// title, event, round and group, and every scramble with a net of the cube it
+// scrambles and the QR code of the options.
+func RenderScrambleSheet(w io.Writer, groups []Group, opts Options) error {
+	type group struct {
+		Event     string
+		Round     int
+		Name      string
+		Scrambles []row
+		Extras    []row
+	}
+	var data []group
+	for _, g := range groups {
+		out := group{Event: g.Event.Name, Round: g.Round, Name: g.Name}
+		for i, moves := range g.Scrambles {
+			r, err := newRow(strconv.Itoa(i+1), g.Event.Size, moves, opts)
+			if err != nil {
+				return err
+			}
+			out.Scrambles = append(out.Scrambles, r)
+		}
+		for i, moves := range g.Extras {
+			r, err := newRow("E"+strconv.Itoa(i+1), g.Event.Size, moves, opts)
+			if err != nil {
+				return err
+			}
+			out.Extras = append(out.Extras, r)
+		}
+		data = append(data, out)
+	}
+
+	return scrambles.Execute(w, struct {
+		Title  string
+		Groups []group
+	}{opts.Title, data})
+}
+
+func newRow(label string, size int, moves []cube.Move, opts Options) (row, error) {
+	diagram, err := netSVG(size, moves, opts.Scheme)
+	if err != nil {
+		return row{}, err
+	}
+	code, err := qrSVG(opts.QR, size, moves)
+	if err != nil {
+		return row{}, err
+	}
+	return row{Label: label, Moves: cube.FormatMoves(moves), Diagram: diagram, QR: code}, nil
+}
+
+// netSVG returns the net of a cube of the size after the moves.
+func netSVG(size int, moves []cube.Move, scheme cube.Scheme) (template.HTML, error) {
+	c := cube.NewCubeWith(size, cube.Options{Scheme: scheme})
+	c.ExecuteMoves(moves...)
+	var b bytes.Buffer
+	if err := c.RenderSVG(&b); err != nil {
+		return "", err
+	}
+	return template.HTML(b.String()), nil
+}
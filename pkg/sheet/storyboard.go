@@ -0,0 +1,149 @@
+package sheet
+
+import (
+	"bytes"
+	_ "embed"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/internal/svg"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+)
+
+//go:embed storyboard.tmpl
+var storyboardTemplate string
+
+var storyboard = template.Must(template.New("storyboard").Parse(storyboardTemplate))
+
+// Step is a part of a solution drawn as one frame of a storyboard, such as
+// a single move or a phase like the cross.
+type Step struct {
+	Name  string // Such as "Cross", left out if empty
+	Moves []cube.Move
+}
+
+// MoveSteps returns a step of every move.
+func MoveSteps(moves []cube.Move) []Step {
+	steps := make([]Step, len(moves))
+	for i, m := range moves {
+		steps[i] = Step{Moves: []cube.Move{m}}
+	}
+	return steps
+}
+
+// StoryboardOptions configures RenderStoryboard and RenderStoryboardSVG.
+type StoryboardOptions struct {
+	Options
+	Columns int // Frames in a row, defaults to 6
+}
+
+// frame is the cube after a step of a storyboard.
+type frame struct {
+	Index   int
+	Name    string
+	Moves   string
+	Diagram []byte
+}
+
+// frames returns the cube after the setup, captioned "Start", and after every
+// step.
+func frames(size int, setup []cube.Move, steps []Step, scheme cube.Scheme) ([]frame, error) {
+	c := cube.NewCubeWith(size, cube.Options{Scheme: scheme})
+	if err := c.ExecuteMoves(setup...); err != nil {
+		return nil, err
+	}
+	var out []frame
+	draw := func(f frame) error {
+		var b bytes.Buffer
+		if err := c.RenderSVG(&b); err != nil {
+			return err
+		}
+		f.Diagram = b.Bytes()
+		out = append(out, f)
+		return nil
+	}
+	if err := draw(frame{Name: "Start"}); err != nil {
+		return nil, err
+	}
+	for i, s := range steps {
+		if err := c.ExecuteMoves(s.Moves...); err != nil {
+			return nil, err
+		}
+		if err := draw(frame{Index: i + 1, Name: s.Name, Moves: cube.FormatMoves(s.Moves)}); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// RenderStoryboard writes an HTML page of the cube of the size after the
+// setup and after every step, in order in rows of frames, every frame
+// captioned with the name and moves of its step. It shows a solution without
+// scripts or animation, such as in documentation.
+func RenderStoryboard(w io.Writer, size int, setup []cube.Move, steps []Step, opts StoryboardOptions) error {
+	if opts.Columns <= 0 {
+		opts.Columns = 6
+	}
+	frames, err := frames(size, setup, steps, opts.Scheme)
+	if err != nil {
+		return err
+	}
+
+	type cell struct {
+		Index   int
+		Name    string
+		Moves   string
+		Diagram template.HTML
+	}
+	cells := make([]cell, len(frames))
+	for i, f := range frames {
+		cells[i] = cell{f.Index, f.Name, f.Moves, template.HTML(f.Diagram)}
+	}
+	return storyboard.Execute(w, struct {
+		Title   string
+		Setup   string
+		Columns int
+		Frames  []cell
+	}{opts.Title, cube.FormatMoves(setup), opts.Columns, cells})
+}
+
+const (
+	storyboardGap     = 12.0
+	storyboardCaption = 14.0 // Height of a line of a caption
+)
+
+// RenderStoryboardSVG draws the frames of RenderStoryboard as one SVG image,
+// every frame captioned below with its number and the name and moves of its
+// step.
+func RenderStoryboardSVG(w io.Writer, size int, setup []cube.Move, steps []Step, opts StoryboardOptions) error {
+	if opts.Columns <= 0 {
+		opts.Columns = 6
+	}
+	frames, err := frames(size, setup, steps, opts.Scheme)
+	if err != nil {
+		return err
+	}
+
+	netWidth, netHeight := cube.SVGSize(size)
+	cellWidth := netWidth + storyboardGap
+	cellHeight := netHeight + 2*storyboardCaption + storyboardGap
+	cols := min(opts.Columns, len(frames))
+	rows := (len(frames) + opts.Columns - 1) / opts.Columns
+	out := svg.NewWriter(w, float64(cols)*cellWidth, float64(rows)*cellHeight)
+	for i, f := range frames {
+		at := svg.Point{X: float64(i%opts.Columns) * cellWidth, Y: float64(i/opts.Columns) * cellHeight}
+		out.Embed(at, f.Diagram)
+
+		title := f.Name
+		if f.Index > 0 {
+			title = strconv.Itoa(f.Index) + ". " + f.Name
+		}
+		x := at.X + netWidth/2
+		out.Text(svg.Point{X: x, Y: at.Y + netHeight + storyboardCaption}, storyboardCaption*0.8, strings.TrimSpace(title))
+		if f.Moves != "" {
+			out.Text(svg.Point{X: x, Y: at.Y + netHeight + 2*storyboardCaption}, storyboardCaption*0.8, f.Moves)
+		}
+	}
+	return out.Close()
+}
@@ -0,0 +1,207 @@
+// Package skewb models the Skewb puzzle and its WCA notation.
+package skewb
+
+import (
+	"errors"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/internal/geom"
+	"github.com/larssont/go-cubic/pkg/internal/svg"
+	"math"
+	"slices"
+)
+
+var (
+	ErrUnsupportedMove = errors.New("move not supported on skewb")
+	ErrWideMove        = errors.New("slices or wide on skewb")
+)
+
+var orders = map[rune]int{
+	'R': 3, 'U': 3, 'L': 3, 'B': 3,
+	'x': 4, 'y': 4, 'z': 4,
+}
+
+// Dialect accepts the WCA corner moves R, U, L, B and the rotations x, y, z.
+var Dialect = cube.NewDialect(orders, validate)
+
+// face describes a face by its center and the directions of its right and
+// down edges when drawn in the net.
+type face struct {
+	color       rune
+	center      geom.Vec
+	right, down geom.Vec
+	netX, netY  float64
+}
+
+var faces = [6]face{
+	{'w', geom.Vec{Y: 1}, geom.Vec{X: 1}, geom.Vec{Z: 1}, 1, 0},
+	{'o', geom.Vec{X: -1}, geom.Vec{Z: 1}, geom.Vec{Y: -1}, 0, 1},
+	{'g', geom.Vec{Z: 1}, geom.Vec{X: 1}, geom.Vec{Y: -1}, 1, 1},
+	{'r', geom.Vec{X: 1}, geom.Vec{Z: -1}, geom.Vec{Y: -1}, 2, 1},
+	{'b', geom.Vec{Z: -1}, geom.Vec{X: -1}, geom.Vec{Y: -1}, 3, 1},
+	{'y', geom.Vec{Y: -1}, geom.Vec{X: 1}, geom.Vec{Z: -1}, 1, 2},
+}
+
+// Sticker outlines in face coordinates from (-1, -1) to (1, 1), in reading
+// order: the two top corners, the center, and the two bottom corners.
+var outlines = [][]svg.Point{
+	{{X: -1, Y: -1}, {X: 0, Y: -1}, {X: -1, Y: 0}},
+	{{X: 1, Y: -1}, {X: 1, Y: 0}, {X: 0, Y: -1}},
+	{{X: 0, Y: -1}, {X: 1, Y: 0}, {X: 0, Y: 1}, {X: -1, Y: 0}},
+	{{X: -1, Y: 1}, {X: -1, Y: 0}, {X: 0, Y: 1}},
+	{{X: 1, Y: 1}, {X: 0, Y: 1}, {X: 1, Y: 0}},
+}
+
+const stickersPerFace = 5
+
+type sticker struct {
+	slot    geom.Slot
+	polygon []svg.Point
+}
+
+var (
+	stickers []sticker
+	moves    = map[rune][]int{}
+)
+
+func init() {
+	for _, f := range faces {
+		for _, outline := range outlines {
+			var u, v float64
+			polygon := make([]svg.Point, len(outline))
+			for i, p := range outline {
+				u += p.X / float64(len(outline))
+				v += p.Y / float64(len(outline))
+				polygon[i] = svg.Point{X: 2*f.netX + 1 + p.X, Y: 2*f.netY + 1 + p.Y}
+			}
+
+			pos := f.center.Add(f.right.Scale(u)).Add(f.down.Scale(v))
+			stickers = append(stickers, sticker{geom.Slot{Pos: pos, Normal: f.center}, polygon})
+		}
+	}
+
+	slots := make([]geom.Slot, len(stickers))
+	for i, s := range stickers {
+		slots[i] = s.slot
+	}
+
+	clockwise := -2 * math.Pi / 3
+	moves['R'] = geom.Permutation(slots, geom.Vec{X: 1, Y: -1, Z: -1}, 0, clockwise)
+	moves['U'] = geom.Permutation(slots, geom.Vec{X: -1, Y: 1, Z: -1}, 0, clockwise)
+	moves['L'] = geom.Permutation(slots, geom.Vec{X: -1, Y: -1, Z: 1}, 0, clockwise)
+	moves['B'] = geom.Permutation(slots, geom.Vec{X: -1, Y: -1, Z: -1}, 0, clockwise)
+
+	quarter := -math.Pi / 2
+	moves['x'] = geom.Permutation(slots, geom.Vec{X: 1}, math.Inf(-1), quarter)
+	moves['y'] = geom.Permutation(slots, geom.Vec{Y: 1}, math.Inf(-1), quarter)
+	moves['z'] = geom.Permutation(slots, geom.Vec{Z: 1}, math.Inf(-1), quarter)
+}
+
+func validate(m *cube.Move) error {
+	if m.Slices > 0 || m.Wide {
+		return ErrWideMove
+	}
+	return nil
+}
+
+// ParseNotation parses Skewb notation into a Group tree.
+func ParseNotation(input string) (*cube.Group, error) {
+	return Dialect.ParseNotation(input)
+}
+
+// Skewb holds the sticker colors of a Skewb. Moves follow the WCA convention:
+// R, U, L and B turn the half around the DBR, UBL, DFL and DBL corners.
+type Skewb struct {
+	stickers []rune
+}
+
+// Faces holds the stickers of each face: the top corners, the center and the
+// bottom corners, oriented as in the cube net.
+type Faces struct {
+	Up    []rune
+	Left  []rune
+	Front []rune
+	Right []rune
+	Back  []rune
+	Down  []rune
+}
+
+func (f *Faces) All() [][]rune {
+	return [][]rune{f.Up, f.Left, f.Front, f.Right, f.Back, f.Down}
+}
+
+func New() *Skewb {
+	s := &Skewb{stickers: make([]rune, 0, len(stickers))}
+	for _, f := range faces {
+		for range stickersPerFace {
+			s.stickers = append(s.stickers, f.color)
+		}
+	}
+	return s
+}
+
+func (s *Skewb) Faces() *Faces {
+	face := func(i int) []rune {
+		return slices.Clone(s.stickers[i*stickersPerFace : (i+1)*stickersPerFace])
+	}
+
+	return &Faces{
+		Up:    face(0),
+		Left:  face(1),
+		Front: face(2),
+		Right: face(3),
+		Back:  face(4),
+		Down:  face(5),
+	}
+}
+
+// IsSolved reports whether every face has a single color, in any orientation.
+func (s *Skewb) IsSolved() bool {
+	for _, f := range s.Faces().All() {
+		for _, color := range f {
+			if color != f[0] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (s *Skewb) ExecuteMove(move cube.Move) error {
+	perm, ok := moves[move.Operator]
+	if !ok {
+		return ErrUnsupportedMove
+	}
+	if err := validate(&move); err != nil {
+		return err
+	}
+
+	order := orders[move.Operator]
+	turns := move.Rotations % order
+	if move.Inverted {
+		turns = (order - turns) % order
+	}
+
+	for range turns {
+		s.stickers = geom.Apply(s.stickers, perm)
+	}
+	return nil
+}
+
+// Apply executes a single move, see ExecuteMove.
+func (s *Skewb) Apply(move cube.Move) error {
+	return s.ExecuteMove(move)
+}
+
+// State returns the sticker colors of all faces in the order of Faces.
+func (s *Skewb) State() string {
+	return string(s.stickers)
+}
+
+func (s *Skewb) ExecuteMoves(moves ...cube.Move) error {
+	for _, m := range moves {
+		if err := s.ExecuteMove(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package skewb
+
+import (
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/internal/svg"
+	"io"
+)
+
+const (
+	svgScale  = 30.0
+	svgMargin = 10.0
+)
+
+// RenderSVG draws the net of the Skewb in the same cross layout as the cube.
+func (s *Skewb) RenderSVG(w io.Writer) error {
+	return s.RenderSVGWith(w, cube.Western)
+}
+
+// RenderSVGWith draws the net as RenderSVG does, in the fills and patterns of
+// the scheme.
+func (s *Skewb) RenderSVGWith(w io.Writer, scheme cube.Scheme) error {
+	style := svg.Style{Fills: scheme.Fills, Patterns: scheme.Patterns}
+	out := svg.NewWriter(w, 8*svgScale+2*svgMargin, 6*svgScale+2*svgMargin)
+
+	for i, st := range stickers {
+		points := make([]svg.Point, len(st.polygon))
+		for k, pt := range st.polygon {
+			points[k] = svg.Point{X: svgMargin + pt.X*svgScale, Y: svgMargin + pt.Y*svgScale}
+		}
+		out.Sticker(style, s.stickers[i], svg.Shrink(0.85, points...)...)
+	}
+
+	return out.Close()
+}
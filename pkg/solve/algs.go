@@ -0,0 +1,173 @@
+package solve
+
+import (
+	"cmp"
+	"errors"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"slices"
+	"strings"
+)
+
+var ErrAlgTarget = errors.New("neither an effect nor a mask given")
+
+// AlgOptions describes the algorithms that GenerateAlgs searches for: those
+// with an exact effect, or those that complete a mask from a case.
+type AlgOptions struct {
+	// Effect, if set, is the exact effect of the algorithms, such as the
+	// StateOf an algorithm to find others for.
+	Effect *State
+
+	// Mask, if Effect is nil, is the partial state that the algorithms
+	// complete from Case, such as LayerMask(cube.FaceUp) for last layer
+	// algorithms of a case set up by its inverse.
+	Mask Mask
+	Case State
+
+	MaxMoves   int         // Longest algorithm, 8 if zero
+	Generators []Generator // The move set, FaceTurns if nil, see Subset
+	Limit      int         // Algorithms returned, all of them if zero
+
+	// Symmetric keeps only one of the algorithms that a symmetry of the
+	// cube keeping the target turns into each other, such as R U R' and
+	// its mirror L' U' L for an effect that the mirror keeps.
+	Symmetric bool
+
+	// Cost, if set, orders the algorithms from the lowest cost rather than
+	// the fewest moves, such as the weighted cost of analysis.CostModel.
+	// Limit still keeps the shortest algorithms found first.
+	Cost func([]cube.Move) float64
+}
+
+// GenerateAlgs returns every algorithm of up to MaxMoves moves of the move
+// set that has the target effect, shortest first or by Cost, as the building
+// block of alg hunting. Moves that commute are tried in one order only, so
+// that R L and L R count once.
+func GenerateAlgs(opts AlgOptions) ([][]cube.Move, error) {
+	if opts.MaxMoves <= 0 {
+		opts.MaxMoves = 8
+	}
+	if opts.Generators == nil {
+		opts.Generators = FaceTurns
+	}
+
+	search := Search{Generators: opts.Generators}
+	start := Solved
+	var keeps func(k int) bool
+	switch {
+	case opts.Effect != nil:
+		target := *opts.Effect
+		dist := distances(target, opts.Generators, min(tableDepth, opts.MaxMoves))
+		search.Goal = func(s State) bool { return s == target }
+		search.Bound = func(s State) int {
+			if d, ok := dist[s]; ok {
+				return d
+			}
+			return tableDepth + 1
+		}
+		keeps = func(k int) bool { return target.Conjugate(k) == target }
+	case len(opts.Mask.alternatives) > 0:
+		start = opts.Case
+		search.Goal = opts.Mask.Matches
+		// The bound counts face turns, which slice and wide turns outdo.
+		if faceTurnsOnly(opts.Generators) {
+			search.Bound = opts.Mask.distance
+		}
+		keeps = func(k int) bool {
+			return opts.Case.Conjugate(k) == opts.Case && opts.Mask.keptBy(Symmetries[k])
+		}
+	default:
+		return nil, ErrAlgTarget
+	}
+
+	// Algorithms that a symmetry turns into each other share the smallest of
+	// the keys of their conjugates.
+	var symmetries []int
+	if opts.Symmetric {
+		for k := range Symmetries {
+			if keeps(k) {
+				symmetries = append(symmetries, k)
+			}
+		}
+	}
+	effects := map[cube.Move]State{}
+	for _, g := range opts.Generators {
+		effects[g.Move] = g.State
+	}
+	seen := map[string]bool{}
+
+	var found [][]cube.Move
+	search.Found = func(moves []cube.Move) bool {
+		if len(symmetries) > 0 {
+			key := ""
+			for _, k := range symmetries {
+				var b []byte
+				for _, m := range moves {
+					c := effects[m].Conjugate(k)
+					b = append(b, c[:]...)
+				}
+				if key == "" || string(b) < key {
+					key = string(b)
+				}
+			}
+			if seen[key] {
+				return true
+			}
+			seen[key] = true
+		}
+		found = append(found, moves)
+		return opts.Limit <= 0 || len(found) < opts.Limit
+	}
+	search.Run(start, opts.MaxMoves)
+	if opts.Cost != nil {
+		sortByCost(found, opts.Cost)
+	}
+	return found, nil
+}
+
+// sortByCost sorts algorithms from the lowest cost, keeping the order of
+// those of the same cost.
+func sortByCost(algs [][]cube.Move, cost func([]cube.Move) float64) {
+	costs := make([]float64, len(algs))
+	order := make([]int, len(algs))
+	for i, moves := range algs {
+		costs[i] = cost(moves)
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return cmp.Compare(costs[a], costs[b]) })
+	sorted := make([][]cube.Move, len(algs))
+	for i, j := range order {
+		sorted[i] = algs[j]
+	}
+	copy(algs, sorted)
+}
+
+// faceTurnsOnly reports whether the generators are all outer layer turns.
+func faceTurnsOnly(gens []Generator) bool {
+	for _, g := range gens {
+		if g.Move.Wide || !strings.ContainsRune(faceLetters, g.Move.Operator) {
+			return false
+		}
+	}
+	return true
+}
+
+// keptBy reports whether a symmetry maps every alternative of the mask to an
+// alternative of it.
+func (m Mask) keptBy(sym State) bool {
+	for _, set := range m.alternatives {
+		var mapped stickerSet
+		for i, in := range set {
+			if in {
+				mapped[sym[i]] = true
+			}
+		}
+		kept := false
+		for _, other := range m.alternatives {
+			kept = kept || other == mapped
+		}
+		if !kept {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,123 @@
+package solve
+
+import (
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"strings"
+)
+
+// Annotation explains the moves of a solution from index Start up to but
+// excluding End, such as "pair FR corner+edge", for output to learn from.
+type Annotation struct {
+	Text  string
+	Start int
+	End   int
+}
+
+// Segment is a run of the moves of a solution, with the text of its
+// annotation or none.
+type Segment struct {
+	Moves []cube.Move
+	Text  string
+}
+
+// Segments splits the moves of the solution at its annotations, with the
+// moves between annotations in segments of their own.
+func (s Solution) Segments() []Segment {
+	var out []Segment
+	at := 0
+	for _, a := range s.Annotations {
+		if a.Start > at {
+			out = append(out, Segment{s.Moves[at:a.Start], ""})
+		}
+		out = append(out, Segment{s.Moves[a.Start:a.End], a.Text})
+		at = max(at, a.End)
+	}
+	if at < len(s.Moves) {
+		out = append(out, Segment{s.Moves[at:], ""})
+	}
+	return out
+}
+
+// Explain returns the segments of the solution a line each, followed by
+// their annotation as a // comment, the way reconstructions are written, as
+// analysis.Annotate does for phases.
+func (s Solution) Explain() string {
+	var sb strings.Builder
+	for _, seg := range s.Segments() {
+		sb.WriteString(cube.FormatMoves(seg.Moves))
+		if len(seg.Moves) > 0 && seg.Text != "" {
+			sb.WriteByte(' ')
+		}
+		if seg.Text != "" {
+			sb.WriteString("// " + seg.Text)
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// annotate appends the moves to the solution with an annotation.
+func (s *Solution) annotate(text string, moves []cube.Move) {
+	start := len(s.Moves)
+	s.Moves = append(s.Moves, moves...)
+	s.Annotations = append(s.Annotations, Annotation{text, start, len(s.Moves)})
+}
+
+// twoPhaseAnnotations returns the annotations of a two-phase solution of the
+// state: its first phase ends at the first move that brings the cube into
+// <U, D, L2, F2, R2, B2>.
+func twoPhaseAnnotations(s State, moves []cube.Move) []Annotation {
+	end := len(moves)
+	t := s
+	for i := 0; i <= len(moves); i++ {
+		if c, err := cubieOf(t); err == nil && c.twist() == 0 && c.flip() == 0 && c.slice() == 0 {
+			end = i
+			break
+		}
+		if i < len(moves) {
+			m, _ := StateOf(moves[i])
+			t = t.Then(m)
+		}
+	}
+	return []Annotation{
+		{"phase 1: orient corners and edges, E slice edges into E", 0, end},
+		{"phase 2: solve with U, D and half turns of the sides", end, len(moves)},
+	}
+}
+
+// pocketExplanations tell what the steps of SolvePocket do, by their name.
+var pocketExplanations = map[string]string{
+	"Rotation": "hold the cube with the DBL corner at home",
+	"Face":     "build the face of the color of D",
+	"OLL":      "orient the last layer",
+	"PBL":      "permute both layers",
+	"Layer":    "build the first layer",
+	"CLL":      "solve the last layer at once",
+}
+
+// PocketSolution returns the steps of SolvePocket as one solution, annotated
+// with what every step does.
+func PocketSolution(steps []PocketStep) Solution {
+	var s Solution
+	for _, step := range steps {
+		s.annotate(step.Name+": "+pocketExplanations[step.Name], step.Moves)
+	}
+	return s
+}
+
+// F2LSolution returns a cross and the steps of SolveF2L as one solution,
+// annotated with the cross, the rotations and the pairing and inserting of
+// every slot.
+func F2LSolution(cross []cube.Move, steps []F2LStep) Solution {
+	var s Solution
+	s.annotate("cross on D", cross)
+	for _, step := range steps {
+		if len(step.Rotation) > 0 {
+			s.annotate(fmt.Sprintf("rotate to bring %s to the front", step.Slot), step.Rotation)
+		}
+		s.annotate(fmt.Sprintf("pair %s corner+edge", step.Slot), step.Pair)
+		s.annotate(fmt.Sprintf("insert %s", step.Slot), step.Insert)
+	}
+	return s
+}
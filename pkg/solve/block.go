@@ -0,0 +1,86 @@
+package solve
+
+import (
+	"github.com/larssont/go-cubic/pkg/cube"
+	"sync"
+)
+
+// blockStickers tracks the 1x2x3 block on L and D of the block table: the
+// L stickers of its edges DL, FL and BL and of its corners DLF and DBL.
+var blockStickers = func() []int {
+	var stickers []int
+	for _, name := range []string{"LD", "LF", "LB", "LFD", "LDB"} {
+		s, _ := stickersOf(name)
+		stickers = append(stickers, s[0])
+	}
+	return stickers
+}()
+
+// blockTable holds the distance of every placement of the block stickers
+// from solved.
+var blockTable = sync.OnceValue(func() []int8 {
+	return loadedTable("block", func() []int8 { return stickerTable(blockStickers) })
+})
+
+// blockTurn returns what a rotation that brings the side to L and the
+// bottom to D does to every sticker, and false for faces that are not
+// adjacent.
+func blockTurn(side, bottom cube.Face) (State, bool) {
+	for _, r := range Rotations {
+		turn := r.Inverse()
+		if int(turn[int(side)*9+4]) == int(cube.FaceLeft)*9+4 && int(turn[int(bottom)*9+4]) == downCenter {
+			return turn, true
+		}
+	}
+	return State{}, false
+}
+
+// BlockLength returns the length of the shortest solution of the 1x2x3
+// block on the side and the bottom, such as the first block of Roux on L and
+// D, for a state with the centers in place. It returns -1 for faces that are
+// not adjacent. The table takes a second to build on first use.
+func BlockLength(s State, side, bottom cube.Face) int {
+	turn, ok := blockTurn(side, bottom)
+	if !ok {
+		return -1
+	}
+	where := s.Inverse()
+	back := turn.Inverse()
+	i := 0
+	for _, sticker := range blockStickers {
+		slot, _ := slotOf(int(turn[where[back[sticker]]]))
+		i = i*24 + slot
+	}
+	return int(blockTable()[i])
+}
+
+// SolveBlock returns the shortest solutions of the 1x2x3 block on the side
+// and the bottom of the state, shortest first. MaxMoves defaults to 10.
+func SolveBlock(s State, side, bottom cube.Face, opts CrossOptions) [][]cube.Move {
+	if opts.MaxMoves <= 0 {
+		opts.MaxMoves = 10
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = 1
+	}
+	if _, ok := blockTurn(side, bottom); !ok {
+		return nil
+	}
+
+	var solutions [][]cube.Move
+	search := Search{
+		Generators: FaceTurns,
+		Goal: func(s State) bool {
+			return BlockLength(s, side, bottom) == 0
+		},
+		Bound: func(s State) int {
+			return BlockLength(s, side, bottom)
+		},
+		Found: func(moves []cube.Move) bool {
+			solutions = append(solutions, moves)
+			return len(solutions) < opts.Limit
+		},
+	}
+	opts.run(&search, s)
+	return solutions
+}
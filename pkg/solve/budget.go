@@ -0,0 +1,91 @@
+package solve
+
+import (
+	"github.com/larssont/go-cubic/pkg/cube"
+	"time"
+)
+
+// StopReason tells why a search ended.
+type StopReason int
+
+const (
+	// Completed searches ran to their end: they found the solutions asked
+	// for, or every sequence up to their maximum length.
+	Completed StopReason = iota
+
+	// ReachedTarget searches stopped at a solution short enough.
+	ReachedTarget
+
+	// OutOfTime searches stopped at their time limit.
+	OutOfTime
+
+	// OutOfNodes searches stopped after visiting their maximum of nodes.
+	OutOfNodes
+)
+
+func (r StopReason) String() string {
+	switch r {
+	case ReachedTarget:
+		return "reached target"
+	case OutOfTime:
+		return "out of time"
+	case OutOfNodes:
+		return "out of nodes"
+	}
+	return "completed"
+}
+
+// SearchStats tells how much a search did and why it ended.
+type SearchStats struct {
+	Nodes   int
+	Elapsed time.Duration
+	Stopped StopReason
+}
+
+// Solution is the result of a solver along with the statistics of its
+// search. When the search ran out of time or nodes, a shorter solution may
+// exist. Solvers asked to, and PocketSolution and F2LSolution, explain
+// segments of the moves in Annotations, in order.
+type Solution struct {
+	Moves       []cube.Move
+	Annotations []Annotation
+	SearchStats
+}
+
+// budgetCheck is the number of nodes between checks of the clock.
+const budgetCheck = 1024
+
+// budget counts the nodes of a search against its limits.
+type budget struct {
+	start    time.Time
+	deadline time.Time // Zero for no limit
+	maxNodes int       // Zero for no limit
+	nodes    int
+	stopped  StopReason
+}
+
+func newBudget(maxTime time.Duration, maxNodes int) budget {
+	b := budget{start: time.Now(), maxNodes: maxNodes}
+	if maxTime > 0 {
+		b.deadline = b.start.Add(maxTime)
+	}
+	return b
+}
+
+// spend counts a node, and reports false once a limit is reached.
+func (b *budget) spend() bool {
+	b.nodes++
+	if b.maxNodes > 0 && b.nodes > b.maxNodes {
+		b.stopped = OutOfNodes
+		return false
+	}
+	if !b.deadline.IsZero() && b.nodes%budgetCheck == 0 && time.Now().After(b.deadline) {
+		b.stopped = OutOfTime
+		return false
+	}
+	return true
+}
+
+func (b *budget) stats() SearchStats {
+	return SearchStats{Nodes: b.nodes, Elapsed: time.Since(b.start), Stopped: b.stopped}
+}
@@ -0,0 +1,115 @@
+package solve
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var ErrEncoding = errors.New("not an encoded 3x3 state")
+
+// EncodedSize is the length of an encoded state.
+const EncodedSize = 9
+
+// The encoding packs the corners as their permutation and twist in the low
+// 27 bits, and the edges as half the rank of their permutation, whose parity
+// follows from the corners, and their flip in the 39 bits above.
+const (
+	cornerBits = 27
+
+	numTwists       = 2187      // 3^7
+	numFlips        = 2048      // 2^11
+	numCornerPerms  = 40320     // 8!
+	numEdgeHalfPerm = 239500800 // 12!/2
+)
+
+// Encode packs a solvable state with the centers in place into EncodedSize
+// bytes, for storing many states, such as datasets and pattern databases.
+// Encoded states can be compared and used as map keys.
+func Encode(s State) ([EncodedSize]byte, error) {
+	c, err := cubieOf(s)
+	if err != nil {
+		return [EncodedSize]byte{}, err
+	}
+
+	corners := uint64(c.cornerPerm())*numTwists + uint64(c.twist())
+	edges := uint64(permIndex(c.ep[:])/2)*numFlips + uint64(c.flip())
+
+	var out [EncodedSize]byte
+	binary.LittleEndian.PutUint64(out[:8], corners|edges<<cornerBits)
+	out[8] = byte(edges >> (64 - cornerBits))
+	return out, nil
+}
+
+// Decode unpacks a state packed by Encode.
+func Decode(b [EncodedSize]byte) (State, error) {
+	low := binary.LittleEndian.Uint64(b[:8])
+	corners := low & (1<<cornerBits - 1)
+	edges := low>>cornerBits | uint64(b[8])<<(64-cornerBits)
+	if corners >= numCornerPerms*numTwists || edges >= numEdgeHalfPerm*numFlips {
+		return State{}, ErrEncoding
+	}
+
+	var c cubie
+	permFromIndex(c.cp[:], int(corners/numTwists))
+	setOrientation(c.co[:], int(corners%numTwists), 3)
+
+	half := int(edges / numFlips)
+	permFromIndex(c.ep[:], 2*half)
+	if parity(c.ep[:]) != parity(c.cp[:]) {
+		permFromIndex(c.ep[:], 2*half+1)
+	}
+	setOrientation(c.eo[:], int(edges%numFlips), 2)
+	return stateOf(c), nil
+}
+
+// MarshalBinary encodes the state as Encode does.
+func (s State) MarshalBinary() ([]byte, error) {
+	b, err := Encode(s)
+	if err != nil {
+		return nil, err
+	}
+	return b[:], nil
+}
+
+// UnmarshalBinary decodes a state encoded by MarshalBinary.
+func (s *State) UnmarshalBinary(data []byte) error {
+	if len(data) != EncodedSize {
+		return ErrEncoding
+	}
+	decoded, err := Decode([EncodedSize]byte(data))
+	if err != nil {
+		return err
+	}
+	*s = decoded
+	return nil
+}
+
+// permFromIndex sets p to the permutation of 0 to len(p)-1 of the rank, the
+// inverse of permIndex.
+func permFromIndex(p []uint8, rank int) {
+	digits := make([]int, len(p))
+	for i := len(p) - 1; i >= 0; i-- {
+		digits[i] = rank % (len(p) - i)
+		rank /= len(p) - i
+	}
+	left := make([]uint8, len(p))
+	for i := range left {
+		left[i] = uint8(i)
+	}
+	for i, d := range digits {
+		p[i] = left[d]
+		left = append(left[:d], left[d+1:]...)
+	}
+}
+
+// setOrientation sets the orientations of all pieces but the last from the
+// digits of n in the base, first digit first as in twist and flip, and the
+// last one so that they sum to a multiple of the base.
+func setOrientation(o []uint8, n, base int) {
+	last := len(o) - 1
+	for i := last - 1; i >= 0; i-- {
+		o[i] = uint8(n % base)
+		n /= base
+	}
+	o[last] = uint8((base - sum(o[:last])%base) % base)
+}
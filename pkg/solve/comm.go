@@ -0,0 +1,121 @@
+package solve
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"slices"
+)
+
+var (
+	ErrNoDecomposition = errors.New("no commutator decomposition found")
+)
+
+// Decomposition is an algorithm written as the conjugate [Setup: [A, B]], or
+// the plain commutator [A, B] if Setup is empty.
+type Decomposition struct {
+	Setup []cube.Move
+	A     []cube.Move
+	B     []cube.Move
+}
+
+func (d Decomposition) String() string {
+	comm := fmt.Sprintf("[%s, %s]", cube.FormatMoves(d.A), cube.FormatMoves(d.B))
+	if len(d.Setup) == 0 {
+		return comm
+	}
+	return fmt.Sprintf("[%s: %s]", cube.FormatMoves(d.Setup), comm)
+}
+
+// Len returns the number of moves of the expanded algorithm, before
+// cancellations.
+func (d Decomposition) Len() int {
+	return 2*len(d.Setup) + 2*len(d.A) + 2*len(d.B)
+}
+
+type CommOptions struct {
+	MaxSetup       int         // Moves of the setup, 2 if zero
+	MaxInsert      int         // Moves of the longer part, 3 if zero
+	MaxInterchange int         // Moves of the shorter part, 1 if zero
+	Generators     []Generator // FaceTurns and SliceTurns if nil
+	Limit          int         // Decompositions returned, 10 if zero
+}
+
+// FindCommutators searches for [A, B] and [S: [A, B]] forms of an
+// algorithm, such as a pure 3-cycle or twist, and returns them from shortest
+// to longest, up to the limit.
+func FindCommutators(alg []cube.Move, opts CommOptions) ([]Decomposition, error) {
+	if opts.MaxSetup == 0 {
+		opts.MaxSetup = 2
+	}
+	if opts.MaxInsert == 0 {
+		opts.MaxInsert = 3
+	}
+	if opts.MaxInterchange == 0 {
+		opts.MaxInterchange = 1
+	}
+	if opts.Generators == nil {
+		opts.Generators = slices.Concat(FaceTurns, SliceTurns)
+	}
+	if opts.Limit == 0 {
+		opts.Limit = 10
+	}
+
+	target, err := StateOf(alg...)
+	if err != nil {
+		return nil, err
+	}
+
+	// [S: C] equals the algorithm when C equals S' alg S, so every setup is
+	// indexed by the commutator it needs.
+	setups := map[State][][]cube.Move{target: {nil}}
+	for _, s := range sequences(opts.Generators, opts.MaxSetup) {
+		needed := s.state.Inverse().Then(target).Then(s.state)
+		setups[needed] = append(setups[needed], s.moves)
+	}
+
+	inserts := sequences(opts.Generators, max(opts.MaxInsert, opts.MaxInterchange))
+	var interchanges []sequence
+	for _, s := range inserts {
+		if len(s.moves) <= opts.MaxInterchange {
+			interchanges = append(interchanges, s)
+		}
+	}
+	inverses := func(seqs []sequence) []State {
+		out := make([]State, len(seqs))
+		for i, s := range seqs {
+			out[i] = s.state.Inverse()
+		}
+		return out
+	}
+	insertInverses, interchangeInverses := inverses(inserts), inverses(interchanges)
+
+	var found []Decomposition
+	check := func(a, b sequence, aInv, bInv State) {
+		comm := a.state.Then(b.state).Then(aInv).Then(bInv)
+		if comm == Solved {
+			return
+		}
+		for _, setup := range setups[comm] {
+			found = append(found, Decomposition{setup, a.moves, b.moves})
+		}
+	}
+	for i, a := range inserts {
+		for j, b := range interchanges {
+			check(a, b, insertInverses[i], interchangeInverses[j])
+			if len(a.moves) > opts.MaxInterchange {
+				check(b, a, interchangeInverses[j], insertInverses[i])
+			}
+		}
+	}
+
+	if len(found) == 0 {
+		return nil, ErrNoDecomposition
+	}
+
+	slices.SortStableFunc(found, func(a, b Decomposition) int {
+		return cmp.Or(cmp.Compare(a.Len(), b.Len()), cmp.Compare(len(a.Setup), len(b.Setup)))
+	})
+	return found[:min(len(found), opts.Limit)], nil
+}
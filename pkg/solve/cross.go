@@ -0,0 +1,162 @@
+package solve
+
+import (
+	"github.com/larssont/go-cubic/pkg/cube"
+	"sync"
+	"time"
+)
+
+// CrossOptions configures SolveCross.
+type CrossOptions struct {
+	// MaxMoves bounds the length of the solutions. Defaults to 8, which is
+	// enough for every cross.
+	MaxMoves int
+
+	// Limit is the number of solutions returned. Defaults to 1.
+	Limit int
+
+	// Table, if set, shares what the search learns with later solves of the
+	// same kind, face and slots, such as a batch of scrambles.
+	Table *TranspositionTable
+
+	// MaxTime and MaxNodes, if set, end the search early with the solutions
+	// found so far.
+	MaxTime  time.Duration
+	MaxNodes int
+
+	// Stats, if set, receives how much the search did and why it ended.
+	Stats *SearchStats
+}
+
+// run runs the search from the state with the table and limits of the
+// options.
+func (opts CrossOptions) run(search *Search, start State) {
+	search.Table = opts.Table
+	search.MaxTime, search.MaxNodes = opts.MaxTime, opts.MaxNodes
+	search.Run(start, opts.MaxMoves)
+	if opts.Stats != nil {
+		*opts.Stats = search.Stats
+	}
+}
+
+// edgeSlots numbers the 24 edge stickers, and is -1 for the other stickers.
+var edgeSlots = func() [NumStickers]int {
+	var slots [NumStickers]int
+	for i := range slots {
+		slots[i] = -1
+	}
+	n := 0
+	for _, stickers := range edgeStickers {
+		for _, s := range stickers {
+			slots[s] = n
+			n++
+		}
+	}
+	return slots
+}()
+
+// edgeMoves holds the slot every edge sticker moves to with every face turn.
+var edgeMoves = func() [][24]int {
+	out := make([][24]int, len(FaceTurns))
+	for i, g := range FaceTurns {
+		forward := g.State.Inverse()
+		for s, slot := range edgeSlots {
+			if slot >= 0 {
+				out[i][slot] = edgeSlots[forward[s]]
+			}
+		}
+	}
+	return out
+}()
+
+// crossStickers returns the edge stickers of the face, which the cross moves
+// back to the face.
+func crossStickers(f cube.Face) [4]int {
+	base := int(f) * 9
+	return [4]int{base + 1, base + 3, base + 5, base + 7}
+}
+
+// crossIndex packs the slots of the four cross stickers.
+func crossIndex(slots [4]int) int {
+	return ((slots[0]*24+slots[1])*24+slots[2])*24 + slots[3]
+}
+
+// crossTable holds the distance of every cross position on D from the solved
+// cross, one entry for the positions symmetric about D. The other faces use
+// it through the rotation that turns their cross to D.
+var crossTable = sync.OnceValue(func() *SymTable {
+	defer observeTable("cross", time.Now())
+	d := crossStickers(cube.FaceDown)
+	return NewSymTable(d[:])
+})
+
+// crossTurn is the rotation of a face to D: the sticker of the face that
+// turns into each cross sticker of D, and the slot each edge slot turns into.
+type crossTurn struct {
+	stickers [4]int
+	slots    [24]int
+}
+
+var crossTurns = func() [6]crossTurn {
+	var out [6]crossTurn
+	d := crossStickers(cube.FaceDown)
+	for f := range out {
+		for _, r := range Rotations {
+			turn := r.Inverse()
+			if int(r[d[0]])/9 != f {
+				continue
+			}
+			for i, s := range d {
+				out[f].stickers[i] = int(r[s])
+			}
+			for s, slot := range edgeSlots {
+				if slot >= 0 {
+					out[f].slots[slot] = edgeSlots[turn[s]]
+				}
+			}
+			break
+		}
+	}
+	return out
+}()
+
+// CrossLength returns the length of the shortest solution of the cross on
+// face f, for a state with the centers in place.
+func CrossLength(s State, f cube.Face) int {
+	where := s.Inverse()
+	turn := &crossTurns[f]
+	var slots [4]int
+	for i, sticker := range turn.stickers {
+		slots[i] = turn.slots[edgeSlots[where[sticker]]]
+	}
+	t := crossTable()
+	return int(t.dist[t.class(slots[:])])
+}
+
+// SolveCross returns the shortest solutions of the cross on face f of the
+// state, shortest first.
+func SolveCross(s State, f cube.Face, opts CrossOptions) [][]cube.Move {
+	if opts.MaxMoves <= 0 {
+		opts.MaxMoves = 8
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = 1
+	}
+
+	var solutions [][]cube.Move
+	search := Search{
+		Generators: FaceTurns,
+		Goal: func(s State) bool {
+			return CrossLength(s, f) == 0
+		},
+		Bound: func(s State) int {
+			return CrossLength(s, f)
+		},
+		Found: func(moves []cube.Move) bool {
+			solutions = append(solutions, moves)
+			return len(solutions) < opts.Limit
+		},
+	}
+	opts.run(&search, s)
+	return solutions
+}
@@ -0,0 +1,221 @@
+package solve
+
+import (
+	"errors"
+)
+
+var ErrInvalidState = errors.New("state is not a solvable 3x3 state")
+
+// The positions of corners and edges of the piece level model. The first
+// letter names the reference sticker of a piece, on U or D, or on F or B for
+// the edges of the E slice.
+var (
+	cornerNames = [8]string{"URF", "UFL", "ULB", "UBR", "DFR", "DLF", "DBL", "DRB"}
+	edgeNames   = [12]string{"UR", "UF", "UL", "UB", "DR", "DF", "DL", "DB", "FR", "FL", "BL", "BR"}
+)
+
+var cornerStickers, edgeStickers = func() (c [8][]int, e [12][]int) {
+	for i, name := range cornerNames {
+		c[i], _ = stickersOf(name)
+	}
+	for i, name := range edgeNames {
+		e[i], _ = stickersOf(name)
+	}
+	return c, e
+}()
+
+// cubie is a 3x3 state at the level of pieces. Position i holds corner cp[i],
+// whose reference sticker is at sticker co[i] of the position, and likewise
+// for the edges.
+type cubie struct {
+	cp [8]uint8
+	co [8]uint8
+	ep [12]uint8
+	eo [12]uint8
+}
+
+var solvedCubie = func() cubie {
+	var c cubie
+	for i := range c.cp {
+		c.cp[i] = uint8(i)
+	}
+	for i := range c.ep {
+		c.ep[i] = uint8(i)
+	}
+	return c
+}()
+
+// cubieOf converts a sticker state, which must keep the centers in place.
+func cubieOf(s State) (cubie, error) {
+	if !s.centersSolved() {
+		return cubie{}, ErrInvalidState
+	}
+
+	var c cubie
+	if !pieces(s, cornerStickers[:], c.cp[:], c.co[:]) ||
+		!pieces(s, edgeStickers[:], c.ep[:], c.eo[:]) ||
+		!c.valid() {
+		return cubie{}, ErrInvalidState
+	}
+	return c, nil
+}
+
+// BadEdges counts the edges of a state with the centers in place that cannot
+// be solved without quarter turns of F or B.
+func BadEdges(s State) int {
+	var perm, orient [12]uint8
+	pieces(s, edgeStickers[:], perm[:], orient[:])
+	return sum(orient[:])
+}
+
+// pieces fills the permutation and orientation of one kind of piece, and
+// reports whether every piece was found.
+func pieces(s State, stickers [][]int, perm, orient []uint8) bool {
+	for pos, slot := range stickers {
+		found := false
+		for k, sticker := range slot {
+			for piece, home := range stickers {
+				if int(s[sticker]) == home[0] {
+					perm[pos], orient[pos] = uint8(piece), uint8(k)
+					found = true
+				}
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// valid reports whether the pieces form a permutation with solvable twist,
+// flip and parity.
+func (c *cubie) valid() bool {
+	return isPermutation(c.cp[:]) && isPermutation(c.ep[:]) &&
+		sum(c.co[:])%3 == 0 && sum(c.eo[:])%2 == 0 &&
+		parity(c.cp[:]) == parity(c.ep[:])
+}
+
+func isPermutation(p []uint8) bool {
+	seen := make([]bool, len(p))
+	for _, v := range p {
+		if int(v) >= len(p) || seen[v] {
+			return false
+		}
+		seen[v] = true
+	}
+	return true
+}
+
+func sum(values []uint8) int {
+	n := 0
+	for _, v := range values {
+		n += int(v)
+	}
+	return n
+}
+
+// parity returns 1 for odd permutations and 0 for even ones.
+func parity(p []uint8) int {
+	n := 0
+	for i := range p {
+		for j := i + 1; j < len(p); j++ {
+			if p[i] > p[j] {
+				n++
+			}
+		}
+	}
+	return n % 2
+}
+
+// then returns the effect of c followed by d.
+func (c cubie) then(d cubie) cubie {
+	var out cubie
+	for i, p := range d.cp {
+		out.cp[i] = c.cp[p]
+		out.co[i] = (c.co[p] + d.co[i]) % 3
+	}
+	for i, p := range d.ep {
+		out.ep[i] = c.ep[p]
+		out.eo[i] = (c.eo[p] + d.eo[i]) % 2
+	}
+	return out
+}
+
+// twist is the orientation of the first seven corners, 0 to 2186.
+func (c *cubie) twist() int {
+	n := 0
+	for _, o := range c.co[:7] {
+		n = 3*n + int(o)
+	}
+	return n
+}
+
+// flip is the orientation of the first eleven edges, 0 to 2047.
+func (c *cubie) flip() int {
+	n := 0
+	for _, o := range c.eo[:11] {
+		n = 2*n + int(o)
+	}
+	return n
+}
+
+// slice is the set of positions of the E slice edges, 0 to 494, which is 0
+// when they are in the E slice.
+func (c *cubie) slice() int {
+	n, k := 0, 0
+	for j := 11; j >= 0; j-- {
+		if c.ep[j] >= 8 {
+			k++
+			n += binomial(11-j, k)
+		}
+	}
+	return n
+}
+
+// cornerPerm is the permutation of the corners, 0 to 40319.
+func (c *cubie) cornerPerm() int {
+	return permIndex(c.cp[:])
+}
+
+// edgePerm is the permutation of the U and D edges, 0 to 40319, for states
+// with the E slice edges in the E slice.
+func (c *cubie) edgePerm() int {
+	return permIndex(c.ep[:8])
+}
+
+// slicePerm is the permutation of the E slice edges, 0 to 23, for states with
+// the E slice edges in the E slice.
+func (c *cubie) slicePerm() int {
+	var p [4]uint8
+	for i, e := range c.ep[8:] {
+		p[i] = e - 8
+	}
+	return permIndex(p[:])
+}
+
+// permIndex returns the rank of a permutation of 0 to n-1.
+func permIndex(p []uint8) int {
+	n := 0
+	for i := range p {
+		smaller := 0
+		for _, v := range p[i+1:] {
+			if v < p[i] {
+				smaller++
+			}
+		}
+		n = n*(len(p)-i) + smaller
+	}
+	return n
+}
+
+func binomial(n, k int) int {
+	if k > n {
+		return 0
+	}
+	out := 1
+	for i := range k {
+		out = out * (n - i) / (i + 1)
+	}
+	return out
+}
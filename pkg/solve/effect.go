@@ -0,0 +1,108 @@
+package solve
+
+import (
+	"errors"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"slices"
+)
+
+var (
+	ErrNoEffect = errors.New("neither cycles nor pieces given")
+)
+
+// EffectOptions describes the effect of the algorithms to search for.
+type EffectOptions struct {
+	// Cycles is the exact effect, as cycles in the format of CycleState,
+	// e.g. {{"UBL", "UFR", "RDF"}}.
+	Cycles [][]string
+
+	// Pieces allows any effect on these pieces, if Cycles is empty.
+	Pieces []string
+
+	MaxMoves   int         // 8 if zero, or 6 when searching by pieces
+	Generators []Generator // FaceTurns if nil
+	Limit      int         // Algorithms returned, 20 if zero
+}
+
+// Candidate is an algorithm found by SearchEffect.
+type Candidate struct {
+	Moves  []cube.Move
+	Cycles [][]string
+}
+
+// tableDepth is the depth of the distance table used as the bound when
+// searching for an exact effect.
+const tableDepth = 4
+
+// SearchEffect returns algorithms of up to the maximum number of moves that
+// affect only the given pieces, shortest first.
+func SearchEffect(opts EffectOptions) ([]Candidate, error) {
+	if opts.MaxMoves == 0 {
+		opts.MaxMoves = 8
+		if len(opts.Cycles) == 0 {
+			opts.MaxMoves = 6
+		}
+	}
+	if opts.Generators == nil {
+		opts.Generators = FaceTurns
+	}
+	if opts.Limit == 0 {
+		opts.Limit = 20
+	}
+
+	search := Search{Generators: opts.Generators}
+	switch {
+	case len(opts.Cycles) > 0:
+		target := Solved
+		for _, c := range opts.Cycles {
+			if err := applyCycle(&target, c); err != nil {
+				return nil, err
+			}
+		}
+
+		dist := distances(target, opts.Generators, min(tableDepth, opts.MaxMoves))
+		search.Goal = func(s State) bool { return s == target }
+		search.Bound = func(s State) int {
+			if d, ok := dist[s]; ok {
+				return d
+			}
+			return tableDepth + 1
+		}
+	case len(opts.Pieces) > 0:
+		allowed := make([]bool, NumStickers)
+		for _, name := range opts.Pieces {
+			stickers, err := stickersOf(name)
+			if err != nil {
+				return nil, err
+			}
+			for _, s := range positions[stickerFaces[stickers[0]].pos] {
+				allowed[s] = true
+			}
+		}
+
+		search.Goal = func(s State) bool {
+			moved := s.Moved()
+			for _, i := range moved {
+				if !allowed[i] {
+					return false
+				}
+			}
+			return len(moved) > 0
+		}
+	default:
+		return nil, ErrNoEffect
+	}
+
+	var found []Candidate
+	search.Found = func(moves []cube.Move) bool {
+		s, _ := StateOf(moves...)
+		found = append(found, Candidate{moves, Cycles(s)})
+		return len(found) < opts.Limit
+	}
+	search.Run(Solved, opts.MaxMoves)
+
+	slices.SortStableFunc(found, func(a, b Candidate) int {
+		return len(a.Moves) - len(b.Moves)
+	})
+	return found, nil
+}
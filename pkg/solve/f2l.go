@@ -0,0 +1,328 @@
+package solve
+
+import (
+	"errors"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"strings"
+	"sync"
+)
+
+var ErrCrossUnsolved = errors.New("cross on D is not solved")
+
+// F2LOptions configures SolveF2L.
+type F2LOptions struct {
+	// Rotations is the most y rotations of the whole solution, each of which
+	// brings a back slot to the front. Without any, back slots are solved
+	// with B turns, which people avoid.
+	Rotations int
+
+	// MaxMoves bounds the moves that pair up the corner and the edge of a
+	// slot. Defaults to 8.
+	MaxMoves int
+}
+
+// F2LStep solves one slot the way people do: an optional rotation, the moves
+// that pair up its corner and edge, and a U turn and a trigger that insert
+// the pair. Its moves are written for the cube held as after the rotations so
+// far.
+type F2LStep struct {
+	Slot     string // The slot after the rotation, such as "FR"
+	Rotation []cube.Move
+	Pair     []cube.Move
+	Insert   []cube.Move
+}
+
+// Moves returns the moves of the step in order.
+func (s F2LStep) Moves() []cube.Move {
+	moves := append([]cube.Move{}, s.Rotation...)
+	moves = append(moves, s.Pair...)
+	return append(moves, s.Insert...)
+}
+
+// f2lFrames are the moves that every slot is solved with where it is held,
+// by its name: the faces of the slot and U, and the triggers that insert a
+// pair from the U layer.
+var f2lFrames = map[string]struct {
+	faces    string
+	triggers []string
+}{
+	"FR": {"RUF", []string{"R U R'", "R U' R'", "R U2 R'", "F' U F", "F' U' F", "F' U2 F"}},
+	"FL": {"LUF", []string{"L' U' L", "L' U L", "L' U2 L", "F U' F'", "F U F'", "F U2 F'"}},
+	"BR": {"RUB", []string{"R' U R", "R' U' R", "R' U2 R", "B U B'", "B U' B'", "B U2 B'"}},
+	"BL": {"LUB", []string{"L U L'", "L U' L'", "L U2 L'", "B' U' B", "B' U B", "B' U2 B"}},
+}
+
+// f2lSlotNames are the slots in the order that ties are broken in.
+var f2lSlotNames = []string{"FR", "FL", "BR", "BL"}
+
+// f2lSides are the side faces in the order that a y rotation turns them
+// to the front: after y, the face that was R is F.
+const f2lSides = "FRBL"
+
+// held returns the face of the scrambled cube that is at the face of the
+// letter after y rotations.
+func held(letter rune, ys int) rune {
+	i := strings.IndexRune(f2lSides, letter)
+	if i < 0 {
+		return letter
+	}
+	return rune(f2lSides[(i+ys)%4])
+}
+
+// holding returns the face that the face of the scrambled cube of the
+// letter is at after y rotations, the inverse of held.
+func holding(letter rune, ys int) rune {
+	return held(letter, 4-ys%4)
+}
+
+// heldName returns the name of the slot after y rotations, with the front or
+// back face first.
+func heldName(slot string, ys int) string {
+	var name []byte
+	for _, letter := range slot {
+		name = append(name, byte(holding(letter, ys)))
+	}
+	if name[0] == 'R' || name[0] == 'L' {
+		name[0], name[1] = name[1], name[0]
+	}
+	return string(name)
+}
+
+// f2lInsertion is a U turn and a trigger, with its effect on the scrambled
+// cube.
+type f2lInsertion struct {
+	moves []cube.Move
+	state State
+}
+
+// f2lMoves holds the turns and the insertions of every slot where it is
+// held, by its name, parsed once.
+var f2lMoves = sync.OnceValue(func() map[string]f2lFrameMoves {
+	parse := func(notation string) []cube.Move {
+		gens, err := NewGenerators(notation)
+		if err != nil {
+			panic(err)
+		}
+		var moves []cube.Move
+		for _, g := range gens {
+			moves = append(moves, g.Move)
+		}
+		return moves
+	}
+	out := map[string]f2lFrameMoves{}
+	for name, frame := range f2lFrames {
+		var m f2lFrameMoves
+		for _, adjust := range []string{"", "U", "U'", "U2"} {
+			for _, trigger := range frame.triggers {
+				m.inserts = append(m.inserts, parse(adjust+" "+trigger))
+			}
+		}
+		out[name] = m
+	}
+	return out
+})
+
+type f2lFrameMoves struct {
+	inserts [][]cube.Move
+}
+
+// heldState returns the effect on the scrambled cube of moves written for
+// the cube after y rotations.
+func heldState(moves []cube.Move, ys int) (State, error) {
+	s := Solved
+	for _, m := range moves {
+		m.Operator = held(m.Operator, ys)
+		t, err := StateOf(m)
+		if err != nil {
+			return State{}, err
+		}
+		s = s.Then(t)
+	}
+	return s, nil
+}
+
+// SolveF2L returns the first two layers of a state with the cross on D
+// solved, a slot at a time, as people solve them rather than as short as can
+// be: every slot is paired up and inserted with the turns of its faces and U
+// only. It takes the slot that is the fewest moves away next.
+func SolveF2L(s State, opts F2LOptions) ([]F2LStep, error) {
+	if !CrossMask(cube.FaceDown).Matches(s) {
+		return nil, ErrCrossUnsolved
+	}
+	if opts.MaxMoves <= 0 {
+		opts.MaxMoves = 8
+	}
+
+	done := CrossMask(cube.FaceDown)
+	var steps []F2LStep
+	ys, rotations := 0, opts.Rotations
+	for {
+		var best *F2LStep
+		var bestState State
+		bestCost, bestTurn := 0, 0
+		for _, slot := range f2lSlotNames {
+			m, _ := SlotMask(cube.FaceDown, slot)
+			if m.Matches(s) {
+				continue
+			}
+			for turn := range 4 {
+				if turn > 0 && rotations == 0 {
+					break
+				}
+				name := heldName(slot, ys+turn)
+				if turn > 0 && name[0] != 'F' {
+					continue
+				}
+				step, after, err := solveSlot(s, done, m, name, ys+turn, opts.MaxMoves)
+				if err != nil {
+					return nil, err
+				}
+				if step == nil {
+					continue
+				}
+				// A B turn or a rotation costs a move more, so that a
+				// slot is taken from the front when it is no longer.
+				cost := 0
+				for _, m := range append(step.Pair, step.Insert...) {
+					cost++
+					if m.Operator == 'B' {
+						cost++
+					}
+				}
+				if turn > 0 {
+					step.Rotation = []cube.Move{yRotation(turn)}
+					cost++
+				}
+				if best == nil || cost < bestCost {
+					best, bestState, bestCost, bestTurn = step, after, cost, turn
+				}
+			}
+		}
+		if best == nil {
+			if len(steps) < 4 && !F2LMask(cube.FaceDown).Matches(s) {
+				return steps, ErrNoSolution
+			}
+			return steps, nil
+		}
+
+		if bestTurn > 0 {
+			rotations--
+			ys += bestTurn
+		}
+		steps = append(steps, *best)
+		s = bestState
+		m, _ := SlotMask(cube.FaceDown, heldSlot(best.Slot, ys))
+		done = done.And(m)
+	}
+}
+
+// heldSlot returns the name of the slot of the scrambled cube that is at the
+// slot of the name after y rotations.
+func heldSlot(name string, ys int) string {
+	var slot []byte
+	for _, letter := range name {
+		slot = append(slot, byte(held(letter, ys)))
+	}
+	if slot[0] == 'R' || slot[0] == 'L' {
+		slot[0], slot[1] = slot[1], slot[0]
+	}
+	return string(slot)
+}
+
+// yRotation returns the rotation of turn quarter turns of y.
+func yRotation(turn int) cube.Move {
+	switch turn % 4 {
+	case 2:
+		return cube.Move{Operator: 'y', Rotations: 2}
+	case 3:
+		return cube.Move{Operator: 'y', Rotations: 1, Inverted: true}
+	}
+	return cube.Move{Operator: 'y', Rotations: 1}
+}
+
+// solveSlot returns the shortest pair up and insertion of the slot held as
+// the name after y rotations, keeping what is done solved, and the state
+// after it, or nil if there is none within maxMoves.
+func solveSlot(s State, done, slot Mask, name string, ys, maxMoves int) (*F2LStep, State, error) {
+	// A corner or an edge stuck in another slot is taken out with the turns
+	// of the faces of that slot.
+	faces := f2lFrames[name].faces
+	for _, piece := range []string{heldSlot(name, ys), heldSlot(name, ys) + "D"} {
+		at, err := Where(s, piece)
+		if err != nil {
+			return nil, State{}, err
+		}
+		if strings.ContainsRune(at, 'U') {
+			continue
+		}
+		for _, letter := range at {
+			if face := holding(letter, ys); face != 'D' && !strings.ContainsRune(faces, face) {
+				faces += string(face)
+			}
+		}
+	}
+
+	var gens []Generator
+	var inserts []f2lInsertion
+	for _, g := range turns(faces) {
+		state, err := heldState([]cube.Move{g.Move}, ys)
+		if err != nil {
+			return nil, State{}, err
+		}
+		gens = append(gens, Generator{g.Move, state})
+	}
+	moves := f2lMoves()[name]
+	for _, seq := range moves.inserts {
+		state, err := heldState(seq, ys)
+		if err != nil {
+			return nil, State{}, err
+		}
+		inserts = append(inserts, f2lInsertion{seq, state})
+	}
+
+	// The pair is up once the state is one insertion away from the slot, or
+	// in it already.
+	goal := done.And(slot)
+	insertion := func(t State) (f2lInsertion, bool) {
+		if goal.Matches(t) {
+			return f2lInsertion{state: Solved}, true
+		}
+		for _, ins := range inserts {
+			if goal.Matches(t.Then(ins.state)) {
+				return ins, true
+			}
+		}
+		return f2lInsertion{}, false
+	}
+
+	var step *F2LStep
+	var after State
+	search := Search{
+		Generators: gens,
+		Goal: func(t State) bool {
+			if !done.Matches(t) {
+				return false
+			}
+			_, ok := insertion(t)
+			return ok
+		},
+		Bound: done.distance,
+		Found: func(moves []cube.Move) bool {
+			t := s
+			for _, m := range moves {
+				for _, g := range gens {
+					if g.Move == m {
+						t = t.Then(g.State)
+						break
+					}
+				}
+			}
+			ins, _ := insertion(t)
+			step = &F2LStep{Slot: name, Pair: moves, Insert: ins.moves}
+			after = t.Then(ins.state)
+			return false
+		},
+	}
+	search.Run(s, maxMoves)
+	return step, after, nil
+}
@@ -0,0 +1,82 @@
+package solve
+
+import (
+	"errors"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"slices"
+)
+
+var ErrFacelets = errors.New("facelets are not the colors of a 3x3 cube")
+
+// homeFacelets holds the color of every sticker of a solved cube.
+var homeFacelets = []rune(cube.NewCube(3).State())
+
+// homeStickers maps the faces of a piece, sorted, and the face of one of its
+// stickers to the index of the sticker.
+var homeStickers = func() map[string]int {
+	out := map[string]int{}
+	for i := range NumStickers {
+		out[pieceKey(i, func(j int) int { return j / 9 })] = i
+	}
+	return out
+}()
+
+// pieceKey returns the faces of the piece holding sticker i, sorted, followed
+// by the face of the sticker.
+func pieceKey(i int, face func(int) int) string {
+	var faces []byte
+	for j := range NumStickers {
+		if SamePiece(i, j) {
+			faces = append(faces, byte('0'+face(j)))
+		}
+	}
+	slices.Sort(faces)
+	return string(append(faces, byte('0'+face(i))))
+}
+
+// ParseFacelets returns the state of a 3x3 cube from the colors of its
+// stickers, face by face in the order of Cube.State. Any six colors work, as
+// the center of every face gives its color. It returns ErrInvalidState for
+// colors of a cube that cannot be solved.
+func ParseFacelets(facelets string) (State, error) {
+	colors := []rune(facelets)
+	if len(colors) != NumStickers {
+		return State{}, ErrFacelets
+	}
+	faces := map[rune]int{}
+	for f := range 6 {
+		faces[colors[f*9+4]] = f
+	}
+	if len(faces) != 6 {
+		return State{}, ErrFacelets
+	}
+
+	var s State
+	used := map[int]bool{}
+	for i, color := range colors {
+		if _, ok := faces[color]; !ok {
+			return State{}, ErrFacelets
+		}
+		home, ok := homeStickers[pieceKey(i, func(j int) int { return faces[colors[j]] })]
+		if !ok || used[home] {
+			return State{}, ErrFacelets
+		}
+		used[home] = true
+		s[i] = uint8(home)
+	}
+
+	if _, err := cubieOf(s); err != nil {
+		return State{}, err
+	}
+	return s, nil
+}
+
+// Facelets returns the colors of the stickers of a solved cube after the
+// state, in the format of Cube.State.
+func (s State) Facelets() string {
+	colors := make([]rune, NumStickers)
+	for i, home := range s {
+		colors[i] = homeFacelets[home]
+	}
+	return string(colors)
+}
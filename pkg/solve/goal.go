@@ -0,0 +1,254 @@
+package solve
+
+import (
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"strings"
+)
+
+var ErrGoal = errors.New("unknown goal")
+
+// Axis is one of the three axes of the cube, by the faces at its ends.
+type Axis int
+
+const (
+	AxisUD Axis = iota
+	AxisLR
+	AxisFB
+)
+
+// axisNames holds the names of the axes, in either order of their faces.
+var axisNames = map[string]Axis{
+	"ud": AxisUD, "du": AxisUD,
+	"lr": AxisLR, "rl": AxisLR,
+	"fb": AxisFB, "bf": AxisFB,
+}
+
+// Goal is an intermediate state to solve to, such as the cross with one
+// pair, the orientation of the edges (EO) or the reduction to the moves of
+// one axis with quarter turns and the others with half turns (DR).
+type Goal struct {
+	done  func(State) bool
+	bound func(State) int
+}
+
+// Done reports whether the goal is reached in the state. The zero Goal is
+// always done.
+func (g Goal) Done(s State) bool {
+	return g.done == nil || g.done(s)
+}
+
+// And returns the goal reached when both goals are, such as EO with the
+// cross.
+func (g Goal) And(other Goal) Goal {
+	if g.done == nil {
+		return other
+	}
+	if other.done == nil {
+		return g
+	}
+	return Goal{
+		done:  func(s State) bool { return g.done(s) && other.done(s) },
+		bound: func(s State) int { return max(g.bound(s), other.bound(s)) },
+	}
+}
+
+// MaskGoal is reached when the partial state is done.
+func MaskGoal(m Mask) Goal {
+	return Goal{done: m.Matches, bound: m.distance}
+}
+
+// EOGoal is reached when every edge is oriented for the axis, so that the
+// cube can be solved without quarter turns of the faces of the axis. EOGoal
+// of AxisFB is the EO of ZZ and of the first phase of the two-phase solver.
+func EOGoal(a Axis) Goal {
+	bad := func(s State) int {
+		axes := colorAxes(s)
+		n := 0
+		for _, e := range edgeStickers {
+			if !edgeOriented(axes, e[0], e[1], a) {
+				n++
+			}
+		}
+		return n
+	}
+	return Goal{
+		done: func(s State) bool { return bad(s) == 0 },
+		// A quarter turn flips four edges, and other turns none.
+		bound: func(s State) int { return (bad(s) + 3) / 4 },
+	}
+}
+
+// DRGoal is reached when the cube can be solved with quarter turns of the
+// faces of the axis and half turns of the others, as after the first phase of
+// the two-phase solver for AxisUD: the corners and edges are oriented for the
+// axis, and the edges of its middle slice are in that slice.
+func DRGoal(a Axis) Goal {
+	bad := func(s State) (corners, edges int) {
+		axes := colorAxes(s)
+		for _, c := range cornerStickers {
+			for _, sticker := range c {
+				if faceAxes[sticker/9] == int(a) && axes[sticker] != a {
+					corners++
+				}
+			}
+		}
+		for _, e := range edgeStickers {
+			for _, sticker := range e {
+				// Edges of the middle slice show the colors of the axes of their
+				// faces, and the others the color of the axis on its faces.
+				onAxis := faceAxes[e[0]/9] == int(a) || faceAxes[e[1]/9] == int(a)
+				if (faceAxes[sticker/9] == int(a) || !onAxis) && axes[sticker] != Axis(faceAxes[sticker/9]) {
+					edges++
+					break
+				}
+			}
+		}
+		return corners, edges
+	}
+	return Goal{
+		done: func(s State) bool {
+			corners, edges := bad(s)
+			return corners == 0 && edges == 0
+		},
+		// A turn moves four corners and four edges, and the cube turned for
+		// the axis to be UD takes at least as long to reach the first phase
+		// of the two-phase solver.
+		bound: func(s State) int {
+			corners, edges := bad(s)
+			bound := (max(corners, edges) + 3) / 4
+			if c, err := cubieOf(s.Conjugate(drTurns[a])); err == nil {
+				bound = max(bound, twoPhaseTables().phase1Bound(c.twist(), c.flip(), c.slice()))
+			}
+			return bound
+		},
+	}
+}
+
+// drTurns holds the symmetry of every axis that turns its quarter turns into
+// those of U and D.
+var drTurns = func() [3]int {
+	var out [3]int
+	for a := range out {
+		// The axes start with the faces U, L and F, the first of FaceTurns.
+		for k := range Rotations {
+			turned := FaceTurns[a*3].State.Conjugate(k)
+			if turned == FaceTurns[0].State || turned == FaceTurns[2].State ||
+				turned == FaceTurns[15].State || turned == FaceTurns[17].State {
+				out[a] = k
+				break
+			}
+		}
+	}
+	return out
+}()
+
+// colorAxes returns the axis of the color of every sticker, following the
+// centers.
+func colorAxes(s State) [NumStickers]Axis {
+	var faceOf [6]int
+	for f := range 6 {
+		faceOf[s[f*9+4]/9] = f
+	}
+	var out [NumStickers]Axis
+	for i, home := range s {
+		out[i] = Axis(faceAxes[faceOf[home/9]])
+	}
+	return out
+}
+
+// edgeOriented reports whether the edge of two stickers is oriented for the
+// axis. One other axis is the reference: the sticker of the edge on a face
+// of the reference axis, or else on a face of the axis, must show the color
+// of the edge of the reference axis, or else of the axis.
+func edgeOriented(axes [NumStickers]Axis, a, b int, axis Axis) bool {
+	ref := AxisUD
+	if axis == AxisUD {
+		ref = AxisFB
+	}
+	if Axis(faceAxes[b/9]) == ref || Axis(faceAxes[a/9]) != ref && Axis(faceAxes[b/9]) == axis {
+		a, b = b, a
+	}
+	want := axis
+	if axes[a] == ref || axes[b] == ref {
+		want = ref
+	}
+	return axes[a] == want
+}
+
+// ParseGoal parses a goal as terms joined by "+" for all of them. Terms are
+// "eo" and "dr" for edge orientation and domino reduction, on the FB and UD
+// axes unless an axis follows, such as "eo lr", and the partial states of
+// ParseMask, such as "eo + cross" or "cross + FR slot".
+func ParseGoal(expr string) (Goal, error) {
+	var goal Goal
+	var masks []string
+	for _, term := range strings.Split(expr, "+") {
+		words := strings.Fields(strings.ToLower(term))
+		if len(words) == 0 || len(words) > 2 || words[0] != "eo" && words[0] != "dr" {
+			masks = append(masks, term)
+			continue
+		}
+		axis := AxisFB
+		if words[0] == "dr" {
+			axis = AxisUD
+		}
+		if len(words) == 2 {
+			a, ok := axisNames[words[1]]
+			if !ok {
+				return Goal{}, fmt.Errorf("%q: %w", strings.TrimSpace(term), ErrGoal)
+			}
+			axis = a
+		}
+		if words[0] == "eo" {
+			goal = goal.And(EOGoal(axis))
+		} else {
+			goal = goal.And(DRGoal(axis))
+		}
+	}
+	if len(masks) > 0 {
+		m, err := ParseMask(strings.Join(masks, "+"))
+		if err != nil {
+			return Goal{}, err
+		}
+		goal = goal.And(MaskGoal(m))
+	}
+	if goal.done == nil {
+		return Goal{}, ErrGoal
+	}
+	return goal, nil
+}
+
+// SolveGoal returns the shortest solutions that reach the goal from the
+// state, shortest first, with the generators, or FaceTurns if there are
+// none, such as "U D L R F2 B2" to reach DR after EO. MaxMoves defaults to
+// 10.
+func SolveGoal(s State, g Goal, gens []Generator, opts CrossOptions) [][]cube.Move {
+	if opts.MaxMoves <= 0 {
+		opts.MaxMoves = 10
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = 1
+	}
+	if len(gens) == 0 {
+		gens = FaceTurns
+	}
+	bound := g.bound
+	if bound == nil {
+		bound = func(State) int { return 0 }
+	}
+
+	var solutions [][]cube.Move
+	search := Search{
+		Generators: gens,
+		Goal:       g.Done,
+		Bound:      bound,
+		Found: func(moves []cube.Move) bool {
+			solutions = append(solutions, moves)
+			return len(solutions) < opts.Limit
+		},
+	}
+	opts.run(&search, s)
+	return solutions
+}
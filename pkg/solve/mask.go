@@ -0,0 +1,356 @@
+package solve
+
+import (
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"strings"
+)
+
+var ErrMask = errors.New("unknown partial state")
+
+// stickerSet holds whether every sticker is part of a partial state.
+type stickerSet [NumStickers]bool
+
+// Mask is a partial state of a 3x3 cube, such as the cross or the first two
+// layers: the stickers that show the color of their face's center when it is
+// done. A mask may have alternatives, as F2L-1 is done with any one of the
+// four slots left out.
+type Mask struct {
+	alternatives []stickerSet
+}
+
+// SolvedMask is done when the whole cube is solved.
+var SolvedMask = func() Mask {
+	var set stickerSet
+	for i := range set {
+		set[i] = true
+	}
+	return Mask{[]stickerSet{set}}
+}()
+
+var oppositeFaces = [6]cube.Face{cube.FaceDown, cube.FaceRight, cube.FaceBack, cube.FaceLeft, cube.FaceFront, cube.FaceUp}
+
+// maskOf returns the mask of the positions that pass the filter.
+func maskOf(filter func(p position) bool) Mask {
+	var set stickerSet
+	for _, p := range positions {
+		if filter(p) {
+			for _, sticker := range p {
+				set[sticker] = true
+			}
+		}
+	}
+	return Mask{[]stickerSet{set}}
+}
+
+func (p position) on(f cube.Face) bool {
+	_, ok := p[faceLetters[f]]
+	return ok
+}
+
+// PiecesMask is done when the pieces are solved, named as in CycleState, such
+// as "UFR" or "DF", or "U" for a center.
+func PiecesMask(names ...string) (Mask, error) {
+	var set stickerSet
+	for _, name := range names {
+		stickers, err := stickersOf(name)
+		if err != nil {
+			return Mask{}, fmt.Errorf("%q: %w", name, err)
+		}
+		for _, s := range stickers {
+			set[s] = true
+		}
+	}
+	return Mask{[]stickerSet{set}}, nil
+}
+
+// CrossMask is done when the cross on the face is solved.
+func CrossMask(f cube.Face) Mask {
+	return maskOf(func(p position) bool { return p.on(f) && len(p) < 3 })
+}
+
+// LayerMask is done when the layer of the face is solved.
+func LayerMask(f cube.Face) Mask {
+	return maskOf(func(p position) bool { return p.on(f) })
+}
+
+// FaceMask is done when the face shows a single color, such as the last
+// layer oriented when the face is the last layer.
+func FaceMask(f cube.Face) Mask {
+	var set stickerSet
+	for i := range 9 {
+		set[int(f)*9+i] = true
+	}
+	return Mask{[]stickerSet{set}}
+}
+
+// F2LMask is done when the first two layers on the bottom face are solved.
+func F2LMask(bottom cube.Face) Mask {
+	return maskOf(func(p position) bool { return !p.on(oppositeFaces[bottom]) })
+}
+
+// SlotMask is done when the F2L slot of the middle edge, such as "FR", is
+// solved with its corner on the bottom face.
+func SlotMask(bottom cube.Face, edge string) (Mask, error) {
+	edge = strings.ToUpper(edge)
+	layers := string([]byte{faceLetters[bottom], faceLetters[oppositeFaces[bottom]]})
+	if len(edge) != 2 || strings.ContainsAny(edge, layers) {
+		return Mask{}, ErrMask
+	}
+	return PiecesMask(edge, edge+string(faceLetters[bottom]))
+}
+
+// F2LMinusOneMask is done when the first two layers on the bottom face are
+// solved except for any one slot.
+func F2LMinusOneMask(bottom cube.Face) Mask {
+	f2l := F2LMask(bottom).alternatives[0]
+	var out Mask
+	for _, p := range positions {
+		if len(p) != 2 || p.on(bottom) || p.on(oppositeFaces[bottom]) {
+			continue
+		}
+		set := f2l
+		var edge []byte
+		for letter, sticker := range p {
+			set[sticker] = false
+			edge = append(edge, letter)
+		}
+		corner, _ := stickersOf(string(edge) + string(faceLetters[bottom]))
+		for _, sticker := range corner {
+			set[sticker] = false
+		}
+		out.alternatives = append(out.alternatives, set)
+	}
+	return out
+}
+
+// And returns the mask done when both masks are.
+func (m Mask) And(other Mask) Mask {
+	if len(m.alternatives) == 0 {
+		return other
+	}
+	if len(other.alternatives) == 0 {
+		return m
+	}
+	var out Mask
+	for _, a := range m.alternatives {
+		for _, b := range other.alternatives {
+			set := a
+			for i := range set {
+				set[i] = set[i] || b[i]
+			}
+			out.alternatives = append(out.alternatives, set)
+		}
+	}
+	return out
+}
+
+// AnyMask returns the mask done when any of the masks is.
+func AnyMask(masks ...Mask) Mask {
+	var out Mask
+	for _, m := range masks {
+		if len(m.alternatives) == 0 {
+			return Mask{}
+		}
+		out.alternatives = append(out.alternatives, m.alternatives...)
+	}
+	return out
+}
+
+// Matches reports whether the partial state is done in the state. Colors are
+// compared with the centers, so that moved centers are followed. The empty
+// mask is always done.
+func (m Mask) Matches(s State) bool {
+	if len(m.alternatives) == 0 {
+		return true
+	}
+	for _, set := range m.alternatives {
+		if set.matches(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func (set *stickerSet) matches(s State) bool {
+	for i, in := range set {
+		if in && s[i]/9 != s[i/9*9+4]/9 {
+			return false
+		}
+	}
+	return true
+}
+
+// Shows reports whether a sticker is part of any alternative of the mask, for
+// renderers that gray out the rest, as in Cube.RenderMaskedSVG.
+func (m Mask) Shows(st cube.Sticker) bool {
+	if len(m.alternatives) == 0 {
+		return true
+	}
+	i := int(st.Face)*9 + st.Index
+	for _, set := range m.alternatives {
+		if i < NumStickers && set[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// distance returns a lower bound of the face turns to complete the mask: a
+// face turn moves four corners and four edges, and a mask with a cross takes
+// at least as long as the cross.
+func (m Mask) distance(s State) int {
+	best := -1
+	for _, set := range m.alternatives {
+		var corners, edges int
+		for _, p := range positions {
+			if len(p) == 1 {
+				continue
+			}
+			for _, sticker := range p {
+				if set[sticker] && s[sticker]/9 != s[sticker/9*9+4]/9 {
+					if len(p) == 3 {
+						corners++
+					} else {
+						edges++
+					}
+					break
+				}
+			}
+		}
+		d := (max(corners, edges) + 3) / 4
+		if s.centersSolved() {
+			for f := range 6 {
+				if set.hasCross(cube.Face(f)) {
+					d = max(d, CrossLength(s, cube.Face(f)))
+				}
+			}
+		}
+		if best < 0 || d < best {
+			best = d
+		}
+	}
+	return max(best, 0)
+}
+
+// hasCross reports whether the set holds both stickers of every edge of the
+// cross on the face, whose solution is then part of the mask.
+func (set *stickerSet) hasCross(f cube.Face) bool {
+	for _, sticker := range crossStickers(f) {
+		for _, s := range positions[stickerFaces[sticker].pos] {
+			if !set[s] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// SolveMask returns the shortest face turn solutions that complete the
+// partial state, shortest first. MaxMoves defaults to 8. Large masks take
+// long, as the search is only bounded by the pieces left to place.
+func SolveMask(s State, m Mask, opts CrossOptions) [][]cube.Move {
+	if opts.MaxMoves <= 0 {
+		opts.MaxMoves = 8
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = 1
+	}
+
+	var solutions [][]cube.Move
+	search := Search{
+		Generators: FaceTurns,
+		Goal:       m.Matches,
+		Bound:      m.distance,
+		Found: func(moves []cube.Move) bool {
+			solutions = append(solutions, moves)
+			return len(solutions) < opts.Limit
+		},
+	}
+	opts.run(&search, s)
+	return solutions
+}
+
+// ParseMask parses a partial state as terms joined by "+" for all of them,
+// and by "|" for any of those groups, such as "cross on D + FR slot" or
+// "f2l-1". Terms are:
+//
+//	cross, f2l, f2l-1        on the bottom face, D unless "on" a face follows
+//	XY slot                  an F2L slot, with its corner on D or the "on" face
+//	oll, last layer oriented the face of the last layer, U or the "on" face
+//	ll, last layer           the layer of the last layer, U or the "on" face
+//	X face, X layer          the face or the layer X
+//	solved                   the whole cube
+//	UFR, DF, U               a piece, as in CycleState
+func ParseMask(expr string) (Mask, error) {
+	var groups []Mask
+	for _, group := range strings.Split(expr, "|") {
+		var all Mask
+		for _, term := range strings.Split(group, "+") {
+			m, err := parseTerm(strings.Fields(term))
+			if err != nil {
+				return Mask{}, fmt.Errorf("%q: %w", strings.TrimSpace(term), err)
+			}
+			all = all.And(m)
+		}
+		groups = append(groups, all)
+	}
+	return AnyMask(groups...), nil
+}
+
+func parseTerm(words []string) (Mask, error) {
+	face := func(word string) (cube.Face, bool) {
+		i := strings.Index(faceLetters, strings.ToUpper(word))
+		return cube.Face(i), len(word) == 1 && i >= 0
+	}
+
+	bottom, last := cube.FaceDown, cube.FaceUp
+	if n := len(words); n > 2 && strings.EqualFold(words[n-2], "on") {
+		f, ok := face(words[n-1])
+		if !ok {
+			return Mask{}, ErrMask
+		}
+		bottom, last = f, f
+		words = words[:n-2]
+	}
+
+	switch name := strings.ToLower(strings.Join(words, " ")); name {
+	case "":
+		return Mask{}, ErrMask
+	case "cross":
+		return CrossMask(bottom), nil
+	case "f2l":
+		return F2LMask(bottom), nil
+	case "f2l-1":
+		return F2LMinusOneMask(bottom), nil
+	case "oll", "last layer oriented":
+		return FaceMask(last), nil
+	case "ll", "last layer":
+		return LayerMask(last), nil
+	case "solved":
+		return SolvedMask, nil
+	}
+
+	if len(words) == 2 {
+		switch strings.ToLower(words[1]) {
+		case "slot":
+			return SlotMask(bottom, words[0])
+		case "face", "layer":
+			f, ok := face(words[0])
+			if !ok {
+				return Mask{}, ErrMask
+			}
+			if strings.EqualFold(words[1], "face") {
+				return FaceMask(f), nil
+			}
+			return LayerMask(f), nil
+		}
+	}
+	if len(words) == 1 {
+		if m, err := PiecesMask(words[0]); err == nil {
+			return m, nil
+		}
+	}
+	return Mask{}, ErrMask
+}
@@ -0,0 +1,25 @@
+package solve
+
+import (
+	"github.com/larssont/go-cubic/pkg/metrics"
+	"time"
+)
+
+// observeSearch counts a finished search of a solver in metrics.Default.
+// Rates of its nodes and seconds give the nodes a second of the solver over
+// any span, and the gauge that of its last search.
+func observeSearch(solver string, stats SearchStats) {
+	m := metrics.Default
+	m.Counter("cubic_solver_searches_total", "Searches of the solvers, by why they ended.", "solver", solver, "stopped", stats.Stopped.String()).Inc()
+	m.Counter("cubic_solver_nodes_total", "Nodes visited by the searches of the solvers.", "solver", solver).Add(float64(stats.Nodes))
+	m.Counter("cubic_solver_search_seconds_total", "Time spent in the searches of the solvers.", "solver", solver).Add(stats.Elapsed.Seconds())
+	if stats.Elapsed > 0 {
+		m.Gauge("cubic_solver_nodes_per_second", "Nodes a second of the last search of the solvers.", "solver", solver).Set(float64(stats.Nodes) / stats.Elapsed.Seconds())
+	}
+}
+
+// observeTable records the time that a table took to build or to load since
+// start.
+func observeTable(name string, start time.Time) {
+	metrics.Default.Gauge("cubic_solver_table_load_seconds", "Time that the tables of the solvers took to build, or to load with UseTables.", "table", name).Set(time.Since(start).Seconds())
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package solve
+
+import "os"
+
+// mapFile reads the whole file, where files cannot be mapped into memory.
+func mapFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
@@ -0,0 +1,26 @@
+//go:build unix
+
+package solve
+
+import (
+	"os"
+	"syscall"
+)
+
+// mapFile maps the whole file into memory read only. The mapping stays for
+// the life of the process.
+func mapFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() == 0 {
+		return nil, ErrTableFile
+	}
+	return syscall.Mmap(int(f.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+}
@@ -0,0 +1,240 @@
+package solve
+
+import (
+	"errors"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrUnknownPiece = errors.New("unknown piece")
+	ErrPieceCycle   = errors.New("pieces of a cycle differ in type")
+)
+
+const faceLetters = "ULFRBD"
+
+// position is a piece position of a 3x3 cube with the index of its sticker on
+// every face it touches.
+type position map[byte]int
+
+var positions = func() []position {
+	var out []position
+	for x := range 3 {
+		for y := range 3 {
+			for z := range 3 {
+				stickers := cube.StickersAt(3, x, y, z)
+				if len(stickers) == 0 {
+					continue
+				}
+				p := position{}
+				for _, s := range stickers {
+					p[faceLetters[s.Face]] = int(s.Face)*9 + s.Index
+				}
+				out = append(out, p)
+			}
+		}
+	}
+	return out
+}()
+
+// stickerFaces maps every sticker to its position and face letter.
+var stickerFaces = func() [NumStickers]struct {
+	pos    int
+	letter byte
+} {
+	var out [NumStickers]struct {
+		pos    int
+		letter byte
+	}
+	for i, p := range positions {
+		for letter, sticker := range p {
+			out[sticker].pos = i
+			out[sticker].letter = letter
+		}
+	}
+	return out
+}()
+
+// normals holds the outward direction of every face.
+var normals = map[byte][3]int{
+	'U': {0, 1, 0}, 'D': {0, -1, 0},
+	'L': {-1, 0, 0}, 'R': {1, 0, 0},
+	'F': {0, 0, 1}, 'B': {0, 0, -1},
+}
+
+// clockwise reports whether the faces a, b and c follow each other clockwise
+// around the corner they meet at.
+func clockwise(a, b, c byte) bool {
+	na, nb, nc := normals[a], normals[b], normals[c]
+	cross := [3]int{
+		na[1]*nb[2] - na[2]*nb[1],
+		na[2]*nb[0] - na[0]*nb[2],
+		na[0]*nb[1] - na[1]*nb[0],
+	}
+	return cross[0]*nc[0]+cross[1]*nc[1]+cross[2]*nc[2] < 0
+}
+
+// letters returns the faces of a position starting with the given one and
+// continuing clockwise around the piece.
+func (p position) letters(first byte) []byte {
+	letters := []byte{first}
+	for letter := range p {
+		if letter != first {
+			letters = append(letters, letter)
+		}
+	}
+	if len(letters) == 3 && !clockwise(letters[0], letters[1], letters[2]) {
+		letters[1], letters[2] = letters[2], letters[1]
+	}
+	return letters
+}
+
+// stickersOf returns the stickers of a piece name such as "UFR". The first
+// letter names the sticker the piece starts with, and the others follow
+// clockwise regardless of their order in the name, as in BLD memo.
+func stickersOf(name string) ([]int, error) {
+	name = strings.ToUpper(name)
+	for _, p := range positions {
+		if len(p) != len(name) {
+			continue
+		}
+		matches := true
+		for i := range len(name) {
+			if _, ok := p[name[i]]; !ok || strings.IndexByte(name[:i], name[i]) >= 0 {
+				matches = false
+			}
+		}
+		if !matches {
+			continue
+		}
+
+		var stickers []int
+		for _, letter := range p.letters(name[0]) {
+			stickers = append(stickers, p[letter])
+		}
+		return stickers, nil
+	}
+	return nil, ErrUnknownPiece
+}
+
+// nameOf returns the name of the piece holding the sticker, starting with the
+// letter of the sticker and continuing clockwise.
+func nameOf(sticker int) string {
+	p := positions[stickerFaces[sticker].pos]
+	return string(p.letters(stickerFaces[sticker].letter))
+}
+
+// CycleState returns the state moving every piece of the cycle to the
+// position of the next one, and the last piece to the first. Stickers follow
+// the order of the letters as in stickersOf, so "UBL UFR RDF" moves the U
+// sticker of UBL to the R sticker of RDF. If the last piece equals the first one, as in "UFR FRU",
+// the cycle closes there, which allows twists and flips.
+func CycleState(pieces ...string) (State, error) {
+	s := Solved
+	if err := applyCycle(&s, pieces); err != nil {
+		return State{}, err
+	}
+	return s, nil
+}
+
+func applyCycle(s *State, pieces []string) error {
+	stickers := make([][]int, len(pieces))
+	for i, name := range pieces {
+		var err error
+		if stickers[i], err = stickersOf(name); err != nil {
+			return err
+		}
+		if len(stickers[i]) != len(stickers[0]) {
+			return ErrPieceCycle
+		}
+	}
+
+	n := len(stickers)
+	if n > 1 && stickerFaces[stickers[n-1][0]].pos == stickerFaces[stickers[0][0]].pos {
+		n--
+	}
+
+	var next State = *s
+	for i := range n {
+		from, to := stickers[i], stickers[(i+1)%len(stickers)]
+		for j := range from {
+			next[to[j]] = s[from[j]]
+		}
+	}
+	*s = next
+	return nil
+}
+
+// Cycles describes a state as cycles of pieces in the format of CycleState,
+// such as "UBL UFR RDF". Pieces that stay in place twisted or flipped are
+// written as a cycle of the piece with itself, e.g. "UFR FRU".
+func Cycles(s State) [][]string {
+	var cycles [][]string
+	seen := make([]bool, len(positions))
+
+	// The state maps destinations to sources, so follow the inverse to walk
+	// the pieces forwards.
+	forward := s.Inverse()
+	for start := range NumStickers {
+		pos := stickerFaces[start].pos
+		if seen[pos] || int(s[start]) == start {
+			continue
+		}
+
+		first, _ := stickersOf(nameOf(start))
+		cycle := []string{nameOf(start)}
+		for stickers := slices.Clone(first); ; {
+			seen[stickerFaces[stickers[0]].pos] = true
+
+			var letters []byte
+			for i, sticker := range stickers {
+				stickers[i] = int(forward[sticker])
+				letters = append(letters, stickerFaces[stickers[i]].letter)
+			}
+
+			if stickerFaces[stickers[0]].pos == pos {
+				if stickers[0] != first[0] {
+					cycle = append(cycle, string(letters))
+				}
+				break
+			}
+			cycle = append(cycle, string(letters))
+		}
+		cycles = append(cycles, cycle)
+	}
+	return cycles
+}
+
+// PieceName returns the name of the piece holding a sticker, starting with
+// the letter of the sticker's face.
+func PieceName(sticker int) string {
+	return nameOf(sticker)
+}
+
+// SamePiece reports whether two stickers belong to the same piece position.
+func SamePiece(a, b int) bool {
+	return stickerFaces[a].pos == stickerFaces[b].pos
+}
+
+// PieceAt returns the piece at a position of the state, named from the
+// sticker on the first face of the position, so "FRU" at "UFR" is the FRU
+// corner with its F sticker on U.
+func PieceAt(s State, position string) (string, error) {
+	stickers, err := stickersOf(position)
+	if err != nil {
+		return "", err
+	}
+	return nameOf(int(s[stickers[0]])), nil
+}
+
+// Where returns the position of a piece in the state, named from the place of
+// the first sticker of the piece, so "RUF" for "UFR" is the UFR corner at UFR
+// with its U sticker on R.
+func Where(s State, piece string) (string, error) {
+	stickers, err := stickersOf(piece)
+	if err != nil {
+		return "", err
+	}
+	return nameOf(int(s.Inverse()[stickers[0]])), nil
+}
@@ -0,0 +1,247 @@
+package solve
+
+import (
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"strings"
+	"sync"
+)
+
+var ErrPocketMethod = errors.New("unknown 2x2 method")
+
+// PocketMethod is a method of solving the 2x2 in steps, as people learn it.
+type PocketMethod int
+
+const (
+	// Ortega builds a face, orients the other face (OLL) and permutes both
+	// layers at once (PBL).
+	Ortega PocketMethod = iota
+	// CLL builds a layer and solves the last layer in one step.
+	CLL
+)
+
+// pocketMethods are the names of the methods for ParsePocketMethod.
+var pocketMethods = map[string]PocketMethod{"ortega": Ortega, "cll": CLL}
+
+// ParsePocketMethod parses the name of a method, "ortega" or "cll".
+func ParsePocketMethod(name string) (PocketMethod, error) {
+	m, ok := pocketMethods[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("%q: %w", name, ErrPocketMethod)
+	}
+	return m, nil
+}
+
+// PocketStep is a labeled step of a 2x2 solution, such as "Face" or "PBL".
+type PocketStep struct {
+	Name  string
+	Moves []cube.Move
+}
+
+// The 2x2 is modeled as the corners of the 3x3, turned with U, R and F so
+// that the DBL corner stays in place. Its other corners are numbered by
+// pocketCorners, and a state is the rank of their permutation, 0 to 5039,
+// and the twist of the first six, 0 to 728.
+const (
+	numPocketPerm  = 5040
+	numPocketTwist = 729
+)
+
+var pocketCorners = [7]int{0, 1, 2, 3, 4, 5, 7}
+
+// pocketMoves are the indices in FaceTurns of the turns of U, R and F, in the
+// order that solutions prefer them.
+var pocketMoves = []int{0, 1, 2, 9, 10, 11, 6, 7, 8}
+
+func (c *cubie) pocketPerm() int {
+	var p [7]uint8
+	for i, pos := range pocketCorners {
+		p[i] = min(c.cp[pos], 6)
+	}
+	return permIndex(p[:])
+}
+
+func (c *cubie) pocketTwist() int {
+	n := 0
+	for _, o := range c.co[:6] {
+		n = 3*n + int(o)
+	}
+	return n
+}
+
+// pocketState unranks the coordinates into the permutation of the corners
+// of pocketCorners, with DBL as 6, and the twist of all seven.
+func pocketState(perm, twist int) (p [7]uint8, o [7]uint8) {
+	var digits [7]int
+	for i := 6; i >= 0; i-- {
+		digits[i] = perm % (7 - i)
+		perm /= 7 - i
+	}
+	left := []uint8{0, 1, 2, 3, 4, 5, 6}
+	for i, d := range digits {
+		p[i] = left[d]
+		left = append(left[:d], left[d+1:]...)
+	}
+
+	sum := 0
+	for i := 5; i >= 0; i-- {
+		o[i] = uint8(twist % 3)
+		sum += twist % 3
+		twist /= 3
+	}
+	o[6] = uint8((3 - sum%3) % 3)
+	return p, o
+}
+
+// pocketGoals tell whether the steps of the methods are done, by the
+// coordinates.
+var pocketGoals = map[string]func(perm, twist int) bool{
+	"Face": pocketFace,
+	// Both faces have their colors.
+	"OLL": func(perm, twist int) bool {
+		return twist == 0 && pocketFace(perm, twist)
+	},
+	// The D layer is solved.
+	"Layer": func(perm, twist int) bool {
+		p, o := pocketState(perm, twist)
+		for _, i := range []int{4, 5, 6} {
+			if p[i] != uint8(i) || o[i] != 0 {
+				return false
+			}
+		}
+		return true
+	},
+	"Solved": func(perm, twist int) bool {
+		return perm == 0 && twist == 0
+	},
+}
+
+// pocketFace reports whether the D face has its color, which needs the D
+// corners in the D layer with their D stickers down.
+func pocketFace(perm, twist int) bool {
+	p, o := pocketState(perm, twist)
+	for _, i := range []int{4, 5, 6} {
+		if p[i] < 4 || o[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// pocketSteps are the steps of the methods, each named after its goal but
+// for the last, which solves the cube.
+var pocketSteps = map[PocketMethod][]string{
+	Ortega: {"Face", "OLL", "PBL"},
+	CLL:    {"Layer", "CLL"},
+}
+
+// pocketTables holds the move tables of the coordinates and, built as they
+// are first needed, the distance of every state from the goals.
+var pocketTables = sync.OnceValue(func() *pocket {
+	t := &tables{}
+	for i, g := range FaceTurns {
+		t.moves[i], _ = cubieOf(g.State)
+	}
+	return &pocket{
+		perm:      t.moveTable(numPocketPerm, pocketMoves, (*cubie).pocketPerm),
+		twist:     t.moveTable(numPocketTwist, pocketMoves, (*cubie).pocketTwist),
+		distances: map[string][]int8{},
+	}
+})
+
+type pocket struct {
+	perm, twist [][numMoves]uint16
+
+	mu        sync.Mutex
+	distances map[string][]int8
+}
+
+// distance returns the table of distances from the goal.
+func (p *pocket) distance(goal string) []int8 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if d, ok := p.distances[goal]; ok {
+		return d
+	}
+	d := distanceTable(p.perm, p.twist, pocketMoves, pocketGoals[goal])
+	p.distances[goal] = d
+	return d
+}
+
+// SolvePocket returns a 2x2 solution in the labeled steps of a method, each
+// step as short as it can be, from the state that the moves of a 2x2
+// scramble have on the 3x3, see StateOf. A first "Rotation" step, if any,
+// turns the whole cube so that the DBL corner is at home, and the other
+// steps turn only U, R and F. Solutions build the face of the color of D.
+func SolvePocket(s State, method PocketMethod) ([]PocketStep, error) {
+	names, ok := pocketSteps[method]
+	if !ok {
+		return nil, ErrPocketMethod
+	}
+
+	var steps []PocketStep
+	rotation, c, err := pocketStart(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(rotation) > 0 {
+		steps = append(steps, PocketStep{"Rotation", rotation})
+	}
+
+	p := pocketTables()
+	perm, twist := c.pocketPerm(), c.pocketTwist()
+	for i, name := range names {
+		goal := name
+		if i == len(names)-1 {
+			goal = "Solved"
+		}
+		table := p.distance(goal)
+
+		// Every state has a move towards the goal until it is reached.
+		step := PocketStep{Name: name}
+		for d := table[perm*numPocketTwist+twist]; d > 0; d-- {
+			for _, m := range pocketMoves {
+				nextPerm, nextTwist := int(p.perm[perm][m]), int(p.twist[twist][m])
+				if table[nextPerm*numPocketTwist+nextTwist] == d-1 {
+					step.Moves = append(step.Moves, FaceTurns[m].Move)
+					perm, twist = nextPerm, nextTwist
+					break
+				}
+			}
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// pocketStart returns the rotation that brings the DBL corner home, and the
+// corners after it.
+func pocketStart(s State) ([]cube.Move, cubie, error) {
+	dbl := cornerStickers[6]
+	rotations := append([]sequence{{state: Solved}}, sequences(turns("xyz"), 2)...)
+	for _, r := range rotations {
+		t := s.Then(r.state)
+		if int(t[dbl[0]]) != dbl[0] || int(t[dbl[1]]) != dbl[1] || int(t[dbl[2]]) != dbl[2] {
+			continue
+		}
+		var c cubie
+		if !pieces(t, cornerStickers[:], c.cp[:], c.co[:]) || !isPermutation(c.cp[:]) || sum(c.co[:])%3 != 0 {
+			return nil, cubie{}, ErrInvalidState
+		}
+		return r.moves, c, nil
+	}
+	return nil, cubie{}, ErrInvalidState
+}
+
+// PocketDistance returns the fewest turns that solve a 2x2, counting half
+// turns as one, from the state that the moves of its scramble have on the
+// 3x3, see StateOf. Rotations of the whole cube are free.
+func PocketDistance(s State) (int, error) {
+	_, c, err := pocketStart(s)
+	if err != nil {
+		return 0, err
+	}
+	table := pocketTables().distance("Solved")
+	return int(table[c.pocketPerm()*numPocketTwist+c.pocketTwist()]), nil
+}
@@ -0,0 +1,51 @@
+package solve
+
+import (
+	"math/rand/v2"
+)
+
+// stateOf returns the sticker state of a piece level state.
+func stateOf(c cubie) State {
+	s := Solved
+	for p, piece := range c.cp {
+		for k, home := range cornerStickers[piece] {
+			s[cornerStickers[p][(k+int(c.co[p]))%3]] = uint8(home)
+		}
+	}
+	for p, piece := range c.ep {
+		for k, home := range edgeStickers[piece] {
+			s[edgeStickers[p][(k+int(c.eo[p]))%2]] = uint8(home)
+		}
+	}
+	return s
+}
+
+// RandomState returns a uniformly random solvable state with the centers in
+// place. A nil rng uses the global source of math/rand/v2.
+func RandomState(rng *rand.Rand) State {
+	perm, intN := rand.Perm, rand.IntN
+	if rng != nil {
+		perm, intN = rng.Perm, rng.IntN
+	}
+
+	var c cubie
+	for i, p := range perm(8) {
+		c.cp[i] = uint8(p)
+	}
+	for i, p := range perm(12) {
+		c.ep[i] = uint8(p)
+	}
+	if parity(c.cp[:]) != parity(c.ep[:]) {
+		c.ep[0], c.ep[1] = c.ep[1], c.ep[0]
+	}
+
+	for i := range 7 {
+		c.co[i] = uint8(intN(3))
+	}
+	c.co[7] = uint8((3 - sum(c.co[:7])%3) % 3)
+	for i := range 11 {
+		c.eo[i] = uint8(intN(2))
+	}
+	c.eo[11] = uint8(sum(c.eo[:11]) % 2)
+	return stateOf(c)
+}
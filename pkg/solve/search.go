@@ -0,0 +1,154 @@
+package solve
+
+import (
+	"github.com/larssont/go-cubic/pkg/cube"
+	"slices"
+	"time"
+)
+
+// Search is an iterative deepening A* search over move sequences.
+type Search struct {
+	Generators []Generator
+
+	// Goal reports whether a state ends a solution.
+	Goal func(State) bool
+
+	// Bound returns a lower bound of the moves left to reach a goal. A nil
+	// bound searches every sequence up to the depth.
+	Bound func(State) int
+
+	// Found is called for every solution, shortest first. Returning false
+	// stops the search.
+	Found func([]cube.Move) bool
+
+	// Table, if set, remembers the states proven farther from a goal than
+	// Bound tells, for the deeper iterations and for other searches sharing
+	// the table.
+	Table *TranspositionTable
+
+	// MaxTime and MaxNodes, if set, stop the search once it has run that
+	// long or visited that many states.
+	MaxTime  time.Duration
+	MaxNodes int
+
+	// Stats tells how much the last Run did and why it ended.
+	Stats SearchStats
+
+	found  int
+	budget budget
+}
+
+// Run searches from the state for solutions of up to max moves.
+func (s *Search) Run(start State, max int) {
+	s.found = 0
+	s.budget = newBudget(s.MaxTime, s.MaxNodes)
+	defer func() {
+		s.Stats = s.budget.stats()
+		observeSearch("search", s.Stats)
+	}()
+	moves := make([]cube.Move, 0, max)
+	for depth := 0; depth <= max; depth++ {
+		if ok, _ := s.search(start, depth, moves); !ok {
+			return
+		}
+	}
+}
+
+func (s *Search) bound(state State, moves []cube.Move) int {
+	bound := 0
+	if s.Bound != nil {
+		bound = s.Bound(state)
+	}
+	if s.Table != nil {
+		if b, ok := s.Table.lookup(keyOf(state, moves)); ok {
+			bound = max(bound, b)
+		}
+	}
+	return bound
+}
+
+// keyOf returns the table key of a state reached by the moves.
+func keyOf(state State, moves []cube.Move) tableKey {
+	k := tableKey{state: state}
+	if n := len(moves); n > 0 {
+		k.last = moves[n-1].Operator
+	}
+	return k
+}
+
+// search visits sequences of exactly depth more moves, returning false once
+// the search is stopped, and a lower bound of the moves from the state to a
+// goal that it learned.
+func (s *Search) search(state State, depth int, moves []cube.Move) (bool, int) {
+	if !s.budget.spend() {
+		return false, 0
+	}
+	if depth == 0 {
+		if s.Goal(state) {
+			s.found++
+			return s.Found(slices.Clone(moves)), 0
+		}
+		return true, 1
+	}
+
+	bound := s.bound(state, moves)
+	if bound > depth {
+		return true, s.passed(state, bound)
+	}
+
+	found, closest := s.found, -1
+	for _, g := range s.Generators {
+		if n := len(moves); n > 0 && redundant(moves[n-1], g.Move) {
+			continue
+		}
+		ok, b := s.search(state.Then(g.State), depth-1, append(moves, g.Move))
+		if !ok {
+			return false, 0
+		}
+		if closest < 0 || b+1 < closest {
+			closest = b + 1
+		}
+	}
+	learned := max(bound, closest)
+
+	// Every move leads at least as far from a goal as learned from it.
+	if s.Table != nil && s.found == found && learned > bound {
+		s.Table.store(keyOf(state, moves), learned)
+	}
+	return true, s.passed(state, learned)
+}
+
+// passed returns the bound that the move into a state learns from it. The
+// table holds bounds of solutions of at least one move, and goals passed on
+// the way to a deeper solution are none away.
+func (s *Search) passed(state State, learned int) int {
+	if s.Table != nil && s.Goal(state) {
+		return 0
+	}
+	return learned
+}
+
+// opposite holds the operators turning the same axis as another, in the order
+// in which such commuting turns are searched.
+var opposite = map[rune]rune{'D': 'U', 'R': 'L', 'B': 'F'}
+
+// redundant reports whether b directly after a can be left out of the search,
+// as it turns the same layer or commutes with a in the wrong order.
+func redundant(a, b cube.Move) bool {
+	return sameLayer(a, b) || (!a.Wide && !b.Wide && opposite[a.Operator] == b.Operator)
+}
+
+// distances returns the distance of every state within depth moves of the
+// target, searched backwards with the inverse generators.
+func distances(target State, gens []Generator, depth int) map[State]int {
+	inverse := make([]Generator, len(gens))
+	for i, g := range gens {
+		inverse[i] = Generator{State: g.State.Inverse(), Move: g.Move}
+	}
+	dist := map[State]int{}
+	BreadthFirst(target, inverse, depth, NewStateSet(), func(s State, d int) bool {
+		dist[s] = d
+		return true
+	})
+	return dist
+}
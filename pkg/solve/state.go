@@ -0,0 +1,196 @@
+// Package solve searches for 3x3 move sequences with a given effect.
+package solve
+
+import (
+	"github.com/larssont/go-cubic/pkg/cube"
+	"slices"
+	"strings"
+)
+
+// NumStickers is the number of stickers of a 3x3 cube.
+const NumStickers = 54
+
+// State is the effect of a move sequence on a 3x3 cube. Sticker i holds the
+// sticker that started at index State[i], with stickers indexed as in
+// cube.Permutation.
+type State [NumStickers]uint8
+
+// Solved is the state of the empty sequence.
+var Solved = func() State {
+	var s State
+	for i := range s {
+		s[i] = uint8(i)
+	}
+	return s
+}()
+
+// StateOf returns the effect of the moves on a solved 3x3 cube.
+func StateOf(moves ...cube.Move) (State, error) {
+	perm, err := cube.Permutation(3, moves...)
+	if err != nil {
+		return State{}, err
+	}
+
+	var s State
+	for i, p := range perm {
+		s[i] = uint8(p)
+	}
+	return s, nil
+}
+
+// Then returns the effect of s followed by t.
+func (s State) Then(t State) State {
+	var out State
+	for i, p := range t {
+		out[i] = s[p]
+	}
+	return out
+}
+
+// Inverse returns the effect that undoes s.
+func (s State) Inverse() State {
+	var out State
+	for i, p := range s {
+		out[p] = uint8(i)
+	}
+	return out
+}
+
+// Moved returns the indices of the stickers that s moves.
+func (s State) Moved() []int {
+	var moved []int
+	for i, p := range s {
+		if int(p) != i {
+			moved = append(moved, i)
+		}
+	}
+	return moved
+}
+
+// Generator is a move together with its effect.
+type Generator struct {
+	Move  cube.Move
+	State State
+}
+
+// NewGenerators parses the moves of a move set, such as "U U2 U' R R2 R'".
+func NewGenerators(notation string) ([]Generator, error) {
+	var gens []Generator
+	// Tokens are parsed one by one, as the parser combines adjacent turns.
+	for _, token := range strings.Fields(notation) {
+		group, err := cube.ParseNotation(token)
+		if err != nil {
+			return nil, err
+		}
+		moves, err := group.Expand()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, m := range moves {
+			s, err := StateOf(m)
+			if err != nil {
+				return nil, err
+			}
+			gens = append(gens, Generator{m, s})
+		}
+	}
+	return gens, nil
+}
+
+// turns returns the quarter, half and inverse turns of every operator.
+func turns(operators string) []Generator {
+	var gens []Generator
+	for _, op := range operators {
+		for _, m := range []cube.Move{
+			{Operator: op, Rotations: 1},
+			{Operator: op, Rotations: 2},
+			{Operator: op, Rotations: 1, Inverted: true},
+		} {
+			s, err := StateOf(m)
+			if err != nil {
+				panic(err)
+			}
+			gens = append(gens, Generator{m, s})
+		}
+	}
+	return gens
+}
+
+var (
+	// FaceTurns are the 18 outer layer turns of the half turn metric.
+	FaceTurns = turns("ULFRBD")
+	// SliceTurns are the 9 turns of the middle slices.
+	SliceTurns = turns("MES")
+)
+
+// sameLayer reports whether two moves turn the same layers, so that one
+// directly after the other could be combined.
+func sameLayer(a, b cube.Move) bool {
+	return a.Operator == b.Operator && a.Wide == b.Wide && a.Slices == b.Slices && a.From == b.From
+}
+
+// sequence is a move sequence of generators, stored with its effect.
+type sequence struct {
+	moves []cube.Move
+	state State
+}
+
+// sequences returns every sequence of one up to max generators without two
+// consecutive turns of the same layer.
+func sequences(gens []Generator, max int) []sequence {
+	var out []sequence
+	level := []sequence{{state: Solved}}
+	for range max {
+		var next []sequence
+		for _, seq := range level {
+			for _, g := range gens {
+				if n := len(seq.moves); n > 0 && sameLayer(seq.moves[n-1], g.Move) {
+					continue
+				}
+				next = append(next, sequence{
+					moves: append(slices.Clip(seq.moves), g.Move),
+					state: seq.state.Then(g.State),
+				})
+			}
+		}
+		out = append(out, next...)
+		level = next
+	}
+	return out
+}
+
+// Rotations are the effects of the 24 orientations of a cube.
+var Rotations = func() []State {
+	out := []State{Solved}
+	for i := 0; i < len(out); i++ {
+		for _, g := range turns("xy") {
+			next := out[i].Then(g.State)
+			if !slices.Contains(out, next) {
+				out = append(out, next)
+			}
+		}
+	}
+	return out
+}()
+
+// Reoriented returns the state followed by the rotation that brings every
+// center back to its face.
+func (s State) Reoriented() State {
+	for _, r := range Rotations {
+		next := s.Then(r)
+		if next.centersSolved() {
+			return next
+		}
+	}
+	return s
+}
+
+func (s State) centersSolved() bool {
+	for f := range 6 {
+		if center := f*9 + 4; int(s[center]) != center {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,171 @@
+package solve
+
+import (
+	"errors"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"math/big"
+	"unicode"
+)
+
+var ErrNotInSubgroup = errors.New("state outside the subgroup of the moves")
+
+// Subset returns the quarter, half and inverse turns of the operators of a
+// move set, such as ParseMoveSet("<R,U>") or "MU" for the last six edges,
+// with lowercase letters other than x, y and z for wide turns.
+func Subset(operators string) ([]Generator, error) {
+	var gens []Generator
+	for _, op := range operators {
+		wide := unicode.IsLower(op) && op != 'x' && op != 'y' && op != 'z'
+		if wide {
+			op = unicode.ToUpper(op)
+		}
+		for _, m := range []cube.Move{
+			{Operator: op, Wide: wide, Rotations: 1},
+			{Operator: op, Wide: wide, Rotations: 2},
+			{Operator: op, Wide: wide, Rotations: 1, Inverted: true},
+		} {
+			m.Normalize()
+			s, err := StateOf(m)
+			if err != nil {
+				return nil, err
+			}
+			gens = append(gens, Generator{m, s})
+		}
+	}
+	return gens, nil
+}
+
+// Subgroup is the group of the states that generators reach, as a chain of
+// stabilizers built with the Schreier-Sims algorithm in Knuth's form. Level k
+// holds the states that keep the stickers after k in place, and one of them
+// for every place that sticker k can go to.
+type Subgroup struct {
+	reps [NumStickers][NumStickers]*State
+	gens [NumStickers][]State
+}
+
+// NewSubgroup returns the subgroup that the generators reach, such as
+// Subset("RU") for the 2-gen states.
+func NewSubgroup(gens []Generator) *Subgroup {
+	g := &Subgroup{}
+	for k := range g.reps {
+		g.reps[k][k] = &Solved
+	}
+	for _, gen := range gens {
+		g.add(NumStickers-1, gen.State)
+	}
+	return g
+}
+
+// Contains reports whether the state is in the subgroup, as it is: a state
+// turned by a rotation is outside a subgroup without rotations.
+func (g *Subgroup) Contains(s State) bool {
+	return g.sift(NumStickers-1, s)
+}
+
+// Order returns the number of states in the subgroup, which is too many for
+// an int for the whole cube.
+func (g *Subgroup) Order() *big.Int {
+	order := big.NewInt(1)
+	for k := range g.reps {
+		n := 0
+		for _, r := range g.reps[k] {
+			if r != nil {
+				n++
+			}
+		}
+		order.Mul(order, big.NewInt(int64(n)))
+	}
+	return order
+}
+
+// sift reports whether a state that keeps the stickers after k in place is
+// in the subgroup, taking out the representative of every level.
+func (g *Subgroup) sift(k int, s State) bool {
+	for ; k >= 0; k-- {
+		r := g.reps[k][s[k]]
+		if r == nil {
+			return false
+		}
+		s = r.Inverse().Then(s)
+	}
+	return true
+}
+
+// add adds a state to level k, with the states it generates together with
+// the representatives.
+func (g *Subgroup) add(k int, s State) {
+	if g.sift(k, s) {
+		return
+	}
+	g.gens[k] = append(g.gens[k], s)
+	for _, r := range g.reps[k] {
+		if r != nil {
+			g.extend(k, s.Then(*r))
+		}
+	}
+}
+
+// extend makes the state a representative of level k if its place is new,
+// and else adds what tells it apart from the representative to the level
+// below.
+func (g *Subgroup) extend(k int, s State) {
+	r := g.reps[k][s[k]]
+	if r == nil {
+		g.reps[k][s[k]] = &s
+		for _, gen := range g.gens[k] {
+			g.extend(k, gen.Then(s))
+		}
+		return
+	}
+	if h := r.Inverse().Then(s); h != Solved {
+		g.add(k-1, h)
+	}
+}
+
+// subsetTableSize bounds the states of the distance table of SolveSubset.
+const subsetTableSize = 1 << 20
+
+// SolveSubset returns the shortest solutions of the state with the
+// generators of a subset, such as Subset("MU") for the last six edges,
+// shortest first. It returns ErrNotInSubgroup rather than searching in vain
+// for a state they cannot solve. MaxMoves defaults to 16.
+func SolveSubset(s State, gens []Generator, opts CrossOptions) ([][]cube.Move, error) {
+	if !NewSubgroup(gens).Contains(s) {
+		return nil, ErrNotInSubgroup
+	}
+	if opts.MaxMoves <= 0 {
+		opts.MaxMoves = 16
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = 1
+	}
+
+	// The table reaches as deep as fits, which is deeper for fewer
+	// generators.
+	depth := 0
+	for size := 1; depth < opts.MaxMoves; depth++ {
+		if size *= len(gens); size > subsetTableSize {
+			break
+		}
+	}
+	dist := distances(Solved, gens, depth)
+
+	var solutions [][]cube.Move
+	search := Search{
+		Generators: gens,
+		Goal:       func(s State) bool { return s == Solved },
+		Bound: func(s State) int {
+			if d, ok := dist[s]; ok {
+				return d
+			}
+			return depth + 1
+		},
+		Found: func(moves []cube.Move) bool {
+			solutions = append(solutions, moves)
+			return len(solutions) < opts.Limit
+		},
+	}
+	opts.run(&search, s)
+	return solutions, nil
+}
@@ -0,0 +1,259 @@
+package solve
+
+import (
+	"github.com/larssont/go-cubic/pkg/cube"
+	"slices"
+)
+
+// NumSymmetries is the number of symmetries of the cube, its 24 rotations
+// with and without a mirror.
+const NumSymmetries = 48
+
+// mirror is the reflection through the M slice, which swaps L and R, as a
+// relabeling of the stickers.
+var mirror = func() State {
+	var s State
+	mirrored := map[cube.Face]cube.Face{cube.FaceLeft: cube.FaceRight, cube.FaceRight: cube.FaceLeft}
+	for x := range 3 {
+		for y := range 3 {
+			for z := range 3 {
+				for _, a := range cube.StickersAt(3, x, y, z) {
+					f, ok := mirrored[a.Face]
+					if !ok {
+						f = a.Face
+					}
+					for _, b := range cube.StickersAt(3, 2-x, y, z) {
+						if b.Face == f {
+							s[int(b.Face)*9+b.Index] = uint8(int(a.Face)*9 + a.Index)
+						}
+					}
+				}
+			}
+		}
+	}
+	return s
+}()
+
+// Symmetries holds the symmetries of the cube as relabelings of the stickers:
+// the Rotations, then each of them followed by the mirror through the M
+// slice. Mirrored symmetries turn every move into a move of the other hand,
+// so that distances from solved are the same for all symmetric states.
+var Symmetries = func() [NumSymmetries]State {
+	var out [NumSymmetries]State
+	for i, r := range Rotations {
+		out[i] = r
+		out[i+len(Rotations)] = r.Then(mirror)
+	}
+	return out
+}()
+
+// Conjugate returns the state as seen through symmetry k: the state that the
+// moves of s turned through the symmetry reach, such as R turned into L' by
+// the mirror.
+func (s State) Conjugate(k int) State {
+	sym := Symmetries[k]
+	return sym.Inverse().Then(s).Then(sym)
+}
+
+// Stabilizer returns the symmetries that keep a set of stickers, such as the
+// eight that keep the cross on D. Tables of the positions of those stickers
+// need only one entry for the states these symmetries relate.
+func Stabilizer(stickers []int) []int {
+	var out []int
+	for k, sym := range Symmetries {
+		keeps := true
+		for _, s := range stickers {
+			keeps = keeps && slices.Contains(stickers, int(sym[s]))
+		}
+		if keeps {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+// Representative returns the smallest of the states related to s by the
+// symmetries, and a symmetry that conjugates s to it. Symmetric states share
+// the representative.
+func Representative(s State, symmetries []int) (State, int) {
+	best, bestSym := s, -1
+	for _, k := range symmetries {
+		if c := s.Conjugate(k); bestSym < 0 || slices.Compare(c[:], best[:]) < 0 {
+			best, bestSym = c, k
+		}
+	}
+	return best, bestSym
+}
+
+// SymTable holds the distance from solved of the placements of a few corner
+// and edge stickers, with one entry for every class of placements that the
+// symmetries keeping the stickers relate, as those are equally far from
+// solved.
+type SymTable struct {
+	stickers []int
+	corner   []bool       // Whether every tracked sticker is of a corner
+	images   [][2][24]int // Image of every edge and corner slot, per symmetry
+	order    [][]int      // Tracked sticker each tracked sticker becomes, per symmetry
+	moves    [][][24]int
+	classes  []uint32 // Raw index of the representative of every class, sorted
+	dist     []int8
+}
+
+// maxSymStickers is the most stickers of a SymTable, whose raw indices fit in
+// 32 bits.
+const maxSymStickers = 6
+
+// NewSymTable builds the table of up to six corner and edge stickers. The raw
+// index of a placement has the slots of the stickers as digits in base 24,
+// the first sticker highest.
+func NewSymTable(stickers []int) *SymTable {
+	t := &SymTable{
+		stickers: slices.Clone(stickers),
+		corner:   make([]bool, len(stickers)),
+		moves:    make([][][24]int, len(stickers)),
+	}
+	for i, s := range stickers {
+		_, t.moves[i] = slotOf(s)
+		t.corner[i] = cornerSlots[s] >= 0
+	}
+
+	// A symmetry moves the sticker in every slot to the slot of its image,
+	// and every tracked sticker to another one.
+	for _, k := range Stabilizer(stickers) {
+		forward := Symmetries[k]
+		var images [2][24]int
+		for sticker := range NumStickers {
+			image := int(forward[sticker])
+			if slot := cornerSlots[sticker]; slot >= 0 {
+				images[1][slot] = cornerSlots[image]
+			} else if slot := edgeSlots[sticker]; slot >= 0 {
+				images[0][slot] = edgeSlots[image]
+			}
+		}
+		order := make([]int, len(stickers))
+		for i, s := range stickers {
+			order[i] = slices.Index(stickers, int(forward[s]))
+		}
+		t.images = append(t.images, images)
+		t.order = append(t.order, order)
+	}
+	t.build()
+	return t
+}
+
+// Symmetries returns the number of symmetries that relate the placements.
+func (t *SymTable) Symmetries() int {
+	return len(t.images)
+}
+
+// Len returns the number of classes, the entries of the table.
+func (t *SymTable) Len() int {
+	return len(t.classes)
+}
+
+// RawLen returns the number of placements of the stickers.
+func (t *SymTable) RawLen() int {
+	n := 1
+	for range t.stickers {
+		n *= 24
+	}
+	return n
+}
+
+// canonical returns the smallest raw index of the placements symmetric to
+// the placement of the slots.
+func (t *SymTable) canonical(slots []int) uint32 {
+	best := uint32(0)
+	var image [maxSymStickers]int
+	for k, images := range t.images {
+		for i, slot := range slots {
+			kind := 0
+			if t.corner[i] {
+				kind = 1
+			}
+			image[t.order[k][i]] = images[kind][slot]
+		}
+		j := uint32(0)
+		for _, slot := range image[:len(slots)] {
+			j = j*24 + uint32(slot)
+		}
+		if k == 0 || j < best {
+			best = j
+		}
+	}
+	return best
+}
+
+// build searches the classes breadth first from solved.
+func (t *SymTable) build() {
+	n := len(t.stickers)
+	start := make([]int, n)
+	for i, s := range t.stickers {
+		start[i], _ = slotOf(s)
+	}
+
+	dist := map[uint32]int8{}
+	first := t.canonical(start)
+	dist[first] = 0
+	level := []uint32{first}
+	slots, to := make([]int, n), make([]int, n)
+	for depth := int8(1); len(level) > 0; depth++ {
+		var next []uint32
+		for _, i := range level {
+			for k, rest := n-1, int(i); k >= 0; k, rest = k-1, rest/24 {
+				slots[k] = rest % 24
+			}
+			for m := range FaceTurns {
+				for k, slot := range slots {
+					to[k] = t.moves[k][m][slot]
+				}
+				if j := t.canonical(to); !hasKey(dist, j) {
+					dist[j] = depth
+					next = append(next, j)
+				}
+			}
+		}
+		level = next
+	}
+
+	t.classes = make([]uint32, 0, len(dist))
+	for j := range dist {
+		t.classes = append(t.classes, j)
+	}
+	slices.Sort(t.classes)
+	t.dist = make([]int8, len(t.classes))
+	for i, j := range t.classes {
+		t.dist[i] = dist[j]
+	}
+}
+
+func hasKey[K comparable, V any](m map[K]V, k K) bool {
+	_, ok := m[k]
+	return ok
+}
+
+// Class returns the class of the placement of the stickers in the state, an
+// index of the table from 0 to Len.
+func (t *SymTable) Class(s State) int {
+	where := s.Inverse()
+	var slots [maxSymStickers]int
+	for i, sticker := range t.stickers {
+		if t.corner[i] {
+			slots[i] = cornerSlots[where[sticker]]
+		} else {
+			slots[i] = edgeSlots[where[sticker]]
+		}
+	}
+	return t.class(slots[:len(t.stickers)])
+}
+
+func (t *SymTable) class(slots []int) int {
+	i, _ := slices.BinarySearch(t.classes, t.canonical(slots))
+	return i
+}
+
+// Distance returns the length of the shortest solution of the stickers of
+// the state.
+func (t *SymTable) Distance(s State) int {
+	return int(t.dist[t.Class(s)])
+}
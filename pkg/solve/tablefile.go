@@ -0,0 +1,224 @@
+package solve
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+var (
+	ErrTableFile     = errors.New("not a pruning table file")
+	ErrTableVersion  = errors.New("pruning table file of another version")
+	ErrTableChecksum = errors.New("pruning table file does not match its checksum")
+)
+
+// A table file is a header of headerSize bytes followed by the distances of
+// the table, one byte each. The header holds, little endian:
+//
+//	magic    [4]byte  "GCPT"
+//	format   uint16   tableFormat
+//	version  uint16   tableVersion, bumped when the tables change
+//	puzzle   [8]byte  such as "3x3", zero padded
+//	name     [32]byte such as "phase1-slice-twist", zero padded
+//	length   uint64   number of distances
+//	checksum uint32   CRC-32 (IEEE) of the distances
+//
+// and zeros up to headerSize, so that the distances of a mapped file are page
+// aligned in memory.
+const (
+	tableMagic   = "GCPT"
+	tableFormat  = 1
+	tableVersion = 1
+	headerSize   = 64
+)
+
+// TableHeader describes the table of a table file.
+type TableHeader struct {
+	Puzzle   string
+	Name     string
+	Version  int
+	Length   int
+	Checksum uint32
+}
+
+// WriteTable writes the table as a table file of the name.
+func WriteTable(w io.Writer, name string, table []int8) error {
+	data := int8Bytes(table)
+	var header [headerSize]byte
+	copy(header[0:4], tableMagic)
+	binary.LittleEndian.PutUint16(header[4:6], tableFormat)
+	binary.LittleEndian.PutUint16(header[6:8], tableVersion)
+	copy(header[8:16], "3x3")
+	copy(header[16:48], name)
+	binary.LittleEndian.PutUint64(header[48:56], uint64(len(data)))
+	binary.LittleEndian.PutUint32(header[56:60], crc32.ChecksumIEEE(data))
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// ReadTableHeader parses the header at the start of a table file.
+func ReadTableHeader(b []byte) (TableHeader, error) {
+	if len(b) < headerSize || string(b[0:4]) != tableMagic {
+		return TableHeader{}, ErrTableFile
+	}
+	if binary.LittleEndian.Uint16(b[4:6]) != tableFormat {
+		return TableHeader{}, ErrTableVersion
+	}
+	return TableHeader{
+		Puzzle:   string(bytes.TrimRight(b[8:16], "\x00")),
+		Name:     string(bytes.TrimRight(b[16:48], "\x00")),
+		Version:  int(binary.LittleEndian.Uint16(b[6:8])),
+		Length:   int(binary.LittleEndian.Uint64(b[48:56])),
+		Checksum: binary.LittleEndian.Uint32(b[56:60]),
+	}, nil
+}
+
+// ReadTable reads a table file of the name into memory.
+func ReadTable(r io.Reader, name string) ([]int8, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return checkTable(b, name)
+}
+
+// checkTable returns the distances of a whole table file of the name, or an
+// error if the file is of another table or version or is damaged.
+func checkTable(b []byte, name string) ([]int8, error) {
+	h, err := ReadTableHeader(b)
+	if err != nil {
+		return nil, err
+	}
+	if h.Name != name || h.Puzzle != "3x3" || h.Length != len(b)-headerSize {
+		return nil, ErrTableFile
+	}
+	if h.Version != tableVersion {
+		return nil, ErrTableVersion
+	}
+	data := b[headerSize:]
+	if crc32.ChecksumIEEE(data) != h.Checksum {
+		return nil, ErrTableChecksum
+	}
+	return bytesInt8(data), nil
+}
+
+func int8Bytes(table []int8) []byte {
+	if len(table) == 0 {
+		return nil
+	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(&table[0])), len(table))
+}
+
+func bytesInt8(b []byte) []int8 {
+	if len(b) == 0 {
+		return nil
+	}
+	return unsafe.Slice((*int8)(unsafe.Pointer(&b[0])), len(b))
+}
+
+// tableNames are the tables of the package that can be kept in files, with
+// the functions that return them.
+var tableNames = []struct {
+	name  string
+	build func() []int8
+}{
+	{"block", func() []int8 { return blockTable() }},
+	{"xcross-corner", func() []int8 { return cornerPairTable() }},
+	{"xcross-edge", func() []int8 { return edgePairTable() }},
+	{"phase1-slice-twist", func() []int8 { return twoPhaseTables().sliceTwist }},
+	{"phase1-slice-flip", func() []int8 { return twoPhaseTables().sliceFlip }},
+	{"phase2-corner-slice", func() []int8 { return twoPhaseTables().cornerSlice }},
+	{"phase2-edge-slice", func() []int8 { return twoPhaseTables().edgeSlice }},
+}
+
+// loadedTables holds the tables that UseTables has loaded by name.
+var loadedTables = struct {
+	sync.Mutex
+	tables map[string][]int8
+}{tables: map[string][]int8{}}
+
+// loadedTable returns the loaded table of the name, or builds it.
+func loadedTable(name string, build func() []int8) []int8 {
+	loadedTables.Lock()
+	table, ok := loadedTables.tables[name]
+	loadedTables.Unlock()
+	if ok {
+		return table
+	}
+	defer observeTable(name, time.Now())
+	return build()
+}
+
+// SaveTables writes a file of every pruning table of the solvers to the
+// directory, named after the table with the .table extension. Building them
+// all takes some seconds.
+func SaveTables(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, t := range tableNames {
+		if err := saveTable(filepath.Join(dir, t.name+".table"), t.name, t.build()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func saveTable(path, name string, table []int8) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteTable(f, name, table); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Warm builds or loads the tables of the two-phase and cross solvers now
+// rather than at their first solve, such as at the start of a server. Call
+// UseTables first to load them from files.
+func Warm() {
+	twoPhaseTables()
+	crossTable()
+}
+
+// UseTables loads the pruning tables that SaveTables wrote to the directory,
+// so that the solvers skip building them. Where the system allows, the files
+// are mapped into memory rather than read, so that loading is instant and
+// processes using the same files share their pages. Tables missing from the
+// directory are built as usual. UseTables must be called before the first
+// solve, and the loaded tables stay in use for the life of the process.
+func UseTables(dir string) error {
+	for _, t := range tableNames {
+		start := time.Now()
+		b, err := mapFile(filepath.Join(dir, t.name+".table"))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		table, err := checkTable(b, t.name)
+		if err != nil {
+			return fmt.Errorf("%s: %w", t.name, err)
+		}
+		loadedTables.Lock()
+		loadedTables.tables[t.name] = table
+		loadedTables.Unlock()
+		observeTable(t.name, start)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package solve
+
+import (
+	"hash/maphash"
+	"sync"
+)
+
+// ReplacementPolicy decides which entry a transposition table keeps when two
+// states share a slot.
+type ReplacementPolicy int
+
+const (
+	// ReplaceAlways keeps the newest entry, which suits a batch of similar
+	// solves.
+	ReplaceAlways ReplacementPolicy = iota
+
+	// KeepDeeper keeps the entry with the larger bound, which cost the most
+	// to find.
+	KeepDeeper
+)
+
+// TableOptions configures NewTranspositionTable.
+type TableOptions struct {
+	// MaxEntries is the number of entries, about 64 bytes each. Defaults to
+	// 1<<18.
+	MaxEntries int
+	Policy     ReplacementPolicy
+}
+
+type tableKey struct {
+	state State
+	last  rune // Operator of the move into the state, which the search does not repeat
+}
+
+type tableEntry struct {
+	key   tableKey
+	bound int8
+	used  bool
+}
+
+// TranspositionTable remembers lower bounds of the moves left from states
+// that an iterative deepening search has proven, so that later iterations and
+// later searches with the same goal and generators skip them. It is safe for
+// concurrent use. A table must only be shared by searches of the same goal,
+// such as the crosses of one face.
+type TranspositionTable struct {
+	mu      sync.Mutex
+	entries []tableEntry
+	policy  ReplacementPolicy
+	seed    maphash.Seed
+	stats   TableStats
+}
+
+// TableStats counts the lookups and stores of a table.
+type TableStats struct {
+	Hits, Misses, Stores, Replaced int
+}
+
+func NewTranspositionTable(opts TableOptions) *TranspositionTable {
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = 1 << 18
+	}
+	return &TranspositionTable{
+		entries: make([]tableEntry, opts.MaxEntries),
+		policy:  opts.Policy,
+		seed:    maphash.MakeSeed(),
+	}
+}
+
+func (t *TranspositionTable) slot(k tableKey) *tableEntry {
+	var h maphash.Hash
+	h.SetSeed(t.seed)
+	h.Write(k.state[:])
+	h.WriteString(string(k.last))
+	return &t.entries[h.Sum64()%uint64(len(t.entries))]
+}
+
+// lookup returns the bound stored for the key, and false if there is none.
+func (t *TranspositionTable) lookup(k tableKey) (int, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if e := t.slot(k); e.used && e.key == k {
+		t.stats.Hits++
+		return int(e.bound), true
+	}
+	t.stats.Misses++
+	return 0, false
+}
+
+// store records that no goal is closer than bound moves from the key.
+func (t *TranspositionTable) store(k tableKey, bound int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	e := t.slot(k)
+	if e.used && e.key == k {
+		e.bound = int8(max(int(e.bound), bound))
+		return
+	}
+	if e.used {
+		if t.policy == KeepDeeper && int(e.bound) > bound {
+			return
+		}
+		t.stats.Replaced++
+	}
+	*e = tableEntry{key: k, bound: int8(bound), used: true}
+	t.stats.Stores++
+}
+
+// Stats returns the counts of the table since it was made or cleared.
+func (t *TranspositionTable) Stats() TableStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stats
+}
+
+// Clear removes every entry, such as before searches with another goal.
+func (t *TranspositionTable) Clear() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	clear(t.entries)
+	t.stats = TableStats{}
+}
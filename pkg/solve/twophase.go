@@ -0,0 +1,637 @@
+package solve
+
+import (
+	"errors"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var ErrNoSolution = errors.New("no solution within the move limit")
+
+// SolveOptions configures Solve.
+type SolveOptions struct {
+	// MaxLength ends the search at the first solution of at most this many
+	// moves, unless KeepImproving is set. Defaults to 21; 30 ends it at the
+	// first solution.
+	MaxLength int
+
+	// Timeout ends the search with the shortest solution found so far.
+	// Defaults to 2 seconds.
+	Timeout time.Duration
+
+	// MaxNodes, if set, ends the search with the shortest solution found so
+	// far once it has visited about that many nodes of both phases.
+	MaxNodes int
+
+	// KeepImproving goes on searching for shorter solutions after one of
+	// MaxLength moves, until the search runs out of time or nodes or no
+	// shorter solution is left to find.
+	KeepImproving bool
+
+	// Workers is the number of goroutines that search, each from its own
+	// share of the first moves. Defaults to 1; runtime.NumCPU suits a single
+	// solve on an otherwise idle machine.
+	Workers int
+
+	// Progress, if set, is called as the search goes: when it starts a
+	// longer first phase, when it finds a shorter solution, at least every
+	// ProgressInterval in between, and once it ends. Calls do not overlap.
+	Progress func(Progress)
+
+	// Annotate explains the two phases of the solution of SolveDetailed in
+	// its Annotations.
+	Annotate bool
+
+	// Cost, if set, orders the solutions of SolveTop from the lowest cost
+	// rather than the fewest moves, such as the weighted cost of
+	// analysis.CostModel, to find the fastest of them to execute.
+	Cost func([]cube.Move) float64
+}
+
+// ProgressInterval is the longest time between calls of
+// SolveOptions.Progress.
+const ProgressInterval = 250 * time.Millisecond
+
+// Progress is a report of a running two-phase search.
+type Progress struct {
+	Depth    int // Length of the first phases being searched
+	Nodes    int
+	Elapsed  time.Duration
+	Best     []cube.Move // Shortest solution so far, nil before the first
+	Improved bool        // Best is shorter than at the last report
+}
+
+// phase2Moves are the indices in FaceTurns of the moves keeping the cube in
+// the group <U, D, L2, F2, R2, B2>.
+var phase2Moves = []int{0, 1, 2, 4, 7, 10, 13, 15, 16, 17}
+
+const (
+	numTwist  = 2187
+	numFlip   = 2048
+	numSlice  = 495
+	numPerm8  = 40320
+	numPerm4  = 24
+	numMoves  = 18
+	unvisited = -1
+)
+
+// tables holds the move and pruning tables of the two-phase algorithm.
+type tables struct {
+	moves [numMoves]cubie
+
+	twist, flip, slice       [][numMoves]uint16
+	cornerPerm, edgePerm     [][numMoves]uint16
+	slicePerm                [][numMoves]uint16
+	sliceTwist, sliceFlip    []int8
+	cornerSlice, edgeSlice   []int8
+	phase1Moves, phase2Moves []int
+}
+
+var twoPhaseTables = sync.OnceValue(func() *tables {
+	defer observeTable("two-phase", time.Now())
+	t := &tables{phase2Moves: phase2Moves}
+	for i, g := range FaceTurns {
+		t.moves[i], _ = cubieOf(g.State)
+		t.phase1Moves = append(t.phase1Moves, i)
+	}
+
+	t.twist = t.moveTable(numTwist, t.phase1Moves, (*cubie).twist)
+	t.flip = t.moveTable(numFlip, t.phase1Moves, (*cubie).flip)
+	t.slice = t.moveTable(numSlice, t.phase1Moves, (*cubie).slice)
+	t.cornerPerm = t.moveTable(numPerm8, phase2Moves, (*cubie).cornerPerm)
+	t.edgePerm = t.moveTable(numPerm8, phase2Moves, (*cubie).edgePerm)
+	t.slicePerm = t.moveTable(numPerm4, phase2Moves, (*cubie).slicePerm)
+
+	t.sliceTwist = loadedTable("phase1-slice-twist", func() []int8 {
+		return pruningTable(t.slice, t.twist, t.phase1Moves)
+	})
+	t.sliceFlip = loadedTable("phase1-slice-flip", func() []int8 {
+		return pruningTable(t.slice, t.flip, t.phase1Moves)
+	})
+	t.cornerSlice = loadedTable("phase2-corner-slice", func() []int8 {
+		return pruningTable(t.slicePerm, t.cornerPerm, phase2Moves)
+	})
+	t.edgeSlice = loadedTable("phase2-edge-slice", func() []int8 {
+		return pruningTable(t.slicePerm, t.edgePerm, phase2Moves)
+	})
+	return t
+})
+
+// moveTable returns the coordinate reached by every move from every
+// coordinate value, found by a breadth first search from the solved cube.
+// Only the given moves are filled in.
+func (t *tables) moveTable(n int, moves []int, coord func(*cubie) int) [][numMoves]uint16 {
+	table := make([][numMoves]uint16, n)
+	seen := make([]bool, n)
+	seen[0] = true
+
+	queue := []cubie{solvedCubie}
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+		from := coord(&c)
+		for _, m := range moves {
+			next := c.then(t.moves[m])
+			to := coord(&next)
+			table[from][m] = uint16(to)
+			if !seen[to] {
+				seen[to] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return table
+}
+
+// pruningTable returns the distance of every pair of coordinates from the
+// solved pair, indexed by a*len(b)+b.
+func pruningTable(a, b [][numMoves]uint16, moves []int) []int8 {
+	return distanceTable(a, b, moves, func(x, y int) bool { return x == 0 && y == 0 })
+}
+
+// distanceTable returns the distance of every pair of coordinates from the
+// nearest pair that is done, indexed by a*len(b)+b.
+func distanceTable(a, b [][numMoves]uint16, moves []int, done func(x, y int) bool) []int8 {
+	table := make([]int8, len(a)*len(b))
+	filled := 0
+	for i := range table {
+		table[i] = unvisited
+		if done(i/len(b), i%len(b)) {
+			table[i] = 0
+			filled++
+		}
+	}
+
+	for depth := int8(0); filled < len(table); depth++ {
+		before := filled
+		for i, d := range table {
+			if d != depth {
+				continue
+			}
+			x, y := i/len(b), i%len(b)
+			for _, m := range moves {
+				j := int(a[x][m])*len(b) + int(b[y][m])
+				if table[j] == unvisited {
+					table[j] = depth + 1
+					filled++
+				}
+			}
+		}
+		if filled == before {
+			break
+		}
+	}
+	return table
+}
+
+// twoPhase is the state of one worker of a two-phase search.
+type twoPhase struct {
+	*tables
+	start    cubie
+	first    []int // First moves of the worker's share of the search
+	moves    []int
+	split    int
+	found    *found
+	target   int
+	improve  bool
+	deadline time.Time
+	maxNodes int
+	nodes    int
+}
+
+// found is the shortest distinct solutions the workers of a search have
+// found, up to n of them, and whether and why they should stop.
+type found struct {
+	mu      sync.Mutex
+	n       int
+	top     [][]int // Shortest first
+	stopped StopReason
+	stop    atomic.Bool
+	nodes   atomic.Int64
+
+	progress   func(Progress) // Nil for no reports
+	start      time.Time
+	reporting  sync.Mutex
+	depth      atomic.Int64
+	lastReport atomic.Int64 // Nanoseconds from start
+}
+
+// report calls the progress function of the search, if any.
+func (f *found) report(improved bool) {
+	if f.progress == nil {
+		return
+	}
+	f.reporting.Lock()
+	defer f.reporting.Unlock()
+	p := Progress{
+		Depth:    int(f.depth.Load()),
+		Nodes:    int(f.nodes.Load()),
+		Elapsed:  time.Since(f.start),
+		Improved: improved,
+	}
+	f.mu.Lock()
+	if len(f.top) > 0 {
+		p.Best = faceTurns(f.top[0])
+	}
+	f.mu.Unlock()
+	f.lastReport.Store(int64(p.Elapsed))
+	f.progress(p)
+}
+
+// reach reports the first phases of a worker getting as long as depth, when
+// no worker has searched that deep yet.
+func (f *found) reach(depth int) {
+	for {
+		d := f.depth.Load()
+		if int64(depth) <= d {
+			return
+		}
+		if f.depth.CompareAndSwap(d, int64(depth)) {
+			f.report(false)
+			return
+		}
+	}
+}
+
+// tick reports the search if no report was made for ProgressInterval.
+func (f *found) tick() {
+	if f.progress != nil && time.Since(f.start)-time.Duration(f.lastReport.Load()) >= ProgressInterval {
+		f.report(false)
+	}
+}
+
+// halt stops the workers for the reason, unless they are stopping already.
+func (f *found) halt(r StopReason) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.stop.Load() {
+		f.stopped = r
+		f.stop.Store(true)
+	}
+}
+
+// length returns the length that solutions must be shorter than to be
+// kept, the length of the longest of the solutions once there are n of them,
+// and false before.
+func (f *found) length() (int, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.top) < f.n {
+		return 0, false
+	}
+	return len(f.top[f.n-1]), true
+}
+
+// offer keeps the solution if it is among the n shortest yet and differs
+// from those, and reports it if it is the shortest.
+func (f *found) offer(moves []int) {
+	if f.keep(moves) {
+		f.report(true)
+	}
+}
+
+// keep keeps the solution as offer does, and reports whether it is the
+// shortest.
+func (f *found) keep(moves []int) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	i := len(f.top)
+	for j, other := range f.top {
+		if slices.Equal(other, moves) {
+			return false
+		}
+		if i == len(f.top) && len(moves) < len(other) {
+			i = j
+		}
+	}
+	if i >= f.n {
+		return false
+	}
+	f.top = slices.Insert(f.top, i, moves)
+	f.top = f.top[:min(len(f.top), f.n)]
+	return i == 0
+}
+
+// Solve returns a solution of the state with Kociemba's two-phase algorithm.
+// The first phase brings the cube into <U, D, L2, F2, R2, B2>, and the second
+// solves it with these moves. Solutions are not optimal but are found
+// quickly, and longer searches only ever shorten them.
+func Solve(s State, opts SolveOptions) ([]cube.Move, error) {
+	solution, err := SolveDetailed(s, opts)
+	return solution.Moves, err
+}
+
+// SolveDetailed solves the state as Solve does, and also tells how much the
+// search did and which limit ended it.
+func SolveDetailed(s State, opts SolveOptions) (Solution, error) {
+	c, err := cubieOf(s)
+	if err != nil {
+		return Solution{}, err
+	}
+	f, stats := solveTwoPhase(c, 1, opts)
+	if len(f.top) == 0 {
+		return Solution{SearchStats: stats}, ErrNoSolution
+	}
+	solution := Solution{Moves: faceTurns(f.top[0]), SearchStats: stats}
+	if opts.Annotate {
+		solution.Annotations = twoPhaseAnnotations(s, solution.Moves)
+	}
+	return solution, nil
+}
+
+// SolveTop returns up to n distinct solutions of the state, shortest first
+// or by Cost, the shortest that the two-phase search finds within the
+// options. No two differ only in the order of turns of opposite faces or in
+// turns that cancel. MaxLength ends the search once there are n solutions
+// that long, unless KeepImproving is set. The solved state has only the
+// empty solution, and for n <= 0 SolveTop returns nil without searching.
+func SolveTop(s State, n int, opts SolveOptions) ([][]cube.Move, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	c, err := cubieOf(s)
+	if err != nil {
+		return nil, err
+	}
+	if s == Solved {
+		return [][]cube.Move{{}}, nil
+	}
+	f, _ := solveTwoPhase(c, n, opts)
+	if len(f.top) == 0 {
+		return nil, ErrNoSolution
+	}
+	solutions := make([][]cube.Move, len(f.top))
+	for i, moves := range f.top {
+		solutions[i] = faceTurns(moves)
+	}
+	if opts.Cost != nil {
+		sortByCost(solutions, opts.Cost)
+	}
+	return solutions, nil
+}
+
+// solveTwoPhase searches for the n shortest solutions of the cube.
+func solveTwoPhase(c cubie, n int, opts SolveOptions) (*found, SearchStats) {
+	if opts.MaxLength <= 0 {
+		opts.MaxLength = 21
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 2 * time.Second
+	}
+	opts.Workers = max(opts.Workers, 1)
+
+	t := twoPhaseTables()
+	start := time.Now()
+	f := &found{n: n, progress: opts.Progress, start: start}
+	deadline := start.Add(opts.Timeout)
+	var wg sync.WaitGroup
+	for w := range opts.Workers {
+		tp := &twoPhase{
+			tables:   t,
+			start:    c,
+			found:    f,
+			target:   opts.MaxLength,
+			improve:  opts.KeepImproving,
+			deadline: deadline,
+			maxNodes: opts.MaxNodes,
+		}
+		for i := w; i < len(t.phase1Moves); i += opts.Workers {
+			tp.first = append(tp.first, t.phase1Moves[i])
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tp.run()
+		}()
+	}
+	wg.Wait()
+	f.report(false)
+
+	stats := SearchStats{
+		Nodes:   int(f.nodes.Load()),
+		Elapsed: time.Since(start),
+		Stopped: f.stopped,
+	}
+	observeSearch("two-phase", stats)
+	return f, stats
+}
+
+// faceTurns returns the moves of indices into FaceTurns.
+func faceTurns(indices []int) []cube.Move {
+	moves := make([]cube.Move, len(indices))
+	for i, m := range indices {
+		moves[i] = FaceTurns[m].Move
+	}
+	return moves
+}
+
+// SolveAll solves the states with Solve, using opts.Workers goroutines for
+// the batch rather than for each state. The solutions and errors are in the
+// order of the states.
+func SolveAll(states []State, opts SolveOptions) ([][]cube.Move, []error) {
+	workers := max(opts.Workers, 1)
+	opts.Workers = 1
+
+	solutions := make([][]cube.Move, len(states))
+	errs := make([]error, len(states))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				solutions[i], errs[i] = Solve(states[i], opts)
+			}
+		}()
+	}
+	for i := range states {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return solutions, errs
+}
+
+// maxLength bounds the length of any solution searched.
+const maxLength = 30
+
+func (tp *twoPhase) run() {
+	defer func() { tp.found.nodes.Add(int64(tp.nodes % budgetCheck)) }()
+	twist, flip, slice := tp.start.twist(), tp.start.flip(), tp.start.slice()
+	for depth := 0; depth <= maxLength; depth++ {
+		if n, ok := tp.found.length(); ok && depth >= n {
+			return
+		}
+		tp.found.reach(depth)
+		if !tp.phase1(twist, flip, slice, depth) {
+			return
+		}
+	}
+}
+
+// spend counts a node, and reports false once the search should stop.
+func (tp *twoPhase) spend() bool {
+	tp.nodes++
+	if tp.nodes%budgetCheck == 0 {
+		nodes := tp.found.nodes.Add(budgetCheck)
+		if tp.maxNodes > 0 && nodes >= int64(tp.maxNodes) {
+			tp.found.halt(OutOfNodes)
+		} else if time.Now().After(tp.deadline) {
+			tp.found.halt(OutOfTime)
+		}
+		tp.found.tick()
+	}
+	return !tp.found.stop.Load()
+}
+
+// phase1Bound returns the moves left to reach the first phase, a lower bound
+// of the moves left to solve the cube.
+func (t *tables) phase1Bound(twist, flip, slice int) int {
+	return int(max(
+		t.sliceTwist[slice*numTwist+twist],
+		t.sliceFlip[slice*numFlip+flip],
+	))
+}
+
+// phase1 searches phase one sequences of exactly depth more moves, returning
+// false once the search should end.
+func (tp *twoPhase) phase1(twist, flip, slice, depth int) bool {
+	if !tp.spend() {
+		return false
+	}
+	if depth == 0 {
+		if twist != 0 || flip != 0 || slice != 0 {
+			return true
+		}
+		// A last move of phase two could have been left to phase two.
+		if n := len(tp.moves); n > 0 && slices.Contains(tp.phase2Moves, tp.moves[n-1]) {
+			return true
+		}
+		return tp.startPhase2()
+	}
+	if tp.phase1Bound(twist, flip, slice) > depth {
+		return true
+	}
+
+	moves := tp.phase1Moves
+	if len(tp.moves) == 0 {
+		moves = tp.first
+	}
+	for _, m := range moves {
+		if tp.redundant(m) {
+			continue
+		}
+		tp.moves = append(tp.moves, m)
+		ok := tp.phase1(int(tp.twist[twist][m]), int(tp.flip[flip][m]), int(tp.slice[slice][m]), depth-1)
+		tp.moves = tp.moves[:len(tp.moves)-1]
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (tp *twoPhase) startPhase2() bool {
+	c := tp.start
+	for _, m := range tp.moves {
+		c = c.then(tp.tables.moves[m])
+	}
+	corners, edges, slice := c.cornerPerm(), c.edgePerm(), c.slicePerm()
+	tp.split = len(tp.moves)
+
+	limit := maxLength
+	if n, ok := tp.found.length(); ok {
+		limit = n - 1
+	}
+	limit -= len(tp.moves)
+
+	for depth := 0; depth <= limit; depth++ {
+		if tp.phase2(corners, edges, slice, depth) {
+			break
+		}
+	}
+	if n, ok := tp.found.length(); ok && n <= tp.target && !tp.improve {
+		tp.found.halt(ReachedTarget)
+	} else if time.Now().After(tp.deadline) {
+		tp.found.halt(OutOfTime)
+	}
+	return !tp.found.stop.Load()
+}
+
+func (tp *twoPhase) phase2Bound(corners, edges, slice int) int {
+	return int(max(
+		tp.cornerSlice[slice*numPerm8+corners],
+		tp.edgeSlice[slice*numPerm8+edges],
+	))
+}
+
+// phase2 searches phase two sequences of exactly depth more moves, and
+// reports whether one solves the cube.
+func (tp *twoPhase) phase2(corners, edges, slice, depth int) bool {
+	if !tp.spend() {
+		return false
+	}
+	if depth == 0 {
+		if corners != 0 || edges != 0 || slice != 0 {
+			return false
+		}
+		tp.found.offer(normalize(tp.moves))
+		return true
+	}
+	if tp.phase2Bound(corners, edges, slice) > depth {
+		return false
+	}
+
+	for _, m := range tp.phase2Moves {
+		// The first move may turn the face of the last move of phase one, as
+		// in F F2 for F', since phase one ends with a quarter turn.
+		if len(tp.moves) > tp.split && tp.redundant(m) {
+			continue
+		}
+		tp.moves = append(tp.moves, m)
+		found := tp.phase2(int(tp.cornerPerm[corners][m]), int(tp.edgePerm[edges][m]), int(tp.slicePerm[slice][m]), depth-1)
+		tp.moves = tp.moves[:len(tp.moves)-1]
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+// redundant reports whether move m directly after the current moves could be
+// left out of the search, see the function of the same name.
+func (tp *twoPhase) redundant(m int) bool {
+	n := len(tp.moves)
+	return n > 0 && redundant(FaceTurns[tp.moves[n-1]].Move, FaceTurns[m].Move)
+}
+
+// faceAxes holds the axis of every face of FaceTurns, in the order U L F R B
+// D.
+var faceAxes = [6]int{0, 1, 2, 1, 2, 0}
+
+// normalize combines the turns of the same face at the end of phase one and
+// the start of phase two, and writes the turns of opposite faces in the
+// order the searches try them, U before D, L before R and F before B, so
+// that solutions differing only in how such turns are ordered or cancel are
+// written the same.
+func normalize(moves []int) []int {
+	var out []int
+	for i := 0; i < len(moves); {
+		axis := faceAxes[moves[i]/3]
+		var turns [6]int
+		for ; i < len(moves) && faceAxes[moves[i]/3] == axis; i++ {
+			// Moves of a face are its quarter, half and inverse turns.
+			turns[moves[i]/3] += moves[i]%3 + 1
+		}
+		for face, n := range turns {
+			if n%4 != 0 {
+				out = append(out, face*3+n%4-1)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,20 @@
+package solve
+
+import "testing"
+
+func TestSolveTopSolved(t *testing.T) {
+	solutions, err := SolveTop(Solved, 3, SolveOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(solutions) != 1 || len(solutions[0]) != 0 {
+		t.Errorf("SolveTop(Solved, 3) = %v, want only the empty solution", solutions)
+	}
+
+	for _, n := range []int{0, -1} {
+		solutions, err := SolveTop(Solved, n, SolveOptions{})
+		if solutions != nil || err != nil {
+			t.Errorf("SolveTop(Solved, %d) = %v, %v, want nil", n, solutions, err)
+		}
+	}
+}
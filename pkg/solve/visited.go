@@ -0,0 +1,146 @@
+package solve
+
+import (
+	"hash/maphash"
+	"math"
+)
+
+// Visited is a set of the states a search has seen.
+type Visited interface {
+	// Add adds a state and reports whether it was not in the set.
+	Add(State) bool
+	Contains(State) bool
+	Len() int
+}
+
+// StateSet is an exact set of states, stored in EncodedSize bytes each when
+// they can be encoded. Other states, such as those with moved centers, are
+// kept whole.
+type StateSet struct {
+	packed map[[EncodedSize]byte]struct{}
+	other  map[State]struct{}
+}
+
+func NewStateSet() *StateSet {
+	return &StateSet{packed: map[[EncodedSize]byte]struct{}{}, other: map[State]struct{}{}}
+}
+
+func (v *StateSet) Add(s State) bool {
+	if b, err := Encode(s); err == nil {
+		if _, ok := v.packed[b]; ok {
+			return false
+		}
+		v.packed[b] = struct{}{}
+		return true
+	}
+	if _, ok := v.other[s]; ok {
+		return false
+	}
+	v.other[s] = struct{}{}
+	return true
+}
+
+func (v *StateSet) Contains(s State) bool {
+	if b, err := Encode(s); err == nil {
+		_, ok := v.packed[b]
+		return ok
+	}
+	_, ok := v.other[s]
+	return ok
+}
+
+func (v *StateSet) Len() int {
+	return len(v.packed) + len(v.other)
+}
+
+// BloomFilter is a set of states of fixed memory that may report states it
+// has not seen as seen, at the rate it was made for, but never the other way
+// around. A search using it may miss some states, in exchange for bounded
+// memory.
+type BloomFilter struct {
+	bits   []uint64
+	hashes int
+	seed   maphash.Seed
+	n      int
+}
+
+// NewBloomFilter returns a filter for up to n states with the rate of false
+// positives, such as 0.01.
+func NewBloomFilter(n int, falsePositive float64) *BloomFilter {
+	n = max(n, 1)
+	falsePositive = min(max(falsePositive, 1e-9), 0.5)
+	bits := math.Ceil(-float64(n) * math.Log(falsePositive) / (math.Ln2 * math.Ln2))
+	hashes := max(int(math.Round(bits/float64(n)*math.Ln2)), 1)
+	return &BloomFilter{
+		bits:   make([]uint64, (int(bits)+63)/64),
+		hashes: hashes,
+		seed:   maphash.MakeSeed(),
+	}
+}
+
+// positions calls fn with the bit of every hash of the state, derived from
+// two halves of one hash.
+func (f *BloomFilter) positions(s State, fn func(word int, mask uint64) bool) bool {
+	h := maphash.Bytes(f.seed, s[:])
+	h1, h2 := h&math.MaxUint32, h>>32|1
+	size := uint64(len(f.bits) * 64)
+	for i := range uint64(f.hashes) {
+		bit := (h1 + i*h2) % size
+		if !fn(int(bit/64), 1<<(bit%64)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *BloomFilter) Add(s State) bool {
+	added := false
+	f.positions(s, func(word int, mask uint64) bool {
+		if f.bits[word]&mask == 0 {
+			f.bits[word] |= mask
+			added = true
+		}
+		return true
+	})
+	if added {
+		f.n++
+	}
+	return added
+}
+
+func (f *BloomFilter) Contains(s State) bool {
+	return f.positions(s, func(word int, mask uint64) bool {
+		return f.bits[word]&mask != 0
+	})
+}
+
+// Len returns the number of states added that were not reported as seen.
+func (f *BloomFilter) Len() int {
+	return f.n
+}
+
+// BreadthFirst visits the states within depth moves of the start, each once
+// with its distance, nearest first. States already in the visited set are
+// skipped. Returning false from visit stops the search.
+func BreadthFirst(start State, gens []Generator, depth int, visited Visited, visit func(s State, distance int) bool) {
+	if !visited.Add(start) || !visit(start, 0) {
+		return
+	}
+	level := []State{start}
+	for d := 1; d <= depth && len(level) > 0; d++ {
+		var next []State
+		for _, state := range level {
+			for _, g := range gens {
+				s := state.Then(g.State)
+				if !visited.Add(s) {
+					continue
+				}
+				if !visit(s, d) {
+					return
+				}
+				next = append(next, s)
+			}
+		}
+		level = next
+	}
+}
@@ -0,0 +1,67 @@
+package solve
+
+import "errors"
+
+var ErrBudget = errors.New("search ran out of time or nodes")
+
+// SolveWithin returns a shortest solution of the state if it has one of at
+// most k moves, and ErrNoSolution once the search has proven that it has
+// none. A search that runs out of the Timeout or MaxNodes of the options, if
+// set, first returns ErrBudget, as the state may have a solution it did not
+// reach.
+// The search is optimal, so k much above 12 takes long; it suits rejecting
+// scrambles solvable in a few moves.
+func SolveWithin(s State, k int, opts SolveOptions) (solution Solution, err error) {
+	c, err := cubieOf(s)
+	if err != nil {
+		return Solution{}, err
+	}
+	defer func() { observeSearch("optimal", solution.SearchStats) }()
+	w := &within{
+		tables: twoPhaseTables(),
+		budget: newBudget(opts.Timeout, opts.MaxNodes),
+	}
+	for depth := 0; depth <= k; depth++ {
+		if w.search(c, c.twist(), c.flip(), c.slice(), depth) {
+			return Solution{Moves: faceTurns(w.moves), SearchStats: w.budget.stats()}, nil
+		}
+		if w.budget.stopped != Completed {
+			return Solution{SearchStats: w.budget.stats()}, ErrBudget
+		}
+	}
+	return Solution{SearchStats: w.budget.stats()}, ErrNoSolution
+}
+
+// within is the state of a search of SolveWithin, bounded by the moves left
+// to reach the first phase of the two-phase algorithm.
+type within struct {
+	*tables
+	budget budget
+	moves  []int
+}
+
+// search reports whether a sequence of exactly depth more moves solves the
+// cube, leaving it in the moves.
+func (w *within) search(c cubie, twist, flip, slice, depth int) bool {
+	if !w.budget.spend() {
+		return false
+	}
+	if depth == 0 {
+		return c == solvedCubie
+	}
+	if w.phase1Bound(twist, flip, slice) > depth {
+		return false
+	}
+
+	for _, m := range w.phase1Moves {
+		if n := len(w.moves); n > 0 && redundant(FaceTurns[w.moves[n-1]].Move, FaceTurns[m].Move) {
+			continue
+		}
+		w.moves = append(w.moves, m)
+		if w.search(c.then(w.tables.moves[m]), int(w.twist[twist][m]), int(w.flip[flip][m]), int(w.slice[slice][m]), depth-1) {
+			return true
+		}
+		w.moves = w.moves[:len(w.moves)-1]
+	}
+	return false
+}
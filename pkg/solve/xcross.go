@@ -0,0 +1,248 @@
+package solve
+
+import (
+	"github.com/larssont/go-cubic/pkg/cube"
+	"slices"
+	"sync"
+)
+
+// XCross is a solution of a cross together with F2L pairs.
+type XCross struct {
+	Moves []cube.Move
+
+	// Slots names the F2L slots solved after the moves by their edge, e.g.
+	// "FR".
+	Slots []string
+}
+
+// f2lSlot is the corner and edge of an F2L pair, each tracked by one
+// sticker.
+type f2lSlot struct {
+	name         string
+	corner, edge int
+
+	// turn moves every sticker to where a rotation puts it that brings the
+	// cross to D and the slot to the reference slot of the pair tables.
+	turn State
+}
+
+// f2lSlots returns the four F2L slots of the cross on face f.
+func f2lSlots(f cube.Face) []f2lSlot {
+	var slots []f2lSlot
+	for _, index := range []int{0, 2, 6, 8} {
+		corner := int(f)*9 + index
+		// The edge of the slot is on the other two faces of the corner.
+		edge, _ := stickersOf(PieceName(corner)[1:])
+		for i, stickers := range edgeStickers {
+			if SamePiece(stickers[0], edge[0]) {
+				slots = append(slots, f2lSlot{name: edgeNames[i], corner: corner, edge: stickers[0]})
+			}
+		}
+	}
+
+	center := int(f)*9 + 4
+	for i, slot := range slots {
+		for _, r := range Rotations {
+			turn := r.Inverse()
+			if int(turn[center]) == downCenter && int(turn[slot.corner]) == pairCorner {
+				slots[i].turn = turn
+			}
+		}
+	}
+	return slots
+}
+
+// solved reports whether the pair of the slot is solved in the state.
+func (slot f2lSlot) solved(s State) bool {
+	return int(s[slot.corner]) == slot.corner && int(s[slot.edge]) == slot.edge
+}
+
+// The pair tables hold the D cross with the corner and the edge of the DFR
+// slot.
+var (
+	downCenter = int(cube.FaceDown)*9 + 4
+	pairCorner = cornerStickers[4][0]
+	pairEdge   = edgeStickers[8][0]
+)
+
+// cornerSlots numbers the 24 corner stickers, and is -1 for the other
+// stickers.
+var cornerSlots = func() [NumStickers]int {
+	var slots [NumStickers]int
+	for i := range slots {
+		slots[i] = -1
+	}
+	n := 0
+	for _, stickers := range cornerStickers {
+		for _, s := range stickers {
+			slots[s] = n
+			n++
+		}
+	}
+	return slots
+}()
+
+// cornerMoves holds the slot every corner sticker moves to with every face
+// turn.
+var cornerMoves = func() [][24]int {
+	out := make([][24]int, len(FaceTurns))
+	for i, g := range FaceTurns {
+		forward := g.State.Inverse()
+		for s, slot := range cornerSlots {
+			if slot >= 0 {
+				out[i][slot] = cornerSlots[forward[s]]
+			}
+		}
+	}
+	return out
+}()
+
+// cornerPairTable and edgePairTable hold the distance of the D cross
+// together with the corner or the edge of the DFR slot from solved, indexed
+// by the crossIndex of the cross times 24 plus the slot of the piece.
+var (
+	cornerPairTable = sync.OnceValue(func() []int8 {
+		return loadedTable("xcross-corner", func() []int8 {
+			cross := crossStickers(cube.FaceDown)
+			return stickerTable(append(cross[:], pairCorner))
+		})
+	})
+	edgePairTable = sync.OnceValue(func() []int8 {
+		return loadedTable("xcross-edge", func() []int8 {
+			cross := crossStickers(cube.FaceDown)
+			return stickerTable(append(cross[:], pairEdge))
+		})
+	})
+)
+
+// slotOf returns the number of a corner or edge sticker among the stickers
+// of its kind, and the moves of those slots.
+func slotOf(sticker int) (int, [][24]int) {
+	if cornerSlots[sticker] >= 0 {
+		return cornerSlots[sticker], cornerMoves
+	}
+	return edgeSlots[sticker], edgeMoves
+}
+
+// stickerTable returns the distance from solved of every placement of up to
+// five corner and edge stickers, indexed by their slots as digits in base 24,
+// the first sticker highest.
+func stickerTable(stickers []int) []int8 {
+	n := len(stickers)
+	size := 1
+	for range n {
+		size *= 24
+	}
+	table := make([]int8, size)
+	for i := range table {
+		table[i] = unvisited
+	}
+
+	moves := make([][][24]int, n)
+	first := 0
+	for i, s := range stickers {
+		var slot int
+		slot, moves[i] = slotOf(s)
+		first = first*24 + slot
+	}
+	table[first] = 0
+
+	level := []int32{int32(first)}
+	slots := make([]int, n)
+	for depth := int8(1); len(level) > 0; depth++ {
+		var next []int32
+		for _, i := range level {
+			for k, rest := n-1, int(i); k >= 0; k, rest = k-1, rest/24 {
+				slots[k] = rest % 24
+			}
+			for m := range FaceTurns {
+				j := 0
+				for k, slot := range slots {
+					j = j*24 + moves[k][m][slot]
+				}
+				if table[j] == unvisited {
+					table[j] = depth
+					next = append(next, int32(j))
+				}
+			}
+		}
+		level = next
+	}
+	return table
+}
+
+// pairLength returns the length of the shortest solution of the cross
+// together with the pair of the slot. Where holds the position of every
+// sticker, as the inverse of a state.
+func pairLength(where State, slot f2lSlot) int {
+	// Rotating the cube maps the cross and the slot to the ones of the
+	// tables, which is where the turned stickers of the state are.
+	back := slot.turn.Inverse()
+	at := func(s int) int {
+		return int(slot.turn[where[back[s]]])
+	}
+
+	var cross [4]int
+	for i, s := range crossStickers(cube.FaceDown) {
+		cross[i] = edgeSlots[at(s)]
+	}
+	i := crossIndex(cross) * 24
+	corner := cornerPairTable()[i+cornerSlots[at(pairCorner)]]
+	edge := edgePairTable()[i+edgeSlots[at(pairEdge)]]
+	return int(max(corner, edge))
+}
+
+// SolveXCross returns the shortest solutions of the cross on face f of the
+// state together with at least pairs solved F2L pairs, shortest first: an
+// X-cross for one pair and a double X-cross for two. MaxMoves defaults to 10.
+// Its tables take a few seconds to build on first use.
+func SolveXCross(s State, f cube.Face, pairs int, opts CrossOptions) []XCross {
+	if opts.MaxMoves <= 0 {
+		opts.MaxMoves = 10
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = 1
+	}
+	slots := f2lSlots(f)
+	pairs = min(max(pairs, 0), len(slots))
+
+	solved := func(s State) []string {
+		var names []string
+		for _, slot := range slots {
+			if slot.solved(s) {
+				names = append(names, slot.name)
+			}
+		}
+		return names
+	}
+
+	var solutions []XCross
+	search := Search{
+		Generators: FaceTurns,
+		Goal: func(s State) bool {
+			return CrossLength(s, f) == 0 && len(solved(s)) >= pairs
+		},
+		// Every set of pairs takes at least as long as its longest pair, so
+		// the pairs-th shortest pair bounds the moves left.
+		Bound: func(s State) int {
+			if pairs == 0 {
+				return CrossLength(s, f)
+			}
+			where := s.Inverse()
+			lengths := make([]int, len(slots))
+			for i, slot := range slots {
+				lengths[i] = pairLength(where, slot)
+			}
+			slices.Sort(lengths)
+			return max(CrossLength(s, f), lengths[pairs-1])
+		},
+		Found: func(moves []cube.Move) bool {
+			effect, _ := StateOf(moves...)
+			end := s.Then(effect)
+			solutions = append(solutions, XCross{Moves: moves, Slots: solved(end)})
+			return len(solutions) < opts.Limit
+		},
+	}
+	opts.run(&search, s)
+	return solutions
+}
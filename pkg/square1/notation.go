@@ -0,0 +1,76 @@
+package square1
+
+import (
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/alg"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrTokenExtraction = errors.New("token extraction")
+)
+
+var reTurn = regexp.MustCompile(`^\(\s*(-?\d+)\s*,\s*(-?\d+)\s*\)`)
+
+// Move turns the top and bottom layers by a number of 30 degree steps, each
+// clockwise as seen from that layer's face, and then optionally applies the
+// slice.
+type Move struct {
+	Top    int
+	Bottom int
+	Slash  bool
+}
+
+func (m *Move) String() string {
+	return fmt.Sprintf("Move: Top=%d, Bottom=%d, Slash=%t", m.Top, m.Bottom, m.Slash)
+}
+
+// ParseNotation parses WCA Square-1 notation such as "(1,0)/ (-3,3)/ /",
+// accepting the Unicode lookalikes that alg.Clean replaces, such as en dashes
+// for minus signs.
+func ParseNotation(input string) ([]Move, error) {
+	var moves []Move
+
+	input = strings.TrimSpace(alg.Clean(input))
+	for len(input) > 0 {
+		var move Move
+
+		m := reTurn.FindStringSubmatch(input)
+		if m != nil {
+			move.Top, _ = strconv.Atoi(m[1])
+			move.Bottom, _ = strconv.Atoi(m[2])
+			input = strings.TrimSpace(input[len(m[0]):])
+		}
+
+		if strings.HasPrefix(input, "/") {
+			move.Slash = true
+			input = strings.TrimSpace(input[1:])
+		} else if m == nil {
+			return nil, ErrTokenExtraction
+		}
+
+		moves = append(moves, move)
+	}
+
+	return moves, nil
+}
+
+// FormatMoves writes moves in WCA Square-1 notation, the inverse of
+// ParseNotation, leaving out the turn of a slice that turns no layer.
+func FormatMoves(moves []Move) string {
+	var parts []string
+	for _, m := range moves {
+		var part string
+		if m.Top != 0 || m.Bottom != 0 || !m.Slash {
+			part = fmt.Sprintf("(%d,%d)", m.Top, m.Bottom)
+		}
+		if m.Slash {
+			part += "/"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,450 @@
+package square1
+
+import (
+	"errors"
+	"math/bits"
+	"sync"
+)
+
+var ErrNoSolution = errors.New("no solution within the move limit")
+
+// Phase is a labeled part of a solution: "Shape" restores the cube shape
+// with even parity, and "Permutation" solves the pieces in cube shape.
+type Phase struct {
+	Name  string
+	Moves []Move
+}
+
+// Solve returns a solution of the puzzle in two phases. The first brings it
+// to cube shape, choosing among the shortest ways one that leaves no parity
+// for the second, which then solves it without leaving cube shape.
+func (s *Square1) Solve() ([]Phase, error) {
+	shape, err := s.solveShape()
+	if err != nil {
+		return nil, err
+	}
+	c := *s
+	if err := c.ExecuteMoves(shape...); err != nil {
+		return nil, err
+	}
+	perm, err := c.solvePermutation()
+	if err != nil {
+		return nil, err
+	}
+	return []Phase{{"Shape", shape}, {"Permutation", perm}}, nil
+}
+
+// squareStarts holds the slots where pieces start in a square layer whose
+// corner is at the front end of the slice. The phases meet at both layers
+// turned this way, which is the solved puzzle with the bottom turned back
+// by one slot.
+const squareStarts = 0b1011_0110_1101
+
+// starts returns the slots where a piece of the layer starts, as bits.
+func (l layer) starts() uint16 {
+	var mask uint16
+	for i := range slots {
+		if !l.isSplit(i) {
+			mask |= 1 << i
+		}
+	}
+	return mask
+}
+
+// rotate returns the starts of a layer turned by n slots.
+func rotate(mask uint16, n int) uint16 {
+	n = mod(n, slots)
+	return (mask<<n | mask>>(slots-n)) & (1<<slots - 1)
+}
+
+// parity returns the parity of the pieces read slot by slot, from the top
+// layer to the bottom one.
+func (s *Square1) parity() int {
+	var order []int
+	for _, l := range []layer{s.top, s.bottom} {
+		for i, p := range l {
+			if !l.isSplit(i) {
+				order = append(order, p)
+			}
+		}
+	}
+	n := 0
+	for i := range order {
+		for j := i + 1; j < len(order); j++ {
+			if order[i] > order[j] {
+				n++
+			}
+		}
+	}
+	return n % 2
+}
+
+// shape is a state of the first phase: the starts of both layers, whether
+// the equator is flipped and the parity of the pieces.
+type shape struct {
+	top, bottom uint16
+	flipped     bool
+	parity      int
+}
+
+// turn returns the shape after turning the layers, which reorders the pieces
+// that pass the start of a layer.
+func (sh shape) turn(top, bottom int) shape {
+	for _, t := range []struct {
+		mask *uint16
+		n    int
+	}{{&sh.top, top}, {&sh.bottom, bottom}} {
+		n := mod(t.n, slots)
+		k := bits.OnesCount16(*t.mask)
+		m := bits.OnesCount16(*t.mask >> (slots - n))
+		sh.parity ^= m * (k - 1) % 2
+		*t.mask = rotate(*t.mask, n)
+	}
+	return sh
+}
+
+// slash returns the shape after the slice, which swaps the pieces of the
+// right half of the top, read after its left half, with those of the right
+// half of the bottom, read before its left half. It returns false if a piece
+// blocks the slice.
+func (sh shape) slash() (shape, bool) {
+	const half = 1<<(slots/2) - 1
+	const ends = 1 | 1<<(slots/2)
+	if sh.top&ends != ends || sh.bottom&ends != ends {
+		return sh, false
+	}
+	topRight, bottomRight := sh.top>>(slots/2), sh.bottom&half
+	sh.parity ^= bits.OnesCount16(topRight) * bits.OnesCount16(bottomRight) % 2
+	sh.top = sh.top&half | bottomRight<<(slots/2)
+	sh.bottom = topRight | sh.bottom&^half
+	sh.flipped = !sh.flipped
+	return sh, true
+}
+
+// solveShape returns the fewest slices that bring the puzzle to both layers
+// in the square of squareStarts with the parity of the goal of the second
+// phase, found by a breadth first search over the shapes.
+func (s *Square1) solveShape() ([]Move, error) {
+	goal := meeting()
+	start := shape{s.top.starts(), s.bottom.starts(), s.flipped, s.parity()}
+
+	type node struct {
+		shape shape
+		moves []Move
+	}
+	seen := map[shape]bool{start: true}
+	level := []node{{start, nil}}
+	for len(level) > 0 {
+		var next []node
+		for _, n := range level {
+			for top := range slots {
+				for bottom := range slots {
+					turned := n.shape.turn(top, bottom)
+					if turned.top == squareStarts && turned.bottom == squareStarts && turned.parity == goal.parity() {
+						if top == 0 && bottom == 0 {
+							return n.moves, nil
+						}
+						return append(n.moves, notated(top, bottom, false)), nil
+					}
+					sliced, ok := turned.slash()
+					if !ok || seen[sliced] {
+						continue
+					}
+					seen[sliced] = true
+					next = append(next, node{sliced, append(n.moves[:len(n.moves):len(n.moves)], notated(top, bottom, true))})
+				}
+			}
+		}
+		level = next
+	}
+	return nil, ErrNoSolution
+}
+
+// notated returns the move turning the layers by the slots, written from -5
+// to 6 as in WCA notation.
+func notated(top, bottom int, slash bool) Move {
+	short := func(n int) int {
+		if n = mod(n, slots); n > slots/2 {
+			return n - slots
+		}
+		return n
+	}
+	return Move{Top: short(top), Bottom: short(bottom), Slash: slash}
+}
+
+// meeting returns the puzzle where the phases meet.
+func meeting() *Square1 {
+	s := New()
+	s.ExecuteMove(Move{Bottom: -1})
+	return s
+}
+
+// In cube shape with both layers turned as squareStarts, the pieces of each
+// layer are read from slot 0, a corner and an edge in turn. Positions 0-7
+// are those of the top layer and 8-15 those of the bottom one, with the
+// corners at the even positions.
+const (
+	positions = 2 * 8
+	numPerm8  = 40320
+)
+
+// cubeMoves are the moves of the second phase, which keep the layers square.
+// Turning both layers by a multiple of three slots keeps every corner next to
+// the edge after it, so half the moves turn them one slot more before the
+// slice and back after it, which pairs the corners with the edges before
+// them instead.
+var cubeMoves = func() [][]Move {
+	var moves [][]Move
+	for top := range 4 {
+		for bottom := range 4 {
+			moves = append(moves, []Move{notated(3*top, 3*bottom, true)})
+		}
+	}
+	for top := range 4 {
+		for bottom := range 4 {
+			moves = append(moves, []Move{notated(3*top+1, 3*bottom+1, true), {Top: -1, Bottom: -1}})
+		}
+	}
+	return moves
+}()
+
+// merge joins every turn without a slice to the move after it.
+func merge(moves []Move) []Move {
+	var out []Move
+	top, bottom := 0, 0
+	for _, m := range moves {
+		top, bottom = top+m.Top, bottom+m.Bottom
+		if m.Slash {
+			out = append(out, notated(top, bottom, true))
+			top, bottom = 0, 0
+		}
+	}
+	if mod(top, slots) != 0 || mod(bottom, slots) != 0 {
+		out = append(out, notated(top, bottom, false))
+	}
+	return out
+}
+
+// read returns the piece at every position of a puzzle in the square of
+// squareStarts.
+func (s *Square1) read() [positions]int {
+	var out [positions]int
+	i := 0
+	for _, l := range []layer{s.top, s.bottom} {
+		for j, p := range l {
+			if !l.isSplit(j) {
+				out[i] = p
+				i++
+			}
+		}
+	}
+	return out
+}
+
+// permTables holds the coordinates that the moves of the second phase take
+// the corners and the edges to, and the distance of every state from the
+// meeting puzzle by the corners with the layers of the edges, and by the
+// edges with the layers of the corners.
+type permTables struct {
+	corners, edges [numPerm8][numCubeMoves]uint16
+	layersOf       [numPerm8]uint8 // Index of the positions of the top pieces
+	cornerDist     []int8
+	edgeDist       []int8
+	goal           [positions]int
+}
+
+const (
+	numCubeMoves = 32
+	numLayers    = 70 // Ways to choose the four positions of the top pieces
+)
+
+var phase2 = sync.OnceValue(func() *permTables {
+	t := &permTables{goal: meeting().read()}
+
+	// The moves are found as permutations of the positions by turning a
+	// puzzle whose pieces name their positions.
+	var effects [][positions]int
+	for _, m := range cubeMoves {
+		s := meeting()
+		s.top, s.bottom = labeled(s.top, 0), labeled(s.bottom, 8)
+		s.ExecuteMoves(m...)
+		effects = append(effects, s.read())
+	}
+	layers := map[int]uint8{}
+	for rank := range numPerm8 {
+		p := unrank(rank)
+		mask := 0
+		for pos, piece := range p {
+			if piece < 4 {
+				mask |= 1 << pos
+			}
+		}
+		if _, ok := layers[mask]; !ok {
+			layers[mask] = uint8(len(layers))
+		}
+		t.layersOf[rank] = layers[mask]
+
+		for m, e := range effects {
+			var corners, edges [8]int
+			for pos := range 8 {
+				corners[pos] = p[positionOf[e[2*pos]]/2]
+				edges[pos] = p[positionOf[e[2*pos+1]]/2]
+			}
+			t.corners[rank][m] = uint16(permRank(corners))
+			t.edges[rank][m] = uint16(permRank(edges))
+		}
+	}
+	t.cornerDist = t.distances(&t.corners, &t.edges)
+	t.edgeDist = t.distances(&t.edges, &t.corners)
+	return t
+})
+
+// distances returns the distance from the meeting puzzle of every state of
+// one kind of pieces, the layers of the other kind and the equator, indexed
+// by (perm*numLayers+layers)*2+flipped.
+func (t *permTables) distances(perm, other *[numPerm8][numCubeMoves]uint16) []int8 {
+	// A permutation of the other kind stands for each of its layers.
+	var rep [numLayers]int
+	for rank := numPerm8 - 1; rank >= 0; rank-- {
+		rep[t.layersOf[rank]] = rank
+	}
+
+	dist := make([]int8, numPerm8*numLayers*2)
+	for i := range dist {
+		dist[i] = -1
+	}
+	start := int(t.layersOf[0]) * 2
+	dist[start] = 0
+	queue := []int32{int32(start)}
+	for len(queue) > 0 {
+		i := int(queue[0])
+		queue = queue[1:]
+		p, l, flip := i/(numLayers*2), i/2%numLayers, i%2
+		for m := range numCubeMoves {
+			j := (int(perm[p][m])*numLayers+int(t.layersOf[other[rep[l]][m]]))*2 + (flip ^ 1)
+			if dist[j] < 0 {
+				dist[j] = dist[i] + 1
+				queue = append(queue, int32(j))
+			}
+		}
+	}
+	return dist
+}
+
+// positionOf maps the pieces of labeled back to their positions.
+var positionOf = func() map[int]int {
+	m := map[int]int{}
+	for pos, p := range labelPieces {
+		m[p] = pos
+	}
+	return m
+}()
+
+// labelPieces are pieces of the kind of every position.
+var labelPieces = [positions]int{0, 1, 2, 3, 4, 5, 6, 7, 9, 8, 11, 10, 13, 12, 15, 14}
+
+// labeled returns a square layer in the shape of the given one with the
+// pieces of labelPieces from the first position on.
+func labeled(l layer, first int) layer {
+	var out layer
+	pos := first - 1
+	for i := range l {
+		if !l.isSplit(i) {
+			pos++
+		}
+		out[i] = labelPieces[pos]
+	}
+	return out
+}
+
+// maxPermutation bounds the moves of the second phase.
+const maxPermutation = 30
+
+// solvePermutation returns the fewest moves of the second phase that solve a
+// puzzle in the square of squareStarts, found by an iterative deepening A*
+// search bounded by the distances of the corners and of the edges.
+func (s *Square1) solvePermutation() ([]Move, error) {
+	t := phase2()
+	at := s.read()
+	home := map[int]int{}
+	for pos, p := range t.goal {
+		home[p] = pos / 2
+	}
+	var corners, edges [8]int
+	for pos := range 8 {
+		corners[pos] = home[at[2*pos]]
+		edges[pos] = home[at[2*pos+1]]
+	}
+	flip := 0
+	if s.flipped {
+		flip = 1
+	}
+
+	var path []int
+	var search func(c, e, flip, depth int) bool
+	search = func(c, e, flip, depth int) bool {
+		byCorners := t.cornerDist[(c*numLayers+int(t.layersOf[e]))*2+flip]
+		byEdges := t.edgeDist[(e*numLayers+int(t.layersOf[c]))*2+flip]
+		if byCorners < 0 || byEdges < 0 || max(byCorners, byEdges) > int8(depth) {
+			return false
+		}
+		if depth == 0 {
+			return true
+		}
+		for m := range cubeMoves {
+			// Two slices in a row undo each other.
+			if m == 0 && len(path) > 0 && path[len(path)-1] == 0 {
+				continue
+			}
+			path = append(path, m)
+			if search(int(t.corners[c][m]), int(t.edges[e][m]), flip^1, depth-1) {
+				return true
+			}
+			path = path[:len(path)-1]
+		}
+		return false
+	}
+
+	c, e := permRank(corners), permRank(edges)
+	for depth := 0; depth <= maxPermutation; depth++ {
+		if search(c, e, flip, depth) {
+			var moves []Move
+			for _, m := range path {
+				moves = append(moves, cubeMoves[m]...)
+			}
+			return merge(append(moves, Move{Bottom: 1})), nil
+		}
+	}
+	return nil, ErrNoSolution
+}
+
+// permRank returns the rank of a permutation of 0 to 7.
+func permRank(p [8]int) int {
+	n := 0
+	for i := range p {
+		smaller := 0
+		for _, v := range p[i+1:] {
+			if v < p[i] {
+				smaller++
+			}
+		}
+		n = n*(8-i) + smaller
+	}
+	return n
+}
+
+// unrank returns the permutation of 0 to 7 of a rank.
+func unrank(n int) [8]int {
+	var digits [8]int
+	for i := 7; i >= 0; i-- {
+		digits[i] = n % (8 - i)
+		n /= 8 - i
+	}
+	left := []int{0, 1, 2, 3, 4, 5, 6, 7}
+	var p [8]int
+	for i, d := range digits {
+		p[i] = left[d]
+		left = append(left[:d], left[d+1:]...)
+	}
+	return p
+}
@@ -0,0 +1,225 @@
+// Package square1 models the Square-1 puzzle, including the shape-shifting
+// of its layers and the state of the equator.
+package square1
+
+import (
+	"errors"
+)
+
+var (
+	ErrSlashBlocked = errors.New("slash blocked by a piece crossing the slice")
+)
+
+const slots = 12
+
+type piece struct {
+	face  rune   // Color facing up or down
+	sides []rune // Side colors in clockwise order, one per 30 degree slot
+}
+
+func (p piece) isCorner() bool {
+	return len(p.sides) == 2
+}
+
+// Pieces 0-7 start in the top layer and 8-15 in the bottom layer.
+var pieces = []piece{
+	{'w', []rune{'g', 'o'}},
+	{'w', []rune{'o'}},
+	{'w', []rune{'o', 'b'}},
+	{'w', []rune{'b'}},
+	{'w', []rune{'b', 'r'}},
+	{'w', []rune{'r'}},
+	{'w', []rune{'r', 'g'}},
+	{'w', []rune{'g'}},
+	{'y', []rune{'g'}},
+	{'y', []rune{'g', 'r'}},
+	{'y', []rune{'r'}},
+	{'y', []rune{'r', 'b'}},
+	{'y', []rune{'b'}},
+	{'y', []rune{'b', 'o'}},
+	{'y', []rune{'o'}},
+	{'y', []rune{'o', 'g'}},
+}
+
+// layer holds the piece in each 30 degree slot, counted clockwise as seen
+// from the layer's own face starting at the front end of the slice. Corners
+// occupy two consecutive slots.
+type layer [slots]int
+
+func newLayer(first int) layer {
+	var l layer
+	i := 0
+	for p := first; p < first+8; p++ {
+		for range pieces[p].sides {
+			l[i] = p
+			i++
+		}
+	}
+	return l
+}
+
+func (l layer) turn(n int) layer {
+	var out layer
+	for i, p := range l {
+		out[mod(i+n, slots)] = p
+	}
+	return out
+}
+
+// isSplit reports whether a piece crosses the boundary before slot i.
+func (l layer) isSplit(i int) bool {
+	return l[mod(i-1, slots)] == l[i]
+}
+
+// isSquare reports whether the layer has the shape of a cube face, with
+// corners and edges alternating.
+func (l layer) isSquare() bool {
+	var shape []bool
+	for i, p := range l {
+		if !l.isSplit(i) {
+			shape = append(shape, pieces[p].isCorner())
+		}
+	}
+
+	if len(shape) != 8 {
+		return false
+	}
+	for i := range shape {
+		if shape[i] == shape[(i+1)%len(shape)] {
+			return false
+		}
+	}
+	return true
+}
+
+// Square1 holds the state of a Square-1 puzzle. The left half of the equator
+// is fixed, and the slice turns the right half of the puzzle.
+type Square1 struct {
+	top, bottom layer
+	flipped     bool // Whether the right half of the equator is turned over
+}
+
+func New() *Square1 {
+	return &Square1{
+		top:    newLayer(0),
+		bottom: newLayer(8),
+	}
+}
+
+// Layers returns the piece shape of the top and bottom layers, one rune per
+// slot: 'c' for corners and 'e' for edges.
+func (s *Square1) Layers() (top, bottom string) {
+	shape := func(l layer) string {
+		out := make([]rune, slots)
+		for i, p := range l {
+			out[i] = 'e'
+			if pieces[p].isCorner() {
+				out[i] = 'c'
+			}
+		}
+		return string(out)
+	}
+	return shape(s.top), shape(s.bottom)
+}
+
+// IsEquatorFlipped reports whether the right half of the equator is turned
+// over relative to the left half.
+func (s *Square1) IsEquatorFlipped() bool {
+	return s.flipped
+}
+
+// IsCubeShape reports whether both layers and the equator are square, ignoring
+// colors and the alignment of the layers.
+func (s *Square1) IsCubeShape() bool {
+	return !s.flipped && s.top.isSquare() && s.bottom.isSquare()
+}
+
+func (s *Square1) IsSolved() bool {
+	return !s.flipped && s.top == newLayer(0) && s.bottom == newLayer(8)
+}
+
+// ExecuteMove turns the top and bottom layers and then applies the slice if
+// the move has one. The state is unchanged if the slice is blocked.
+func (s *Square1) ExecuteMove(move Move) error {
+	top := s.top.turn(move.Top)
+	bottom := s.bottom.turn(move.Bottom)
+
+	if move.Slash {
+		if top.isSplit(0) || top.isSplit(slots/2) || bottom.isSplit(0) || bottom.isSplit(slots/2) {
+			return ErrSlashBlocked
+		}
+
+		// The right half of the top is the second half of its slots, while
+		// the right half of the bottom is the first half of its slots.
+		for i := range slots / 2 {
+			top[i+slots/2], bottom[i] = bottom[i], top[i+slots/2]
+		}
+		s.flipped = !s.flipped
+	}
+
+	s.top, s.bottom = top, bottom
+	return nil
+}
+
+// Apply executes a single move, see ExecuteMove.
+func (s *Square1) Apply(move Move) error {
+	return s.ExecuteMove(move)
+}
+
+// State returns the pieces of the top and bottom layers slot by slot as hex
+// digits, followed by '|' if the equator is square or '/' if it is flipped.
+func (s *Square1) State() string {
+	const digits = "0123456789abcdef"
+
+	state := make([]byte, 0, 2*slots+1)
+	for _, p := range s.top {
+		state = append(state, digits[p])
+	}
+	for _, p := range s.bottom {
+		state = append(state, digits[p])
+	}
+	if s.flipped {
+		return string(append(state, '/'))
+	}
+	return string(append(state, '|'))
+}
+
+func (s *Square1) ExecuteMoves(moves ...Move) error {
+	for _, m := range moves {
+		if err := s.ExecuteMove(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// slotColors returns the face and side color shown in every slot of the layer.
+func slotColors(l layer) (faces, sides []rune) {
+	faces = make([]rune, slots)
+	sides = make([]rune, slots)
+	for i, p := range l {
+		half := 0
+		if l.isSplit(i) {
+			half = 1
+		}
+		faces[i] = pieces[p].face
+		sides[i] = pieces[p].sides[half]
+	}
+	return
+}
+
+func mod(a, n int) int {
+	return ((a % n) + n) % n
+}
+
+// Top returns the face colors of the top layer, one per slot.
+func (s *Square1) Top() []rune {
+	faces, _ := slotColors(s.top)
+	return faces
+}
+
+// Bottom returns the face colors of the bottom layer, one per slot.
+func (s *Square1) Bottom() []rune {
+	faces, _ := slotColors(s.bottom)
+	return faces
+}
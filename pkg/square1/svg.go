@@ -0,0 +1,106 @@
+package square1
+
+import (
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/internal/svg"
+	"io"
+	"math"
+)
+
+const (
+	svgHalfSide = 60.0 // Half the side of a layer in cube shape
+	svgMargin   = 10.0
+	svgInner    = 0.7 // Part of each wedge showing the up or down color
+)
+
+var (
+	svgEdgeRadius   = svgHalfSide / math.Cos(math.Pi/12)
+	svgCornerRadius = svgHalfSide * math.Sqrt2
+	svgLayerSize    = 2 * svgCornerRadius
+)
+
+// RenderSVG draws the top layer seen from above and the bottom layer seen from
+// below, both with the front towards the bottom of the slice, followed by the
+// equator seen from the front.
+func (s *Square1) RenderSVG(w io.Writer) error {
+	return s.RenderSVGWith(w, cube.Western)
+}
+
+// RenderSVGWith draws the layers as RenderSVG does, in the fills and patterns
+// of the scheme.
+func (s *Square1) RenderSVGWith(w io.Writer, scheme cube.Scheme) error {
+	style := svg.Style{Fills: scheme.Fills, Patterns: scheme.Patterns}
+	width := 2*svgLayerSize + 3*svgMargin
+	out := svg.NewWriter(w, width, svgLayerSize+3*svgMargin+svgHalfSide/2)
+
+	// Slot angles start at the front end of the slice, which is 15 degrees
+	// clockwise of the front center.
+	top := svg.Point{X: svgMargin + svgCornerRadius, Y: svgMargin + svgCornerRadius}
+	drawLayer(out, style, s.top, top, func(deg float64) svg.Point {
+		rad := (deg + 15) * math.Pi / 180
+		return svg.Point{X: -math.Sin(rad), Y: math.Cos(rad)}
+	})
+
+	bottom := svg.Point{X: 2*svgMargin + 3*svgCornerRadius, Y: top.Y}
+	drawLayer(out, style, s.bottom, bottom, func(deg float64) svg.Point {
+		rad := (deg - 15) * math.Pi / 180
+		return svg.Point{X: math.Sin(rad), Y: -math.Cos(rad)}
+	})
+
+	drawEquator(out, style, s.flipped, svg.Point{X: width / 2, Y: 2*svgMargin + svgLayerSize})
+
+	return out.Close()
+}
+
+func drawLayer(out *svg.Writer, style svg.Style, l layer, center svg.Point, direction func(deg float64) svg.Point) {
+	at := func(deg, radius float64) svg.Point {
+		d := direction(deg)
+		return svg.Point{X: center.X + d.X*radius, Y: center.Y + d.Y*radius}
+	}
+	inner := func(p svg.Point) svg.Point {
+		return svg.Point{X: center.X + (p.X-center.X)*svgInner, Y: center.Y + (p.Y-center.Y)*svgInner}
+	}
+
+	faces, sides := slotColors(l)
+	for i, p := range l {
+		start := float64(i * 30)
+
+		// Corners are drawn as two halves split along their diagonal.
+		var a, b svg.Point
+		switch {
+		case !pieces[p].isCorner():
+			a, b = at(start, svgEdgeRadius), at(start+30, svgEdgeRadius)
+		case l.isSplit(i):
+			a, b = at(start, svgCornerRadius), at(start+30, svgEdgeRadius)
+		default:
+			a, b = at(start, svgEdgeRadius), at(start+30, svgCornerRadius)
+		}
+
+		out.Sticker(style, sides[i], center, a, b)
+		out.Sticker(style, faces[i], center, inner(a), inner(b))
+	}
+}
+
+// drawEquator draws the fixed left half and the turning right half of the
+// equator. The slice does not pass through the front center, so the right
+// half shows its narrower back side when it is flipped.
+func drawEquator(out *svg.Writer, style svg.Style, flipped bool, top svg.Point) {
+	offset := svgHalfSide * math.Tan(math.Pi/12)
+	height := svgHalfSide / 2
+
+	rect := func(fill rune, x0, x1 float64) {
+		out.Sticker(style, fill,
+			svg.Point{X: top.X + x0, Y: top.Y},
+			svg.Point{X: top.X + x1, Y: top.Y},
+			svg.Point{X: top.X + x1, Y: top.Y + height},
+			svg.Point{X: top.X + x0, Y: top.Y + height},
+		)
+	}
+
+	rect('g', -svgHalfSide, -offset)
+	if flipped {
+		rect('b', offset, svgHalfSide)
+	} else {
+		rect('g', -offset, svgHalfSide)
+	}
+}
@@ -0,0 +1,104 @@
+package store
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// Progress is how far the learning of a trainer case has come.
+type Progress string
+
+const (
+	Unlearned Progress = "unlearned" // Not drilled yet, and not due
+	Learning  Progress = "learning"
+	Learned   Progress = "learned" // Correct on the attempts of LearnedBox boxes in a row
+)
+
+const day = 24 * time.Hour
+
+// Intervals are the waits before a case is due again by its box, in a
+// Leitner system: a correct attempt moves a case up a box, and a wrong one
+// back to the first, to drill again the same day.
+var Intervals = []time.Duration{0, day, 2 * day, 4 * day, 8 * day, 16 * day, 32 * day, 64 * day}
+
+// LearnedBox is the box from which a case counts as learned.
+const LearnedBox = 4
+
+// progress returns the progress of a case, of records written before cases
+// had one too.
+func (f caseFile) progress() Progress {
+	switch {
+	case f.Progress != "":
+		return f.Progress
+	case f.Attempts > 0:
+		return Learning
+	}
+	return Unlearned
+}
+
+// schedule moves the case to its next box after an attempt at the time, and
+// sets when it is due and its progress.
+func (f *caseFile) schedule(correct bool, at time.Time) {
+	if correct {
+		f.Box = min(f.Box+1, len(Intervals)-1)
+	} else {
+		f.Box = 0
+	}
+	f.Due = at.Add(Intervals[f.Box])
+	f.Progress = Learning
+	if f.Box >= LearnedBox {
+		f.Progress = Learned
+	}
+}
+
+// SetProgress sets the progress of a case of a set, such as Learning to start
+// drilling it or Learned for a case known from before, and returns its
+// statistics. Cases set to Learning or Unlearned start over from the first
+// box, and Learned ones from LearnedBox.
+func (s *Store) SetProgress(set, name string, p Progress) (CaseStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cases, err := load[caseFile](s, casesFile)
+	if err != nil {
+		return CaseStats{}, err
+	}
+	i := 0
+	for i < len(cases) && (cases[i].Set != set || cases[i].Case != name) {
+		i++
+	}
+	if i == len(cases) {
+		cases = append(cases, caseFile{Set: set, Case: name})
+	}
+	c := &cases[i]
+	c.Progress, c.Box, c.Due = p, 0, time.Now()
+	if p == Learned {
+		c.Box = LearnedBox
+		c.Due = c.Due.Add(Intervals[LearnedBox])
+	}
+	return c.stats(), save(s, casesFile, cases)
+}
+
+// Drill returns the cases of the set, or of every set if set is empty, to
+// drill on the day of now: those being learned or learned and due by the end
+// of the day, the longest overdue first, then those in lower boxes.
+func (s *Store) Drill(set string, now time.Time) ([]CaseStats, error) {
+	cases, err := s.Cases(set)
+	if err != nil {
+		return nil, err
+	}
+	y, m, d := now.Date()
+	end := time.Date(y, m, d+1, 0, 0, 0, 0, now.Location())
+
+	var due []CaseStats
+	for _, c := range cases {
+		if c.Progress != Unlearned && c.Due.Before(end) {
+			due = append(due, c)
+		}
+	}
+	slices.SortStableFunc(due, func(a, b CaseStats) int {
+		return cmp.Or(a.Due.Compare(b.Due), cmp.Compare(a.Box, b.Box))
+	})
+	return due, nil
+}
@@ -0,0 +1,320 @@
+package store
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/replay"
+	"github.com/larssont/go-cubic/pkg/session"
+	"time"
+)
+
+// Session is a timed attempt at a set of cubes, such as a relay, whose cubes
+// are solves of the session.
+type Session struct {
+	ID      string
+	Event   string
+	Time    time.Duration
+	Started time.Time
+}
+
+// sessionFile is the stored format of a session, with the time in
+// milliseconds.
+type sessionFile struct {
+	ID      string    `json:"id"`
+	Event   string    `json:"event"`
+	Time    int64     `json:"time_ms"`
+	Started time.Time `json:"started"`
+}
+
+// Solve is a timed solve of a scrambled cube, alone or of a session.
+type Solve struct {
+	ID        string
+	Session   string // ID of the session, or empty
+	Dimension int
+	Scramble  []cube.Move
+	Result    replay.Result
+	Replay    string // ID of the replay of the moves in Store.Replays, or empty
+	Recorded  time.Time
+}
+
+// solveFile is the stored format of a solve, with the moves as notation and
+// the time in milliseconds as in replays.
+type solveFile struct {
+	ID        string         `json:"id"`
+	Session   string         `json:"session,omitempty"`
+	Dimension int            `json:"dimension"`
+	Scramble  string         `json:"scramble"`
+	Time      int64          `json:"time_ms"`
+	Solved    bool           `json:"solved"`
+	Penalty   replay.Penalty `json:"penalty,omitempty"`
+	Replay    string         `json:"replay,omitempty"`
+	Recorded  time.Time      `json:"recorded"`
+}
+
+func newSolveFile(s Solve) solveFile {
+	return solveFile{
+		ID:        s.ID,
+		Session:   s.Session,
+		Dimension: s.Dimension,
+		Scramble:  cube.FormatMoves(s.Scramble),
+		Time:      s.Result.Time.Milliseconds(),
+		Solved:    s.Result.Solved,
+		Penalty:   s.Result.Penalty,
+		Replay:    s.Replay,
+		Recorded:  s.Recorded,
+	}
+}
+
+func (f solveFile) solve() (Solve, error) {
+	group, err := cube.ParseNotation(f.Scramble)
+	if err != nil {
+		return Solve{}, err
+	}
+	scramble, err := group.Expand()
+	if err != nil {
+		return Solve{}, err
+	}
+	return Solve{
+		ID:        f.ID,
+		Session:   f.Session,
+		Dimension: f.Dimension,
+		Scramble:  scramble,
+		Result: replay.Result{
+			Time:    time.Duration(f.Time) * time.Millisecond,
+			Solved:  f.Solved,
+			Penalty: f.Penalty,
+		},
+		Replay:   f.Replay,
+		Recorded: f.Recorded,
+	}, nil
+}
+
+// solveOf returns the solve of a replay, of the session if not empty.
+func solveOf(r *replay.Replay, session string) Solve {
+	return Solve{
+		ID:        r.ID,
+		Session:   session,
+		Dimension: r.Dimension,
+		Scramble:  r.Scramble,
+		Result:    r.Result,
+		Replay:    r.ID,
+		Recorded:  r.Recorded,
+	}
+}
+
+// CaseStats holds how a trainer case went over its attempts, and when to
+// drill it next, see Drill.
+type CaseStats struct {
+	Set, Case string
+	Attempts  int
+	Correct   int
+	Total     time.Duration // Of the correct attempts
+	Best      time.Duration
+	Last      time.Time
+
+	Progress Progress
+	Box      int       // Of the Leitner system of Intervals
+	Due      time.Time // When to drill the case next, zero for at once
+}
+
+// Mean returns the mean time of the correct attempts, or zero for none.
+func (c CaseStats) Mean() time.Duration {
+	if c.Correct == 0 {
+		return 0
+	}
+	return c.Total / time.Duration(c.Correct)
+}
+
+// caseFile is the stored format of the statistics of a case, with times in
+// milliseconds.
+type caseFile struct {
+	Set      string    `json:"set"`
+	Case     string    `json:"case"`
+	Attempts int       `json:"attempts"`
+	Correct  int       `json:"correct"`
+	Total    int64     `json:"total_ms"`
+	Best     int64     `json:"best_ms"`
+	Last     time.Time `json:"last"`
+	Progress Progress  `json:"progress,omitempty"`
+	Box      int       `json:"box,omitempty"`
+	Due      time.Time `json:"due"`
+}
+
+func (f caseFile) stats() CaseStats {
+	return CaseStats{
+		Set:      f.Set,
+		Case:     f.Case,
+		Attempts: f.Attempts,
+		Correct:  f.Correct,
+		Total:    time.Duration(f.Total) * time.Millisecond,
+		Best:     time.Duration(f.Best) * time.Millisecond,
+		Last:     f.Last,
+		Progress: f.progress(),
+		Box:      f.Box,
+		Due:      f.Due,
+	}
+}
+
+// newID returns a random identifier of 16 hex digits, as of replays.
+func newID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// SaveSession records a finished session and its cubes as solves of it,
+// timed from the start of the session, and returns the session.
+func (s *Store) SaveSession(ses *session.Session, started time.Time) (Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record := Session{ID: newID(), Event: ses.Event, Time: ses.Time(), Started: started}
+	sessions, err := load[sessionFile](s, sessionsFile)
+	if err != nil {
+		return Session{}, err
+	}
+	solves, err := load[solveFile](s, solvesFile)
+	if err != nil {
+		return Session{}, err
+	}
+	for _, p := range ses.Puzzles {
+		result := replay.Result{Time: p.Solved, Solved: p.Solved > 0}
+		if !result.Solved {
+			result.Penalty = replay.DNF
+		}
+		solves = append(solves, newSolveFile(Solve{
+			ID:        newID(),
+			Session:   record.ID,
+			Dimension: p.Dimension,
+			Scramble:  p.Scramble,
+			Result:    result,
+			Recorded:  started,
+		}))
+	}
+	if err := save(s, solvesFile, solves); err != nil {
+		return Session{}, err
+	}
+	f := sessionFile{record.ID, record.Event, record.Time.Milliseconds(), record.Started}
+	return record, save(s, sessionsFile, append(sessions, f))
+}
+
+// Sessions returns the recorded sessions, oldest first.
+func (s *Store) Sessions() ([]Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files, err := load[sessionFile](s, sessionsFile)
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]Session, len(files))
+	for i, f := range files {
+		sessions[i] = Session{f.ID, f.Event, time.Duration(f.Time) * time.Millisecond, f.Started}
+	}
+	return sessions, nil
+}
+
+// SaveReplay records a replay in Replays and a solve of it, of the session
+// if not empty, and returns the solve.
+func (s *Store) SaveReplay(r *replay.Replay, session string) (Solve, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.Replays.Save(r); err != nil {
+		return Solve{}, err
+	}
+	solves, err := load[solveFile](s, solvesFile)
+	if err != nil {
+		return Solve{}, err
+	}
+	solve := solveOf(r, session)
+	return solve, save(s, solvesFile, append(solves, newSolveFile(solve)))
+}
+
+// Solves returns the recorded solves of the session, or every solve if
+// session is empty, oldest first.
+func (s *Store) Solves(session string) ([]Solve, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files, err := load[solveFile](s, solvesFile)
+	if err != nil {
+		return nil, err
+	}
+	var solves []Solve
+	for _, f := range files {
+		if session != "" && f.Session != session {
+			continue
+		}
+		solve, err := f.solve()
+		if err != nil {
+			return nil, err
+		}
+		solves = append(solves, solve)
+	}
+	return solves, nil
+}
+
+// Replay returns the replay of a solve, for analysis, or ErrNotFound if it
+// has none.
+func (s *Store) Replay(solve Solve) (*replay.Replay, error) {
+	if solve.Replay == "" {
+		return nil, ErrNotFound
+	}
+	return s.Replays.Load(solve.Replay)
+}
+
+// RecordCase adds an attempt at a trainer case of a set, with its time if
+// it was correct, to the statistics of the case, schedules its next drill
+// and returns them.
+func (s *Store) RecordCase(set, name string, t time.Duration, correct bool) (CaseStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cases, err := load[caseFile](s, casesFile)
+	if err != nil {
+		return CaseStats{}, err
+	}
+	i := 0
+	for i < len(cases) && (cases[i].Set != set || cases[i].Case != name) {
+		i++
+	}
+	if i == len(cases) {
+		cases = append(cases, caseFile{Set: set, Case: name})
+	}
+	c := &cases[i]
+	c.Attempts++
+	c.Last = time.Now()
+	c.schedule(correct, c.Last)
+	if correct {
+		ms := t.Milliseconds()
+		c.Correct++
+		c.Total += ms
+		if c.Correct == 1 || ms < c.Best {
+			c.Best = ms
+		}
+	}
+	return c.stats(), save(s, casesFile, cases)
+}
+
+// Cases returns the statistics of the trainer cases of the set, or of every
+// set if set is empty, in the order of their first attempt.
+func (s *Store) Cases(set string) ([]CaseStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files, err := load[caseFile](s, casesFile)
+	if err != nil {
+		return nil, err
+	}
+	var cases []CaseStats
+	for _, f := range files {
+		if set == "" || f.Set == set {
+			cases = append(cases, f.stats())
+		}
+	}
+	return cases, nil
+}
@@ -0,0 +1,69 @@
+package store
+
+import "time"
+
+// scrambleFile is the stored format of an issued scramble, by the key of its
+// state from scramble.StateKey.
+type scrambleFile struct {
+	Scope  string    `json:"scope"`
+	Key    string    `json:"key"`
+	Issued time.Time `json:"issued"`
+}
+
+// Issue records the key of the state of a scramble as issued in the scope,
+// such as a session or an event of a competition, and reports false if it
+// was issued there before. It makes the store a scramble.History, so that
+// scrambles stay unique across runs of a program.
+func (s *Store) Issue(scope, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scrambles, err := load[scrambleFile](s, scramblesFile)
+	if err != nil {
+		return false, err
+	}
+	for _, f := range scrambles {
+		if f.Scope == scope && f.Key == key {
+			return false, nil
+		}
+	}
+	scrambles = append(scrambles, scrambleFile{Scope: scope, Key: key, Issued: time.Now()})
+	return true, save(s, scramblesFile, scrambles)
+}
+
+// IssuedScrambles returns how many scrambles were issued in the scope.
+func (s *Store) IssuedScrambles(scope string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scrambles, err := load[scrambleFile](s, scramblesFile)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, f := range scrambles {
+		if f.Scope == scope {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// ForgetScrambles forgets the scrambles issued in the scope, such as at the
+// end of a competition, so that they may be issued again.
+func (s *Store) ForgetScrambles(scope string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scrambles, err := load[scrambleFile](s, scramblesFile)
+	if err != nil {
+		return err
+	}
+	kept := scrambles[:0]
+	for _, f := range scrambles {
+		if f.Scope != scope {
+			kept = append(kept, f)
+		}
+	}
+	return save(s, scramblesFile, kept)
+}
@@ -0,0 +1,185 @@
+// Package store keeps the history of practice in a directory of JSON files,
+// for the commands and programs built on this module to share rather than
+// each writing files of their own: timed sessions, the solves of them with
+// their replays, the statistics of trainer cases, and the scrambles issued,
+// to keep them unique.
+//
+// The directory holds a file per kind of record and the replays in a
+// replay.Store of their own. Open migrates directories written by older
+// versions of the package, and directories of a replay.Store.
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/larssont/go-cubic/pkg/replay"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrVersion  = errors.New("store written by a newer version")
+	ErrNotFound = errors.New("record not found")
+)
+
+// Version is the version of the layout of the directory written by this
+// package.
+const Version = 1
+
+// Files of the directory.
+const (
+	metaFile      = "store.json"
+	sessionsFile  = "sessions.json"
+	solvesFile    = "solves.json"
+	casesFile     = "cases.json"
+	scramblesFile = "scrambles.json"
+	replaysDir    = "replays"
+)
+
+// migrations upgrade the directory from the version of their index to the
+// next one.
+var migrations = []func(dir string) error{
+	// Version 0 is a directory without a store.json, which may be that of a
+	// replay.Store, whose replays move to replays with a solve each.
+	func(dir string) error {
+		if err := os.MkdirAll(filepath.Join(dir, replaysDir), 0o755); err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		solves := []solveFile{}
+		for _, e := range entries {
+			id, ok := strings.CutSuffix(e.Name(), ".json")
+			if !ok || e.IsDir() {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+			if err != nil {
+				return err
+			}
+			r := &replay.Replay{}
+			if json.Unmarshal(data, r) != nil || r.ID != id {
+				continue
+			}
+			if err := os.Rename(filepath.Join(dir, e.Name()), filepath.Join(dir, replaysDir, e.Name())); err != nil {
+				return err
+			}
+			solves = append(solves, newSolveFile(solveOf(r, "")))
+		}
+		return writeJSON(filepath.Join(dir, solvesFile), solves)
+	},
+}
+
+// meta is the content of store.json.
+type meta struct {
+	Version int `json:"version"`
+}
+
+// Store is a directory of the history of practice. Its methods are safe for
+// concurrent use, but not for several stores of the same directory.
+type Store struct {
+	// Replays holds the replays of the solves, by the ID of their replay.
+	Replays *replay.Store
+
+	dir string
+	mu  sync.Mutex
+}
+
+// DefaultDir returns the directory of the store of the commands:
+// $GO_CUBIC_DATA if set, and else the go-cubic directory of the user's
+// config directory, next to the config file.
+func DefaultDir() (string, error) {
+	if dir := os.Getenv("GO_CUBIC_DATA"); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "go-cubic", "data"), nil
+}
+
+// Open opens the store in the directory, which is created if needed, and
+// migrates it to Version first. It returns ErrVersion for a directory that
+// a newer version of the package wrote.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	var m meta
+	err := readJSON(filepath.Join(dir, metaFile), &m)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if m.Version > Version {
+		return nil, fmt.Errorf("%s: version %d: %w", dir, m.Version, ErrVersion)
+	}
+	for ; m.Version < Version; m.Version++ {
+		if err := migrations[m.Version](dir); err != nil {
+			return nil, fmt.Errorf("%s: migrating from version %d: %w", dir, m.Version, err)
+		}
+		if err := writeJSON(filepath.Join(dir, metaFile), meta{m.Version + 1}); err != nil {
+			return nil, err
+		}
+	}
+
+	replays, err := replay.NewStore(filepath.Join(dir, replaysDir))
+	if err != nil {
+		return nil, err
+	}
+	return &Store{Replays: replays, dir: dir}, nil
+}
+
+// Dir returns the directory of the store.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// readJSON reads the JSON file at the path into v.
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// writeJSON writes v as JSON to the path, replacing the file at once so that
+// readers never see half of it.
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// load reads the records of a file of the store, none if there is no file.
+func load[T any](s *Store, name string) ([]T, error) {
+	var records []T
+	err := readJSON(filepath.Join(s.dir, name), &records)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return records, err
+}
+
+// save writes the records of a file of the store.
+func save[T any](s *Store, name string, records []T) error {
+	if records == nil {
+		records = []T{}
+	}
+	return writeJSON(filepath.Join(s.dir, name), records)
+}
@@ -0,0 +1,117 @@
+package trainer
+
+import (
+	"github.com/larssont/go-cubic/pkg/cube"
+	"sync"
+)
+
+// caseAlgs maps the case names of a set to their algorithms, in the order of
+// the set.
+type caseAlgs [][2]string
+
+var pllAlgs = caseAlgs{
+	{"Aa", "x R' U R' D2 R U' R' D2 R2 x'"},
+	{"Ab", "x R2 D2 R U R' D2 R U' R x'"},
+	{"E", "x' R U' R' D R U R' D' R U R' D R U' R' D' x"},
+	{"F", "R' U' F' R U R' U' R' F R2 U' R' U' R U R' U R"},
+	{"Ga", "R2 U R' U R' U' R U' R2 U' D R' U R D'"},
+	{"Gb", "R' U' R U D' R2 U R' U R U' R U' R2 D"},
+	{"Gc", "R2 U' R U' R U R' U R2 U D' R U' R' D"},
+	{"Gd", "R U R' U' D R2 U' R U' R' U R' U R2 D'"},
+	{"H", "M2 U M2 U2 M2 U M2"},
+	{"Ja", "R' U L' U2 R U' R' U2 R L U'"},
+	{"Jb", "R U R' F' R U R' U' R' F R2 U' R' U'"},
+	{"Na", "R U R' U R U R' F' R U R' U' R' F R2 U' R' U2 R U' R'"},
+	{"Nb", "R' U R U' R' F' U' F R U R' F R' F' R U' R"},
+	{"Ra", "R U' R' U' R U R D R' U' R D' R' U2 R' U'"},
+	{"Rb", "R' U2 R U2 R' F R U R' U' R' F' R2"},
+	{"T", "R U R' U' R' F R2 U' R' U' R U R' F'"},
+	{"Ua", "M2 U M U2 M' U M2"},
+	{"Ub", "M2 U' M U2 M' U' M2"},
+	{"V", "R' U R' U' R D' R' D R' U D' R2 U' R2 D R2"},
+	{"Y", "F R U' R' U' R U R' F' R U R' U' R' F R F'"},
+	{"Z", "M' U M2 U M2 U M' U2 M2"},
+}
+
+var ollAlgs = caseAlgs{
+	{"OLL 1", "R U2 R2 F R F' U2 R' F R F'"},
+	{"OLL 2", "F R U R' U' F' Fw R U R' U' Fw'"},
+	{"OLL 3", "Fw R U R' U' Fw' U' F R U R' U' F'"},
+	{"OLL 4", "Fw R U R' U' Fw' U F R U R' U' F'"},
+	{"OLL 5", "Rw' U2 R U R' U Rw"},
+	{"OLL 6", "Rw U2 R' U' R U' Rw'"},
+	{"OLL 7", "Rw U R' U R U2 Rw'"},
+	{"OLL 8", "Lw' U' L U' L' U2 Lw"},
+	{"OLL 9", "R U R' U' R' F R2 U R' U' F'"},
+	{"OLL 10", "R U R' U R' F R F' R U2 R'"},
+	{"OLL 11", "Rw U R' U R' F R F' R U2 Rw'"},
+	{"OLL 12", "M' R' U' R U' R' U2 R U' M"},
+	{"OLL 13", "F U R U' R2 F' R U R U' R'"},
+	{"OLL 14", "R' F R U R' F' R F U' F'"},
+	{"OLL 15", "Lw' U' Lw L' U' L U Lw' U Lw"},
+	{"OLL 16", "Rw U Rw' R U R' U' Rw U' Rw'"},
+	{"OLL 17", "R U R' U R' F R F' U2 R' F R F'"},
+	{"OLL 18", "Rw U R' U R U2 Rw2 U' R U' R' U2 Rw"},
+	{"OLL 19", "M U R U R' U' M' R' F R F'"},
+	{"OLL 20", "Rw U R' U' M2 U R U' R' U' M'"},
+	{"OLL 21", "R U2 R' U' R U R' U' R U' R'"},
+	{"OLL 22", "R U2 R2 U' R2 U' R2 U2 R"},
+	{"OLL 23", "R2 D' R U2 R' D R U2 R"},
+	{"OLL 24", "Rw U R' U' Rw' F R F'"},
+	{"OLL 25", "F' Rw U R' U' Rw' F R"},
+	{"OLL 26", "R U2 R' U' R U' R'"},
+	{"OLL 27", "R U R' U R U2 R'"},
+	{"OLL 28", "Rw U R' U' Rw' R U R U' R'"},
+	{"OLL 29", "R U R' U' R U' R' F' U' F R U R'"},
+	{"OLL 30", "F R' F R2 U' R' U' R U R' F2"},
+	{"OLL 31", "R' U' F U R U' R' F' R"},
+	{"OLL 32", "L U F' U' L' U L F L'"},
+	{"OLL 33", "R U R' U' R' F R F'"},
+	{"OLL 34", "R U R2 U' R' F R U R U' F'"},
+	{"OLL 35", "R U2 R2 F R F' R U2 R'"},
+	{"OLL 36", "L' U' L U' L' U L U L F' L' F"},
+	{"OLL 37", "F R' F' R U R U' R'"},
+	{"OLL 38", "R U R' U R U' R' U' R' F R F'"},
+	{"OLL 39", "L F' L' U' L U F U' L'"},
+	{"OLL 40", "R' F R U R' U' F' U R"},
+	{"OLL 41", "R U R' U R U2 R' F R U R' U' F'"},
+	{"OLL 42", "R' U' R U' R' U2 R F R U R' U' F'"},
+	{"OLL 43", "F' U' L' U L F"},
+	{"OLL 44", "F U R U' R' F'"},
+	{"OLL 45", "F R U R' U' F'"},
+	{"OLL 46", "R' U' R' F R F' U R"},
+	{"OLL 47", "R' U' R' F R F' R' F R F' U R"},
+	{"OLL 48", "F R U R' U' R U R' U' F'"},
+	{"OLL 49", "Rw U' Rw2 U Rw2 U Rw2 U' Rw"},
+	{"OLL 50", "Rw' U Rw2 U' Rw2 U' Rw2 U Rw'"},
+	{"OLL 51", "F U R U' R' U R U' R' F'"},
+	{"OLL 52", "R U R' U R U' B U' B' R'"},
+	{"OLL 53", "Lw' U2 L U L' U' L U L' U Lw"},
+	{"OLL 54", "Rw U2 R' U' R U R' U' R U' Rw'"},
+	{"OLL 55", "R' F R U R U' R2 F' R2 U' R' U R U R'"},
+	{"OLL 56", "Rw' U' Rw U' R' U R U' R' U R Rw' U Rw"},
+	{"OLL 57", "R U R' U' M' U R U' Rw'"},
+}
+
+func (c caseAlgs) parse() []Case {
+	cases := make([]Case, len(c))
+	for i, entry := range c {
+		group, err := cube.ParseNotation(entry[1])
+		if err != nil {
+			panic(err)
+		}
+		moves, err := group.Expand()
+		if err != nil {
+			panic(err)
+		}
+		cases[i] = Case{Name: entry[0], Alg: moves}
+	}
+	return cases
+}
+
+var (
+	// PLL returns the 21 permutation cases of the last layer.
+	PLL = sync.OnceValue(pllAlgs.parse)
+	// OLL returns the 57 orientation cases of the last layer.
+	OLL = sync.OnceValue(ollAlgs.parse)
+)
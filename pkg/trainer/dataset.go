@@ -0,0 +1,100 @@
+package trainer
+
+import (
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/internal/svg"
+	"io"
+)
+
+// LastLayer holds the stickers seen when recognizing a last layer case. Up is
+// read row by row from the back, and every side holds the top row of its face
+// from left to right as seen when facing it.
+type LastLayer struct {
+	Up    string `json:"up"`
+	Front string `json:"front"`
+	Right string `json:"right"`
+	Back  string `json:"back"`
+	Left  string `json:"left"`
+}
+
+// Sample is a labelled last layer case for recognition training.
+type Sample struct {
+	Set      string    `json:"set"`
+	Case     string    `json:"case"`
+	AUF      int       `json:"auf"`
+	Stickers LastLayer `json:"stickers"`
+}
+
+// Dataset returns a sample for every case of the set under every AUF.
+func Dataset(set string, cases []Case) []Sample {
+	samples := make([]Sample, 0, 4*len(cases))
+	for _, c := range cases {
+		for auf := range 4 {
+			cb := cube.NewCube(3)
+			cb.ExecuteMoves(newSetup(c, 0, 0, auf).Moves...)
+			faces := cb.Faces()
+
+			samples = append(samples, Sample{
+				Set:  set,
+				Case: c.Name,
+				AUF:  auf,
+				Stickers: LastLayer{
+					Up:    string(faces.Up),
+					Front: string(faces.Front[:3]),
+					Right: string(faces.Right[:3]),
+					Back:  string(faces.Back[:3]),
+					Left:  string(faces.Left[:3]),
+				},
+			})
+		}
+	}
+	return samples
+}
+
+// LastLayerDataset returns the samples of all OLL and PLL cases.
+func LastLayerDataset() []Sample {
+	return append(Dataset("OLL", OLL()), Dataset("PLL", PLL())...)
+}
+
+const (
+	svgSticker = 20.0
+	svgStrip   = 8.0
+	svgMargin  = 4.0
+)
+
+// RenderSVG draws the last layer from above, with the top row of every side
+// as a strip around the Up face and the back at the top.
+func (ll LastLayer) RenderSVG(w io.Writer) error {
+	return ll.RenderSVGWith(w, cube.Western)
+}
+
+// RenderSVGWith draws the last layer as RenderSVG does, in the fills and
+// patterns of the scheme.
+func (ll LastLayer) RenderSVGWith(w io.Writer, scheme cube.Scheme) error {
+	style := svg.Style{Fills: scheme.Fills, Patterns: scheme.Patterns}
+	size := 3*svgSticker + 2*svgStrip + 2*svgMargin
+	out := svg.NewWriter(w, size, size)
+
+	rect := func(color byte, x, y, width, height float64) {
+		out.Sticker(style, rune(color),
+			svg.Point{X: x, Y: y},
+			svg.Point{X: x + width, Y: y},
+			svg.Point{X: x + width, Y: y + height},
+			svg.Point{X: x, Y: y + height},
+		)
+	}
+
+	face := svgMargin + svgStrip
+	for i := range 3 {
+		along := face + float64(i)*svgSticker
+		rect(ll.Back[2-i], along, svgMargin, svgSticker, svgStrip)
+		rect(ll.Front[i], along, face+3*svgSticker, svgSticker, svgStrip)
+		rect(ll.Left[i], svgMargin, along, svgStrip, svgSticker)
+		rect(ll.Right[2-i], face+3*svgSticker, along, svgStrip, svgSticker)
+	}
+	for i := range 9 {
+		rect(ll.Up[i], face+float64(i%3)*svgSticker, face+float64(i/3)*svgSticker, svgSticker, svgSticker)
+	}
+
+	return out.Close()
+}
@@ -0,0 +1,112 @@
+// Package trainer generates practice setups for algorithm cases.
+package trainer
+
+import (
+	"errors"
+	"github.com/larssont/go-cubic/pkg/cube"
+	"math/rand/v2"
+	"slices"
+)
+
+var (
+	ErrNoCases = errors.New("no cases to train")
+)
+
+// Case is a named algorithm case, such as "T-perm".
+type Case struct {
+	Name string
+	Alg  []cube.Move
+}
+
+type Options struct {
+	AUF      bool       // Random U turns before and after the case
+	Rotation bool       // Random y rotation before the setup
+	Rand     *rand.Rand // Source of randomness, the global one if nil
+}
+
+// Setup is a move sequence that puts a solved cube into a case.
+type Setup struct {
+	Case  string
+	Moves []cube.Move
+
+	// AUF is the number of clockwise U turns needed before the case's
+	// algorithm, and Rotation the number of y rotations applied first.
+	AUF      int
+	Rotation int
+}
+
+// Generator draws setups for a set of cases.
+type Generator struct {
+	cases []Case
+	opts  Options
+}
+
+// NewGenerator returns a generator mixing the given cases at random.
+func NewGenerator(cases []Case, opts Options) (*Generator, error) {
+	if len(cases) == 0 {
+		return nil, ErrNoCases
+	}
+	return &Generator{cases: cases, opts: opts}, nil
+}
+
+func (g *Generator) intN(n int) int {
+	if g.opts.Rand == nil {
+		return rand.IntN(n)
+	}
+	return g.opts.Rand.IntN(n)
+}
+
+// Next returns a setup for a random case.
+func (g *Generator) Next() Setup {
+	return g.Setup(g.cases[g.intN(len(g.cases))])
+}
+
+// Setup returns a setup for the given case: its inverse algorithm, with the
+// random U turns and rotation of the options.
+func (g *Generator) Setup(c Case) Setup {
+	var rotation, preAUF, auf int
+	if g.opts.Rotation {
+		rotation = g.intN(4)
+	}
+	if g.opts.AUF {
+		preAUF, auf = g.intN(4), g.intN(4)
+	}
+	return newSetup(c, rotation, preAUF, auf)
+}
+
+// newSetup returns the setup of a case after the given y rotations, with
+// preAUF U turns before the inverse algorithm and auf U turns needed before
+// the algorithm solves the case.
+func newSetup(c Case, rotation, preAUF, auf int) Setup {
+	var moves []cube.Move
+	moves = append(moves, quarterTurns('y', rotation)...)
+	moves = append(moves, quarterTurns('U', preAUF)...)
+	moves = append(moves, cube.ReverseMoves(c.Alg)...)
+	moves = append(moves, quarterTurns('U', 4-auf)...)
+
+	normalized, _ := cube.NormalizeMoves(moves)
+	return Setup{Case: c.Name, Moves: normalized, AUF: auf, Rotation: rotation}
+}
+
+func quarterTurns(operator rune, n int) []cube.Move {
+	if n%4 == 0 {
+		return nil
+	}
+	m := cube.Move{Operator: operator, Rotations: n}
+	m.Normalize()
+	return []cube.Move{m}
+}
+
+// Playback returns the setup followed by the U turns and the algorithm of
+// the case that solve it, and the U turn left after the algorithm, for
+// rendering or playing the case back.
+func (s Setup) Playback(c Case) cube.Playback {
+	alg := append(quarterTurns('U', s.AUF), c.Alg...)
+	for n := range 4 {
+		p := cube.Playback{Dimension: 3, Setup: s.Moves, Alg: append(slices.Clone(alg), quarterTurns('U', n)...)}
+		if end, err := p.Cube(-1); err == nil && end.IsSolved() {
+			return p
+		}
+	}
+	return cube.Playback{Dimension: 3, Setup: s.Moves, Alg: alg}
+}
@@ -0,0 +1,111 @@
+package widget
+
+import (
+	"github.com/larssont/go-cubic/pkg/cube"
+	"math"
+)
+
+// MinDrag is the shortest drag that turns a layer, as a share of the side of
+// a sticker. Shorter drags are taps.
+const MinDrag = 0.5
+
+// Drag returns the moves of a drag of the pointer from one point of the
+// widget to another: the layer under the start turns the way the drag goes
+// along the face, such as U for a drag to the left along the top row of the
+// front. It returns false for drags that start off the cube or are shorter
+// than MinDrag. Layers inside big cubes turn as two wide moves, such as 3Rw
+// 2Rw', as the notation of single inner layers varies.
+func (w *Widget) Drag(from, to Point) ([]cube.Move, bool) {
+	s, ok := w.StickerAt(from)
+	if !ok {
+		return nil, false
+	}
+	n := w.Cube.Dimension()
+	row, col := s.Index/n, s.Index%n
+	_, across, down := frameOf(s.Face, n)
+
+	// The drag in units of the columns and rows of the sticker, solving
+	// drag = a*across + b*down on the screen.
+	p := s.Polygon
+	ax, ay := p[1].X-p[0].X, p[1].Y-p[0].Y
+	dx, dy := p[3].X-p[0].X, p[3].Y-p[0].Y
+	det := ax*dy - ay*dx
+	if det == 0 {
+		return nil, false
+	}
+	mx, my := to.X-from.X, to.Y-from.Y
+	a := (mx*dy - my*dx) / det
+	b := (ax*my - ay*mx) / det
+	if max(math.Abs(a), math.Abs(b)) < MinDrag {
+		return nil, false
+	}
+	dir := across
+	if math.Abs(b) > math.Abs(a) {
+		dir, a = down, b
+	}
+	if a < 0 {
+		dir = [3]float64{-dir[0], -dir[1], -dir[2]}
+	}
+
+	// Turning about the normal of the face crossed with the drag carries the
+	// sticker along the drag, counterclockwise about the axis.
+	normal := cross(down, across)
+	axisVec := cross(normal, dir)
+	axis := 0
+	for k := range axisVec {
+		if math.Abs(axisVec[k]) > math.Abs(axisVec[axis]) {
+			axis = k
+		}
+	}
+	// The layer is the coordinate of the sticker along the axis, from the
+	// middle of its cell.
+	corner, _, _ := frameOf(s.Face, n)
+	var center [3]float64
+	for k := range center {
+		center[k] = corner[k] + (float64(col)+0.5)*across[k] + (float64(row)+0.5)*down[k]
+	}
+	layer := min(int(center[axis]), n-1)
+	return layerMoves(n, axis, layer, axisVec[axis] > 0), true
+}
+
+// cross returns the cross product of two vectors.
+func cross(a, b [3]float64) [3]float64 {
+	return [3]float64{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+}
+
+// axisFaces are the faces at the positive and negative ends of the axes x,
+// y and z, and the middle slice, which turns as the face of its side.
+var axisFaces = [3]struct {
+	positive, negative, middle rune
+	middleSide                 int // 1 if the middle turns as the positive face
+}{
+	{'R', 'L', 'M', -1},
+	{'U', 'D', 'E', -1},
+	{'F', 'B', 'S', 1},
+}
+
+// layerMoves returns the moves that turn a layer of a cube of dimension n,
+// counted from the negative end of the axis, a quarter counterclockwise
+// about the axis if ccw, else clockwise.
+func layerMoves(n, axis, layer int, ccw bool) []cube.Move {
+	faces := axisFaces[axis]
+	max := n - 1
+	// Face turns are clockwise seen from their face, which is clockwise
+	// about the axis for the positive face and counterclockwise for the
+	// negative one.
+	if n%2 == 1 && n > 1 && layer == max/2 {
+		return []cube.Move{{Operator: faces.middle, Rotations: 1, Inverted: ccw == (faces.middleSide > 0)}}
+	}
+	op, depth, inverted := faces.positive, max-layer+1, ccw
+	if layer < n/2 {
+		op, depth, inverted = faces.negative, layer+1, !ccw
+	}
+	if depth == 1 {
+		return []cube.Move{{Operator: op, Rotations: 1, Inverted: inverted}}
+	}
+	undo := cube.Move{Operator: op, Rotations: 1, Inverted: !inverted}
+	if depth > 2 {
+		undo.Slices, undo.Wide = depth-1, true
+	}
+	return []cube.Move{{Operator: op, Slices: depth, Wide: true, Rotations: 1, Inverted: inverted}, undo}
+}
@@ -0,0 +1,293 @@
+// Package widget draws interactive cubes for native Go GUI toolkits, such as
+// Fyne and Gio, to embed. A Widget lays out the stickers of a cube as a net
+// or an isometric view in a rectangle of the toolkit, draws them on any
+// Canvas that fills polygons or into an image a frame, and turns the points
+// and drags of the pointer into stickers and moves:
+//
+//	w := widget.New(c, widget.Isometric)
+//	w.Resize(400, 300)
+//	frame := w.Image()
+//	...
+//	if moves, ok := w.Drag(press, release); ok {
+//		c.ExecuteMoves(moves...)
+//	}
+package widget
+
+import (
+	"github.com/larssont/go-cubic/pkg/cube"
+	"github.com/larssont/go-cubic/pkg/internal/raster"
+	"github.com/larssont/go-cubic/pkg/internal/svg"
+	"image"
+	"image/color"
+	"math"
+)
+
+// View is how a Widget shows the cube.
+type View int
+
+const (
+	Net       View = iota // Every face, unfolded as cube.RenderSVG draws them
+	Isometric             // The Up, Front and Right faces, as seen from above the front right corner
+)
+
+// Point is a point in the coordinates of the toolkit, with y down.
+type Point struct {
+	X, Y float64
+}
+
+// Canvas is what a Widget draws on, such as the painter of a toolkit: it
+// fills convex polygons, later ones over earlier ones.
+type Canvas interface {
+	FillPolygon(fill color.Color, points []Point)
+}
+
+// Sticker is a sticker of the cube as the widget lays it out: its polygon,
+// with its corners in the order of its top left, top right, bottom right and
+// bottom left as the face is read, and its color.
+type Sticker struct {
+	cube.Sticker
+	Polygon [4]Point
+	Color   color.Color
+}
+
+// Center returns the middle of the sticker.
+func (s Sticker) Center() Point {
+	var c Point
+	for _, p := range s.Polygon {
+		c.X += p.X / 4
+		c.Y += p.Y / 4
+	}
+	return c
+}
+
+// Contains reports whether the point is on the sticker.
+func (s Sticker) Contains(p Point) bool {
+	sign := 0.0
+	for i, a := range s.Polygon {
+		b := s.Polygon[(i+1)%4]
+		cross := (b.X-a.X)*(p.Y-a.Y) - (b.Y-a.Y)*(p.X-a.X)
+		if cross*sign < 0 {
+			return false
+		}
+		if cross != 0 {
+			sign = cross
+		}
+	}
+	return true
+}
+
+var (
+	// Body is the color of the plastic between the stickers.
+	Body color.Color = color.Black
+
+	// Hidden is the color of the stickers that Shown leaves out.
+	Hidden color.Color = color.RGBA{0x40, 0x40, 0x40, 0xff}
+)
+
+// stickerShare is the share of the side of a cell that its sticker covers,
+// the rest showing the body.
+const stickerShare = 0.88
+
+// Widget lays out and draws a cube. Its fields may be changed between frames,
+// such as the cube after moves.
+type Widget struct {
+	Cube *cube.Cube
+	View View
+
+	// Shown, if set, tells the stickers drawn in their colors, with the rest
+	// Hidden, as for cube.RenderMaskedSVG.
+	Shown func(cube.Sticker) bool
+
+	width, height float64
+	frame         *raster.Canvas
+}
+
+// New returns a widget of the cube in the view, of a size of 0 by 0 until it
+// is resized.
+func New(c *cube.Cube, view View) *Widget {
+	return &Widget{Cube: c, View: view}
+}
+
+// Resize sets the size of the rectangle of the widget, in which the cube is
+// centered as large as it fits.
+func (w *Widget) Resize(width, height float64) {
+	w.width, w.height = width, height
+}
+
+// Size returns the size of the widget.
+func (w *Widget) Size() (width, height float64) {
+	return w.width, w.height
+}
+
+// Stickers returns the stickers shown, laid out in the rectangle of the
+// widget.
+func (w *Widget) Stickers() []Sticker {
+	n := w.Cube.Dimension()
+	faces := w.Cube.Faces()
+	scheme := w.Cube.Scheme()
+
+	var stickers []Sticker
+	for f, colors := range faces.All() {
+		for i, c := range *colors {
+			s := cube.Sticker{Face: cube.Face(f), Index: i}
+			polygon, ok := w.cell(s, i/n, i%n)
+			if !ok {
+				continue
+			}
+			var fill color.Color = raster.ParseColor(scheme.Fill(c))
+			if w.Shown != nil && !w.Shown(s) {
+				fill = Hidden
+			}
+			stickers = append(stickers, Sticker{s, polygon, fill})
+		}
+	}
+	return w.fit(stickers)
+}
+
+// cell returns the polygon of a sticker in the units of the view, and false
+// for stickers the view does not show.
+func (w *Widget) cell(s cube.Sticker, row, col int) ([4]Point, bool) {
+	n := w.Cube.Dimension()
+	if w.View == Net {
+		r := cube.Net{Dimension: n, Layout: cube.CrossLayout}.Rect(s.Face, row, col)
+		return [4]Point{{r.X, r.Y}, {r.X + r.Size, r.Y}, {r.X + r.Size, r.Y + r.Size}, {r.X, r.Y + r.Size}}, true
+	}
+	if s.Face != cube.FaceUp && s.Face != cube.FaceFront && s.Face != cube.FaceRight {
+		return [4]Point{}, false
+	}
+	// The corner of the sticker at its top left, and the directions of its
+	// columns and rows, in the coordinates of the cube from its left, bottom
+	// and back corner.
+	corner, across, down := frameOf(s.Face, n)
+	var polygon [4]Point
+	for i, d := range [4][2]float64{{0, 0}, {1, 0}, {1, 1}, {0, 1}} {
+		var p [3]float64
+		for k := range p {
+			p[k] = corner[k] + (float64(col)+d[0])*across[k] + (float64(row)+d[1])*down[k]
+		}
+		polygon[i] = isometric(p)
+	}
+	return polygon, true
+}
+
+// isometric projects a point of the cube, with x to the right, y up and z to
+// the front, as seen from above the front right corner.
+func isometric(p [3]float64) Point {
+	return Point{(p[0] - p[2]) * math.Cos(math.Pi/6), (p[0]+p[2])*math.Sin(math.Pi/6) - p[1]}
+}
+
+// frameOf returns the corner of a face of the cube at the top left of its
+// stickers as they are read, and the directions of its columns and rows, in
+// the coordinates of the cube from 0 to n, x to the right, y up and z to the
+// front.
+func frameOf(f cube.Face, n int) (corner, across, down [3]float64) {
+	m := float64(n)
+	switch f {
+	case cube.FaceUp:
+		return [3]float64{0, m, 0}, [3]float64{1, 0, 0}, [3]float64{0, 0, 1}
+	case cube.FaceDown:
+		return [3]float64{0, 0, m}, [3]float64{1, 0, 0}, [3]float64{0, 0, -1}
+	case cube.FaceLeft:
+		return [3]float64{0, m, 0}, [3]float64{0, 0, 1}, [3]float64{0, -1, 0}
+	case cube.FaceRight:
+		return [3]float64{m, m, m}, [3]float64{0, 0, -1}, [3]float64{0, -1, 0}
+	case cube.FaceBack:
+		return [3]float64{m, m, 0}, [3]float64{-1, 0, 0}, [3]float64{0, -1, 0}
+	}
+	return [3]float64{0, m, m}, [3]float64{1, 0, 0}, [3]float64{0, -1, 0}
+}
+
+// fit scales and moves the stickers to the middle of the widget, as large as
+// they fit with a margin.
+func (w *Widget) fit(stickers []Sticker) []Sticker {
+	if len(stickers) == 0 {
+		return nil
+	}
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, s := range stickers {
+		for _, p := range s.Polygon {
+			minX, maxX = min(minX, p.X), max(maxX, p.X)
+			minY, maxY = min(minY, p.Y), max(maxY, p.Y)
+		}
+	}
+	// The net has margins of its own, and the isometric view a margin of a
+	// twentieth of its size.
+	if w.View == Net {
+		width, height := cube.SVGSize(w.Cube.Dimension())
+		minX, minY, maxX, maxY = 0, 0, width, height
+	} else {
+		pad := max(maxX-minX, maxY-minY) / 20
+		minX, minY, maxX, maxY = minX-pad, minY-pad, maxX+pad, maxY+pad
+	}
+	scale := max(0, min(w.width/(maxX-minX), w.height/(maxY-minY)))
+	offX := (w.width - (maxX-minX)*scale) / 2
+	offY := (w.height - (maxY-minY)*scale) / 2
+	for i := range stickers {
+		for j, p := range stickers[i].Polygon {
+			stickers[i].Polygon[j] = Point{offX + (p.X-minX)*scale, offY + (p.Y-minY)*scale}
+		}
+	}
+	return stickers
+}
+
+// Draw draws the cube on a canvas: the body under every sticker and the
+// sticker over it.
+func (w *Widget) Draw(c Canvas) {
+	for _, s := range w.Stickers() {
+		c.FillPolygon(Body, s.Polygon[:])
+		c.FillPolygon(s.Color, shrink(s.Polygon[:], stickerShare))
+	}
+}
+
+// shrink moves the points towards their centroid by factor.
+func shrink(points []Point, factor float64) []Point {
+	var c Point
+	for _, p := range points {
+		c.X += p.X / float64(len(points))
+		c.Y += p.Y / float64(len(points))
+	}
+	out := make([]Point, len(points))
+	for i, p := range points {
+		out[i] = Point{c.X + (p.X-c.X)*factor, c.Y + (p.Y-c.Y)*factor}
+	}
+	return out
+}
+
+// Image draws a frame of the widget, of its size rounded up to pixels with
+// a transparent background, for toolkits that show images. The image is
+// reused by the next frame of the same size, so it must not be kept.
+func (w *Widget) Image() *image.RGBA {
+	width, height := int(math.Ceil(w.width)), int(math.Ceil(w.height))
+	if w.frame == nil || w.frame.Image().Bounds() != image.Rect(0, 0, width, height) {
+		w.frame = raster.NewCanvas(w.width, w.height, 1)
+	} else {
+		w.frame.Clear()
+	}
+	w.Draw(frameCanvas{w.frame})
+	return w.frame.Image()
+}
+
+// frameCanvas draws a widget on a raster canvas.
+type frameCanvas struct {
+	*raster.Canvas
+}
+
+func (c frameCanvas) FillPolygon(fill color.Color, points []Point) {
+	converted := make([]svg.Point, len(points))
+	for i, p := range points {
+		converted[i] = svg.Point{X: p.X, Y: p.Y}
+	}
+	c.Fill(fill, converted...)
+}
+
+// StickerAt returns the sticker at a point of the widget, such as under the
+// pointer, and false for points off the cube.
+func (w *Widget) StickerAt(p Point) (Sticker, bool) {
+	for _, s := range w.Stickers() {
+		if s.Contains(p) {
+			return s, true
+		}
+	}
+	return Sticker{}, false
+}